@@ -0,0 +1,272 @@
+// Package auth adalah satu-satunya tempat token login ditandatangani dan diverifikasi.
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+	"warehouse-api/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// devSecret hanya dipakai di luar mode production bila tidak ada kunci yang dikonfigurasi
+const devSecret = "supersecretkey"
+
+// minSecretLen adalah panjang minimal JWT_SECRET di production (256 bit, sesuai HS256)
+const minSecretLen = 32
+
+var (
+	ErrNoSigningKey = errors.New("kunci JWT belum dikonfigurasi: isi JWT_PRIVATE_KEY_FILE (atau JWT_SECRET) untuk APP_ENV=production")
+	ErrWeakSecret   = fmt.Errorf("JWT_SECRET terlalu lemah untuk APP_ENV=production: minimal %d byte dan bukan secret development", minSecretLen)
+)
+
+// Key adalah satu kunci penandatangan/verifikasi beserta algoritmanya.
+// ID (kid) kunci asimetris adalah thumbprint RFC 7638 sehingga stabil tanpa konfigurasi tambahan.
+type Key struct {
+	ID     string
+	method jwt.SigningMethod
+	sign   interface{} // nil untuk kunci yang hanya memverifikasi
+	verify interface{}
+	// notAfter membatasi kunci verifikasi lama; token yang diperiksa setelahnya ditolak
+	notAfter time.Time
+}
+
+// Algorithm mengembalikan nama algoritma JWT (RS256, EdDSA atau HS256)
+func (k *Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+// NewHMACKey membuat kunci HS256; tidak pernah dipublikasikan lewat JWKS
+func NewHMACKey(secret []byte) *Key {
+	sum := sha256.Sum256(secret)
+	return &Key{
+		ID:     "hs-" + base64.RawURLEncoding.EncodeToString(sum[:6]),
+		method: jwt.SigningMethodHS256,
+		sign:   secret,
+		verify: secret,
+	}
+}
+
+// NewPrivateKey membungkus kunci privat RSA (minimal 2048 bit, RS256) atau Ed25519 (EdDSA)
+func NewPrivateKey(priv crypto.PrivateKey) (*Key, error) {
+	switch p := priv.(type) {
+	case *rsa.PrivateKey:
+		key, err := NewPublicKey(&p.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		key.sign = p
+		return key, nil
+	case ed25519.PrivateKey:
+		key, err := NewPublicKey(p.Public())
+		if err != nil {
+			return nil, err
+		}
+		key.sign = p
+		return key, nil
+	default:
+		return nil, fmt.Errorf("tipe kunci privat %T tidak didukung, gunakan RSA atau Ed25519", priv)
+	}
+}
+
+// NewPublicKey membuat kunci yang hanya memverifikasi (mis. kunci lama saat rotasi)
+func NewPublicKey(pub crypto.PublicKey) (*Key, error) {
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		if p.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("kunci RSA minimal 2048 bit, didapat %d", p.N.BitLen())
+		}
+		return &Key{ID: thumbprint(rsaJWK(p)), method: jwt.SigningMethodRS256, verify: p}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: thumbprint(ed25519JWK(p)), method: jwt.SigningMethodEdDSA, verify: p}, nil
+	default:
+		return nil, fmt.Errorf("tipe kunci publik %T tidak didukung, gunakan RSA atau Ed25519", pub)
+	}
+}
+
+// ParseKeyPEM membaca kunci privat (PKCS#1/PKCS#8) atau publik (PKIX/PKCS#1) dari PEM
+func ParseKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("format PEM tidak valid")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewPrivateKey(priv)
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewPrivateKey(priv)
+	case "RSA PUBLIC KEY":
+		pub, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewPublicKey(pub)
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewPublicKey(pub)
+	default:
+		return nil, fmt.Errorf("blok PEM %q tidak didukung", block.Type)
+	}
+}
+
+func loadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// LoadKeySet menyiapkan kunci dari konfigurasi:
+//   - PrivateKeyFile terisi: token ditandatangani RS256/EdDSA; VerifyKeyFiles tetap diterima untuk
+//     token yang terbit sebelum rotasi. Secret hanya diterima sampai LegacySecretUntil, yang tidak
+//     boleh lebih dari RefreshTTL ke depan; tanpa batas itu Secret diabaikan
+//   - hanya Secret: HS256
+//   - tidak ada kunci: gagal di production, selain itu memakai secret development dengan peringatan
+//
+// Di production Secret minimal minSecretLen byte dan tidak boleh sama dengan secret development.
+func LoadKeySet(cfg config.Auth) (*KeySet, error) {
+	if cfg.Production && len(cfg.Secret) > 0 && (len(cfg.Secret) < minSecretLen || string(cfg.Secret) == devSecret) {
+		return nil, ErrWeakSecret
+	}
+
+	var verify []*Key
+	for _, path := range cfg.VerifyKeyFiles {
+		key, err := loadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verify = append(verify, key)
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		signing, err := loadKeyFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if signing.sign == nil {
+			return nil, fmt.Errorf("%s: JWT_PRIVATE_KEY_FILE harus berisi kunci privat", cfg.PrivateKeyFile)
+		}
+		legacy, err := legacySecretKey(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if legacy != nil {
+			verify = append(verify, legacy)
+		}
+		return NewKeySet(signing, verify...), nil
+	}
+
+	if len(cfg.Secret) > 0 {
+		return NewKeySet(NewHMACKey(cfg.Secret), verify...), nil
+	}
+	if cfg.Production {
+		return nil, ErrNoSigningKey
+	}
+	log.Println("PERINGATAN: JWT_PRIVATE_KEY_FILE dan JWT_SECRET kosong, memakai secret development. Jangan dipakai di production.")
+	return NewKeySet(NewHMACKey([]byte(devSecret)), verify...), nil
+}
+
+// legacySecretKey mengembalikan Secret sebagai kunci HS256 verifikasi-saja selama masa migrasi
+// ke kunci asimetris, atau nil bila masa itu tidak diaktifkan atau sudah lewat
+func legacySecretKey(cfg config.Auth) (*Key, error) {
+	if len(cfg.Secret) == 0 {
+		return nil, nil
+	}
+	if cfg.LegacySecretUntil.IsZero() {
+		log.Println("PERINGATAN: JWT_SECRET diabaikan karena JWT_PRIVATE_KEY_FILE diisi. Isi JWT_LEGACY_SECRET_UNTIL untuk tetap menerima token HS256 lama selama migrasi.")
+		return nil, nil
+	}
+	if limit := time.Now().Add(cfg.RefreshTTL); cfg.LegacySecretUntil.After(limit) {
+		return nil, fmt.Errorf("JWT_LEGACY_SECRET_UNTIL %s melewati batas REFRESH_TOKEN_TTL (%s)",
+			cfg.LegacySecretUntil.Format(time.RFC3339), limit.Format(time.RFC3339))
+	}
+	if !time.Now().Before(cfg.LegacySecretUntil) {
+		log.Println("PERINGATAN: JWT_LEGACY_SECRET_UNTIL sudah lewat; JWT_SECRET tidak lagi diterima dan bisa dihapus.")
+		return nil, nil
+	}
+	key := NewHMACKey(cfg.Secret)
+	key.sign = nil
+	key.notAfter = cfg.LegacySecretUntil
+	return key, nil
+}
+
+// JWK adalah representasi publik kunci untuk /.well-known/jwks.json
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func rsaJWK(p *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(p.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.E)).Bytes()),
+	}
+}
+
+func ed25519JWK(p ed25519.PublicKey) JWK {
+	return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(p)}
+}
+
+func publicJWK(k *Key) (JWK, bool) {
+	var jwk JWK
+	switch pub := k.verify.(type) {
+	case *rsa.PublicKey:
+		jwk = rsaJWK(pub)
+	case ed25519.PublicKey:
+		jwk = ed25519JWK(pub)
+	default:
+		return JWK{}, false
+	}
+	jwk.Kid, jwk.Alg, jwk.Use = k.ID, k.Algorithm(), "sig"
+	return jwk, true
+}
+
+// thumbprint menghitung JWK thumbprint RFC 7638 (member wajib, urut leksikografis, tanpa spasi)
+func thumbprint(jwk JWK) string {
+	var canonical string
+	if jwk.Kty == "RSA" {
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	} else {
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s"}`, jwk.Crv, jwk.Kty, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrTokenInvalid = errors.New("token tidak valid")
+
+// Claims adalah isi access token
+type Claims struct {
+	UserID     int
+	Username   string
+	Role       string
+	Visibility string // claim vis: "all" atau "own", kosong untuk token lama
+	ID         string // jti
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+}
+
+// KeySet menandatangani token dengan satu kunci aktif dan memverifikasi dengan semua kunci
+// yang masih diterima (kunci aktif + kunci lama selama rotasi), dipilih lewat header kid.
+type KeySet struct {
+	signing *Key
+	verify  map[string]*Key
+	methods []string
+}
+
+func NewKeySet(signing *Key, verify ...*Key) *KeySet {
+	ks := &KeySet{signing: signing, verify: map[string]*Key{}}
+	seen := map[string]bool{}
+	for _, k := range append([]*Key{signing}, verify...) {
+		if _, dup := ks.verify[k.ID]; dup {
+			continue
+		}
+		ks.verify[k.ID] = k
+		if !seen[k.Algorithm()] {
+			seen[k.Algorithm()] = true
+			ks.methods = append(ks.methods, k.Algorithm())
+		}
+	}
+	return ks
+}
+
+// SigningKeyID adalah kid kunci aktif
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
+
+func (ks *KeySet) Sign(c Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method, jwt.MapClaims{
+		"user_id":  c.UserID,
+		"username": c.Username,
+		"role":     c.Role,
+		"vis":      c.Visibility,
+		"jti":      c.ID,
+		"iat":      c.IssuedAt.Unix(),
+		"exp":      c.ExpiresAt.Unix(),
+	})
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.sign)
+}
+
+// Verify memeriksa tanda tangan, algoritma (harus sama dengan algoritma kunci kid) dan masa berlaku.
+// Token HS256 tanpa kid (terbit sebelum kid dipakai) diterima bila ada tepat satu kunci HMAC.
+func (ks *KeySet) Verify(tokenString string) (*Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, ks.keyFunc, jwt.WithValidMethods(ks.methods), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("%w: user_id tidak ada", ErrTokenInvalid)
+	}
+	c := &Claims{UserID: int(userID)}
+	c.Username, _ = claims["username"].(string)
+	c.ID, _ = claims["jti"].(string)
+	c.Visibility, _ = claims["vis"].(string)
+	if c.Role, ok = claims["role"].(string); !ok {
+		c.Role = "staff"
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		c.IssuedAt = iat.Time
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		c.ExpiresAt = exp.Time
+	}
+	return c, nil
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	var key *Key
+	if kid != "" {
+		key = ks.verify[kid]
+	} else {
+		for _, k := range ks.verify {
+			if k.method == jwt.SigningMethodHS256 {
+				if key != nil {
+					return nil, errors.New("kid wajib diisi")
+				}
+				key = k
+			}
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+	if token.Method.Alg() != key.Algorithm() {
+		return nil, fmt.Errorf("algoritma %s tidak cocok dengan kunci %s", token.Method.Alg(), key.ID)
+	}
+	if !key.notAfter.IsZero() && !time.Now().Before(key.notAfter) {
+		return nil, fmt.Errorf("kunci %s sudah tidak diterima sejak %s", key.ID, key.notAfter.Format(time.RFC3339))
+	}
+	return key.verify, nil
+}
+
+// JWKS mengembalikan kunci publik asimetris yang masih diterima, kunci aktif lebih dulu.
+// Kunci HMAC tidak pernah dipublikasikan.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	ids := make([]string, 0, len(ks.verify))
+	for id := range ks.verify {
+		if id != ks.signing.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range append([]string{ks.signing.ID}, ids...) {
+		if jwk, ok := publicJWK(ks.verify[id]); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
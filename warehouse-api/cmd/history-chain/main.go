@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"warehouse-api/config"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+)
+
+// Memeriksa hash chain riwayat stok dan melaporkan link rusak pertama. Keluar dengan kode 1 bila rusak.
+//
+//	go run ./cmd/history-chain              # verifikasi semua barang
+//	go run ./cmd/history-chain -barang 12   # verifikasi satu barang
+//	go run ./cmd/history-chain -seal        # seal riwayat lama (sebelum migrasi 020) sekali, lalu verifikasi
+//
+// HISTORY_CHAIN_KEY harus sama dengan kunci server. Seal hanya menyentuh barang yang belum ditandai
+// di-seal dan belum punya anchor; barang yang sudah di-seal tidak pernah di-hash ulang.
+func main() {
+	barangID := flag.Int("barang", 0, "ID barang yang diperiksa (0 = semua)")
+	seal := flag.Bool("seal", false, "Beri hash riwayat barang yang belum di-seal sebelum verifikasi")
+	flag.Parse()
+
+	config.ConnectDB()
+	defer config.DB.Close()
+
+	chainKey, err := config.HistoryChainKey()
+	if err != nil {
+		fatalf("Gagal memuat kunci hash chain: %v", err)
+	}
+	repositories.SetHistoryChainKey(chainKey)
+
+	repo := repositories.NewStokRepository(config.DB)
+	if *seal {
+		barang, baris, err := repo.SealHistory()
+		if err != nil {
+			fatalf("Gagal men-seal riwayat stok: %v", err)
+		}
+		fmt.Printf("Di-seal     : %d baris dari %d barang\n", baris, barang)
+	}
+
+	report, err := services.VerifyHistoryChain(repo, *barangID)
+	if err != nil {
+		fatalf("Gagal memverifikasi riwayat stok: %v", err)
+	}
+
+	fmt.Printf("Barang      : %d\n", report.Barang)
+	fmt.Printf("Baris       : %d\n", report.Baris)
+	fmt.Printf("Belum seal  : %d\n", report.BelumDiseal)
+	if !report.Valid {
+		fmt.Printf("\nHash chain RUSAK pada history_stok id %d (barang %d): %s\n",
+			report.Rusak.HistoryID, report.Rusak.BarangID, report.Rusak.Alasan)
+		os.Exit(1)
+	}
+	if report.BelumDiseal > 0 {
+		fmt.Println("\nHash chain utuh; sebagian riwayat lama belum di-seal (jalankan dengan -seal)")
+		return
+	}
+	fmt.Println("\nHash chain utuh")
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"warehouse-api/config"
+	"warehouse-api/ctxkeys"
+	"warehouse-api/documents"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+// Memuat saldo awal stok saat go-live dari file CSV/XLSX (kolom kode_barang, qty, harga opsional).
+//
+//	go run ./cmd/saldo-awal -file saldo_awal.csv -dry-run
+//	go run ./cmd/saldo-awal -file saldo_awal.csv
+func main() {
+	file := flag.String("file", "", "File saldo awal (.csv atau .xlsx)")
+	username := flag.String("user", "admin", "Username yang dicatat pada riwayat stok")
+	force := flag.Bool("force", false, "Timpa saldo awal barang yang sudah pernah dimuat")
+	dryRun := flag.Bool("dry-run", false, "Validasi saja tanpa menyimpan")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fatalf("Gagal membaca file: %v", err)
+	}
+	rows, err := documents.ReadSpreadsheet(data, format)
+	if err != nil {
+		fatalf("Gagal membaca %s: %v", *file, err)
+	}
+
+	config.ConnectDB()
+	defer config.DB.Close()
+
+	// Saldo awal menyambung hash chain riwayat stok, jadi kuncinya harus sama dengan server
+	chainKey, err := config.HistoryChainKey()
+	if err != nil {
+		fatalf("Gagal memuat kunci hash chain: %v", err)
+	}
+	repositories.SetHistoryChainKey(chainKey)
+
+	user, err := repositories.NewUserRepository(config.DB).GetByUsername(*username)
+	if err != nil {
+		fatalf("User %s tidak ditemukan", *username)
+	}
+
+	service := services.NewSaldoAwalService(config.DB, repositories.NewStokRepository(config.DB), repositories.NewBarangRepository(config.DB))
+	// Audit log mencatat user yang sama dengan pelaku di history_stok; CLI membaca semua data
+	ctx := context.WithValue(ctxkeys.WithSystem(context.Background()), ctxkeys.UserIDKey, user.ID)
+	result, err := service.Load(ctx, rows, format, models.SaldoAwalOptions{UserID: user.ID, Force: *force, DryRun: *dryRun})
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	printSummary(result)
+	if result.Failed > 0 {
+		fmt.Printf("\nSaldo awal dibatalkan: %d baris gagal, tidak ada data yang disimpan\n", result.Failed)
+		os.Exit(1)
+	}
+	if result.DryRun {
+		fmt.Println("\nValidasi berhasil (dry-run), tidak ada data yang disimpan")
+		return
+	}
+	fmt.Println("\nSaldo awal stok berhasil dimuat")
+}
+
+func printSummary(result *models.SaldoAwalResult) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Baris\tKode\tNama\tQty\tHarga\tNilai\tStok Sebelum\tStok Sesudah\t")
+	for _, l := range result.Items {
+		kode := l.KodeBarang
+		if l.Replaced {
+			kode += " (force)"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t\n", l.Row, kode, l.NamaBarang,
+			utils.FormatNumberID(float64(l.Qty), 0), utils.FormatNumberID(l.Harga, 2), utils.FormatNumberID(l.Nilai, 2),
+			l.StokSebelum, l.StokSesudah)
+	}
+	tw.Flush()
+
+	for _, e := range result.Errors {
+		fmt.Printf("Baris %d (%s): %s\n", e.Row, e.KodeBarang, e.Error)
+	}
+
+	fmt.Printf("\nTotal baris : %d\n", result.TotalRows)
+	fmt.Printf("Dimuat      : %d\n", result.Loaded)
+	fmt.Printf("Gagal       : %d\n", result.Failed)
+	fmt.Printf("Total qty   : %s\n", utils.FormatNumberID(float64(result.TotalQty), 0))
+	fmt.Printf("Total nilai : %s\n", utils.FormatRupiah(result.TotalNilai))
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log"
 	"warehouse-api/config"
 	"warehouse-api/database/seeders"
+	"warehouse-api/repositories"
 )
 
 func main() {
@@ -16,6 +18,12 @@ func main() {
 	config.ConnectDB()
     defer config.DB.Close()
 
+    chainKey, err := config.HistoryChainKey()
+    if err != nil {
+        log.Fatalf("Gagal memuat kunci hash chain: %v", err)
+    }
+    repositories.SetHistoryChainKey(chainKey)
+
     if *runSeed {
         runSeeders()
         return
@@ -29,6 +37,11 @@ func runSeeders() {
     
     seeders.SeedUsers(config.DB)
     seeders.SeedBarang(config.DB)
+
+    // Riwayat stok seed ditulis langsung lewat SQL, beri hash chain agar lolos verifikasi
+    if _, _, err := repositories.NewStokRepository(config.DB).SealHistory(); err != nil {
+        fmt.Printf("Gagal seal riwayat stok: %v\n", err)
+    }
     
     fmt.Println("--- Database Seeding Completed ---")
 }
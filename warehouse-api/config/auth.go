@@ -0,0 +1,71 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Auth berisi pengaturan kunci dan umur token login
+type Auth struct {
+	// PrivateKeyFile adalah kunci RSA/Ed25519 (PEM) untuk menandatangani token baru
+	PrivateKeyFile string
+	// VerifyKeyFiles adalah kunci lama (PEM publik atau privat) yang masih diterima selama rotasi
+	VerifyKeyFiles []string
+	// Secret dipakai untuk HS256 bila PrivateKeyFile kosong, atau hanya untuk verifikasi token lama
+	Secret []byte
+	// LegacySecretUntil adalah batas waktu Secret masih diterima untuk verifikasi setelah pindah ke
+	// PrivateKeyFile; nol berarti Secret diabaikan begitu PrivateKeyFile diisi
+	LegacySecretUntil time.Time
+	Production        bool
+	AccessTTL         time.Duration
+	RefreshTTL        time.Duration
+}
+
+// LoadAuth membaca JWT_PRIVATE_KEY_FILE, JWT_VERIFY_KEY_FILES (dipisah koma), JWT_SECRET,
+// JWT_LEGACY_SECRET_UNTIL (RFC 3339), APP_ENV, ACCESS_TOKEN_TTL (default 15m) dan
+// REFRESH_TOKEN_TTL (default 168h)
+func LoadAuth() Auth {
+	var verify []string
+	for _, f := range strings.Split(os.Getenv("JWT_VERIFY_KEY_FILES"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			verify = append(verify, f)
+		}
+	}
+	return Auth{
+		PrivateKeyFile:    strings.TrimSpace(os.Getenv("JWT_PRIVATE_KEY_FILE")),
+		VerifyKeyFiles:    verify,
+		Secret:            []byte(os.Getenv("JWT_SECRET")),
+		LegacySecretUntil: envTime("JWT_LEGACY_SECRET_UNTIL"),
+		Production:        os.Getenv("APP_ENV") == "production",
+		AccessTTL:         envDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTTL:        envDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+	}
+}
+
+func envTime(key string) time.Time {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		log.Printf("%s tidak valid (%q), diabaikan", key, v)
+		return time.Time{}
+	}
+	return t
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("%s tidak valid (%q), memakai default %s", key, v, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,27 @@
+package config
+
+import "os"
+
+// Company berisi identitas perusahaan yang dicetak di kop dokumen (faktur, surat jalan, dll)
+type Company struct {
+	Name    string
+	Address string
+	Phone   string
+	Email   string
+	NPWP    string
+}
+
+// LoadCompany membaca identitas perusahaan dari environment variable COMPANY_*
+func LoadCompany() Company {
+	c := Company{
+		Name:    os.Getenv("COMPANY_NAME"),
+		Address: os.Getenv("COMPANY_ADDRESS"),
+		Phone:   os.Getenv("COMPANY_PHONE"),
+		Email:   os.Getenv("COMPANY_EMAIL"),
+		NPWP:    os.Getenv("COMPANY_NPWP"),
+	}
+	if c.Name == "" {
+		c.Name = "Warehouse Inventory"
+	}
+	return c
+}
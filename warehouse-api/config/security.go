@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// PasswordPolicy adalah aturan password untuk registrasi, reset dan ganti password
+type PasswordPolicy struct {
+	MinLength int
+	// MinClasses adalah jumlah minimal jenis karakter (huruf kecil, huruf besar, angka, simbol)
+	MinClasses int
+}
+
+// LoadPasswordPolicy membaca PASSWORD_MIN_LENGTH (default 6) dan PASSWORD_MIN_CLASSES (default 1)
+func LoadPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:  envInt("PASSWORD_MIN_LENGTH", 6, 1, 128),
+		MinClasses: envInt("PASSWORD_MIN_CLASSES", 1, 1, 4),
+	}
+}
+
+// LoginLockout mengatur penguncian sementara per username setelah login gagal berulang
+type LoginLockout struct {
+	// MaxAttempts adalah jumlah gagal berturut-turut sebelum username dikunci
+	MaxAttempts int
+	// Window: hitungan gagal dimulai dari nol bila gagal terakhir lebih lama dari ini
+	Window time.Duration
+	// Duration adalah lama kunci pertama; setiap gagal berikutnya menggandakannya sampai MaxDuration
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// LoadLoginLockout membaca LOGIN_MAX_ATTEMPTS (default 5), LOGIN_ATTEMPT_WINDOW (default 15m),
+// LOGIN_LOCKOUT (default 1m) dan LOGIN_LOCKOUT_MAX (default 1h)
+func LoadLoginLockout() LoginLockout {
+	return LoginLockout{
+		MaxAttempts: envInt("LOGIN_MAX_ATTEMPTS", 5, 1, 100),
+		Window:      envDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+		Duration:    envDuration("LOGIN_LOCKOUT", time.Minute),
+		MaxDuration: envDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+	}
+}
+
+// TwoFactor mengatur TOTP (2FA)
+type TwoFactor struct {
+	// Issuer adalah nama yang tampil di aplikasi authenticator
+	Issuer string
+	// RequireAdmin memaksa role admin memakai 2FA; admin yang belum mendaftar diminta mendaftar saat login
+	RequireAdmin bool
+	// ChallengeTTL adalah umur challenge token antara langkah password dan langkah kode
+	ChallengeTTL time.Duration
+}
+
+// LoadTwoFactor membaca TOTP_ISSUER (default COMPANY_NAME), REQUIRE_2FA_ADMIN (true/false, default false)
+// dan TWO_FACTOR_CHALLENGE_TTL (default 5m)
+func LoadTwoFactor() TwoFactor {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = LoadCompany().Name
+	}
+	require, _ := strconv.ParseBool(os.Getenv("REQUIRE_2FA_ADMIN"))
+	return TwoFactor{
+		Issuer:       issuer,
+		RequireAdmin: require,
+		ChallengeTTL: envDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+	}
+}
+
+// devHistoryChainKey hanya dipakai di luar mode production bila HISTORY_CHAIN_KEY kosong
+const devHistoryChainKey = "dev-history-chain-key"
+
+// minHistoryChainKeyLen adalah panjang minimal HISTORY_CHAIN_KEY di production (256 bit)
+const minHistoryChainKeyLen = 32
+
+var ErrHistoryChainKey = fmt.Errorf("HISTORY_CHAIN_KEY wajib diisi minimal %d byte untuk APP_ENV=production", minHistoryChainKeyLen)
+
+// HistoryChainKey membaca HISTORY_CHAIN_KEY, kunci HMAC hash chain riwayat stok. Kunci harus sama
+// di server, seeder dan cmd/history-chain; mengganti kunci membuat seluruh rantai lama rusak.
+// Di luar production kunci development dipakai dengan peringatan di log.
+func HistoryChainKey() ([]byte, error) {
+	key := os.Getenv("HISTORY_CHAIN_KEY")
+	production := os.Getenv("APP_ENV") == "production"
+	switch {
+	case len(key) >= minHistoryChainKeyLen && key != devHistoryChainKey:
+		return []byte(key), nil
+	case production:
+		return nil, ErrHistoryChainKey
+	case key == "":
+		log.Println("HISTORY_CHAIN_KEY kosong, memakai kunci development untuk hash chain riwayat stok")
+		return []byte(devHistoryChainKey), nil
+	default:
+		log.Printf("HISTORY_CHAIN_KEY kurang dari %d byte", minHistoryChainKeyLen)
+		return []byte(key), nil
+	}
+}
+
+func envInt(key string, fallback, min, max int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		log.Printf("%s tidak valid (%q), memakai default %d", key, v, fallback)
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,53 @@
+// Package ctxkeys menyimpan key context yang diisi middleware HTTP dan dibaca lapisan data
+// (audit log, visibilitas transaksi), sehingga repositories tidak bergantung pada middleware.
+package ctxkeys
+
+import "context"
+
+type key string
+
+const (
+	// UserIDKey berisi ID pengguna (atau service principal API key) pelaku request
+	UserIDKey key = "userID"
+	// VisibilityKey berisi models.VisibilityAll atau models.VisibilityOwn
+	VisibilityKey key = "visibility"
+	// RequestIDKey dan ClientIPKey dicatat di log dan audit log
+	RequestIDKey key = "requestID"
+	ClientIPKey  key = "clientIP"
+	// systemKey menandai pekerjaan internal (CLI, scheduler, seeder) yang membaca semua data;
+	// hanya diisi lewat WithSystem, tidak pernah dari request HTTP
+	systemKey key = "system"
+)
+
+// WithSystem menandai ctx sebagai pekerjaan internal yang tidak dibatasi visibilitas transaksi
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemKey, true)
+}
+
+// IsSystem melaporkan apakah ctx dibuat lewat WithSystem
+func IsSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(systemKey).(bool)
+	return system
+}
+
+// UserID mengembalikan pelaku request; 0 bila context tidak membawa pengguna
+func UserID(ctx context.Context) int {
+	id, _ := ctx.Value(UserIDKey).(int)
+	return id
+}
+
+// Visibility mengembalikan visibilitas request; ok false bila context tidak membawanya
+func Visibility(ctx context.Context) (visibility string, ok bool) {
+	visibility, ok = ctx.Value(VisibilityKey).(string)
+	return visibility, ok
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPKey).(string)
+	return ip
+}
@@ -0,0 +1,7 @@
+-- Soft delete untuk master_barang
+-- Barang yang sudah punya transaksi tidak boleh dihapus permanen karena
+-- history_stok, beli_detail dan jual_detail masih mereferensikannya.
+ALTER TABLE master_barang ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE master_barang ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_master_barang_is_active ON master_barang(is_active);
@@ -0,0 +1,38 @@
+-- Table Kategori (hierarki, parent_id NULL = kategori utama)
+CREATE TABLE IF NOT EXISTS kategori (
+ id SERIAL PRIMARY KEY,
+ nama VARCHAR(150) NOT NULL,
+ parent_id INTEGER REFERENCES kategori(id),
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+ updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_kategori_parent_id ON kategori(parent_id);
+
+-- Table Merek
+CREATE TABLE IF NOT EXISTS merek (
+ id SERIAL PRIMARY KEY,
+ nama VARCHAR(150) UNIQUE NOT NULL,
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+ updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Table Tag (bebas, disimpan lowercase)
+CREATE TABLE IF NOT EXISTS tag (
+ id SERIAL PRIMARY KEY,
+ nama VARCHAR(100) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS barang_tag (
+ barang_id INTEGER NOT NULL REFERENCES master_barang(id),
+ tag_id INTEGER NOT NULL REFERENCES tag(id),
+ PRIMARY KEY (barang_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_barang_tag_tag_id ON barang_tag(tag_id);
+
+ALTER TABLE master_barang ADD COLUMN IF NOT EXISTS kategori_id INTEGER REFERENCES kategori(id);
+ALTER TABLE master_barang ADD COLUMN IF NOT EXISTS merek_id INTEGER REFERENCES merek(id);
+
+CREATE INDEX IF NOT EXISTS idx_master_barang_kategori_id ON master_barang(kategori_id);
+CREATE INDEX IF NOT EXISTS idx_master_barang_merek_id ON master_barang(merek_id);
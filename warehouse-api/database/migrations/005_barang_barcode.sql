@@ -0,0 +1,9 @@
+-- Table Barcode Barang (satu barang bisa punya lebih dari satu barcode EAN/UPC)
+CREATE TABLE IF NOT EXISTS barang_barcode (
+ id SERIAL PRIMARY KEY,
+ barang_id INTEGER NOT NULL REFERENCES master_barang(id),
+ barcode VARCHAR(64) UNIQUE NOT NULL,
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_barang_barcode_barang_id ON barang_barcode(barang_id);
@@ -0,0 +1,7 @@
+-- Index keyset untuk pagination cursor riwayat stok (urutan created_at DESC, id DESC)
+CREATE INDEX IF NOT EXISTS idx_history_stok_created_id ON history_stok(created_at DESC, id DESC);
+
+-- Index per filter yang umum dipakai, tetap dengan urutan keyset yang sama
+CREATE INDEX IF NOT EXISTS idx_history_stok_barang_created_id ON history_stok(barang_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_history_stok_user_created_id ON history_stok(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_history_stok_jenis_created_id ON history_stok(jenis_transaksi, created_at DESC, id DESC);
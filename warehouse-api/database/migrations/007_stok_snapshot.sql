@@ -0,0 +1,11 @@
+-- Snapshot stok akhir bulan. Dibuat otomatis oleh server setelah bulan berakhir (atau manual
+-- lewat POST /api/stok/snapshot) sehingga angka penutupan tidak berubah walaupun ada koreksi
+-- riwayat stok yang diinput mundur. Satu periode selalu ditulis dalam satu statement.
+CREATE TABLE IF NOT EXISTS stok_snapshot (
+ id SERIAL PRIMARY KEY,
+ periode DATE NOT NULL,
+ barang_id INTEGER NOT NULL REFERENCES master_barang(id),
+ stok INTEGER NOT NULL,
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+ UNIQUE (periode, barang_id)
+);
@@ -0,0 +1,56 @@
+-- Klasifikasi ABC (kontribusi nilai penjualan) dan XYZ (variabilitas permintaan) per barang.
+-- Setiap proses klasifikasi disimpan sebagai satu run beserta tanggal dan parameternya;
+-- klasifikasi yang berlaku untuk barang adalah run terakhir.
+CREATE TABLE IF NOT EXISTS klasifikasi_run (
+ id SERIAL PRIMARY KEY,
+ start_date DATE NOT NULL,
+ end_date DATE NOT NULL,
+ periode VARCHAR(10) NOT NULL, -- 'month' atau 'week', ukuran periode untuk hitung variabilitas
+ batas_a DECIMAL(5,2) NOT NULL,
+ batas_b DECIMAL(5,2) NOT NULL,
+ batas_x DECIMAL(6,3) NOT NULL,
+ batas_y DECIMAL(6,3) NOT NULL,
+ jumlah_barang INTEGER NOT NULL DEFAULT 0,
+ user_id INTEGER REFERENCES users(id),
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS barang_klasifikasi (
+ run_id INTEGER NOT NULL REFERENCES klasifikasi_run(id) ON DELETE CASCADE,
+ barang_id INTEGER NOT NULL REFERENCES master_barang(id),
+ kelas_abc CHAR(1) NOT NULL,
+ kelas_xyz CHAR(1) NOT NULL,
+ nilai DECIMAL(15,2) NOT NULL DEFAULT 0,
+ kontribusi DECIMAL(7,4) NOT NULL DEFAULT 0, -- persen dari total nilai
+ kumulatif DECIMAL(7,4) NOT NULL DEFAULT 0,
+ qty INTEGER NOT NULL DEFAULT 0,
+ frekuensi INTEGER NOT NULL DEFAULT 0,
+ cv DECIMAL(10,4), -- NULL jika tidak ada permintaan
+ PRIMARY KEY (run_id, barang_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_barang_klasifikasi_barang ON barang_klasifikasi (barang_id, run_id DESC);
+
+-- Sesi stock opname. Barang dan stok sistemnya dibekukan saat sesi dibuat.
+CREATE TABLE IF NOT EXISTS stok_opname (
+ id SERIAL PRIMARY KEY,
+ no_opname VARCHAR(100) UNIQUE NOT NULL,
+ klasifikasi_run_id INTEGER REFERENCES klasifikasi_run(id),
+ kelas_abc VARCHAR(10) NOT NULL DEFAULT '', -- mis. 'AB', kosong = semua
+ kelas_xyz VARCHAR(10) NOT NULL DEFAULT '',
+ keterangan TEXT,
+ status VARCHAR(50) DEFAULT 'draft',
+ user_id INTEGER REFERENCES users(id),
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS stok_opname_detail (
+ id SERIAL PRIMARY KEY,
+ stok_opname_id INTEGER NOT NULL REFERENCES stok_opname(id) ON DELETE CASCADE,
+ barang_id INTEGER NOT NULL REFERENCES master_barang(id),
+ kelas_abc CHAR(1) NOT NULL,
+ kelas_xyz CHAR(1) NOT NULL,
+ stok_sistem INTEGER NOT NULL,
+ stok_fisik INTEGER,
+ UNIQUE (stok_opname_id, barang_id)
+);
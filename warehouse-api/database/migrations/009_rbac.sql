@@ -0,0 +1,68 @@
+-- Role dan permission. users.role merujuk roles.nama; role 'admin' selalu memiliki semua
+-- permission (dicek di aplikasi) sehingga tidak bisa mengunci dirinya sendiri.
+CREATE TABLE IF NOT EXISTS roles (
+ id SERIAL PRIMARY KEY,
+ nama VARCHAR(50) UNIQUE NOT NULL,
+ deskripsi TEXT,
+ is_system BOOLEAN NOT NULL DEFAULT FALSE,
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+ id SERIAL PRIMARY KEY,
+ kode VARCHAR(100) UNIQUE NOT NULL,
+ deskripsi TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+ role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+ permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+ PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO roles (nama, deskripsi, is_system) VALUES
+ ('admin', 'Administrator, memiliki semua akses', TRUE),
+ ('staff', 'Staf gudang', TRUE)
+ON CONFLICT (nama) DO NOTHING;
+
+-- Role lain yang sudah dipakai user tetap dipertahankan
+INSERT INTO roles (nama, deskripsi)
+SELECT DISTINCT role, 'Role lama' FROM users WHERE role IS NOT NULL
+ON CONFLICT (nama) DO NOTHING;
+
+INSERT INTO permissions (kode, deskripsi) VALUES
+ ('barang:read', 'Melihat barang, kategori, merek, tag dan label'),
+ ('barang:write', 'Menambah, mengubah, import dan memulihkan barang serta barcode'),
+ ('barang:delete', 'Mengarsipkan barang'),
+ ('kategori:write', 'Mengelola kategori dan merek'),
+ ('stok:read', 'Melihat stok, riwayat, kartu stok dan sesi stock opname'),
+ ('stok:adjust', 'Memuat saldo awal dan membuat snapshot stok'),
+ ('stok:opname', 'Membuat sesi stock opname'),
+ ('pembelian:read', 'Melihat pembelian dan mencetak nota'),
+ ('pembelian:create', 'Mencatat pembelian'),
+ ('penjualan:read', 'Melihat penjualan, faktur dan surat jalan'),
+ ('penjualan:create', 'Mencatat penjualan'),
+ ('report:view', 'Melihat dashboard dan laporan'),
+ ('report:manage', 'Menjalankan klasifikasi ABC/XYZ'),
+ ('user:manage', 'Mendaftarkan dan melihat pengguna'),
+ ('role:manage', 'Mengelola role dan permission')
+ON CONFLICT (kode) DO NOTHING;
+
+-- Hak default staff: operasional harian tanpa hapus barang, pembelian dan pengaturan
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.nama = 'staff' AND p.kode IN (
+ 'barang:read', 'barang:write', 'stok:read', 'stok:opname',
+ 'pembelian:read', 'penjualan:read', 'penjualan:create', 'report:view'
+)
+ON CONFLICT DO NOTHING;
+
+-- Role lama di luar admin/staff diberi hak yang sama dengan staff
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, rp.permission_id FROM roles r
+JOIN role_permissions rp ON rp.role_id = (SELECT id FROM roles WHERE nama = 'staff')
+WHERE r.is_system = FALSE AND NOT EXISTS (SELECT 1 FROM role_permissions x WHERE x.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(nama) ON UPDATE CASCADE;
@@ -0,0 +1,27 @@
+-- Refresh token (disimpan sebagai hash SHA-256) dan denylist access token.
+-- Setiap login membuka satu family; refresh token dirotasi setiap dipakai dan token lama yang
+-- dipakai ulang mencabut seluruh family-nya (indikasi token dicuri).
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+ id SERIAL PRIMARY KEY,
+ user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+ token_hash CHAR(64) UNIQUE NOT NULL,
+ family_id VARCHAR(32) NOT NULL,
+ expires_at TIMESTAMP NOT NULL,
+ used_at TIMESTAMP,
+ revoked_at TIMESTAMP,
+ created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+
+-- jti access token yang dicabut lewat logout; baris boleh dihapus setelah expires_at lewat
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+ jti VARCHAR(32) PRIMARY KEY,
+ user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+ expires_at TIMESTAMP NOT NULL,
+ revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- "Logout semua sesi": access token yang diterbitkan pada atau sebelum waktu ini ditolak
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sesi_dicabut_at TIMESTAMP;
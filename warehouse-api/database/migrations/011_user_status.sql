@@ -0,0 +1,2 @@
+-- Status akun: user nonaktif tidak bisa login dan token yang sudah terbit ditolak middleware
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
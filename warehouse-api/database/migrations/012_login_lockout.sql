@@ -0,0 +1,19 @@
+-- Penguncian login per username (termasuk username yang tidak terdaftar agar tidak bisa ditebak)
+CREATE TABLE IF NOT EXISTS login_lockout (
+    username VARCHAR(100) PRIMARY KEY,
+    gagal INTEGER NOT NULL DEFAULT 0,
+    terakhir_gagal TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    terkunci_sampai TIMESTAMP
+);
+
+-- Jejak audit login gagal
+CREATE TABLE IF NOT EXISTS login_gagal (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    alasan VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_gagal_username ON login_gagal(username, created_at);
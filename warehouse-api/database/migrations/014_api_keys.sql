@@ -0,0 +1,28 @@
+-- API key untuk integrasi mesin-ke-mesin (konektor e-commerce, printer label).
+-- Setiap key punya service principal sendiri di tabel users (is_service = TRUE) agar
+-- history_stok.user_id dan transaksi tercatat atas nama integrasi, bukan akun manusia.
+-- Service principal tidak bisa login dengan password.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_service BOOLEAN NOT NULL DEFAULT FALSE;
+
+INSERT INTO roles (nama, deskripsi, is_system) VALUES
+ ('service', 'Service principal API key; hak akses diambil dari scope key', TRUE)
+ON CONFLICT (nama) DO NOTHING;
+
+INSERT INTO permissions (kode, deskripsi) VALUES
+ ('apikey:manage', 'Membuat, melihat dan mencabut API key')
+ON CONFLICT (kode) DO NOTHING;
+
+-- Key hanya disimpan sebagai hash SHA-256; prefix disimpan apa adanya untuk mengenali key di daftar
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    nama VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) UNIQUE NOT NULL,
+    key_hash CHAR(64) UNIQUE NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
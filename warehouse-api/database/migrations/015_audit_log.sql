@@ -0,0 +1,23 @@
+-- Jejak audit setiap perubahan lewat API (create/update/delete). Baris ditulis di transaksi yang
+-- sama dengan perubahannya, sehingga perubahan yang di-rollback juga tidak meninggalkan jejak.
+-- sebelum/sesudah hanya berisi field yang berubah (create: sesudah lengkap, delete: sebelum lengkap).
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    aksi VARCHAR(30) NOT NULL,
+    entitas VARCHAR(50) NOT NULL,
+    entitas_id VARCHAR(100) NOT NULL,
+    sebelum JSONB,
+    sesudah JSONB,
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entitas ON audit_log(entitas, entitas_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user ON audit_log(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
+
+INSERT INTO permissions (kode, deskripsi) VALUES
+ ('audit:read', 'Melihat audit log perubahan data')
+ON CONFLICT (kode) DO NOTHING;
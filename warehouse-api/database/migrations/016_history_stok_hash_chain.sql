@@ -0,0 +1,9 @@
+-- Hash chain riwayat stok: setiap baris menyimpan hash isinya ditambah hash baris sebelumnya
+-- untuk barang yang sama (urut id), sehingga perubahan, penyisipan atau penghapusan baris langsung
+-- di database bisa dideteksi. Baris genesis punya prev_hash NULL.
+-- Baris lama (sebelum migrasi ini) di-seal sekali lewat `go run ./cmd/history-chain -seal` (lihat migrasi 019 dan 020).
+ALTER TABLE history_stok ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE history_stok ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+-- Urutan rantai per barang
+CREATE INDEX IF NOT EXISTS idx_history_stok_barang_id ON history_stok(barang_id, id);
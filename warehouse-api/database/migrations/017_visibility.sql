@@ -0,0 +1,20 @@
+-- Visibilitas data per pengguna: tanpa transaksi:read-all, pengguna hanya melihat penjualan,
+-- pembelian dan riwayat stok yang ia buat sendiri atau yang dibagikan kepadanya.
+-- Role admin selalu memiliki semua permission; role lain diberi lewat PUT /roles/{nama}/permissions.
+INSERT INTO permissions (kode, deskripsi) VALUES
+ ('transaksi:read-all', 'Melihat penjualan, pembelian dan riwayat stok semua pengguna')
+ON CONFLICT (kode) DO NOTHING;
+
+-- Transaksi yang dibagikan pemiliknya ke pengguna lain (jenis: penjualan atau pembelian)
+CREATE TABLE IF NOT EXISTS transaksi_share (
+    jenis VARCHAR(20) NOT NULL CHECK (jenis IN ('penjualan', 'pembelian')),
+    transaksi_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shared_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (jenis, transaksi_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaksi_share_user ON transaksi_share(user_id, jenis);
+CREATE INDEX IF NOT EXISTS idx_jual_header_user ON jual_header(user_id);
+CREATE INDEX IF NOT EXISTS idx_beli_header_user ON beli_header(user_id);
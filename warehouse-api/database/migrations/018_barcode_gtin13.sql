@@ -0,0 +1,15 @@
+-- Barcode UPC-A (12 digit) disimpan dalam bentuk GTIN-13 (diawali 0) sehingga UPC-A dan
+-- EAN-13 yang sama tidak bisa terdaftar di dua barang dan hasil scan tidak bergantung scanner.
+
+-- UPC-A yang bentuk GTIN-13-nya sudah terdaftar di barang yang sama cukup dihapus
+DELETE FROM barang_barcode b12
+USING barang_barcode b13
+WHERE b12.barcode ~ '^[0-9]{12}$'
+  AND b13.barcode = '0' || b12.barcode
+  AND b13.barang_id = b12.barang_id;
+
+-- Jika gagal karena unique violation, GTIN yang sama terdaftar di dua barang berbeda. Cari dengan
+--   SELECT b12.barang_id, b12.barcode, b13.barang_id, b13.barcode FROM barang_barcode b12
+--   JOIN barang_barcode b13 ON b13.barcode = '0' || b12.barcode;
+-- lalu hapus barcode dari barang yang salah sebelum menjalankan ulang migrasi ini.
+UPDATE barang_barcode SET barcode = '0' || barcode WHERE barcode ~ '^[0-9]{12}$';
@@ -0,0 +1,20 @@
+-- Penanda barang yang riwayat stoknya sudah masuk hash chain. Setelah di-seal, baris tanpa hash
+-- di barang itu selalu dianggap link rusak (tidak pernah di-seal ulang otomatis).
+-- Barang baru langsung ber-rantai sejak baris pertama, jadi defaultnya TRUE. Barang yang seluruh
+-- riwayatnya masih tanpa hash (data sebelum migrasi 016) ditandai FALSE dan harus di-seal sekali
+-- lewat `go run ./cmd/history-chain -seal`; transaksi stok barang itu ditolak sampai di-seal.
+-- Backfill hanya berjalan saat kolom baru ditambahkan agar menjalankan ulang migrasi ini tidak
+-- membuka kembali barang yang sudah di-seal.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'master_barang' AND column_name = 'history_sealed'
+    ) THEN
+        ALTER TABLE master_barang ADD COLUMN history_sealed BOOLEAN NOT NULL DEFAULT TRUE;
+
+        UPDATE master_barang b SET history_sealed = FALSE
+        WHERE EXISTS (SELECT 1 FROM history_stok h WHERE h.barang_id = b.id)
+          AND NOT EXISTS (SELECT 1 FROM history_stok h WHERE h.barang_id = b.id AND h.hash IS NOT NULL);
+    END IF;
+END $$;
@@ -0,0 +1,35 @@
+-- Hash chain riwayat stok memakai HMAC-SHA256 dengan kunci server (HISTORY_CHAIN_KEY, tidak pernah
+-- disimpan di database) mulai migrasi ini.
+-- history_chain_anchor menyimpan jumlah baris dan hash terakhir rantai tiap barang, ditandatangani
+-- dengan kunci yang sama, sehingga penghapusan baris paling akhir ikut terdeteksi. sealed_at diisi
+-- saat rantai barang dibuat/di-seal dan tidak pernah diubah.
+-- history_chain_seal (satu baris) ditulis setelah seluruh riwayat lama di-seal; setelahnya riwayat
+-- tanpa anchor selalu dianggap rusak walaupun master_barang.history_sealed dikembalikan ke FALSE.
+-- Hash SHA-256 tanpa kunci dari migrasi 016 bisa dihitung ulang siapa saja yang bisa menulis ke
+-- database, jadi seluruh riwayat di-seal ulang sekali lewat `go run ./cmd/history-chain -seal`.
+-- Backfill hanya berjalan saat tabel anchor baru dibuat.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.tables WHERE table_name = 'history_chain_anchor'
+    ) THEN
+        CREATE TABLE history_chain_anchor (
+            barang_id INT PRIMARY KEY REFERENCES master_barang(id),
+            baris INT NOT NULL,
+            last_id INT NOT NULL,
+            last_hash VARCHAR(64) NOT NULL,
+            sealed_at TIMESTAMP NOT NULL,
+            signature VARCHAR(64) NOT NULL
+        );
+
+        CREATE TABLE history_chain_seal (
+            id SMALLINT PRIMARY KEY CHECK (id = 1),
+            sealed_at TIMESTAMP NOT NULL,
+            signature VARCHAR(64) NOT NULL
+        );
+
+        UPDATE history_stok SET hash = NULL, prev_hash = NULL WHERE hash IS NOT NULL OR prev_hash IS NOT NULL;
+        UPDATE master_barang b SET history_sealed = FALSE
+        WHERE EXISTS (SELECT 1 FROM history_stok h WHERE h.barang_id = b.id);
+    END IF;
+END $$;
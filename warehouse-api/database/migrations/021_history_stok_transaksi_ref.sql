@@ -0,0 +1,17 @@
+-- Riwayat stok dari penjualan/pembelian merujuk header transaksinya lewat foreign key, sehingga
+-- visibilitas (transaksi yang dibagikan) dan kartu stok tidak lagi mencocokkan teks keterangan.
+-- Kolom ini tidak ikut hash chain: keterangan yang berisi nomor faktur tetap di-hash.
+ALTER TABLE history_stok ADD COLUMN IF NOT EXISTS jual_header_id INTEGER REFERENCES jual_header(id);
+ALTER TABLE history_stok ADD COLUMN IF NOT EXISTS beli_header_id INTEGER REFERENCES beli_header(id);
+
+-- Backfill riwayat lama dari keterangan "Penjualan <no_faktur>" / "Pembelian <no_faktur>"
+UPDATE history_stok h SET jual_header_id = jh.id
+FROM jual_header jh
+WHERE h.jual_header_id IS NULL AND h.jenis_transaksi = 'keluar' AND h.keterangan = 'Penjualan ' || jh.no_faktur;
+
+UPDATE history_stok h SET beli_header_id = bh.id
+FROM beli_header bh
+WHERE h.beli_header_id IS NULL AND h.jenis_transaksi = 'masuk' AND h.keterangan = 'Pembelian ' || bh.no_faktur;
+
+CREATE INDEX IF NOT EXISTS idx_history_stok_jual_header ON history_stok(jual_header_id) WHERE jual_header_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_history_stok_beli_header ON history_stok(beli_header_id) WHERE beli_header_id IS NOT NULL;
@@ -34,7 +34,8 @@ func SeedBarang(db *sql.DB) {
 		if err == sql.ErrNoRows {
 			// Insert Barang
             var newID int
-			err = db.QueryRow("INSERT INTO master_barang (kode_barang, nama_barang, deskripsi, satuan, harga_beli, harga_jual) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+			// Riwayat seed ditulis lewat SQL tanpa hash, jadi barang di-seal setelahnya oleh SealHistory
+			err = db.QueryRow("INSERT INTO master_barang (kode_barang, nama_barang, deskripsi, satuan, harga_beli, harga_jual, history_sealed) VALUES ($1, $2, $3, $4, $5, $6, FALSE) RETURNING id",
 				b.KodeBarang, b.NamaBarang, b.Deskripsi, b.Satuan, b.HargaBeli, b.HargaJual).Scan(&newID)
 			
             if err != nil {
@@ -46,6 +47,13 @@ func SeedBarang(db *sql.DB) {
             _, err = db.Exec("INSERT INTO mstok (barang_id, stok_akhir) VALUES ($1, $2)", newID, b.StokAwal)
             if err != nil {
                 log.Printf("Failed to insert initial stock for %s: %v", b.NamaBarang, err)
+            } else {
+                // Catat sebagai saldo awal agar cmd/saldo-awal tidak memuat ulang barang ini
+                _, err = db.Exec(`INSERT INTO history_stok (barang_id, user_id, jenis_transaksi, jumlah, stok_sebelum, stok_sesudah, keterangan)
+                    VALUES ($1, (SELECT id FROM users WHERE username = 'admin'), 'saldo_awal', $2, 0, $2, 'Saldo awal (seeder)')`, newID, b.StokAwal)
+                if err != nil {
+                    log.Printf("Failed to record saldo awal for %s: %v", b.NamaBarang, err)
+                }
             }
 
             fmt.Printf("Inserted barang: %s (Stok: %d)\n", b.NamaBarang, b.StokAwal)
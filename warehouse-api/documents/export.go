@@ -0,0 +1,316 @@
+package documents
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"warehouse-api/utils"
+)
+
+// Format file export yang didukung
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// Locale export menentukan format angka dan tanggal.
+// ExportLocaleID: "1.234.567,50", tanggal dd/mm/yyyy, CSV memakai pemisah titik koma (cocok untuk Excel Indonesia).
+// ExportLocaleRaw: angka apa adanya "1234567.5", tanggal ISO 8601, CSV memakai koma.
+const (
+	ExportLocaleID  = "id"
+	ExportLocaleRaw = "raw"
+)
+
+var ErrUnsupportedExport = errors.New("format export tidak didukung")
+
+// ValidExportFormat mengecek format dan locale export
+func ValidExportFormat(format, locale string) bool {
+	return (format == ExportCSV || format == ExportXLSX) && (locale == ExportLocaleID || locale == ExportLocaleRaw)
+}
+
+// ExportContentType mengembalikan MIME type untuk format export
+func ExportContentType(format string) string {
+	if format == ExportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ExportWriter menulis tabel baris per baris langsung ke io.Writer tanpa menampung seluruh data.
+// Nilai yang didukung: string, int, float64, time.Time dan *time.Time (nil = kosong).
+type ExportWriter interface {
+	WriteRow(values ...interface{}) error
+	Close() error
+}
+
+// NewExportWriter membuat writer CSV/XLSX dan langsung menulis baris judul kolom
+func NewExportWriter(w io.Writer, format, locale, sheetName string, headers []string) (ExportWriter, error) {
+	if !ValidExportFormat(format, locale) {
+		return nil, ErrUnsupportedExport
+	}
+	if format == ExportXLSX {
+		return newXLSXWriter(w, locale, sheetName, headers)
+	}
+	return newCSVWriter(w, locale, headers)
+}
+
+type csvExportWriter struct {
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	locale string
+}
+
+func newCSVWriter(w io.Writer, locale string, headers []string) (*csvExportWriter, error) {
+	buf := bufio.NewWriter(w)
+	cw := csv.NewWriter(buf)
+	if locale == ExportLocaleID {
+		// BOM agar Excel membaca UTF-8, titik koma karena koma dipakai sebagai desimal
+		buf.WriteString("\uFEFF")
+		cw.Comma = ';'
+	}
+
+	e := &csvExportWriter{buf: buf, csv: cw, locale: locale}
+	if err := cw.Write(headers); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvExportWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = e.format(v)
+	}
+	return e.csv.Write(record)
+}
+
+func (e *csvExportWriter) format(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return sanitizeCSVText(val)
+	case int:
+		if e.locale == ExportLocaleID {
+			return utils.FormatNumberID(float64(val), 0)
+		}
+		return strconv.Itoa(val)
+	case float64:
+		if e.locale == ExportLocaleID {
+			decimals := 0
+			if val != math.Trunc(val) {
+				decimals = 2
+			}
+			return utils.FormatNumberID(val, decimals)
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		if e.locale == ExportLocaleID {
+			return val.Format("02/01/2006 15:04:05")
+		}
+		return val.Format(time.RFC3339)
+	case *time.Time:
+		if val == nil {
+			return ""
+		}
+		return e.format(*val)
+	case *float64:
+		if val == nil {
+			return ""
+		}
+		return e.format(*val)
+	case nil:
+		return ""
+	default:
+		return sanitizeCSVText(fmt.Sprint(val))
+	}
+}
+
+// sanitizeCSVText mencegah formula injection saat CSV dibuka di spreadsheet
+func sanitizeCSVText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (e *csvExportWriter) Close() error {
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.buf.Flush()
+}
+
+// Index style pada styles.xml
+const (
+	xlsxStyleInteger = 1
+	xlsxStyleDecimal = 2
+	xlsxStyleDate    = 3
+	xlsxStyleHeader  = 4
+)
+
+// excelEpoch adalah hari ke-0 serial tanggal Excel (sistem 1900)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxExportWriter menulis workbook satu sheet. Bagian statis ditulis di awal dan
+// sheet1.xml menjadi entry zip terakhir, sehingga baris bisa di-stream sampai Close.
+type xlsxExportWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+}
+
+func newXLSXWriter(w io.Writer, locale, sheetName string, headers []string) (*xlsxExportWriter, error) {
+	dateFormat := "dd/mm/yyyy hh:mm:ss"
+	if locale == ExportLocaleRaw {
+		dateFormat = "yyyy-mm-dd hh:mm:ss"
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", fmt.Sprintf(xlsxStyles, xmlEscape(dateFormat))},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e := &xlsxExportWriter{zip: zw, sheet: bufio.NewWriter(f)}
+
+	fmt.Fprintf(e.sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" state="frozen"/></sheetView></sheetViews>`+
+		`<cols><col min="1" max="%d" width="20" customWidth="1"/></cols><sheetData>`, len(headers))
+
+	e.sheet.WriteString("<row>")
+	for _, h := range headers {
+		fmt.Fprintf(e.sheet, `<c t="inlineStr" s="%d"><is><t xml:space="preserve">%s</t></is></c>`, xlsxStyleHeader, xmlEscape(h))
+	}
+	e.sheet.WriteString("</row>")
+
+	return e, nil
+}
+
+func (e *xlsxExportWriter) WriteRow(values ...interface{}) error {
+	e.sheet.WriteString("<row>")
+	for _, v := range values {
+		e.writeCell(v)
+	}
+	_, err := e.sheet.WriteString("</row>")
+	return err
+}
+
+func (e *xlsxExportWriter) writeCell(v interface{}) {
+	switch val := v.(type) {
+	case string:
+		fmt.Fprintf(e.sheet, `<c t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, xmlEscape(val))
+	case int:
+		fmt.Fprintf(e.sheet, `<c s="%d"><v>%d</v></c>`, xlsxStyleInteger, val)
+	case float64:
+		style := xlsxStyleInteger
+		if val != math.Trunc(val) {
+			style = xlsxStyleDecimal
+		}
+		fmt.Fprintf(e.sheet, `<c s="%d"><v>%s</v></c>`, style, strconv.FormatFloat(val, 'f', -1, 64))
+	case time.Time:
+		fmt.Fprintf(e.sheet, `<c s="%d"><v>%s</v></c>`, xlsxStyleDate, strconv.FormatFloat(excelSerial(val), 'f', -1, 64))
+	case *time.Time:
+		if val == nil {
+			e.sheet.WriteString("<c/>")
+			return
+		}
+		e.writeCell(*val)
+	case *float64:
+		if val == nil {
+			e.sheet.WriteString("<c/>")
+			return
+		}
+		e.writeCell(*val)
+	case nil:
+		e.sheet.WriteString("<c/>")
+	default:
+		e.writeCell(fmt.Sprint(val))
+	}
+}
+
+func (e *xlsxExportWriter) Close() error {
+	e.sheet.WriteString("</sheetData></worksheet>")
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zip.Close()
+}
+
+// excelSerial mengubah waktu (jam dinding, tanpa konversi zona) ke serial tanggal Excel
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// numFmtId 3 = "#,##0", 4 = "#,##0.00" (built-in, pemisah mengikuti locale Excel pengguna)
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="%s"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="5">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+</cellXfs>
+</styleSheet>`
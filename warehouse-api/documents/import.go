@@ -0,0 +1,278 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"warehouse-api/utils"
+)
+
+var ErrInvalidSpreadsheet = errors.New("file spreadsheet tidak valid")
+
+// ReadSpreadsheet membaca seluruh baris CSV atau sheet pertama XLSX sebagai teks.
+// Untuk CSV, pemisah koma atau titik koma dideteksi dari baris judul dan BOM UTF-8 diabaikan.
+func ReadSpreadsheet(data []byte, format string) ([][]string, error) {
+	switch format {
+	case ExportCSV:
+		return readCSV(data)
+	case ExportXLSX:
+		return readXLSX(data)
+	default:
+		return nil, ErrUnsupportedExport
+	}
+}
+
+// ColumnKey menormalkan judul kolom: tidak peka huruf besar/kecil dan spasi dianggap
+// garis bawah, sehingga file hasil export ("Kode Barang") juga bisa dipakai untuk import.
+func ColumnKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+}
+
+// ParseCellNumber membaca angka dari sel. Sel angka XLSX selalu berformat baku,
+// sedangkan CSV/teks bisa berformat Indonesia. Sel kosong dianggap 0.
+func ParseCellNumber(s, format string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if format == ExportXLSX {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v, nil
+		}
+	}
+	return utils.ParseNumberID(s)
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, ErrInvalidSpreadsheet
+	}
+	return rows, nil
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string     `xml:"t"`
+		Runs []xlsxText `xml:"r"`
+	} `xml:"is"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+}
+
+// readXLSX membaca sheet pertama workbook. Nilai angka dikembalikan apa adanya
+// (tanpa format tampilan), string diambil dari sharedStrings atau inline string.
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidSpreadsheet
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrInvalidSpreadsheet
+	}
+	rc, err := sheet.Open()
+	if err != nil {
+		return nil, ErrInvalidSpreadsheet
+	}
+	defer rc.Close()
+
+	var rows [][]string
+	var current []string
+	inRow := false
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidSpreadsheet
+		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "row":
+				inRow = true
+				current = nil
+			case "c":
+				if !inRow {
+					continue
+				}
+				var c xlsxCell
+				if err := dec.DecodeElement(&c, &el); err != nil {
+					return nil, ErrInvalidSpreadsheet
+				}
+				col := len(current)
+				if c.Ref != "" {
+					col = columnIndex(c.Ref)
+				}
+				for len(current) < col {
+					current = append(current, "")
+				}
+				current = append(current, cellText(c, shared))
+			}
+		case xml.EndElement:
+			if el.Name.Local == "row" {
+				inRow = false
+				rows = append(rows, current)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func cellText(c xlsxCell, shared []string) string {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+		return shared[i]
+	case "inlineStr":
+		if c.Inline.Text != "" {
+			return c.Inline.Text
+		}
+		var b strings.Builder
+		for _, r := range c.Inline.Runs {
+			b.WriteString(r.Text)
+		}
+		return b.String()
+	default:
+		return c.Value
+	}
+}
+
+// columnIndex mengubah referensi sel ("C12") menjadi index kolom berbasis 0
+func columnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+	return col - 1
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, ErrInvalidSpreadsheet
+	}
+	defer rc.Close()
+
+	var sst struct {
+		Items []struct {
+			Text string     `xml:"t"`
+			Runs []xlsxText `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&sst); err != nil {
+		return nil, ErrInvalidSpreadsheet
+	}
+
+	shared := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		if si.Text != "" || len(si.Runs) == 0 {
+			shared[i] = si.Text
+			continue
+		}
+		var b strings.Builder
+		for _, r := range si.Runs {
+			b.WriteString(r.Text)
+		}
+		shared[i] = b.String()
+	}
+	return shared, nil
+}
+
+// firstSheetPath mencari file XML sheet pertama lewat workbook.xml dan relasinya
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+
+	if err := decodeZipXML(files, "xl/workbook.xml", &workbook); err != nil || len(workbook.Sheets) == 0 {
+		return "", ErrInvalidSpreadsheet
+	}
+	if err := decodeZipXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", ErrInvalidSpreadsheet
+	}
+
+	for _, rel := range rels.Items {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", ErrInvalidSpreadsheet
+}
+
+func decodeZipXML(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return ErrInvalidSpreadsheet
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
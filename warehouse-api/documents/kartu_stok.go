@@ -0,0 +1,146 @@
+package documents
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"warehouse-api/config"
+	"warehouse-api/models"
+	"warehouse-api/utils"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// RenderKartuStok menulis kartu stok satu barang (A4 landscape) dengan saldo berjalan
+func RenderKartuStok(w io.Writer, company config.Company, k *models.KartuStok) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, "Dicetak "+time.Now().Format("02/01/2006 15:04")+" - Halaman "+strconv.Itoa(pdf.PageNo())+"/{nb}", "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	writeCompanyHeader(pdf, company)
+
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, "KARTU STOK", "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont("Helvetica", "", 9.5)
+	info := [][2]string{
+		{"Kode Barang", k.Barang.KodeBarang},
+		{"Nama Barang", k.Barang.NamaBarang},
+		{"Satuan", k.Barang.Satuan},
+		{"Periode", periodeLabel(k.StartDate, k.EndDate)},
+	}
+	for _, row := range info {
+		pdf.CellFormat(28, 5, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, ": "+tr(row[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	type column struct {
+		title string
+		width float64
+		align string
+	}
+	cols := []column{
+		{"Tanggal", 28, "L"}, {"Referensi", 32, "L"}, {"Keterangan", 0, "L"}, {"Masuk", 18, "R"}, {"Keluar", 18, "R"},
+		{"Saldo", 20, "R"}, {"Harga Satuan", 28, "R"}, {"Nilai", 30, "R"}, {"Nilai Saldo", 32, "R"},
+	}
+	fixedWidth := 0.0
+	for _, c := range cols {
+		fixedWidth += c.width
+	}
+	for i := range cols {
+		if cols[i].width == 0 {
+			cols[i].width = 277 - fixedWidth
+		}
+	}
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 8.5)
+		pdf.SetFillColor(230, 230, 230)
+		for _, c := range cols {
+			pdf.CellFormat(c.width, 7, c.title, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 8.5)
+	}
+	row := func(values []string, bold bool) {
+		if pdf.GetY()+6.5 > 195 {
+			pdf.AddPage()
+			header()
+		}
+		if bold {
+			pdf.SetFont("Helvetica", "B", 8.5)
+		}
+		for j, c := range cols {
+			pdf.CellFormat(c.width, 6.5, values[j], "1", 0, c.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+		if bold {
+			pdf.SetFont("Helvetica", "", 8.5)
+		}
+	}
+
+	header()
+	row([]string{"", "", "Saldo awal", "", "", qty(k.SaldoAwal), "", "", rupiah(k.NilaiSaldoAwal)}, true)
+	for _, e := range k.Entries {
+		keterangan := e.Keterangan
+		if keterangan == "" {
+			keterangan = e.JenisTransaksi
+		}
+		row([]string{
+			e.Tanggal.Format("02/01/2006 15:04"), e.Referensi, tr(truncate(keterangan, 60)),
+			qtyOrBlank(e.Masuk), qtyOrBlank(e.Keluar), qty(e.Saldo),
+			rupiah(e.HargaSatuan), rupiah(e.Nilai), rupiah(e.NilaiSaldo),
+		}, false)
+	}
+	row([]string{"", "", "Saldo akhir", qty(k.TotalMasuk), qty(k.TotalKeluar), qty(k.SaldoAkhir), "", "", rupiah(k.NilaiSaldoAkhir)}, true)
+
+	pdf.Ln(3)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.MultiCell(0, 4, "Nilai persediaan dihitung dengan metode rata-rata bergerak. Kolom nilai kosong jika harga pokok barang masuk sebelumnya tidak diketahui.", "", "L", false)
+
+	return pdf.Output(w)
+}
+
+func periodeLabel(start, end string) string {
+	format := func(s, fallback string) string {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return fallback
+		}
+		return t.Format("02/01/2006")
+	}
+	return format(start, "awal") + " s/d " + format(end, "sekarang")
+}
+
+func qty(v int) string {
+	return utils.FormatNumberID(float64(v), 0)
+}
+
+func qtyOrBlank(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return qty(v)
+}
+
+func rupiah(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	decimals := 0
+	if *v != float64(int64(*v)) {
+		decimals = 2
+	}
+	return utils.FormatNumberID(*v, decimals)
+}
@@ -0,0 +1,231 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"warehouse-api/utils"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Jenis barcode yang didukung untuk label
+const (
+	SymbologyCode128 = "code128"
+	SymbologyEAN13   = "ean13"
+	SymbologyQR      = "qr"
+)
+
+// Label adalah data yang dicetak pada satu label rak
+type Label struct {
+	KodeBarang string
+	NamaBarang string
+	HargaJual  float64
+	EAN        string // Barcode EAN-13 barang, wajib jika symbology ean13
+}
+
+// LabelLayout menentukan jumlah kolom dan baris label per halaman A4
+type LabelLayout struct {
+	Columns int
+	Rows    int
+}
+
+// DefaultLabelLayout adalah lembar stiker A4 3x8 (24 label, ukuran ~70x37mm)
+var DefaultLabelLayout = LabelLayout{Columns: 3, Rows: 8}
+
+// ParseLabelLayout membaca format "KOLOMxBARIS", misal "3x8"
+func ParseLabelLayout(s string) (LabelLayout, error) {
+	if s == "" {
+		return DefaultLabelLayout, nil
+	}
+	var l LabelLayout
+	if _, err := fmt.Sscanf(strings.ToLower(s), "%dx%d", &l.Columns, &l.Rows); err != nil {
+		return l, errors.New("format layout harus KOLOMxBARIS, misal 3x8")
+	}
+	if l.Columns < 1 || l.Columns > 6 || l.Rows < 1 || l.Rows > 15 {
+		return l, errors.New("layout maksimal 6 kolom x 15 baris")
+	}
+	return l, nil
+}
+
+// ValidSymbology mengecek apakah jenis barcode didukung
+func ValidSymbology(s string) bool {
+	return s == SymbologyCode128 || s == SymbologyEAN13 || s == SymbologyQR
+}
+
+// encodeBarcode membuat barcode sesuai symbology. Code128 dan QR memakai kode_barang,
+// EAN-13 memakai barcode EAN milik barang.
+func encodeBarcode(l Label, symbology string) (barcode.Barcode, error) {
+	switch symbology {
+	case SymbologyEAN13:
+		if len(l.EAN) != 13 {
+			return nil, fmt.Errorf("barang %s tidak punya barcode EAN-13", l.KodeBarang)
+		}
+		return ean.Encode(l.EAN)
+	case SymbologyQR:
+		return qr.Encode(l.KodeBarang, qr.M, qr.Auto)
+	default:
+		return code128.Encode(l.KodeBarang)
+	}
+}
+
+// barcodeImage menskalakan barcode ke ukuran piksel yang diminta
+func barcodeImage(l Label, symbology string, width, height int) (image.Image, error) {
+	bc, err := encodeBarcode(l, symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbology == SymbologyQR {
+		size := height
+		if width < size {
+			size = width
+		}
+		return barcode.Scale(bc, size, size)
+	}
+
+	// Barcode linear tidak boleh lebih sempit dari jumlah modulnya
+	if minWidth := bc.Bounds().Dx(); width < minWidth {
+		width = minWidth
+	}
+	return barcode.Scale(bc, width, height)
+}
+
+// drawText menulis teks dengan font bitmap yang diperbesar sebanyak scale kali
+func drawText(dst draw.Image, text string, x, y, scale int) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	if width == 0 {
+		return
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, width, face.Height))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+	d := &font.Drawer{
+		Dst:  src,
+		Src:  image.Black,
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	d.DrawString(text)
+
+	target := image.Rect(x, y, x+width*scale, y+face.Height*scale)
+	xdraw.NearestNeighbor.Scale(dst, target, src, src.Bounds(), draw.Over, nil)
+}
+
+// truncate memotong teks agar muat di label
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "~"
+}
+
+// RenderLabelPNG merender satu label (400x240 px) sebagai PNG
+func RenderLabelPNG(l Label, symbology string) ([]byte, error) {
+	const width, height = 400, 240
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.White}, image.Point{}, draw.Src)
+
+	drawText(img, truncate(l.NamaBarang, 27), 10, 8, 2)
+
+	bc, err := barcodeImage(l, symbology, 380, 120)
+	if err != nil {
+		return nil, err
+	}
+	x := (width - bc.Bounds().Dx()) / 2
+	if x < 0 {
+		x = 0
+	}
+	draw.Draw(img, bc.Bounds().Add(image.Pt(x, 40)), bc, image.Point{}, draw.Src)
+
+	drawText(img, l.KodeBarang, 10, 170, 2)
+	drawText(img, utils.FormatRupiah(l.HargaJual), 10, 200, 2)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderLabelSheetPDF menyusun label ke lembar stiker A4 dan menulis PDF ke w.
+// Label yang melebihi satu halaman otomatis lanjut ke halaman berikutnya.
+func RenderLabelSheetPDF(w io.Writer, labels []Label, symbology string, layout LabelLayout) error {
+	const (
+		pageW, pageH = 210.0, 297.0
+		marginX      = 4.0
+		marginY      = 10.0
+		padding      = 2.0
+	)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	cellW := (pageW - 2*marginX) / float64(layout.Columns)
+	cellH := (pageH - 2*marginY) / float64(layout.Rows)
+	perPage := layout.Columns * layout.Rows
+
+	for i, l := range labels {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+		pos := i % perPage
+		x := marginX + float64(pos%layout.Columns)*cellW
+		y := marginY + float64(pos/layout.Columns)*cellH
+
+		// Nama barang
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetXY(x+padding, y+padding)
+		pdf.CellFormat(cellW-2*padding, 4, tr(truncate(l.NamaBarang, 40)), "", 0, "L", false, 0, "")
+
+		// Barcode
+		bcH := cellH * 0.45
+		img, err := barcodeImage(l, symbology, 600, 200)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("bc-%d", i)
+		pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, &buf)
+
+		bcW := cellW - 2*padding
+		if symbology == SymbologyQR {
+			bcW = bcH
+		}
+		pdf.ImageOptions(name, x+(cellW-bcW)/2, y+padding+5, bcW, bcH, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		// Kode dan harga
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.SetXY(x+padding, y+padding+6+bcH)
+		pdf.CellFormat(cellW-2*padding, 3.5, tr(l.KodeBarang), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetXY(x+padding, y+padding+10+bcH)
+		pdf.CellFormat(cellW-2*padding, 4, utils.FormatRupiah(l.HargaJual), "", 0, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,26 @@
+package documents
+
+import (
+	"bytes"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+// RenderQRPNG merender teks bebas (mis. URI otpauth 2FA) sebagai QR PNG persegi
+func RenderQRPNG(content string, size int) ([]byte, error) {
+	bc, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+	img, err := barcode.Scale(bc, size, size)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
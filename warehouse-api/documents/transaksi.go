@@ -0,0 +1,238 @@
+package documents
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"warehouse-api/config"
+	"warehouse-api/models"
+	"warehouse-api/utils"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// transaksiDoc adalah bentuk umum dokumen transaksi (faktur, surat jalan, nota pembelian)
+type transaksiDoc struct {
+	Title      string
+	NoFaktur   string
+	Tanggal    string
+	PartyLabel string // "Kepada" atau "Supplier"
+	Party      string
+	Petugas    string
+	Items      []transaksiItem
+	Total      float64
+	WithPrices bool
+	Signatures []string
+}
+
+type transaksiItem struct {
+	Kode   string
+	Nama   string
+	Satuan string
+	Qty    int
+	Harga  float64
+	Sub    float64
+}
+
+func username(u *models.User) string {
+	if u == nil {
+		return ""
+	}
+	return u.Username
+}
+
+func itemFromBarang(b *models.Barang) (string, string, string) {
+	if b == nil {
+		return "", "", ""
+	}
+	return b.KodeBarang, b.NamaBarang, b.Satuan
+}
+
+// RenderFakturPenjualan menulis faktur penjualan lengkap dengan harga dan terbilang
+func RenderFakturPenjualan(w io.Writer, company config.Company, h *models.JualHeader) error {
+	doc := penjualanDoc(h)
+	doc.Title = "FAKTUR PENJUALAN"
+	doc.WithPrices = true
+	doc.Signatures = []string{"Hormat Kami", "Penerima"}
+	return renderTransaksi(w, company, doc)
+}
+
+// RenderSuratJalan menulis surat jalan (delivery note) tanpa harga
+func RenderSuratJalan(w io.Writer, company config.Company, h *models.JualHeader) error {
+	doc := penjualanDoc(h)
+	doc.Title = "SURAT JALAN"
+	doc.WithPrices = false
+	doc.Signatures = []string{"Pengirim", "Sopir", "Penerima"}
+	return renderTransaksi(w, company, doc)
+}
+
+// RenderNotaPembelian menulis bukti penerimaan barang dari pembelian
+func RenderNotaPembelian(w io.Writer, company config.Company, h *models.BeliHeader) error {
+	doc := transaksiDoc{
+		Title:      "BUKTI PEMBELIAN",
+		NoFaktur:   h.NoFaktur,
+		Tanggal:    h.CreatedAt.Format("02/01/2006 15:04"),
+		PartyLabel: "Supplier",
+		Party:      h.Supplier,
+		Petugas:    username(h.User),
+		Total:      h.Total,
+		WithPrices: true,
+		Signatures: []string{"Diterima Oleh", "Supplier"},
+	}
+	for _, d := range h.Details {
+		kode, nama, satuan := itemFromBarang(d.Barang)
+		doc.Items = append(doc.Items, transaksiItem{kode, nama, satuan, d.Qty, d.Harga, d.Subtotal})
+	}
+	return renderTransaksi(w, company, doc)
+}
+
+func penjualanDoc(h *models.JualHeader) transaksiDoc {
+	doc := transaksiDoc{
+		NoFaktur:   h.NoFaktur,
+		Tanggal:    h.CreatedAt.Format("02/01/2006 15:04"),
+		PartyLabel: "Kepada",
+		Party:      h.Customer,
+		Petugas:    username(h.User),
+		Total:      h.Total,
+	}
+	for _, d := range h.Details {
+		kode, nama, satuan := itemFromBarang(d.Barang)
+		doc.Items = append(doc.Items, transaksiItem{kode, nama, satuan, d.Qty, d.Harga, d.Subtotal})
+	}
+	return doc
+}
+
+// writeCompanyHeader mencetak kop perusahaan di bagian atas halaman
+func writeCompanyHeader(pdf *fpdf.Fpdf, company config.Company) {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 7, tr(company.Name), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	if company.Address != "" {
+		pdf.MultiCell(0, 4.5, tr(company.Address), "", "L", false)
+	}
+	var contact []string
+	if company.Phone != "" {
+		contact = append(contact, "Telp. "+company.Phone)
+	}
+	if company.Email != "" {
+		contact = append(contact, company.Email)
+	}
+	if company.NPWP != "" {
+		contact = append(contact, "NPWP "+company.NPWP)
+	}
+	if len(contact) > 0 {
+		pdf.CellFormat(0, 4.5, tr(strings.Join(contact, "  |  ")), "", 1, "L", false, 0, "")
+	}
+
+	x, y := pdf.GetXY()
+	pageWidth, _ := pdf.GetPageSize()
+	_, _, right, _ := pdf.GetMargins()
+	pdf.Line(x, y+1.5, pageWidth-right, y+1.5)
+	pdf.Ln(4)
+}
+
+func renderTransaksi(w io.Writer, company config.Company, doc transaksiDoc) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	writeCompanyHeader(pdf, company)
+
+	// Judul dan identitas dokumen
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, doc.Title, "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont("Helvetica", "", 9.5)
+	info := [][2]string{
+		{"No. Faktur", doc.NoFaktur},
+		{"Tanggal", doc.Tanggal},
+		{doc.PartyLabel, doc.Party},
+		{"Petugas", doc.Petugas},
+	}
+	for _, row := range info {
+		pdf.CellFormat(28, 5, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, ": "+tr(row[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	// Tabel item
+	type column struct {
+		title string
+		width float64
+		align string
+	}
+	cols := []column{{"No", 10, "C"}, {"Kode", 28, "L"}, {"Nama Barang", 0, "L"}, {"Qty", 16, "R"}, {"Satuan", 18, "L"}}
+	if doc.WithPrices {
+		cols = append(cols, column{"Harga", 30, "R"}, column{"Subtotal", 32, "R"})
+	}
+	fixedWidth := 0.0
+	for _, c := range cols {
+		fixedWidth += c.width
+	}
+	for i := range cols {
+		if cols[i].width == 0 {
+			cols[i].width = 190 - fixedWidth
+		}
+	}
+
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for _, c := range cols {
+		pdf.CellFormat(c.width, 7, c.title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	totalQty := 0
+	for i, item := range doc.Items {
+		values := []string{strconv.Itoa(i + 1), item.Kode, tr(item.Nama), utils.FormatNumberID(float64(item.Qty), 0), item.Satuan}
+		if doc.WithPrices {
+			values = append(values, utils.FormatNumberID(item.Harga, 0), utils.FormatNumberID(item.Sub, 0))
+		}
+		for j, c := range cols {
+			pdf.CellFormat(c.width, 6.5, values[j], "1", 0, c.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+		totalQty += item.Qty
+	}
+
+	// Baris total
+	pdf.SetFont("Helvetica", "B", 9)
+	labelWidth := cols[0].width + cols[1].width + cols[2].width
+	pdf.CellFormat(labelWidth, 7, "Total", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(cols[3].width, 7, utils.FormatNumberID(float64(totalQty), 0), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(cols[4].width, 7, "", "1", 0, "L", false, 0, "")
+	if doc.WithPrices {
+		pdf.CellFormat(cols[5].width, 7, "", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(cols[6].width, 7, utils.FormatRupiah(doc.Total), "1", 0, "R", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	if doc.WithPrices {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.MultiCell(0, 5, "Terbilang: "+utils.TerbilangRupiah(doc.Total), "", "L", false)
+	}
+
+	// Kolom tanda tangan
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "", 9.5)
+	sigWidth := 190.0 / float64(len(doc.Signatures))
+	for _, s := range doc.Signatures {
+		pdf.CellFormat(sigWidth, 5, s, "", 0, "C", false, 0, "")
+	}
+	pdf.Ln(22)
+	for range doc.Signatures {
+		pdf.CellFormat(sigWidth, 5, "(________________________)", "", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	return pdf.Output(w)
+}
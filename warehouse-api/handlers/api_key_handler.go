@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+// APIKeyHandler mengelola API key untuk integrasi mesin-ke-mesin (butuh apikey:manage)
+type APIKeyHandler struct {
+	service services.APIKeyService
+}
+
+func NewAPIKeyHandler(service services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service}
+}
+
+// GetAll godoc
+// @Summary Daftar API key
+// @Description Mengambil semua API key beserta scope, masa berlaku dan waktu terakhir dipakai (butuh apikey:manage). Key lengkap tidak pernah ditampilkan lagi.
+// @Tags Auth
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api-keys [get]
+func (h *APIKeyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.GetAll()
+	if err != nil {
+		log.Printf("gagal mengambil API key: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil data API key")
+		return
+	}
+	utils.JSONSuccess(w, "Berhasil mengambil data API key", keys)
+}
+
+// Create godoc
+// @Summary Buat API key
+// @Description Membuat API key beserta service principal-nya (butuh apikey:manage). Scope hanya boleh berisi permission yang dimiliki pembuat. Key lengkap hanya ditampilkan sekali di respons ini; kirim lewat header X-API-Key.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.CreateAPIKeyRequest true "Data API key"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	key, err := h.service.Create(r.Context(), userID, role, req)
+	if err != nil {
+		apiKeyError(w, err, "Gagal membuat API key")
+		return
+	}
+	utils.JSONCreated(w, "API key berhasil dibuat, simpan key sekarang karena tidak akan ditampilkan lagi", key)
+}
+
+// Revoke godoc
+// @Summary Cabut API key
+// @Description Mencabut API key dan menonaktifkan service principal-nya (butuh apikey:manage). Riwayat stok atas nama key tetap tersimpan.
+// @Tags Auth
+// @Produce  json
+// @Param   id path int true "ID API key"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "ID API key tidak valid")
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		apiKeyError(w, err, "Gagal mencabut API key")
+		return
+	}
+	utils.JSONSuccess(w, "API key berhasil dicabut", nil)
+}
+
+func apiKeyError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNamaKosong), errors.Is(err, services.ErrAPIKeyScopeKosong),
+		errors.Is(err, services.ErrAPIKeyMasaBerlaku), errors.Is(err, services.ErrAPIKeyScopeTidakAda):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrAPIKeyScopeDitolak):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repositories.ErrAPIKeyNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("%s: %v", fallback, err)
+		utils.JSONError(w, http.StatusInternalServerError, fallback)
+	}
+}
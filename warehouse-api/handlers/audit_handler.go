@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/utils"
+)
+
+type AuditHandler struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditHandler(repo repositories.AuditRepository) *AuditHandler {
+	return &AuditHandler{repo}
+}
+
+// GetAll godoc
+// @Summary Audit log
+// @Description Riwayat perubahan data (create/update/delete) terbaru dulu, berisi pelaku, field sebelum/sesudah, IP dan request ID (butuh audit:read)
+// @Tags Auth
+// @Produce  json
+// @Param   page query int false "Nomor halaman"
+// @Param   limit query int false "Jumlah item per halaman"
+// @Param   entitas query string false "Jenis entitas (barang, kategori, merek, user, role, api_key, pembelian, penjualan, stok_opname, saldo_awal)"
+// @Param   entitas_id query string false "ID entitas (nama untuk role)"
+// @Param   user_id query int false "Filter pelaku"
+// @Param   aksi query string false "Filter aksi (create, update, delete)"
+// @Param   start_date query string false "Tanggal Mulai (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal Selesai (YYYY-MM-DD, inklusif)"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /audit [get]
+func (h *AuditHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.AuditFilter{
+		Entitas:   q.Get("entitas"),
+		EntitasID: q.Get("entitas_id"),
+		Aksi:      q.Get("aksi"),
+	}
+
+	var err error
+	if filter.StartDate, err = parseDateParam(q.Get("start_date")); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Parameter filter tidak valid")
+		return
+	}
+	if filter.EndDate, err = parseDateParam(q.Get("end_date")); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Parameter filter tidak valid")
+		return
+	}
+	if v := q.Get("user_id"); v != "" {
+		if filter.UserID, err = strconv.Atoi(v); err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Parameter filter tidak valid")
+			return
+		}
+	}
+	page, limit, offset := parsePagination(r)
+
+	logs, total, err := h.repo.GetAll(filter, limit, offset)
+	if err != nil {
+		log.Printf("gagal mengambil audit log: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil audit log")
+		return
+	}
+
+	utils.JSONWithMeta(w, "Data berhasil diambil", logs, models.Pagination{
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	})
+}
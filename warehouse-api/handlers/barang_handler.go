@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"warehouse-api/documents"
 	"warehouse-api/models"
 	"warehouse-api/repositories"
 	"warehouse-api/utils"
@@ -18,6 +21,56 @@ func NewBarangHandler(repo repositories.BarangRepository) *BarangHandler {
 	return &BarangHandler{repo}
 }
 
+// parseBarangFilter membaca filter listing barang dari query string
+func parseBarangFilter(r *http.Request) models.BarangFilter {
+	q := r.URL.Query()
+	kategoriID, _ := strconv.Atoi(q.Get("kategori_id"))
+	merekID, _ := strconv.Atoi(q.Get("merek_id"))
+
+	return models.BarangFilter{
+		Search:          q.Get("search"),
+		IncludeArchived: q.Get("include_archived") == "true",
+		KategoriID:      kategoriID,
+		MerekID:         merekID,
+		Tag:             q.Get("tag"),
+	}
+}
+
+// validateBarangRequest menerapkan aturan wajib barang (nama, harga positif) dan
+// mengisi satuan default. Dipakai oleh create, update dan import.
+func validateBarangRequest(req *models.CreateBarangRequest) error {
+	if strings.TrimSpace(req.NamaBarang) == "" {
+		return errors.New("Nama barang wajib diisi")
+	}
+	if req.HargaBeli <= 0 {
+		return errors.New("Harga beli wajib diisi")
+	}
+	if req.HargaJual <= 0 {
+		return errors.New("Harga jual wajib diisi")
+	}
+	if strings.TrimSpace(req.Satuan) == "" {
+		req.Satuan = "pcs"
+	}
+	return nil
+}
+
+// normalizeBarcodes memvalidasi daftar barcode dan membuang duplikat
+func normalizeBarcodes(codes []string) ([]string, error) {
+	barcodes := []string{}
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		code = utils.NormalizeBarcode(code)
+		if err := utils.ValidateBarcode(code); err != nil {
+			return nil, fmt.Errorf("Barcode %s: %w", code, err)
+		}
+		if !seen[code] {
+			seen[code] = true
+			barcodes = append(barcodes, code)
+		}
+	}
+	return barcodes, nil
+}
+
 // GetAll godoc
 // @Summary Ambil semua data barang
 // @Description Mengambil daftar barang dengan fitur pencarian, pagination, dan sorting.
@@ -29,28 +82,22 @@ func NewBarangHandler(repo repositories.BarangRepository) *BarangHandler {
 // @Param   limit query int false "Jumlah item per halaman"
 // @Param   sort_by query string false "Urutkan berdasarkan (harga_beli, harga_jual, nama, id)"
 // @Param   order query string false "Urutan (asc, desc)"
+// @Param   include_archived query bool false "Sertakan barang yang diarsipkan"
+// @Param   kategori_id query int false "Filter kategori (termasuk sub-kategori)"
+// @Param   merek_id query int false "Filter merek"
+// @Param   tag query string false "Filter tag"
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /barang [get]
 func (h *BarangHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	search := r.URL.Query().Get("search")
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
+	page, limit, offset := parsePagination(r)
     sortBy := r.URL.Query().Get("sort_by")
     order := r.URL.Query().Get("order")
 
-	page, _ := strconv.Atoi(pageStr)
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(limitStr)
-	if limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
+	filter := parseBarangFilter(r)
 
-	barangs, total, err := h.repo.GetAll(search, limit, offset, sortBy, order)
+	barangs, total, err := h.repo.GetAll(filter, limit, offset, sortBy, order)
 	if err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Server error")
 		return
@@ -75,28 +122,30 @@ func (h *BarangHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Param   limit query int false "Jumlah item per halaman"
 // @Param   sort_by query string false "Urutkan berdasarkan (harga_beli, harga_jual, nama, id, stok)"
 // @Param   order query string false "Urutan (asc, desc)"
+// @Param   include_archived query bool false "Sertakan barang yang diarsipkan"
+// @Param   kategori_id query int false "Filter kategori (termasuk sub-kategori)"
+// @Param   merek_id query int false "Filter merek"
+// @Param   tag query string false "Filter tag"
+// @Param   format query string false "Export seluruh hasil filter (csv, xlsx) alih-alih JSON"
+// @Param   locale query string false "Format angka/tanggal export (id, raw)"
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /barang/stok [get]
 func (h *BarangHandler) GetAllWithStok(w http.ResponseWriter, r *http.Request) {
-	search := r.URL.Query().Get("search")
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
+	page, limit, offset := parsePagination(r)
 	sortBy := r.URL.Query().Get("sort_by")
 	order := r.URL.Query().Get("order")
 
-	page, _ := strconv.Atoi(pageStr)
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(limitStr)
-	if limit < 1 {
-		limit = 10
+	filter := parseBarangFilter(r)
+
+	if format, locale, ok := exportRequest(r); ok {
+		h.exportWithStok(w, r, format, locale, filter, sortBy, order)
+		return
 	}
-	offset := (page - 1) * limit
 
-	barangs, total, err := h.repo.GetAllWithStok(search, limit, offset, sortBy, order)
+	barangs, total, err := h.repo.GetAllWithStok(filter, limit, offset, sortBy, order)
 	if err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Server error")
 		return
@@ -109,6 +158,20 @@ func (h *BarangHandler) GetAllWithStok(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *BarangHandler) exportWithStok(w http.ResponseWriter, r *http.Request, format, locale string, filter models.BarangFilter, sortBy, order string) {
+	headers := []string{"Kode Barang", "Nama Barang", "Satuan", "Harga Beli", "Harga Jual", "Stok", "Nilai Stok", "Tags", "Status"}
+	streamExport(w, format, locale, "stok-barang", headers, func(ew documents.ExportWriter) error {
+		return h.repo.StreamWithStok(r.Context(), filter, sortBy, order, func(b models.BarangWithStok) error {
+			status := "Aktif"
+			if !b.IsActive {
+				status = "Diarsipkan"
+			}
+			return ew.WriteRow(b.KodeBarang, b.NamaBarang, b.Satuan, b.HargaBeli, b.HargaJual, b.Stok,
+				float64(b.Stok)*b.HargaBeli, strings.Join(b.Tags, ", "), status)
+		})
+	})
+}
+
 // GetByID godoc
 // @Summary Ambil barang berdasarkan ID
 // @Description Mengambil detail barang spesifik
@@ -157,21 +220,16 @@ func (h *BarangHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.TrimSpace(req.NamaBarang) == "" {
-		utils.JSONError(w, http.StatusBadRequest, "Nama barang wajib diisi")
+	if err := validateBarangRequest(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if req.HargaBeli <= 0 {
-		utils.JSONError(w, http.StatusBadRequest, "Harga beli wajib diisi")
-		return
-	}
-	if req.HargaJual <= 0 {
-		utils.JSONError(w, http.StatusBadRequest, "Harga jual wajib diisi")
+
+	barcodes, err := normalizeBarcodes(req.Barcodes)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if strings.TrimSpace(req.Satuan) == "" {
-		req.Satuan = "pcs"
-	}
 
 	barang := &models.Barang{
 		NamaBarang: req.NamaBarang,
@@ -179,10 +237,18 @@ func (h *BarangHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Satuan:     req.Satuan,
 		HargaBeli:  req.HargaBeli,
 		HargaJual:  req.HargaJual,
+		KategoriID: req.KategoriID,
+		MerekID:    req.MerekID,
+		Tags:       req.Tags,
+		Barcodes:   barcodes,
 	}
 
-	err := h.repo.Create(barang)
+	err = h.repo.Create(r.Context(), barang)
 	if err != nil {
+		if errors.Is(err, repositories.ErrKategoriNotFound) || errors.Is(err, repositories.ErrMerekNotFound) || errors.Is(err, repositories.ErrBarcodeExists) {
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat barang: "+err.Error())
 		return
 	}
@@ -218,21 +284,10 @@ func (h *BarangHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.TrimSpace(req.NamaBarang) == "" {
-		utils.JSONError(w, http.StatusBadRequest, "Nama barang wajib diisi")
-		return
-	}
-	if req.HargaBeli <= 0 {
-		utils.JSONError(w, http.StatusBadRequest, "Harga beli wajib diisi")
-		return
-	}
-	if req.HargaJual <= 0 {
-		utils.JSONError(w, http.StatusBadRequest, "Harga jual wajib diisi")
+	if err := validateBarangRequest(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if strings.TrimSpace(req.Satuan) == "" {
-		req.Satuan = "pcs"
-	}
 
 	exists, _ := h.repo.Exists(id)
 	if !exists {
@@ -246,6 +301,12 @@ func (h *BarangHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// tags yang tidak dikirim dipertahankan; kirim [] untuk menghapus semua tag
+	tags := req.Tags
+	if tags == nil {
+		tags = existing.Tags
+	}
+
 	barang := &models.Barang{
 		ID:         id,
 		KodeBarang: existing.KodeBarang,
@@ -254,10 +315,20 @@ func (h *BarangHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Satuan:     req.Satuan,
 		HargaBeli:  req.HargaBeli,
 		HargaJual:  req.HargaJual,
+		KategoriID: req.KategoriID,
+		MerekID:    req.MerekID,
+		Tags:       tags,
+		Barcodes:   existing.Barcodes,
+		IsActive:   existing.IsActive,
+		DeletedAt:  existing.DeletedAt,
 	}
 
-	err = h.repo.Update(barang)
+	err = h.repo.Update(r.Context(), barang)
 	if err != nil {
+		if errors.Is(err, repositories.ErrKategoriNotFound) || errors.Is(err, repositories.ErrMerekNotFound) {
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.JSONError(w, http.StatusInternalServerError, "Gagal memperbarui barang")
 		return
 	}
@@ -266,8 +337,8 @@ func (h *BarangHandler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 // Delete godoc
-// @Summary Hapus barang
-// @Description Menghapus barang dari inventaris
+// @Summary Arsipkan barang
+// @Description Mengarsipkan barang (soft delete). Riwayat transaksi tetap utuh dan barang tidak bisa dipakai di transaksi baru sampai dipulihkan.
 // @Tags Barang
 // @Accept  json
 // @Produce  json
@@ -286,17 +357,180 @@ func (h *BarangHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	exists, _ := h.repo.Exists(id)
-	if !exists {
+	existing, err := h.repo.GetByID(id)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Barang tidak ditemukan")
+		return
+	}
+	if !existing.IsActive {
+		utils.JSONError(w, http.StatusBadRequest, "Barang sudah diarsipkan")
+		return
+	}
+
+	err = h.repo.Delete(r.Context(), id)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal mengarsipkan barang")
+		return
+	}
+
+	utils.JSONSuccess(w, "Barang berhasil diarsipkan", nil)
+}
+
+// Restore godoc
+// @Summary Pulihkan barang
+// @Description Mengaktifkan kembali barang yang sudah diarsipkan
+// @Tags Barang
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Barang"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /barang/{id}/restore [post]
+func (h *BarangHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	existing, err := h.repo.GetByID(id)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Barang tidak ditemukan")
+		return
+	}
+	if existing.IsActive {
+		utils.JSONError(w, http.StatusBadRequest, "Barang tidak dalam status arsip")
+		return
+	}
+
+	if err := h.repo.Restore(r.Context(), id); err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal memulihkan barang")
+		return
+	}
+
+	existing.IsActive = true
+	existing.DeletedAt = nil
+	utils.JSONSuccess(w, "Barang berhasil dipulihkan", existing)
+}
+
+// GetByBarcode godoc
+// @Summary Cari barang berdasarkan barcode
+// @Description Lookup barang beserta stok dari hasil scan barcode EAN-13/EAN-8/UPC-A
+// @Tags Barang
+// @Accept  json
+// @Produce  json
+// @Param   code path string true "Barcode"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /barang/barcode/{code} [get]
+func (h *BarangHandler) GetByBarcode(w http.ResponseWriter, r *http.Request) {
+	code := utils.NormalizeBarcode(r.PathValue("code"))
+	if err := utils.ValidateBarcode(code); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	barang, err := h.repo.GetByBarcode(code)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Barang dengan barcode tersebut tidak ditemukan")
+		return
+	}
+
+	utils.JSONSuccess(w, "Data berhasil diambil", barang)
+}
+
+// AddBarcode godoc
+// @Summary Tambah barcode barang
+// @Description Menambahkan barcode baru ke barang. Barcode harus unik dan check digit valid.
+// @Tags Barang
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Barang"
+// @Param   request body models.BarcodeRequest true "Barcode"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /barang/{id}/barcode [post]
+func (h *BarangHandler) AddBarcode(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	var req models.BarcodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	code := utils.NormalizeBarcode(req.Barcode)
+	if err := utils.ValidateBarcode(code); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := h.repo.GetByID(id); err != nil {
 		utils.JSONError(w, http.StatusNotFound, "Barang tidak ditemukan")
 		return
 	}
 
-	err = h.repo.Delete(id)
+	if err := h.repo.AddBarcode(r.Context(), id, code); err != nil {
+		if errors.Is(err, repositories.ErrBarcodeExists) {
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal menambahkan barcode")
+		return
+	}
+
+	barang, err := h.repo.GetByID(id)
 	if err != nil {
-		utils.JSONError(w, http.StatusInternalServerError, "Gagal menghapus barang")
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+
+	utils.JSONCreated(w, "Barcode berhasil ditambahkan", barang)
+}
+
+// RemoveBarcode godoc
+// @Summary Hapus barcode barang
+// @Description Menghapus salah satu barcode milik barang
+// @Tags Barang
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Barang"
+// @Param   code path string true "Barcode"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /barang/{id}/barcode/{code} [delete]
+func (h *BarangHandler) RemoveBarcode(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	code := utils.NormalizeBarcode(r.PathValue("code"))
+	if err := h.repo.RemoveBarcode(r.Context(), id, code); err != nil {
+		if errors.Is(err, repositories.ErrBarcodeNotFound) {
+			utils.JSONError(w, http.StatusNotFound, "Barcode tidak ditemukan pada barang ini")
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal menghapus barcode")
 		return
 	}
 
-	utils.JSONSuccess(w, "Barang berhasil dihapus", nil)
+	utils.JSONSuccess(w, "Barcode berhasil dihapus", nil)
 }
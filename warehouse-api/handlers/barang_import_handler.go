@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"warehouse-api/documents"
+	"warehouse-api/models"
+	"warehouse-api/utils"
+)
+
+// maxImportSize membatasi ukuran file import (10 MB cukup untuk puluhan ribu SKU)
+const maxImportSize = 10 << 20
+
+// Kolom yang dikenali pada file import (judul dinormalkan dengan documents.ColumnKey)
+var importColumns = map[string]bool{
+	"kode_barang": true, "nama_barang": true, "deskripsi": true, "satuan": true,
+	"harga_beli": true, "harga_jual": true, "kategori_id": true, "merek_id": true,
+	"tags": true, "barcodes": true,
+}
+
+// Import godoc
+// @Summary Import barang dari CSV/XLSX
+// @Description Import massal master barang. Kolom: kode_barang (opsional, upsert jika sudah ada; kode baru tidak boleh berpola BRG-<nomor>), nama_barang, deskripsi, satuan, harga_beli, harga_jual, kategori_id, merek_id, tags (pisahkan dengan koma), barcodes (pisahkan dengan koma).
+// @Description Import bersifat all-or-nothing: jika ada baris gagal tidak ada data yang disimpan. Gunakan dry_run=true untuk validasi tanpa menyimpan.
+// @Tags Barang
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   file formData file true "File CSV atau XLSX"
+// @Param   dry_run query bool false "Validasi saja tanpa menyimpan"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /barang/import [post]
+func (h *BarangHandler) Import(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	rows, format, ok := readUploadedSpreadsheet(w, r)
+	if !ok {
+		return
+	}
+
+	items, rowErrors, err := parseImportRows(rows, format)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := &models.BarangImportResult{DryRun: dryRun, Errors: []models.ImportRowError{}}
+	if len(items) > 0 {
+		// Jika sudah ada baris yang gagal validasi, repository tetap dijalankan (tanpa commit)
+		// agar laporan juga memuat error dari database seperti barcode duplikat
+		result, err = h.repo.Import(r.Context(), items, dryRun || len(rowErrors) > 0)
+		if err != nil {
+			utils.JSONError(w, http.StatusInternalServerError, "Gagal memproses import: "+err.Error())
+			return
+		}
+		result.DryRun = dryRun
+	}
+
+	result.Errors = mergeImportErrors(rowErrors, result.Errors)
+	result.TotalRows = len(items) + len(rowErrors)
+	result.Failed = len(result.Errors)
+
+	if result.Failed > 0 {
+		// Semua perubahan sudah di-rollback, jumlah created/updated hanya gambaran jika file diperbaiki
+		utils.JSONResponse(w, http.StatusBadRequest, false, fmt.Sprintf("Import dibatalkan: %d baris gagal", result.Failed), result, nil)
+		return
+	}
+	if dryRun {
+		utils.JSONSuccess(w, "Validasi import berhasil, tidak ada data yang disimpan", result)
+		return
+	}
+	utils.JSONSuccess(w, "Import barang berhasil", result)
+}
+
+// readUploadedSpreadsheet membaca file CSV/XLSX dari field multipart "file".
+// Jika gagal, response error sudah dikirim dan ok bernilai false.
+func readUploadedSpreadsheet(w http.ResponseWriter, r *http.Request) (rows [][]string, format string, ok bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "File import wajib diunggah (field 'file', maksimal 10 MB)")
+		return nil, "", false
+	}
+	defer file.Close()
+
+	format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+	if format != documents.ExportCSV && format != documents.ExportXLSX {
+		utils.JSONError(w, http.StatusBadRequest, "Format file harus .csv atau .xlsx")
+		return nil, "", false
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "File import tidak bisa dibaca")
+		return nil, "", false
+	}
+	rows, err = documents.ReadSpreadsheet(data, format)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return nil, "", false
+	}
+	return rows, format, true
+}
+
+// parseImportRows mengubah baris spreadsheet menjadi item import. Baris yang tidak valid
+// dikumpulkan sebagai error per baris; error hanya dikembalikan jika judul kolom tidak valid.
+func parseImportRows(rows [][]string, format string) ([]models.BarangImportItem, []models.ImportRowError, error) {
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("File import kosong")
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		key := documents.ColumnKey(name)
+		if importColumns[key] {
+			columns[key] = i
+		}
+	}
+	for _, required := range []string{"nama_barang", "harga_beli", "harga_jual"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("Kolom %s wajib ada di baris judul", required)
+		}
+	}
+
+	var items []models.BarangImportItem
+	var rowErrors []models.ImportRowError
+	for i, row := range rows[1:] {
+		rowNum := i + 2 // baris 1 adalah judul
+		get := func(col string) string {
+			idx, ok := columns[col]
+			if !ok || idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
+		}
+
+		if isBlankRow(row) {
+			continue
+		}
+
+		item, err := parseImportRow(get, format)
+		if err != nil {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: rowNum, KodeBarang: get("kode_barang"), Error: err.Error()})
+			continue
+		}
+		item.Row = rowNum
+		// Kolom tags yang tidak ada di file berarti tag barang lama tidak diubah
+		if _, ok := columns["tags"]; !ok {
+			item.Barang.Tags = nil
+		}
+		items = append(items, item)
+	}
+	return items, rowErrors, nil
+}
+
+func parseImportRow(get func(string) string, format string) (models.BarangImportItem, error) {
+	var item models.BarangImportItem
+	req := models.CreateBarangRequest{
+		NamaBarang: get("nama_barang"),
+		Deskripsi:  get("deskripsi"),
+		Satuan:     get("satuan"),
+		Tags:       splitList(get("tags")),
+	}
+
+	var err error
+	if req.HargaBeli, err = documents.ParseCellNumber(get("harga_beli"), format); err != nil {
+		return item, fmt.Errorf("Harga beli tidak valid")
+	}
+	if req.HargaJual, err = documents.ParseCellNumber(get("harga_jual"), format); err != nil {
+		return item, fmt.Errorf("Harga jual tidak valid")
+	}
+	if req.KategoriID, err = parseOptionalID(get("kategori_id")); err != nil {
+		return item, fmt.Errorf("kategori_id tidak valid")
+	}
+	if req.MerekID, err = parseOptionalID(get("merek_id")); err != nil {
+		return item, fmt.Errorf("merek_id tidak valid")
+	}
+	if err := validateBarangRequest(&req); err != nil {
+		return item, err
+	}
+	barcodes, err := normalizeBarcodes(splitList(get("barcodes")))
+	if err != nil {
+		return item, err
+	}
+
+	item.Barang = models.Barang{
+		KodeBarang: get("kode_barang"),
+		NamaBarang: strings.TrimSpace(req.NamaBarang),
+		Deskripsi:  req.Deskripsi,
+		Satuan:     req.Satuan,
+		HargaBeli:  req.HargaBeli,
+		HargaJual:  req.HargaJual,
+		KategoriID: req.KategoriID,
+		MerekID:    req.MerekID,
+		Tags:       req.Tags,
+		Barcodes:   barcodes,
+	}
+	return item, nil
+}
+
+func parseOptionalID(s string) (*int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 {
+		return nil, errInvalidQuery
+	}
+	return &id, nil
+}
+
+// splitList memecah nilai "a, b; c" menjadi daftar tanpa elemen kosong
+func splitList(s string) []string {
+	list := []string{}
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeImportErrors menggabungkan error validasi dan error database, urut berdasarkan nomor baris
+func mergeImportErrors(a, b []models.ImportRowError) []models.ImportRowError {
+	merged := make([]models.ImportRowError, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if j >= len(b) || (i < len(a) && a[i].Row <= b[j].Row) {
+			merged = append(merged, a[i])
+			i++
+		} else {
+			merged = append(merged, b[j])
+			j++
+		}
+	}
+	return merged
+}
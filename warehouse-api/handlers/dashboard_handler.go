@@ -1,11 +1,19 @@
 package handlers
 
 import (
+    "context"
+    "errors"
     "net/http"
+    "strconv"
+    "time"
+    "warehouse-api/models"
     "warehouse-api/repositories"
     "warehouse-api/utils"
 )
 
+// dashboardTimeout membatasi total waktu query dashboard
+const dashboardTimeout = 10 * time.Second
+
 type DashboardHandler struct {
     repo repositories.DashboardRepository
 }
@@ -16,20 +24,72 @@ func NewDashboardHandler(repo repositories.DashboardRepository) *DashboardHandle
 
 // GetStats godoc
 // @Summary Ambil statistik dashboard
-// @Description Mengambil ringkasan data gudang (Total Barang, Stok, Nilai Aset, Top Selling)
+// @Description Mengambil ringkasan data gudang (Total Barang, Stok, Nilai Aset, Top Selling) dan KPI periode:
+// @Description pendapatan, pembelian, HPP (qty terjual x harga beli barang), laba & margin kotor, jumlah faktur,
+// @Description rata-rata belanja per faktur, dead stock (stok > 0 tanpa penjualan dalam periode) dan stok menipis.
+// @Description Tanpa start_date/end_date KPI dihitung untuk semua waktu.
+// @Description Untuk visibilitas milik sendiri, pendapatan, HPP, pembelian, jumlah faktur dan top selling hanya dari transaksi yang terlihat; stok, aset dan dead stock tetap seluruh gudang.
 // @Tags Dashboard
 // @Accept  json
 // @Produce  json
+// @Param   start_date query string false "Tanggal awal (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal akhir, inklusif (YYYY-MM-DD)"
+// @Param   top query int false "Jumlah produk terlaris (default 5, maks 50)"
+// @Param   low_stock_threshold query int false "Batas stok menipis, inklusif (default 10)"
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
+// @Failure 504 {object} models.APIResponse
 // @Router /dashboard [get]
 func (h *DashboardHandler) GetStats(w http.ResponseWriter, r *http.Request) {
-    stats, err := h.repo.GetStats()
+    filter, err := parseDashboardFilter(r)
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(r.Context(), dashboardTimeout)
+    defer cancel()
+
+    stats, err := h.repo.GetStats(ctx, filter)
     if err != nil {
+        if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            utils.JSONError(w, http.StatusGatewayTimeout, "Waktu pengambilan data dashboard habis, persempit rentang tanggal")
+            return
+        }
         utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil data dashboard: "+err.Error())
         return
     }
 
     utils.JSONSuccess(w, "Data dashboard berhasil diambil", stats)
 }
+
+// parseDashboardFilter membaca parameter dashboard. Error yang dikembalikan siap ditampilkan ke pengguna.
+func parseDashboardFilter(r *http.Request) (models.DashboardFilter, error) {
+    params := r.URL.Query()
+    filter := models.DashboardFilter{TopN: 5, LowStockThreshold: 10}
+
+    var err error
+    if filter.StartDate, err = parseDateParam(params.Get("start_date")); err != nil {
+        return filter, errors.New("Parameter filter tidak valid")
+    }
+    if filter.EndDate, err = parseDateParam(params.Get("end_date")); err != nil {
+        return filter, errors.New("Parameter filter tidak valid")
+    }
+    if filter.StartDate != "" && filter.EndDate != "" && filter.StartDate > filter.EndDate {
+        return filter, errors.New("start_date tidak boleh setelah end_date")
+    }
+
+    if v := params.Get("top"); v != "" {
+        if filter.TopN, err = strconv.Atoi(v); err != nil || filter.TopN < 1 || filter.TopN > 50 {
+            return filter, errors.New("Parameter top harus 1 sampai 50")
+        }
+    }
+    if v := params.Get("low_stock_threshold"); v != "" {
+        if filter.LowStockThreshold, err = strconv.Atoi(v); err != nil || filter.LowStockThreshold < 0 {
+            return filter, errors.New("Parameter low_stock_threshold harus bilangan bulat >= 0")
+        }
+    }
+    return filter, nil
+}
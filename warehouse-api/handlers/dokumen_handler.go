@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"warehouse-api/config"
+	"warehouse-api/documents"
+	"warehouse-api/repositories"
+	"warehouse-api/utils"
+)
+
+type DokumenHandler struct {
+	penjualanRepo repositories.PenjualanRepository
+	pembelianRepo repositories.PembelianRepository
+	company       config.Company
+}
+
+func NewDokumenHandler(penjualanRepo repositories.PenjualanRepository, pembelianRepo repositories.PembelianRepository, company config.Company) *DokumenHandler {
+	return &DokumenHandler{penjualanRepo, pembelianRepo, company}
+}
+
+// writePDF mengirim hasil render PDF. Render dilakukan ke buffer agar error masih bisa dikirim sebagai JSON.
+func writePDF(w http.ResponseWriter, filename string, render func(buf *bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat dokumen: "+err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
+	w.Write(buf.Bytes())
+}
+
+// FakturPenjualan godoc
+// @Summary Cetak faktur penjualan
+// @Description Menghasilkan faktur penjualan (PDF) lengkap dengan harga dan terbilang
+// @Tags Penjualan
+// @Produce  application/pdf
+// @Param   id path int true "ID Transaksi"
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /penjualan/{id}/pdf [get]
+func (h *DokumenHandler) FakturPenjualan(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	transaksi, err := h.penjualanRepo.GetByID(r.Context(), id)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Transaksi tidak ditemukan")
+		return
+	}
+
+	writePDF(w, "faktur-"+transaksi.NoFaktur+".pdf", func(buf *bytes.Buffer) error {
+		return documents.RenderFakturPenjualan(buf, h.company, transaksi)
+	})
+}
+
+// SuratJalan godoc
+// @Summary Cetak surat jalan
+// @Description Menghasilkan surat jalan (PDF) untuk penjualan, tanpa harga
+// @Tags Penjualan
+// @Produce  application/pdf
+// @Param   id path int true "ID Transaksi"
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /penjualan/{id}/surat-jalan [get]
+func (h *DokumenHandler) SuratJalan(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	transaksi, err := h.penjualanRepo.GetByID(r.Context(), id)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Transaksi tidak ditemukan")
+		return
+	}
+
+	writePDF(w, "surat-jalan-"+transaksi.NoFaktur+".pdf", func(buf *bytes.Buffer) error {
+		return documents.RenderSuratJalan(buf, h.company, transaksi)
+	})
+}
+
+// NotaPembelian godoc
+// @Summary Cetak bukti pembelian
+// @Description Menghasilkan bukti penerimaan barang dari pembelian (PDF) dengan terbilang
+// @Tags Pembelian
+// @Produce  application/pdf
+// @Param   id path int true "ID Transaksi"
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /pembelian/{id}/pdf [get]
+func (h *DokumenHandler) NotaPembelian(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	transaksi, err := h.pembelianRepo.GetByID(r.Context(), id)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Transaksi tidak ditemukan")
+		return
+	}
+
+	writePDF(w, "pembelian-"+transaksi.NoFaktur+".pdf", func(buf *bytes.Buffer) error {
+		return documents.RenderNotaPembelian(buf, h.company, transaksi)
+	})
+}
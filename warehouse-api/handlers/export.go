@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"warehouse-api/documents"
+	"warehouse-api/utils"
+)
+
+// exportRequest membaca parameter format (csv|xlsx) dan locale (id|raw, default id).
+// ok bernilai false jika request bukan permintaan export.
+func exportRequest(r *http.Request) (format, locale string, ok bool) {
+	format = r.URL.Query().Get("format")
+	if format == "" {
+		return "", "", false
+	}
+	locale = r.URL.Query().Get("locale")
+	if locale == "" {
+		locale = documents.ExportLocaleID
+	}
+	return format, locale, true
+}
+
+// streamExport menulis file export langsung ke response. write dipanggil dengan writer yang
+// sudah berisi judul kolom dan sebaiknya meneruskan baris dari cursor repository satu per satu.
+func streamExport(w http.ResponseWriter, format, locale, name string, headers []string, write func(documents.ExportWriter) error) {
+	if !documents.ValidExportFormat(format, locale) {
+		utils.JSONError(w, http.StatusBadRequest, "Format export harus csv atau xlsx, locale harus id atau raw")
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format)
+	out := &exportResponseWriter{w: w, filename: filename, contentType: documents.ExportContentType(format)}
+
+	ew, err := documents.NewExportWriter(out, format, locale, name, headers)
+	if err == nil {
+		err = write(ew)
+	}
+	if err != nil && !out.started {
+		// Isi file masih tertahan di buffer writer, error masih bisa dikirim sebagai JSON
+		log.Printf("export %s gagal: %v", filename, err)
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat export")
+		return
+	}
+	if err == nil {
+		err = ew.Close()
+	}
+	if err != nil {
+		// Sebagian file sudah terkirim, error hanya bisa dicatat
+		log.Printf("export %s gagal: %v", filename, err)
+	}
+}
+
+// exportResponseWriter baru mengirim header file export saat byte pertama ditulis
+type exportResponseWriter struct {
+	w           http.ResponseWriter
+	filename    string
+	contentType string
+	started     bool
+}
+
+func (e *exportResponseWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.w.Header().Set("Content-Type", e.contentType)
+		e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.filename+`"`)
+	}
+	return e.w.Write(p)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"warehouse-api/auth"
+)
+
+type JWKSHandler struct {
+	keys *auth.KeySet
+}
+
+func NewJWKSHandler(keys *auth.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys}
+}
+
+// Get menyajikan kunci publik verifikasi token di /.well-known/jwks.json (tanpa autentikasi) agar
+// service internal lain bisa memverifikasi access token. Formatnya JWK Set standar (RFC 7517),
+// bukan APIResponse, sehingga tidak didokumentasikan di Swagger.
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// Kunci baru muncul di sini setelah restart; klien cukup cache sebentar dan refetch saat kid tidak dikenal
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.keys.JWKS())
+}
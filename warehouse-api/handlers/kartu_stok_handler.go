@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-api/config"
+	"warehouse-api/documents"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+type KartuStokHandler struct {
+	service services.KartuStokService
+	company config.Company
+}
+
+func NewKartuStokHandler(service services.KartuStokService, company config.Company) *KartuStokHandler {
+	return &KartuStokHandler{service, company}
+}
+
+// Get godoc
+// @Summary Kartu stok barang
+// @Description Mutasi satu barang dalam periode secara kronologis: saldo awal, barang masuk/keluar beserta dokumen referensi, saldo berjalan, nilai persediaan (rata-rata bergerak, null jika harga pokok tidak diketahui) dan saldo akhir.
+// @Description Periode default adalah awal bulan berjalan sampai hari ini. format=csv|xlsx|pdf untuk mengunduh.
+// @Description Untuk visibilitas milik sendiri, mutasi transaksi pengguna lain tidak ditampilkan dan tidak masuk total masuk/keluar, tetapi tetap dihitung ke saldo.
+// @Tags Stok
+// @Produce  json
+// @Param   id path int true "ID Barang"
+// @Param   start_date query string false "Tanggal awal (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal akhir, inklusif (YYYY-MM-DD)"
+// @Param   format query string false "Unduh sebagai csv, xlsx atau pdf"
+// @Param   locale query string false "Format angka/tanggal export: id (default) atau raw"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /stok/{id}/kartu [get]
+func (h *KartuStokHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	startDate, endDate, err := kartuStokPeriode(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Parameter filter tidak valid")
+		return
+	}
+
+	kartu, err := h.service.Get(r.Context(), id, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.JSONError(w, http.StatusNotFound, "Barang tidak ditemukan")
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+
+	name := "kartu-stok-" + kartu.Barang.KodeBarang
+	format, locale, ok := exportRequest(r)
+	if !ok {
+		utils.JSONSuccess(w, "Kartu stok berhasil diambil", kartu)
+		return
+	}
+	if format == "pdf" {
+		writePDF(w, name+".pdf", func(buf *bytes.Buffer) error {
+			return documents.RenderKartuStok(buf, h.company, kartu)
+		})
+		return
+	}
+
+	headers := []string{"Tanggal", "Jenis", "Referensi", "Keterangan", "Petugas", "Masuk", "Keluar", "Saldo", "Harga Satuan", "Nilai", "Nilai Saldo"}
+	streamExport(w, format, locale, name, headers, func(ew documents.ExportWriter) error {
+		if err := ew.WriteRow(nil, "", "", "Saldo awal", "", nil, nil, kartu.SaldoAwal, nil, nil, kartu.NilaiSaldoAwal); err != nil {
+			return err
+		}
+		for _, e := range kartu.Entries {
+			if err := ew.WriteRow(e.Tanggal, e.JenisTransaksi, e.Referensi, e.Keterangan, e.Petugas,
+				e.Masuk, e.Keluar, e.Saldo, e.HargaSatuan, e.Nilai, e.NilaiSaldo); err != nil {
+				return err
+			}
+		}
+		return ew.WriteRow(nil, "", "", "Saldo akhir", "", kartu.TotalMasuk, kartu.TotalKeluar, kartu.SaldoAkhir, nil, nil, kartu.NilaiSaldoAkhir)
+	})
+}
+
+// kartuStokPeriode membaca start_date/end_date. Default: awal bulan dari end_date (atau hari ini) s/d hari ini.
+func kartuStokPeriode(r *http.Request) (string, string, error) {
+	q := r.URL.Query()
+	startDate, err := parseDateParam(q.Get("start_date"))
+	if err != nil {
+		return "", "", err
+	}
+	endDate, err := parseDateParam(q.Get("end_date"))
+	if err != nil {
+		return "", "", err
+	}
+
+	end := time.Now()
+	if endDate == "" {
+		endDate = end.Format("2006-01-02")
+	} else {
+		end, _ = time.Parse("2006-01-02", endDate)
+	}
+	if startDate == "" {
+		startDate = time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	}
+	if startDate > endDate {
+		return "", "", errInvalidQuery
+	}
+	return startDate, endDate, nil
+}
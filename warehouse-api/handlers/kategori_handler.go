@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/utils"
+)
+
+type KategoriHandler struct {
+	repo repositories.KategoriRepository
+}
+
+func NewKategoriHandler(repo repositories.KategoriRepository) *KategoriHandler {
+	return &KategoriHandler{repo}
+}
+
+// buildKategoriTree menyusun daftar kategori datar menjadi pohon berdasarkan parent_id
+func buildKategoriTree(flat []models.Kategori) []models.Kategori {
+	children := make(map[int][]models.Kategori)
+	var roots []models.Kategori
+	for _, k := range flat {
+		if k.ParentID == nil {
+			roots = append(roots, k)
+		} else {
+			children[*k.ParentID] = append(children[*k.ParentID], k)
+		}
+	}
+
+	var attach func(nodes []models.Kategori) []models.Kategori
+	attach = func(nodes []models.Kategori) []models.Kategori {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots)
+}
+
+// GetAll godoc
+// @Summary Ambil semua kategori
+// @Description Mengambil kategori dalam bentuk pohon (default) atau daftar datar dengan flat=true
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   flat query bool false "Kembalikan daftar datar"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /kategori [get]
+func (h *KategoriHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	kategori, err := h.repo.GetAll()
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+
+	if r.URL.Query().Get("flat") == "true" {
+		utils.JSONSuccess(w, "Data kategori berhasil diambil", kategori)
+		return
+	}
+
+	utils.JSONSuccess(w, "Data kategori berhasil diambil", buildKategoriTree(kategori))
+}
+
+// Create godoc
+// @Summary Tambah kategori
+// @Description Menambahkan kategori baru. Isi parent_id untuk membuat sub-kategori.
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   request body models.CreateKategoriRequest true "Data Kategori"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /kategori [post]
+func (h *KategoriHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateKategoriRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if strings.TrimSpace(req.Nama) == "" {
+		utils.JSONError(w, http.StatusBadRequest, "Nama kategori wajib diisi")
+		return
+	}
+
+	if req.ParentID != nil {
+		if _, err := h.repo.GetByID(*req.ParentID); err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Kategori induk tidak ditemukan")
+			return
+		}
+	}
+
+	kategori := &models.Kategori{
+		Nama:     strings.TrimSpace(req.Nama),
+		ParentID: req.ParentID,
+	}
+
+	if err := h.repo.Create(r.Context(), kategori); err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat kategori")
+		return
+	}
+
+	utils.JSONCreated(w, "Kategori berhasil dibuat", kategori)
+}
+
+// Update godoc
+// @Summary Perbarui kategori
+// @Description Memperbarui nama atau memindahkan kategori ke induk lain
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Kategori"
+// @Param   request body models.CreateKategoriRequest true "Data Kategori"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /kategori/{id} [put]
+func (h *KategoriHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	var req models.CreateKategoriRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if strings.TrimSpace(req.Nama) == "" {
+		utils.JSONError(w, http.StatusBadRequest, "Nama kategori wajib diisi")
+		return
+	}
+
+	kategori, err := h.repo.GetByID(id)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Kategori tidak ditemukan")
+		return
+	}
+
+	if req.ParentID != nil {
+		if _, err := h.repo.GetByID(*req.ParentID); err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Kategori induk tidak ditemukan")
+			return
+		}
+	}
+
+	kategori.Nama = strings.TrimSpace(req.Nama)
+	kategori.ParentID = req.ParentID
+
+	if err := h.repo.Update(r.Context(), kategori); err != nil {
+		// Induk tidak boleh dirinya sendiri atau salah satu turunannya; diperiksa di tx update
+		if errors.Is(err, repositories.ErrKategoriSiklus) {
+			utils.JSONError(w, http.StatusBadRequest, "Kategori induk tidak boleh kategori itu sendiri atau turunannya")
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal memperbarui kategori")
+		return
+	}
+
+	utils.JSONSuccess(w, "Kategori berhasil diperbarui", kategori)
+}
+
+// Delete godoc
+// @Summary Hapus kategori
+// @Description Menghapus kategori yang tidak punya sub-kategori dan tidak dipakai barang
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Kategori"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /kategori/{id} [delete]
+func (h *KategoriHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if _, err := h.repo.GetByID(id); err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Kategori tidak ditemukan")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repositories.ErrKategoriInUse) {
+			utils.JSONError(w, http.StatusBadRequest, "Gagal menghapus: "+err.Error())
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal menghapus kategori")
+		return
+	}
+
+	utils.JSONSuccess(w, "Kategori berhasil dihapus", nil)
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+type KlasifikasiHandler struct {
+	service services.KlasifikasiService
+}
+
+func NewKlasifikasiHandler(service services.KlasifikasiService) *KlasifikasiHandler {
+	return &KlasifikasiHandler{service}
+}
+
+// Run godoc
+// @Summary Jalankan klasifikasi ABC/XYZ
+// @Description Mengklasifikasikan semua barang aktif dari penjualan dalam rentang (default 12 bulan terakhir) dan menyimpannya sebagai run baru (butuh report:manage).
+// @Description ABC menurut kontribusi kumulatif nilai penjualan (default A < 80%, B < 95%, sisanya C).
+// @Description XYZ menurut koefisien variasi qty per periode (default X <= 0.5, Y <= 1.0, sisanya atau tanpa penjualan Z).
+// @Tags Laporan
+// @Produce  json
+// @Param   start_date query string false "Tanggal awal (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal akhir, inklusif (YYYY-MM-DD)"
+// @Param   periode query string false "month (default) atau week"
+// @Param   batas_a query number false "Batas kumulatif kelas A dalam persen (default 80)"
+// @Param   batas_b query number false "Batas kumulatif kelas B dalam persen (default 95)"
+// @Param   batas_x query number false "Batas CV kelas X (default 0.5)"
+// @Param   batas_y query number false "Batas CV kelas Y (default 1.0)"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /reports/abc-xyz [post]
+func (h *KlasifikasiHandler) Run(w http.ResponseWriter, r *http.Request) {
+	params, err := parseKlasifikasiParams(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.UserID, _ = r.Context().Value(middleware.UserIDKey).(int)
+
+	report, err := h.service.Run(params)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal menjalankan klasifikasi")
+		return
+	}
+	utils.JSONCreated(w, "Klasifikasi ABC/XYZ berhasil dijalankan", report)
+}
+
+// Get godoc
+// @Summary Laporan klasifikasi ABC/XYZ
+// @Description Hasil run klasifikasi terakhir: matriks jumlah barang dan nilai per kelas, serta daftar barang urut kontribusi terbesar.
+// @Description Tanpa transaksi:read-all hanya kelas yang ditampilkan (terbatas=true): nilai, qty, kontribusi, kumulatif, frekuensi dan cv dikosongkan.
+// @Tags Laporan
+// @Produce  json
+// @Param   kelas_abc query string false "Filter kelas ABC, mis. A atau AB"
+// @Param   kelas_xyz query string false "Filter kelas XYZ, mis. X atau XY"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /reports/abc-xyz [get]
+func (h *KlasifikasiHandler) Get(w http.ResponseWriter, r *http.Request) {
+	filter := models.KlasifikasiFilter{
+		KelasABC: r.URL.Query().Get("kelas_abc"),
+		KelasXYZ: r.URL.Query().Get("kelas_xyz"),
+	}
+	report, err := h.service.Latest(r.Context(), filter)
+	if err != nil {
+		if !h.writeKlasifikasiError(w, err) {
+			utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil klasifikasi")
+		}
+		return
+	}
+	utils.JSONSuccess(w, "Klasifikasi ABC/XYZ berhasil diambil", report)
+}
+
+// CreateOpname godoc
+// @Summary Buat sesi stock opname per kelas
+// @Description Membuat sesi stock opname berisi barang aktif pada kelas ABC/XYZ terpilih dari run klasifikasi terakhir.
+// @Description Stok sistem setiap barang dibekukan saat sesi dibuat. Kelas kosong berarti semua kelas.
+// @Tags Stok
+// @Accept  json
+// @Produce  json
+// @Param   request body models.CreateOpnameRequest true "Kelas barang yang dihitung"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /stok-opname [post]
+func (h *KlasifikasiHandler) CreateOpname(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateOpnameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	opname, err := h.service.CreateOpname(r.Context(), req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrKlasifikasiNotFound):
+			utils.JSONError(w, http.StatusConflict, "Jalankan klasifikasi ABC/XYZ terlebih dahulu")
+		case errors.Is(err, repositories.ErrOpnameKosong):
+			utils.JSONError(w, http.StatusBadRequest, "Tidak ada barang pada kelas yang dipilih")
+		case !h.writeKlasifikasiError(w, err):
+			utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat sesi stock opname")
+		}
+		return
+	}
+	utils.JSONCreated(w, "Sesi stock opname berhasil dibuat", opname)
+}
+
+// GetAllOpname godoc
+// @Summary Daftar sesi stock opname
+// @Tags Stok
+// @Produce  json
+// @Param   page query int false "Halaman (default 1)"
+// @Param   limit query int false "Jumlah per halaman (default 10)"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /stok-opname [get]
+func (h *KlasifikasiHandler) GetAllOpname(w http.ResponseWriter, r *http.Request) {
+	page, limit, offset := parsePagination(r)
+	list, total, err := h.service.ListOpname(limit, offset)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+	utils.JSONWithMeta(w, "Data berhasil diambil", list, models.Pagination{
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	})
+}
+
+// GetOpname godoc
+// @Summary Detail sesi stock opname
+// @Description Mengambil sesi stock opname beserta daftar barang dan stok sistemnya
+// @Tags Stok
+// @Produce  json
+// @Param   id path int true "ID Stock Opname"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /stok-opname/{id} [get]
+func (h *KlasifikasiHandler) GetOpname(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "ID tidak valid")
+		return
+	}
+	opname, err := h.service.GetOpname(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.JSONError(w, http.StatusNotFound, "Sesi stock opname tidak ditemukan")
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+	utils.JSONSuccess(w, "Data berhasil diambil", opname)
+}
+
+// writeKlasifikasiError menangani error yang disebabkan input atau belum adanya run klasifikasi
+func (h *KlasifikasiHandler) writeKlasifikasiError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrKelasTidakValid):
+		utils.JSONError(w, http.StatusBadRequest, "kelas_abc hanya boleh berisi A, B, C dan kelas_xyz hanya X, Y, Z")
+	case errors.Is(err, repositories.ErrKlasifikasiNotFound):
+		utils.JSONError(w, http.StatusNotFound, "Klasifikasi ABC/XYZ belum pernah dijalankan")
+	default:
+		return false
+	}
+	return true
+}
+
+// parseKlasifikasiParams membaca parameter run klasifikasi. Error yang dikembalikan siap ditampilkan ke pengguna.
+func parseKlasifikasiParams(r *http.Request) (models.KlasifikasiParams, error) {
+	q := r.URL.Query()
+	params := models.KlasifikasiParams{Periode: q.Get("periode"), BatasA: 80, BatasB: 95, BatasX: 0.5, BatasY: 1.0}
+	if params.Periode == "" {
+		params.Periode = "month"
+	}
+	if params.Periode != "month" && params.Periode != "week" {
+		return params, errors.New("Parameter periode harus month atau week")
+	}
+
+	var err error
+	if params.StartDate, err = parseDateParam(q.Get("start_date")); err != nil {
+		return params, errors.New("Parameter filter tidak valid")
+	}
+	if params.EndDate, err = parseDateParam(q.Get("end_date")); err != nil {
+		return params, errors.New("Parameter filter tidak valid")
+	}
+	if params.EndDate == "" {
+		params.EndDate = time.Now().Format("2006-01-02")
+	}
+	if params.StartDate == "" {
+		end, _ := time.Parse("2006-01-02", params.EndDate)
+		params.StartDate = end.AddDate(-1, 0, 1).Format("2006-01-02")
+	}
+	if params.StartDate > params.EndDate {
+		return params, errors.New("start_date tidak boleh setelah end_date")
+	}
+	n := len(services.TimeBuckets(params.Periode, params.StartDate, params.EndDate))
+	if n < 2 {
+		return params, errors.New("Rentang tanggal minimal mencakup 2 periode untuk menghitung variabilitas")
+	}
+	if n > maxReportPoints {
+		return params, errors.New("Rentang tanggal terlalu panjang")
+	}
+
+	for _, p := range []struct {
+		name string
+		dest *float64
+	}{{"batas_a", &params.BatasA}, {"batas_b", &params.BatasB}, {"batas_x", &params.BatasX}, {"batas_y", &params.BatasY}} {
+		if v := q.Get(p.name); v != "" {
+			if *p.dest, err = strconv.ParseFloat(v, 64); err != nil {
+				return params, errors.New("Parameter " + p.name + " harus berupa angka")
+			}
+		}
+	}
+	if params.BatasA <= 0 || params.BatasA >= params.BatasB || params.BatasB > 100 {
+		return params, errors.New("Batas kelas harus 0 < batas_a < batas_b <= 100")
+	}
+	if params.BatasX <= 0 || params.BatasX >= params.BatasY {
+		return params, errors.New("Batas kelas harus 0 < batas_x < batas_y")
+	}
+	return params, nil
+}
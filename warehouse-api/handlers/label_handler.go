@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"warehouse-api/documents"
+	"warehouse-api/repositories"
+	"warehouse-api/utils"
+)
+
+type LabelHandler struct {
+	barangRepo repositories.BarangRepository
+}
+
+func NewLabelHandler(barangRepo repositories.BarangRepository) *LabelHandler {
+	return &LabelHandler{barangRepo}
+}
+
+// Print godoc
+// @Summary Cetak label barang
+// @Description Merender label rak berisi nama, barcode kode_barang dan harga jual. Format png untuk satu label, pdf untuk lembar stiker A4.
+// @Tags Barang
+// @Produce  png
+// @Produce  application/pdf
+// @Param   ids query string true "Daftar ID barang dipisah koma, misal 1,2,3"
+// @Param   format query string false "png atau pdf (default pdf)"
+// @Param   type query string false "Jenis barcode: code128 (default), ean13, qr"
+// @Param   copies query int false "Jumlah salinan per barang (default 1, hanya pdf)"
+// @Param   layout query string false "Layout lembar stiker KOLOMxBARIS (default 3x8)"
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /barang/label [get]
+func (h *LabelHandler) Print(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "png" && format != "pdf" {
+		utils.JSONError(w, http.StatusBadRequest, "Format harus png atau pdf")
+		return
+	}
+
+	symbology := q.Get("type")
+	if symbology == "" {
+		symbology = documents.SymbologyCode128
+	}
+	if !documents.ValidSymbology(symbology) {
+		utils.JSONError(w, http.StatusBadRequest, "Jenis barcode harus code128, ean13 atau qr")
+		return
+	}
+
+	var ids []int
+	for _, s := range strings.Split(q.Get("ids"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Daftar ID barang tidak valid")
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		utils.JSONError(w, http.StatusBadRequest, "Parameter ids wajib diisi")
+		return
+	}
+	if format == "png" && len(ids) != 1 {
+		utils.JSONError(w, http.StatusBadRequest, "Format png hanya untuk satu label, gunakan pdf untuk banyak label")
+		return
+	}
+
+	copies, _ := strconv.Atoi(q.Get("copies"))
+	if copies < 1 {
+		copies = 1
+	}
+	if len(ids)*copies > 1000 {
+		utils.JSONError(w, http.StatusBadRequest, "Maksimal 1000 label per permintaan")
+		return
+	}
+
+	layout, err := documents.ParseLabelLayout(q.Get("layout"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var labels []documents.Label
+	for _, id := range ids {
+		barang, err := h.barangRepo.GetByID(id)
+		if err != nil {
+			utils.JSONError(w, http.StatusNotFound, "Barang ID "+strconv.Itoa(id)+" tidak ditemukan")
+			return
+		}
+
+		label := documents.Label{
+			KodeBarang: barang.KodeBarang,
+			NamaBarang: barang.NamaBarang,
+			HargaJual:  barang.HargaJual,
+		}
+		for _, code := range barang.Barcodes {
+			if len(code) == 13 {
+				label.EAN = code
+				break
+			}
+		}
+		if symbology == documents.SymbologyEAN13 && label.EAN == "" {
+			utils.JSONError(w, http.StatusBadRequest, "Barang "+barang.KodeBarang+" tidak punya barcode EAN-13")
+			return
+		}
+
+		for i := 0; i < copies; i++ {
+			labels = append(labels, label)
+		}
+	}
+
+	if format == "png" {
+		img, err := documents.RenderLabelPNG(labels[0], symbology)
+		if err != nil {
+			utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat label: "+err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Disposition", `inline; filename="label-`+labels[0].KodeBarang+`.png"`)
+		w.Write(img)
+		return
+	}
+
+	writePDF(w, "label-barang.pdf", func(buf *bytes.Buffer) error {
+		return documents.RenderLabelSheetPDF(buf, labels, symbology, layout)
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/utils"
+)
+
+type MerekHandler struct {
+	repo repositories.MerekRepository
+}
+
+func NewMerekHandler(repo repositories.MerekRepository) *MerekHandler {
+	return &MerekHandler{repo}
+}
+
+// GetAll godoc
+// @Summary Ambil semua merek
+// @Description Mengambil daftar merek
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /merek [get]
+func (h *MerekHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	merek, err := h.repo.GetAll()
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+
+	utils.JSONSuccess(w, "Data merek berhasil diambil", merek)
+}
+
+// Create godoc
+// @Summary Tambah merek
+// @Description Menambahkan merek baru
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   request body models.CreateMerekRequest true "Data Merek"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /merek [post]
+func (h *MerekHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateMerekRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if strings.TrimSpace(req.Nama) == "" {
+		utils.JSONError(w, http.StatusBadRequest, "Nama merek wajib diisi")
+		return
+	}
+
+	merek := &models.Merek{Nama: strings.TrimSpace(req.Nama)}
+	if err := h.repo.Create(r.Context(), merek); err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat merek: "+err.Error())
+		return
+	}
+
+	utils.JSONCreated(w, "Merek berhasil dibuat", merek)
+}
+
+// Update godoc
+// @Summary Perbarui merek
+// @Description Memperbarui nama merek
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Merek"
+// @Param   request body models.CreateMerekRequest true "Data Merek"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /merek/{id} [put]
+func (h *MerekHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	var req models.CreateMerekRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if strings.TrimSpace(req.Nama) == "" {
+		utils.JSONError(w, http.StatusBadRequest, "Nama merek wajib diisi")
+		return
+	}
+
+	merek, err := h.repo.GetByID(id)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Merek tidak ditemukan")
+		return
+	}
+
+	merek.Nama = strings.TrimSpace(req.Nama)
+	if err := h.repo.Update(r.Context(), merek); err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal memperbarui merek")
+		return
+	}
+
+	utils.JSONSuccess(w, "Merek berhasil diperbarui", merek)
+}
+
+// Delete godoc
+// @Summary Hapus merek
+// @Description Menghapus merek yang tidak dipakai barang
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Merek"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /merek/{id} [delete]
+func (h *MerekHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if _, err := h.repo.GetByID(id); err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Merek tidak ditemukan")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repositories.ErrMerekInUse) {
+			utils.JSONError(w, http.StatusBadRequest, "Gagal menghapus: "+err.Error())
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal menghapus merek")
+		return
+	}
+
+	utils.JSONSuccess(w, "Merek berhasil dihapus", nil)
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
     "strconv"
     // "fmt" // Removed unused import
+	"warehouse-api/documents"
 	"warehouse-api/models"
 	"warehouse-api/repositories"
     "warehouse-api/services"
@@ -43,9 +45,14 @@ func (h *PembelianHandler) Create(w http.ResponseWriter, r *http.Request) {
     userID := r.Context().Value(middleware.UserIDKey).(int)
     req.UserID = userID
 
-    header, err := h.service.Create(req)
+    header, err := h.service.Create(r.Context(), req)
     if err != nil {
-        utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat transaksi: "+err.Error())
+        // Error validasi barang (tidak ditemukan / diarsipkan) adalah kesalahan input
+        if isTransaksiInputError(err) {
+            utils.JSONError(w, http.StatusBadRequest, err.Error())
+        } else {
+            utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat transaksi: "+err.Error())
+        }
         return
     }
 
@@ -54,27 +61,60 @@ func (h *PembelianHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // GetAll godoc
 // @Summary Ambil semua transaksi pembelian
-// @Description Mengambil daftar transaksi pembelian. Mendukung filter rentang tanggal.
+// @Description Mengambil daftar transaksi pembelian dengan pagination, sorting, dan filter.
 // @Tags Pembelian
 // @Accept  json
 // @Produce  json
+// @Param   page query int false "Nomor halaman"
+// @Param   limit query int false "Jumlah item per halaman"
+// @Param   sort_by query string false "Urutkan berdasarkan (tanggal, no_faktur, total, supplier, id)"
+// @Param   order query string false "Urutan (asc, desc)"
 // @Param   start_date query string false "Tanggal Mulai (YYYY-MM-DD)"
-// @Param   end_date query string false "Tanggal Selesai (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal Selesai (YYYY-MM-DD, inklusif)"
+// @Param   supplier query string false "Cari nama supplier"
+// @Param   user_id query int false "Filter petugas"
+// @Param   status query string false "Filter status"
+// @Param   no_faktur query string false "Awalan nomor faktur"
+// @Param   min_total query number false "Total minimum"
+// @Param   max_total query number false "Total maksimum"
+// @Param   format query string false "Export seluruh hasil filter (csv, xlsx) alih-alih JSON"
+// @Param   locale query string false "Format angka/tanggal export (id, raw)"
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /pembelian [get]
 func (h *PembelianHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-    startDate := r.URL.Query().Get("start_date")
-    endDate := r.URL.Query().Get("end_date")
+    filter, err := parseTransaksiFilter(r, "supplier")
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Parameter filter tidak valid")
+        return
+    }
+    page, limit, offset := parsePagination(r)
+    sortBy := r.URL.Query().Get("sort_by")
+    order := r.URL.Query().Get("order")
+
+    if format, locale, ok := exportRequest(r); ok {
+        headers := []string{"No Faktur", "Tanggal", "Supplier", "Petugas", "Status", "Total"}
+        streamExport(w, format, locale, "pembelian", headers, func(ew documents.ExportWriter) error {
+            return h.repo.Stream(r.Context(), filter, sortBy, order, func(t models.BeliHeader) error {
+                return ew.WriteRow(t.NoFaktur, t.CreatedAt, t.Supplier, t.User.Username, t.Status, t.Total)
+            })
+        })
+        return
+    }
 
-    transaksi, err := h.repo.GetAll(startDate, endDate)
+    transaksi, total, err := h.repo.GetAll(r.Context(), filter, limit, offset, sortBy, order)
     if err != nil {
         utils.JSONError(w, http.StatusInternalServerError, "Server error")
         return
     }
 
-    utils.JSONSuccess(w, "Data berhasil diambil", transaksi)
+    utils.JSONWithMeta(w, "Data berhasil diambil", transaksi, models.Pagination{
+        Page:  page,
+        Limit: limit,
+        Total: total,
+    })
 }
 
 // GetByID godoc
@@ -97,7 +137,7 @@ func (h *PembelianHandler) GetByID(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    transaksi, err := h.repo.GetByID(id)
+    transaksi, err := h.repo.GetByID(r.Context(), id)
     if err != nil {
         utils.JSONError(w, http.StatusNotFound, "Transaksi tidak ditemukan")
         return
@@ -105,3 +145,10 @@ func (h *PembelianHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
     utils.JSONSuccess(w, "Data berhasil diambil", transaksi)
 }
+
+// isTransaksiInputError memisahkan kesalahan isi detail transaksi (400) dari kegagalan server
+func isTransaksiInputError(err error) bool {
+    return errors.Is(err, services.ErrBarangWajib) || errors.Is(err, services.ErrBarangTidakDitemukan) ||
+        errors.Is(err, services.ErrBarangDiarsipkan) || errors.Is(err, services.ErrStokTidakCukup) ||
+        errors.Is(err, services.ErrBarcodeBedaBarang)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
     "strconv"
     // "fmt" // Removed unused import
+	"warehouse-api/documents"
 	"warehouse-api/models"
 	"warehouse-api/repositories"
     "warehouse-api/services"
@@ -43,11 +44,9 @@ func (h *PenjualanHandler) Create(w http.ResponseWriter, r *http.Request) {
     userID := r.Context().Value(middleware.UserIDKey).(int)
     req.UserID = userID
 
-    header, err := h.service.Create(req)
+    header, err := h.service.Create(r.Context(), req)
     if err != nil {
-        // Cek error string untuk menentukan status code (sederhana)
-        // Idealnya menggunakan error type khusus
-        if err.Error()[:4] == "stok" || err.Error()[:6] == "barang" {
+        if isTransaksiInputError(err) {
              utils.JSONError(w, http.StatusBadRequest, err.Error())
         } else {
              utils.JSONError(w, http.StatusInternalServerError, "Gagal memproses transaksi: "+err.Error())
@@ -60,27 +59,60 @@ func (h *PenjualanHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // GetAll godoc
 // @Summary Ambil semua transaksi penjualan
-// @Description Mengambil daftar transaksi penjualan. Mendukung filter rentang tanggal.
+// @Description Mengambil daftar transaksi penjualan dengan pagination, sorting, dan filter.
 // @Tags Penjualan
 // @Accept  json
 // @Produce  json
+// @Param   page query int false "Nomor halaman"
+// @Param   limit query int false "Jumlah item per halaman"
+// @Param   sort_by query string false "Urutkan berdasarkan (tanggal, no_faktur, total, customer, id)"
+// @Param   order query string false "Urutan (asc, desc)"
 // @Param   start_date query string false "Tanggal Mulai (YYYY-MM-DD)"
-// @Param   end_date query string false "Tanggal Selesai (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal Selesai (YYYY-MM-DD, inklusif)"
+// @Param   customer query string false "Cari nama customer"
+// @Param   user_id query int false "Filter petugas"
+// @Param   status query string false "Filter status"
+// @Param   no_faktur query string false "Awalan nomor faktur"
+// @Param   min_total query number false "Total minimum"
+// @Param   max_total query number false "Total maksimum"
+// @Param   format query string false "Export seluruh hasil filter (csv, xlsx) alih-alih JSON"
+// @Param   locale query string false "Format angka/tanggal export (id, raw)"
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /penjualan [get]
 func (h *PenjualanHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-    startDate := r.URL.Query().Get("start_date")
-    endDate := r.URL.Query().Get("end_date")
+    filter, err := parseTransaksiFilter(r, "customer")
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Parameter filter tidak valid")
+        return
+    }
+    page, limit, offset := parsePagination(r)
+    sortBy := r.URL.Query().Get("sort_by")
+    order := r.URL.Query().Get("order")
 
-    transaksi, err := h.repo.GetAll(startDate, endDate)
+    if format, locale, ok := exportRequest(r); ok {
+        headers := []string{"No Faktur", "Tanggal", "Customer", "Petugas", "Status", "Total"}
+        streamExport(w, format, locale, "penjualan", headers, func(ew documents.ExportWriter) error {
+            return h.repo.Stream(r.Context(), filter, sortBy, order, func(t models.JualHeader) error {
+                return ew.WriteRow(t.NoFaktur, t.CreatedAt, t.Customer, t.User.Username, t.Status, t.Total)
+            })
+        })
+        return
+    }
+
+    transaksi, total, err := h.repo.GetAll(r.Context(), filter, limit, offset, sortBy, order)
     if err != nil {
         utils.JSONError(w, http.StatusInternalServerError, "Server error")
         return
     }
 
-    utils.JSONSuccess(w, "Data berhasil diambil", transaksi)
+    utils.JSONWithMeta(w, "Data berhasil diambil", transaksi, models.Pagination{
+        Page:  page,
+        Limit: limit,
+        Total: total,
+    })
 }
 
 // GetByID godoc
@@ -103,7 +135,7 @@ func (h *PenjualanHandler) GetByID(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    transaksi, err := h.repo.GetByID(id)
+    transaksi, err := h.repo.GetByID(r.Context(), id)
     if err != nil {
         utils.JSONError(w, http.StatusNotFound, "Transaksi tidak ditemukan")
         return
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-api/models"
+)
+
+var errInvalidQuery = errors.New("parameter query tidak valid")
+
+// parsePagination membaca page & limit dari query string (default page 1, limit 10)
+func parsePagination(r *http.Request) (page, limit, offset int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+	return page, limit, (page - 1) * limit
+}
+
+// parseDateParam memvalidasi tanggal berformat YYYY-MM-DD. String kosong berarti tidak difilter.
+func parseDateParam(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", errInvalidQuery
+	}
+	return value, nil
+}
+
+func parseAmountParam(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < 0 {
+		return nil, errInvalidQuery
+	}
+	return &v, nil
+}
+
+// parseTransaksiFilter membaca filter listing penjualan/pembelian.
+// partyParam adalah "customer" atau "supplier".
+func parseTransaksiFilter(r *http.Request, partyParam string) (models.TransaksiFilter, error) {
+	q := r.URL.Query()
+	filter := models.TransaksiFilter{
+		Party:        q.Get(partyParam),
+		Status:       q.Get("status"),
+		FakturPrefix: q.Get("no_faktur"),
+	}
+
+	var err error
+	if filter.StartDate, err = parseDateParam(q.Get("start_date")); err != nil {
+		return filter, err
+	}
+	if filter.EndDate, err = parseDateParam(q.Get("end_date")); err != nil {
+		return filter, err
+	}
+	if v := q.Get("user_id"); v != "" {
+		if filter.UserID, err = strconv.Atoi(v); err != nil {
+			return filter, errInvalidQuery
+		}
+	}
+	if filter.MinTotal, err = parseAmountParam(q.Get("min_total")); err != nil {
+		return filter, err
+	}
+	if filter.MaxTotal, err = parseAmountParam(q.Get("max_total")); err != nil {
+		return filter, err
+	}
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		return filter, errInvalidQuery
+	}
+	return filter, nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+// maxReportPoints membatasi panjang seri waktu (mis. group_by=day maksimal ~3 tahun)
+const maxReportPoints = 1100
+
+type ReportHandler struct {
+	service services.ReportService
+}
+
+func NewReportHandler(service services.ReportService) *ReportHandler {
+	return &ReportHandler{service}
+}
+
+// Penjualan godoc
+// @Summary Laporan penjualan
+// @Description Seri total penjualan, qty dan jumlah faktur per kelompok. Rentang default 30 hari terakhir.
+// @Description Seri waktu (day/week/month) diisi nol untuk periode tanpa transaksi; barang/customer/user diurutkan dari total terbesar.
+// @Description Pengguna dengan visibilitas milik sendiri hanya menghitung penjualan yang dibuat atau dibagikan kepadanya.
+// @Tags Laporan
+// @Produce  json
+// @Param   group_by query string false "day (default), week, month, barang, customer, user"
+// @Param   start_date query string false "Tanggal awal (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal akhir, inklusif (YYYY-MM-DD)"
+// @Param   compare query bool false "Bandingkan dengan periode sebelumnya dengan panjang yang sama"
+// @Param   limit query int false "Jumlah kelompok untuk group_by non-waktu (default 10, maks 100)"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /reports/penjualan [get]
+func (h *ReportHandler) Penjualan(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, "penjualan")
+}
+
+// Pembelian godoc
+// @Summary Laporan pembelian
+// @Description Seri total pembelian, qty dan jumlah faktur per kelompok. Rentang default 30 hari terakhir.
+// @Description Seri waktu (day/week/month) diisi nol untuk periode tanpa transaksi; barang/supplier/user diurutkan dari total terbesar.
+// @Description Pengguna dengan visibilitas milik sendiri hanya menghitung pembelian yang dibuat atau dibagikan kepadanya.
+// @Tags Laporan
+// @Produce  json
+// @Param   group_by query string false "day (default), week, month, barang, supplier, user"
+// @Param   start_date query string false "Tanggal awal (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal akhir, inklusif (YYYY-MM-DD)"
+// @Param   compare query bool false "Bandingkan dengan periode sebelumnya dengan panjang yang sama"
+// @Param   limit query int false "Jumlah kelompok untuk group_by non-waktu (default 10, maks 100)"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /reports/pembelian [get]
+func (h *ReportHandler) Pembelian(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, "pembelian")
+}
+
+func (h *ReportHandler) report(w http.ResponseWriter, r *http.Request, jenis string) {
+	q, err := parseReportQuery(r, jenis)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.Get(r.Context(), q, r.URL.Query().Get("compare") == "true")
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidReport) {
+			utils.JSONError(w, http.StatusBadRequest, "Parameter group_by tidak valid")
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil laporan")
+		return
+	}
+	utils.JSONSuccess(w, "Laporan "+jenis+" berhasil diambil", report)
+}
+
+// parseReportQuery membaca parameter laporan. Error yang dikembalikan siap ditampilkan ke pengguna.
+func parseReportQuery(r *http.Request, jenis string) (models.ReportQuery, error) {
+	params := r.URL.Query()
+	q := models.ReportQuery{Jenis: jenis, GroupBy: params.Get("group_by"), Limit: 10}
+	if q.GroupBy == "" {
+		q.GroupBy = "day"
+	}
+
+	party := "customer"
+	if jenis == "pembelian" {
+		party = "supplier"
+	}
+	switch q.GroupBy {
+	case "day", "week", "month", "barang", "user", party:
+	default:
+		return q, errors.New("Parameter group_by harus day, week, month, barang, " + party + " atau user")
+	}
+
+	var err error
+	if q.StartDate, err = parseDateParam(params.Get("start_date")); err != nil {
+		return q, errors.New("Parameter filter tidak valid")
+	}
+	if q.EndDate, err = parseDateParam(params.Get("end_date")); err != nil {
+		return q, errors.New("Parameter filter tidak valid")
+	}
+	if q.EndDate == "" {
+		q.EndDate = time.Now().Format("2006-01-02")
+	}
+	if q.StartDate == "" {
+		end, _ := time.Parse("2006-01-02", q.EndDate)
+		q.StartDate = end.AddDate(0, 0, -29).Format("2006-01-02")
+	}
+	if q.StartDate > q.EndDate {
+		return q, errors.New("start_date tidak boleh setelah end_date")
+	}
+	if services.IsTimeGrouping(q.GroupBy) && len(services.TimeBuckets(q.GroupBy, q.StartDate, q.EndDate)) > maxReportPoints {
+		return q, errors.New("Rentang tanggal terlalu panjang untuk group_by=" + q.GroupBy)
+	}
+
+	if v := params.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 1 || q.Limit > 100 {
+			return q, errors.New("Parameter limit harus 1 sampai 100")
+		}
+	}
+	return q, nil
+}
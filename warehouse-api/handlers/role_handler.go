@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+type RoleHandler struct {
+	service services.RoleService
+}
+
+func NewRoleHandler(service services.RoleService) *RoleHandler {
+	return &RoleHandler{service}
+}
+
+// GetAll godoc
+// @Summary Daftar role
+// @Description Mengambil semua role beserta permission dan jumlah pengguna (butuh role:manage). Role admin selalu memiliki semua permission.
+// @Tags Auth
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /roles [get]
+func (h *RoleHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.service.GetAll()
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil data role")
+		return
+	}
+	utils.JSONSuccess(w, "Berhasil mengambil data role", roles)
+}
+
+// GetPermissions godoc
+// @Summary Daftar permission
+// @Description Katalog permission yang bisa diberikan ke role (butuh role:manage)
+// @Tags Auth
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /permissions [get]
+func (h *RoleHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, err := h.service.GetPermissions()
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil data permission")
+		return
+	}
+	utils.JSONSuccess(w, "Berhasil mengambil data permission", permissions)
+}
+
+// Create godoc
+// @Summary Tambah role
+// @Description Membuat role baru beserta permission-nya (butuh role:manage)
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.CreateRoleRequest true "Data Role"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /roles [post]
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	role, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	utils.JSONCreated(w, "Role berhasil dibuat", role)
+}
+
+// SetPermissions godoc
+// @Summary Atur permission role
+// @Description Mengganti seluruh permission sebuah role (butuh role:manage). Permission role admin tidak bisa diubah.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   nama path string true "Nama Role"
+// @Param   request body models.RolePermissionsRequest true "Daftar permission"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /roles/{nama}/permissions [put]
+func (h *RoleHandler) SetPermissions(w http.ResponseWriter, r *http.Request) {
+	var req models.RolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if err := h.service.SetPermissions(r.Context(), r.PathValue("nama"), req.Permissions); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	utils.JSONSuccess(w, "Permission role berhasil diperbarui", nil)
+}
+
+// Delete godoc
+// @Summary Hapus role
+// @Description Menghapus role yang tidak dipakai pengguna mana pun (butuh role:manage). Role sistem (admin, staff) tidak bisa dihapus.
+// @Tags Auth
+// @Produce  json
+// @Param   nama path string true "Nama Role"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /roles/{nama} [delete]
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), r.PathValue("nama")); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	utils.JSONSuccess(w, "Role berhasil dihapus", nil)
+}
+
+func writeRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoleNamaTidakValid), errors.Is(err, repositories.ErrPermissionNotFound):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRoleAdminTetap), errors.Is(err, repositories.ErrRoleSystem):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repositories.ErrRoleNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, repositories.ErrRoleExists), errors.Is(err, repositories.ErrRoleInUse):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal memproses role")
+	}
+}
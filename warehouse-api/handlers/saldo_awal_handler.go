@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+type SaldoAwalHandler struct {
+	service services.SaldoAwalService
+}
+
+func NewSaldoAwalHandler(service services.SaldoAwalService) *SaldoAwalHandler {
+	return &SaldoAwalHandler{service}
+}
+
+// Load godoc
+// @Summary Muat saldo awal stok
+// @Description Memuat saldo awal stok per barang dari CSV/XLSX saat go-live (butuh stok:adjust). Kolom: kode_barang, qty, harga (opsional, default harga beli barang).
+// @Description Setiap barang hanya bisa dimuat sekali; gunakan force=true untuk mengganti saldo awal (hanya selisihnya yang dibukukan). Proses bersifat all-or-nothing, gunakan dry_run=true untuk validasi.
+// @Tags Stok
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   file formData file true "File CSV atau XLSX"
+// @Param   force query bool false "Timpa saldo awal yang sudah pernah dimuat"
+// @Param   dry_run query bool false "Validasi saja tanpa menyimpan"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /stok/saldo-awal [post]
+func (h *SaldoAwalHandler) Load(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	rows, format, ok := readUploadedSpreadsheet(w, r)
+	if !ok {
+		return
+	}
+
+	opts := models.SaldoAwalOptions{
+		UserID: userID,
+		Force:  r.URL.Query().Get("force") == "true",
+		DryRun: r.URL.Query().Get("dry_run") == "true",
+	}
+	result, err := h.service.Load(r.Context(), rows, format, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrSaldoAwalFile) {
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal memuat saldo awal: "+err.Error())
+		return
+	}
+
+	if result.Failed > 0 {
+		utils.JSONResponse(w, http.StatusBadRequest, false, fmt.Sprintf("Saldo awal dibatalkan: %d baris gagal", result.Failed), result, nil)
+		return
+	}
+	if opts.DryRun {
+		utils.JSONSuccess(w, "Validasi saldo awal berhasil, tidak ada data yang disimpan", result)
+		return
+	}
+	utils.JSONSuccess(w, "Saldo awal stok berhasil dimuat", result)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+// ShareHandler mengatur pembagian transaksi ke pengguna yang visibilitasnya hanya milik sendiri
+type ShareHandler struct {
+	service services.ShareService
+}
+
+func NewShareHandler(service services.ShareService) *ShareHandler {
+	return &ShareHandler{service}
+}
+
+// ListPenjualan godoc
+// @Summary Daftar pembagian penjualan
+// @Description Pengguna yang diberi akses melihat transaksi penjualan ini selain pembuatnya
+// @Tags Penjualan
+// @Produce  json
+// @Param   id path int true "ID Transaksi"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /penjualan/{id}/share [get]
+func (h *ShareHandler) ListPenjualan(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, models.TransaksiPenjualan)
+}
+
+// SharePenjualan godoc
+// @Summary Bagikan penjualan
+// @Description Memberi pengguna lain akses lihat ke transaksi penjualan ini. Hanya pembuat transaksi atau pengguna dengan transaksi:read-all yang boleh membagikan.
+// @Tags Penjualan
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Transaksi"
+// @Param   request body models.ShareTransaksiRequest true "Pengguna tujuan"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /penjualan/{id}/share [post]
+func (h *ShareHandler) SharePenjualan(w http.ResponseWriter, r *http.Request) {
+	h.share(w, r, models.TransaksiPenjualan)
+}
+
+// UnsharePenjualan godoc
+// @Summary Cabut pembagian penjualan
+// @Description Mencabut akses lihat pengguna ke transaksi penjualan ini
+// @Tags Penjualan
+// @Produce  json
+// @Param   id path int true "ID Transaksi"
+// @Param   user_id path int true "ID pengguna"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /penjualan/{id}/share/{user_id} [delete]
+func (h *ShareHandler) UnsharePenjualan(w http.ResponseWriter, r *http.Request) {
+	h.unshare(w, r, models.TransaksiPenjualan)
+}
+
+// ListPembelian godoc
+// @Summary Daftar pembagian pembelian
+// @Description Pengguna yang diberi akses melihat transaksi pembelian ini selain pembuatnya
+// @Tags Pembelian
+// @Produce  json
+// @Param   id path int true "ID Transaksi"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /pembelian/{id}/share [get]
+func (h *ShareHandler) ListPembelian(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, models.TransaksiPembelian)
+}
+
+// SharePembelian godoc
+// @Summary Bagikan pembelian
+// @Description Memberi pengguna lain akses lihat ke transaksi pembelian ini. Hanya pembuat transaksi atau pengguna dengan transaksi:read-all yang boleh membagikan.
+// @Tags Pembelian
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Transaksi"
+// @Param   request body models.ShareTransaksiRequest true "Pengguna tujuan"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /pembelian/{id}/share [post]
+func (h *ShareHandler) SharePembelian(w http.ResponseWriter, r *http.Request) {
+	h.share(w, r, models.TransaksiPembelian)
+}
+
+// UnsharePembelian godoc
+// @Summary Cabut pembagian pembelian
+// @Description Mencabut akses lihat pengguna ke transaksi pembelian ini
+// @Tags Pembelian
+// @Produce  json
+// @Param   id path int true "ID Transaksi"
+// @Param   user_id path int true "ID pengguna"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /pembelian/{id}/share/{user_id} [delete]
+func (h *ShareHandler) UnsharePembelian(w http.ResponseWriter, r *http.Request) {
+	h.unshare(w, r, models.TransaksiPembelian)
+}
+
+func (h *ShareHandler) list(w http.ResponseWriter, r *http.Request, jenis string) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "ID transaksi tidak valid")
+		return
+	}
+
+	shares, err := h.service.List(r.Context(), jenis, id)
+	if err != nil {
+		shareError(w, err, "Gagal mengambil daftar pembagian")
+		return
+	}
+	if shares == nil {
+		shares = []models.TransaksiShare{}
+	}
+	utils.JSONSuccess(w, "Data berhasil diambil", shares)
+}
+
+func (h *ShareHandler) share(w http.ResponseWriter, r *http.Request, jenis string) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "ID transaksi tidak valid")
+		return
+	}
+	var req models.ShareTransaksiRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID <= 0 {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	share, err := h.service.Share(r.Context(), jenis, id, req.UserID)
+	if err != nil {
+		shareError(w, err, "Gagal membagikan transaksi")
+		return
+	}
+	utils.JSONCreated(w, "Transaksi berhasil dibagikan", share)
+}
+
+func (h *ShareHandler) unshare(w http.ResponseWriter, r *http.Request, jenis string) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "ID transaksi tidak valid")
+		return
+	}
+	userID, err := strconv.Atoi(r.PathValue("user_id"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "ID pengguna tidak valid")
+		return
+	}
+
+	if err := h.service.Unshare(r.Context(), jenis, id, userID); err != nil {
+		shareError(w, err, "Gagal mencabut pembagian transaksi")
+		return
+	}
+	utils.JSONSuccess(w, "Pembagian transaksi berhasil dicabut", nil)
+}
+
+func shareError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrSharePenggunaTidakAda), errors.Is(err, services.ErrShareKePemilik):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrShareDitolak):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrTransaksiNotFound), errors.Is(err, repositories.ErrShareNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("%s: %v", fallback, err)
+		utils.JSONError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -3,8 +3,12 @@ package handlers
 import (
 	"net/http"
 	"strconv"
-	
+	"time"
+
+	"warehouse-api/documents"
+	"warehouse-api/models"
 	"warehouse-api/repositories"
+	"warehouse-api/services"
     "warehouse-api/utils"
 )
 
@@ -19,15 +23,24 @@ func NewStokHandler(repo repositories.StokRepository) *StokHandler {
 
 // GetAll godoc
 // @Summary Ambil semua stok
-// @Description Mengambil data stok terkini untuk semua barang
+// @Description Mengambil data stok terkini untuk semua barang. Dengan as_of, stok per barang pada akhir tanggal tersebut direkonstruksi dari riwayat stok;
+// @Description jika tanggal itu sudah memiliki snapshot akhir bulan, angka snapshot yang dipakai (live=true untuk tetap menghitung dari riwayat).
 // @Tags Stok
 // @Accept  json
 // @Produce  json
+// @Param   as_of query string false "Tanggal posisi stok (YYYY-MM-DD)"
+// @Param   live query bool false "Abaikan snapshot dan hitung dari riwayat stok"
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
 // @Router /stok [get]
 func (h *StokHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("as_of") {
+		h.getAsOf(w, r)
+		return
+	}
+
 	stoks, err := h.repo.GetAll()
 	if err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Server error")
@@ -37,6 +50,111 @@ func (h *StokHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	utils.JSONSuccess(w, "Data stok berhasil diambil", stoks)
 }
 
+func (h *StokHandler) getAsOf(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	asOf, err := parseDateParam(q.Get("as_of"))
+	if err != nil || asOf == "" {
+		utils.JSONError(w, http.StatusBadRequest, "Parameter as_of harus berformat YYYY-MM-DD")
+		return
+	}
+
+	result := models.StokAsOfResult{AsOf: asOf, Source: "history"}
+	if q.Get("live") != "true" {
+		items, createdAt, err := h.repo.GetSnapshot(asOf)
+		if err != nil {
+			utils.JSONError(w, http.StatusInternalServerError, "Server error")
+			return
+		}
+		if createdAt != nil {
+			result.Source, result.SnapshotAt, result.Items = "snapshot", createdAt, items
+			utils.JSONSuccess(w, "Data stok berhasil diambil", result)
+			return
+		}
+	}
+
+	if result.Items, err = h.repo.GetAsOf(asOf); err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+	utils.JSONSuccess(w, "Data stok berhasil diambil", result)
+}
+
+// CreateSnapshot godoc
+// @Summary Buat snapshot stok
+// @Description Membekukan stok semua barang pada akhir tanggal periode (butuh stok:adjust). Server membuat snapshot akhir bulan secara otomatis;
+// @Description endpoint ini untuk periode yang terlewat. Snapshot yang sudah ada tidak bisa ditimpa.
+// @Tags Stok
+// @Produce  json
+// @Param   periode query string true "Tanggal periode (YYYY-MM-DD), harus sebelum hari ini"
+// @Security BearerAuth
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /stok/snapshot [post]
+func (h *StokHandler) CreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	periode, err := parseDateParam(r.URL.Query().Get("periode"))
+	if err != nil || periode == "" || periode >= time.Now().Format("2006-01-02") {
+		utils.JSONError(w, http.StatusBadRequest, "Periode harus tanggal sebelum hari ini (YYYY-MM-DD)")
+		return
+	}
+
+	exists, err := h.repo.HasSnapshot(periode)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+	if exists {
+		utils.JSONError(w, http.StatusConflict, "Snapshot stok periode "+periode+" sudah ada")
+		return
+	}
+
+	n, err := h.repo.CreateSnapshot(r.Context(), periode)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat snapshot stok")
+		return
+	}
+	utils.JSONCreated(w, "Snapshot stok berhasil dibuat", map[string]interface{}{"periode": periode, "jumlah_barang": n})
+}
+
+// VerifyHistory godoc
+// @Summary Verifikasi hash chain riwayat stok
+// @Description Menelusuri hash chain history_stok per barang dan melaporkan link rusak pertama (baris diubah, dihapus atau disisipkan langsung di database).
+// @Description Respons tetap 200; lihat field valid dan rusak (butuh audit:read).
+// @Tags Stok
+// @Produce  json
+// @Param   barang_id query int false "Periksa satu barang saja"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /history-stok/verify [get]
+func (h *StokHandler) VerifyHistory(w http.ResponseWriter, r *http.Request) {
+	barangID := 0
+	if v := r.URL.Query().Get("barang_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Parameter barang_id tidak valid")
+			return
+		}
+		barangID = id
+	}
+
+	report, err := services.VerifyHistoryChain(h.repo, barangID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Gagal memverifikasi riwayat stok")
+		return
+	}
+
+	message := "Hash chain riwayat stok utuh"
+	if !report.Valid {
+		message = "Hash chain riwayat stok rusak"
+	}
+	utils.JSONSuccess(w, message, report)
+}
+
 // GetByBarangID godoc
 // @Summary Ambil stok berdasarkan ID barang
 // @Description Mengambil informasi stok untuk barang tertentu
@@ -68,11 +186,25 @@ func (h *StokHandler) GetByBarangID(w http.ResponseWriter, r *http.Request) {
 
 // GetHistory godoc
 // @Summary Ambil riwayat stok
-// @Description Mengambil riwayat pergerakan stok. Opsional: filter berdasarkan ID barang.
+// @Description Mengambil riwayat pergerakan stok. Mode default memakai page/limit (meta berisi total).
+// @Description Untuk data besar gunakan mode cursor: kirim `cursor` (kosong untuk halaman pertama) lalu lanjutkan dengan `meta.next_cursor` sampai kosong. Mode cursor selalu terurut dari yang terbaru dan tidak menghitung total.
 // @Tags Stok
 // @Accept  json
 // @Produce  json
 // @Param   id path int false "ID Barang (opsional)"
+// @Param   cursor query string false "Cursor dari meta.next_cursor (mengaktifkan mode cursor)"
+// @Param   search query string false "Cari berdasarkan nama/kode barang"
+// @Param   page query int false "Nomor halaman"
+// @Param   limit query int false "Jumlah item per halaman"
+// @Param   sort_by query string false "Urutkan berdasarkan (tanggal, jumlah, id)"
+// @Param   order query string false "Urutan (asc, desc)"
+// @Param   barang_id query int false "Filter barang"
+// @Param   user_id query int false "Filter petugas"
+// @Param   jenis_transaksi query string false "Filter jenis transaksi (masuk, keluar)"
+// @Param   start_date query string false "Tanggal Mulai (YYYY-MM-DD)"
+// @Param   end_date query string false "Tanggal Selesai (YYYY-MM-DD, inklusif)"
+// @Param   format query string false "Export seluruh hasil filter (csv, xlsx) alih-alih JSON"
+// @Param   locale query string false "Format angka/tanggal export (id, raw)"
 // @Security BearerAuth
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.APIResponse
@@ -80,22 +212,97 @@ func (h *StokHandler) GetByBarangID(w http.ResponseWriter, r *http.Request) {
 // @Router /history-stok [get]
 // @Router /history-stok/{id} [get]
 func (h *StokHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
-    idStr := r.PathValue("id") // Opsional, untuk riwayat barang tertentu
-    var barangID int
-    if idStr != "" {
-        var err error
-        barangID, err = strconv.Atoi(idStr)
-        if err != nil {
-            utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
-            return
-        }
+    q := r.URL.Query()
+    filter, err := parseHistoryFilter(r)
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Parameter filter tidak valid")
+        return
+    }
+
+    if format, locale, ok := exportRequest(r); ok {
+        headers := []string{"Waktu", "Kode Barang", "Nama Barang", "Jenis", "Jumlah", "Stok Sebelum", "Stok Sesudah", "Petugas", "Keterangan"}
+        streamExport(w, format, locale, "riwayat-stok", headers, func(ew documents.ExportWriter) error {
+            return h.repo.StreamHistory(r.Context(), filter, func(hs models.HistoryStok) error {
+                return ew.WriteRow(hs.CreatedAt, hs.Barang.KodeBarang, hs.Barang.NamaBarang, hs.JenisTransaksi, hs.Jumlah,
+                    hs.StokSebelum, hs.StokSesudah, hs.User.Username, hs.Keterangan)
+            })
+        })
+        return
     }
 
-	history, err := h.repo.GetHistory(barangID)
+    if q.Has("cursor") {
+        h.getHistoryByCursor(w, r, filter)
+        return
+    }
+
+    page, limit, offset := parsePagination(r)
+
+	history, total, err := h.repo.GetHistory(r.Context(), filter, limit, offset, q.Get("sort_by"), q.Get("order"))
 	if err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Server error")
 		return
 	}
 
-	utils.JSONSuccess(w, "Riwayat stok berhasil diambil", history)
+	utils.JSONWithMeta(w, "Riwayat stok berhasil diambil", history, models.Pagination{
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	})
+}
+
+func (h *StokHandler) getHistoryByCursor(w http.ResponseWriter, r *http.Request, filter models.HistoryStokFilter) {
+    var cursor *models.HistoryCursor
+    if raw := r.URL.Query().Get("cursor"); raw != "" {
+        createdAt, id, err := utils.DecodeCursor(raw)
+        if err != nil {
+            utils.JSONError(w, http.StatusBadRequest, "Cursor tidak valid")
+            return
+        }
+        cursor = &models.HistoryCursor{CreatedAt: createdAt, ID: id}
+    }
+    _, limit, _ := parsePagination(r)
+
+    history, next, err := h.repo.GetHistoryAfter(r.Context(), filter, cursor, limit)
+    if err != nil {
+        utils.JSONError(w, http.StatusInternalServerError, "Server error")
+        return
+    }
+
+    meta := models.CursorPagination{Limit: limit}
+    if next != nil {
+        meta.NextCursor = utils.EncodeCursor(next.CreatedAt, next.ID)
+    }
+    utils.JSONWithCursor(w, "Riwayat stok berhasil diambil", history, meta)
+}
+
+// parseHistoryFilter membaca filter riwayat stok. ID barang bisa dari path atau query barang_id.
+func parseHistoryFilter(r *http.Request) (models.HistoryStokFilter, error) {
+    q := r.URL.Query()
+    filter := models.HistoryStokFilter{
+        Search:         q.Get("search"),
+        JenisTransaksi: q.Get("jenis_transaksi"),
+    }
+
+    var err error
+    barangStr := r.PathValue("id") // Opsional, untuk riwayat barang tertentu
+    if barangStr == "" {
+        barangStr = q.Get("barang_id")
+    }
+    if barangStr != "" {
+        if filter.BarangID, err = strconv.Atoi(barangStr); err != nil {
+            return filter, errInvalidQuery
+        }
+    }
+    if v := q.Get("user_id"); v != "" {
+        if filter.UserID, err = strconv.Atoi(v); err != nil {
+            return filter, errInvalidQuery
+        }
+    }
+    if filter.StartDate, err = parseDateParam(q.Get("start_date")); err != nil {
+        return filter, err
+    }
+    if filter.EndDate, err = parseDateParam(q.Get("end_date")); err != nil {
+        return filter, err
+    }
+    return filter, nil
 }
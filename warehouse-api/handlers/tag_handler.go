@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"warehouse-api/repositories"
+	"warehouse-api/utils"
+)
+
+type TagHandler struct {
+	repo repositories.TagRepository
+}
+
+func NewTagHandler(repo repositories.TagRepository) *TagHandler {
+	return &TagHandler{repo}
+}
+
+// GetAll godoc
+// @Summary Ambil semua tag
+// @Description Mengambil daftar tag beserta jumlah barang aktif yang memakainya
+// @Tags Kategori
+// @Accept  json
+// @Produce  json
+// @Param   search query string false "Cari tag"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /tag [get]
+func (h *TagHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.repo.GetAll(r.URL.Query().Get("search"))
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Server error")
+		return
+	}
+
+	utils.JSONSuccess(w, "Data tag berhasil diambil", tags)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+	"warehouse-api/utils"
+)
+
+// TwoFactorHandler menangani pendaftaran dan pengelolaan 2FA milik pengguna yang sedang login
+type TwoFactorHandler struct {
+	service services.TwoFactorService
+}
+
+func NewTwoFactorHandler(service services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{service}
+}
+
+// Setup godoc
+// @Summary Mulai pendaftaran 2FA
+// @Description Membuat secret TOTP baru beserta URI otpauth dan QR (data URL PNG). 2FA belum aktif sampai dikonfirmasi lewat POST /me/2fa/enable.
+// @Tags Auth
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /me/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	setup, err := h.service.Setup(userID)
+	if err != nil {
+		twoFactorError(w, err, "Gagal memulai pendaftaran 2FA")
+		return
+	}
+
+	utils.JSONSuccess(w, "Pindai QR dengan aplikasi authenticator lalu konfirmasi dengan kodenya", setup)
+}
+
+// Enable godoc
+// @Summary Aktifkan 2FA
+// @Description Mengonfirmasi pendaftaran dengan kode pertama dari aplikasi authenticator. Respons berisi kode pemulihan yang hanya ditampilkan sekali.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.TwoFactorCodeRequest true "Kode TOTP"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /me/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	codes, err := h.service.Enable(r.Context(), userID, req.Code)
+	if err != nil {
+		twoFactorError(w, err, "Gagal mengaktifkan 2FA")
+		return
+	}
+
+	utils.JSONSuccess(w, "2FA berhasil diaktifkan, simpan kode pemulihan di tempat aman", models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Nonaktifkan 2FA
+// @Description Mematikan 2FA setelah memasukkan password dan kode TOTP (atau kode pemulihan). Tidak tersedia untuk role yang wajib 2FA.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.DisableTwoFactorRequest true "Password dan kode"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /me/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	var req models.DisableTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	if err := h.service.Disable(r.Context(), userID, req.Password, req.Code); err != nil {
+		twoFactorError(w, err, "Gagal menonaktifkan 2FA")
+		return
+	}
+
+	utils.JSONSuccess(w, "2FA berhasil dinonaktifkan", nil)
+}
+
+// RecoveryCodes godoc
+// @Summary Buat ulang kode pemulihan
+// @Description Mengganti semua kode pemulihan lama dengan yang baru (butuh kode TOTP atau kode pemulihan yang masih berlaku)
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.TwoFactorCodeRequest true "Kode"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		twoFactorError(w, err, "Gagal membuat kode pemulihan")
+		return
+	}
+
+	utils.JSONSuccess(w, "Kode pemulihan baru berhasil dibuat, kode lama tidak berlaku lagi", models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func twoFactorError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrKode2FASalah), errors.Is(err, services.ErrPasswordSalah),
+		errors.Is(err, services.Err2FASudahAktif), errors.Is(err, services.Err2FABelumAktif),
+		errors.Is(err, services.Err2FABelumSetup):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.Err2FAWajib):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repositories.ErrUserNotFound):
+		utils.JSONError(w, http.StatusUnauthorized, err.Error())
+	default:
+		log.Printf("%s: %v", fallback, err)
+		utils.JSONError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -1,29 +1,38 @@
 package handlers
 
 import (
+    "database/sql"
     "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "math"
     "net/http"
-    "os"
+    "strconv"
     "time"
+    "warehouse-api/middleware"
     "warehouse-api/models"
+    "warehouse-api/repositories"
     "warehouse-api/services"
     
-    "warehouse-api/middleware"
     "warehouse-api/utils"
-    "github.com/golang-jwt/jwt/v5"
 )
 
 type UserHandler struct {
-    service services.UserService
+    service   services.UserService
+    tokens    services.TokenService
+    guard     services.LoginGuard
+    twoFactor services.TwoFactorService
 }
 
-func NewUserHandler(service services.UserService) *UserHandler {
-    return &UserHandler{service}
+func NewUserHandler(service services.UserService, tokens services.TokenService, guard services.LoginGuard, twoFactor services.TwoFactorService) *UserHandler {
+    return &UserHandler{service, tokens, guard, twoFactor}
 }
 
 // Register godoc
 // @Summary Mendaftarkan pengguna baru
-// @Description Mendaftarkan pengguna baru (hanya admin)
+// @Description Mendaftarkan pengguna baru (butuh user:manage). Role harus salah satu role yang terdaftar.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
@@ -35,21 +44,14 @@ func NewUserHandler(service services.UserService) *UserHandler {
 // @Failure 500 {object} models.APIResponse
 // @Router /register [post]
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
-    // 1. Cek Peran Pengguna
-    role := r.Context().Value(middleware.RoleKey).(string)
-    if role != "admin" {
-         utils.JSONError(w, http.StatusForbidden, "Akses ditolak: Hanya admin yang dapat mendaftarkan pengguna baru")
-         return
-    }
-
-    // 2. Parsing Data Masukan
+    // 1. Parsing Data Masukan
     var req models.RegisterRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
          utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
          return
     }
 
-    user, err := h.service.Register(&req)
+    user, err := h.service.Register(r.Context(), &req)
     if err != nil {
          utils.JSONError(w, http.StatusBadRequest, err.Error())
          return
@@ -60,7 +62,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 // GetAll Users
 // @Summary Mendapatkan semua pengguna
-// @Description Mendapatkan daftar semua pengguna (hanya admin)
+// @Description Mendapatkan daftar semua pengguna (butuh user:manage)
 // @Tags Auth
 // @Accept  json
 // @Produce  json
@@ -71,14 +73,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 // @Failure 500 {object} models.APIResponse
 // @Router /users [get]
 func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-    // 1. Cek Peran Pengguna
-    role := r.Context().Value(middleware.RoleKey).(string)
-    if role != "admin" {
-         utils.JSONError(w, http.StatusForbidden, "Akses ditolak: Hanya admin yang dapat melihat daftar pengguna")
-         return
-    }
-
-    // 2. Ambil Data
+    // 1. Ambil Data
     users, err := h.service.GetAll()
     if err != nil {
          utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil data pengguna")
@@ -90,7 +85,9 @@ func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 
 // Login godoc
 // @Summary Masuk sistem
-// @Description Otentikasi pengguna dan mendapatkan token JWT
+// @Description Otentikasi pengguna. Mengembalikan access token JWT berumur pendek (expires_in detik) dan refresh token untuk POST /refresh.
+// @Description Username dikunci sementara setelah beberapa kali gagal berturut-turut (429 dengan header Retry-After).
+// @Description Bila akun memakai (atau wajib) 2FA, data berisi two_factor_required dan challenge_token untuk POST /login/2fa, bukan token.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
@@ -98,6 +95,8 @@ func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.APIResponse
 // @Failure 401 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
 // @Router /login [post]
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
     var req models.LoginRequest
@@ -106,41 +105,510 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    // Kunci dicek sebelum password agar password yang benar pun tidak bisa dikonfirmasi selama terkunci
+    wait, err := h.guard.Check(req.Username)
+    if err != nil {
+        log.Printf("gagal memeriksa kunci login %q: %v", req.Username, err)
+        utils.JSONError(w, http.StatusInternalServerError, "Gagal memproses login")
+        return
+    }
+    if wait > 0 {
+        seconds := int(math.Ceil(wait.Seconds()))
+        w.Header().Set("Retry-After", strconv.Itoa(seconds))
+        utils.JSONError(w, http.StatusTooManyRequests, fmt.Sprintf("Terlalu banyak percobaan login gagal, coba lagi dalam %d detik", seconds))
+        return
+    }
+
     // Validate credentials using service
     user, err := h.service.ValidateCredentials(req.Username, req.Password)
     if err != nil {
+        attempt := &models.LoginAttempt{
+            Username:  req.Username,
+            IP:        middleware.ClientIP(r),
+            UserAgent: r.UserAgent(),
+            Alasan:    err.Error(),
+        }
+        if gErr := h.guard.Failed(attempt); gErr != nil {
+            log.Printf("gagal mencatat login gagal %q: %v", req.Username, gErr)
+        }
         utils.JSONError(w, http.StatusUnauthorized, err.Error())
         return
     }
 
-    // Generate Token JWT
-    secret := []byte(os.Getenv("JWT_SECRET"))
-    if len(secret) == 0 {
-        secret = []byte("supersecretkey")
+    // Hitungan gagal baru direset setelah langkah 2FA lolos
+    if h.twoFactor.Required(user) {
+        challenge, err := h.twoFactor.StartChallenge(user)
+        if err != nil {
+            log.Printf("gagal membuat challenge 2FA untuk user %d: %v", user.ID, err)
+            utils.JSONError(w, http.StatusInternalServerError, "Gagal memproses login")
+            return
+        }
+        utils.JSONSuccess(w, "Masukkan kode 2FA untuk melanjutkan login", challenge)
+        return
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "user_id": user.ID,
-        "username": user.Username,
-        "role":    user.Role,
-        "exp":     time.Now().Add(time.Hour * 24).Unix(),
-    })
+    h.completeLogin(w, user, nil)
+}
+
+// LoginTwoFactor godoc
+// @Summary Login langkah kedua (2FA)
+// @Description Menukar challenge_token dari POST /login dan kode TOTP (atau kode pemulihan) dengan access token dan refresh token.
+// @Description Untuk akun yang baru mendaftar lewat POST /login/2fa/setup, kode pertama ini sekaligus mengaktifkan 2FA dan respons berisi recovery_codes.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.TwoFactorLoginRequest true "Challenge token dan kode"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /login/2fa [post]
+func (h *UserHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+    var req models.TwoFactorLoginRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+        return
+    }
+
+    user, recovery, err := h.twoFactor.CompleteChallenge(r.Context(), req.ChallengeToken, req.Code)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrKode2FASalah):
+            attempt := &models.LoginAttempt{
+                Username:  user.Username,
+                IP:        middleware.ClientIP(r),
+                UserAgent: r.UserAgent(),
+                Alasan:    err.Error(),
+            }
+            if gErr := h.guard.Failed(attempt); gErr != nil {
+                log.Printf("gagal mencatat login gagal %q: %v", user.Username, gErr)
+            }
+            utils.JSONError(w, http.StatusUnauthorized, err.Error())
+        case errors.Is(err, services.ErrChallengeInvalid), errors.Is(err, repositories.ErrUserNotFound):
+            utils.JSONError(w, http.StatusUnauthorized, services.ErrChallengeInvalid.Error())
+        case errors.Is(err, services.Err2FABelumSetup):
+            utils.JSONError(w, http.StatusBadRequest, err.Error())
+        default:
+            log.Printf("gagal memeriksa kode 2FA: %v", err)
+            utils.JSONError(w, http.StatusInternalServerError, "Gagal memproses login")
+        }
+        return
+    }
 
-    tokenString, err := token.SignedString(secret)
+    // Permission diisi ulang lewat Profile karena challenge hanya menyimpan ID user
+    profile, err := h.service.Profile(user.ID)
     if err != nil {
+        log.Printf("gagal mengambil profil user %d: %v", user.ID, err)
+        utils.JSONError(w, http.StatusInternalServerError, "Gagal memproses login")
+        return
+    }
+    h.completeLogin(w, profile, recovery)
+}
+
+// LoginTwoFactorSetup godoc
+// @Summary Daftar 2FA saat login
+// @Description Untuk akun yang wajib 2FA tapi belum mendaftar (setup_required pada respons POST /login).
+// @Description Mengembalikan secret dan QR untuk aplikasi authenticator; lanjutkan dengan POST /login/2fa memakai kode dari aplikasi.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.TwoFactorChallengeRequest true "Challenge token"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /login/2fa/setup [post]
+func (h *UserHandler) LoginTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+    var req models.TwoFactorChallengeRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+        return
+    }
+
+    setup, err := h.twoFactor.ChallengeSetup(req.ChallengeToken)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrChallengeInvalid), errors.Is(err, repositories.ErrUserNotFound):
+            utils.JSONError(w, http.StatusUnauthorized, services.ErrChallengeInvalid.Error())
+        case errors.Is(err, services.Err2FASudahAktif):
+            utils.JSONError(w, http.StatusBadRequest, err.Error())
+        default:
+            log.Printf("gagal memulai pendaftaran 2FA: %v", err)
+            utils.JSONError(w, http.StatusInternalServerError, "Gagal memulai pendaftaran 2FA")
+        }
+        return
+    }
+
+    utils.JSONSuccess(w, "Pindai QR dengan aplikasi authenticator lalu kirim kodenya", setup)
+}
+
+// completeLogin menerbitkan token setelah semua langkah login lolos
+func (h *UserHandler) completeLogin(w http.ResponseWriter, user *models.User, recovery []string) {
+    if err := h.guard.Succeeded(user.Username); err != nil {
+        log.Printf("gagal mereset hitungan login %q: %v", user.Username, err)
+    }
+
+    tokens, err := h.tokens.Issue(user)
+    if err != nil {
+        log.Printf("gagal menerbitkan token untuk user %d: %v", user.ID, err)
         utils.JSONError(w, http.StatusInternalServerError, "Gagal membuat token")
         return
     }
 
-    resp := models.APIResponse{
-        Success: true,
-        Message: "Login berhasil",
-        Data: models.LoginResponse{
-            Token: tokenString,
-            User:  *user,
-        },
+    utils.JSONSuccess(w, "Login berhasil", models.LoginResponse{
+        TokenResponse: *tokens,
+        User:          *user,
+        RecoveryCodes: recovery,
+    })
+}
+
+// Refresh godoc
+// @Summary Perbarui access token
+// @Description Menukar refresh token dengan access token dan refresh token baru. Refresh token lama langsung tidak berlaku;
+// @Description memakai ulang refresh token lama mencabut seluruh sesi tersebut.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /refresh [post]
+func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+    var req models.RefreshRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+        return
+    }
+
+    tokens, err := h.tokens.Refresh(req.RefreshToken)
+    if err != nil {
+        if errors.Is(err, services.ErrRefreshTokenInvalid) || errors.Is(err, services.ErrRefreshTokenReused) || errors.Is(err, services.ErrAkunNonaktif) {
+            utils.JSONError(w, http.StatusUnauthorized, err.Error())
+            return
+        }
+        log.Printf("gagal merotasi refresh token: %v", err)
+        utils.JSONError(w, http.StatusInternalServerError, "Gagal memperbarui token")
+        return
+    }
+
+    utils.JSONSuccess(w, "Token berhasil diperbarui", tokens)
+}
+
+// Logout godoc
+// @Summary Keluar sistem
+// @Description Mencabut access token yang sedang dipakai. Kirim refresh_token untuk ikut mencabut sesinya, atau all=true untuk keluar dari semua perangkat.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.LogoutRequest false "Refresh token / semua sesi"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /logout [post]
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+    userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+    jti, _ := r.Context().Value(middleware.TokenIDKey).(string)
+    expiresAt, _ := r.Context().Value(middleware.TokenExpiresKey).(time.Time)
+    if jti == "" {
+        utils.JSONError(w, http.StatusUnauthorized, "Token tidak valid")
+        return
+    }
+
+    // Body opsional
+    var req models.LogoutRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+        utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+        return
+    }
+
+    var err error
+    if req.All {
+        err = h.tokens.LogoutAll(r.Context(), userID)
+    } else {
+        err = h.tokens.Logout(userID, jti, expiresAt, req.RefreshToken)
+    }
+    if err != nil {
+        log.Printf("gagal logout user %d: %v", userID, err)
+        utils.JSONError(w, http.StatusInternalServerError, "Gagal logout")
+        return
+    }
+
+    utils.JSONSuccess(w, "Logout berhasil", nil)
+}
+
+// LogoutAll godoc
+// @Summary Cabut semua sesi pengguna
+// @Description Mengeluarkan pengguna dari semua perangkat, misalnya saat perangkat hilang (butuh user:manage)
+// @Tags Auth
+// @Produce  json
+// @Param   id path int true "ID Pengguna"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /users/{id}/logout-all [post]
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "ID pengguna tidak valid")
+        return
+    }
+
+    if err := h.tokens.LogoutAll(r.Context(), id); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            utils.JSONError(w, http.StatusNotFound, "Pengguna tidak ditemukan")
+            return
+        }
+        log.Printf("gagal mencabut sesi user %d: %v", id, err)
+        utils.JSONError(w, http.StatusInternalServerError, "Gagal mencabut sesi pengguna")
+        return
+    }
+
+    utils.JSONSuccess(w, "Semua sesi pengguna berhasil dicabut", nil)
+}
+
+// Update godoc
+// @Summary Ubah data pengguna
+// @Description Mengubah email, nama lengkap dan role pengguna (butuh user:manage). Username tidak bisa diubah.
+// @Description Jika role berubah, semua sesi pengguna dicabut agar role dan visibilitas lama di token tidak berlaku lagi.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Pengguna"
+// @Param   request body models.UpdateUserRequest true "Data Pengguna"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /users/{id} [put]
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "ID pengguna tidak valid")
+        return
+    }
+
+    var req models.UpdateUserRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+        return
+    }
+
+    // Pergantian role mencabut semua sesi di tx yang sama (lihat UserRepository.Update)
+    user, err := h.service.Update(r.Context(), id, &req)
+    if err != nil {
+        h.userError(w, err)
+        return
+    }
+
+    utils.JSONSuccess(w, "Data pengguna berhasil diubah", user)
+}
+
+// Deactivate godoc
+// @Summary Nonaktifkan pengguna
+// @Description Memblokir login pengguna dan mencabut semua sesinya; token yang masih berlaku langsung ditolak (butuh user:manage)
+// @Tags Auth
+// @Produce  json
+// @Param   id path int true "ID Pengguna"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /users/{id}/deactivate [post]
+func (h *UserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "ID pengguna tidak valid")
+        return
+    }
+    if userID, _ := r.Context().Value(middleware.UserIDKey).(int); userID == id {
+        utils.JSONError(w, http.StatusBadRequest, "Tidak bisa menonaktifkan akun sendiri")
+        return
+    }
+
+    if err := h.service.SetActive(r.Context(), id, false); err != nil {
+        h.userError(w, err)
+        return
+    }
+
+    utils.JSONSuccess(w, "Pengguna berhasil dinonaktifkan", nil)
+}
+
+// Activate godoc
+// @Summary Aktifkan kembali pengguna
+// @Description Mengizinkan pengguna yang dinonaktifkan untuk login lagi (butuh user:manage)
+// @Tags Auth
+// @Produce  json
+// @Param   id path int true "ID Pengguna"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /users/{id}/activate [post]
+func (h *UserHandler) Activate(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "ID pengguna tidak valid")
+        return
+    }
+
+    if err := h.service.SetActive(r.Context(), id, true); err != nil {
+        h.userError(w, err)
+        return
+    }
+
+    utils.JSONSuccess(w, "Pengguna berhasil diaktifkan", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password pengguna
+// @Description Admin mengganti password pengguna tanpa password lama; semua sesi pengguna dicabut (butuh user:manage)
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "ID Pengguna"
+// @Param   request body models.ResetPasswordRequest true "Password baru"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /users/{id}/reset-password [post]
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "ID pengguna tidak valid")
+        return
+    }
+
+    var req models.ResetPasswordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+        return
+    }
+
+    if err := h.service.ResetPassword(r.Context(), id, req.Password); err != nil {
+        h.userError(w, err)
+        return
+    }
+
+    utils.JSONSuccess(w, "Password pengguna berhasil direset", nil)
+}
+
+// Me godoc
+// @Summary Profil pengguna yang sedang login
+// @Description Mengembalikan data pengguna pemilik token beserta permission role-nya
+// @Tags Auth
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /me [get]
+func (h *UserHandler) Me(w http.ResponseWriter, r *http.Request) {
+    userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+    user, err := h.service.Profile(userID)
+    if err != nil {
+        if errors.Is(err, repositories.ErrUserNotFound) {
+            utils.JSONError(w, http.StatusUnauthorized, "Pengguna tidak ditemukan")
+            return
+        }
+        log.Printf("gagal mengambil profil user %d: %v", userID, err)
+        utils.JSONError(w, http.StatusInternalServerError, "Gagal mengambil profil")
+        return
+    }
+
+    utils.JSONSuccess(w, "Berhasil mengambil profil", user)
+}
+
+// ChangePassword godoc
+// @Summary Ganti password sendiri
+// @Description Mengganti password pengguna yang sedang login setelah memasukkan password saat ini.
+// @Description Semua sesi (termasuk sesi ini) dicabut sehingga pengguna harus login ulang.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request body models.ChangePasswordRequest true "Password saat ini dan password baru"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /me/password [post]
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+    userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+    var req models.ChangePasswordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "Data input tidak valid")
+        return
+    }
+
+    if err := h.service.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+        h.userError(w, err)
+        return
+    }
+
+    utils.JSONSuccess(w, "Password berhasil diganti, silakan login ulang", nil)
+}
+
+// Unlock godoc
+// @Summary Buka kunci login pengguna
+// @Description Menghapus hitungan login gagal dan kunci sementara pengguna (butuh user:manage)
+// @Tags Auth
+// @Produce  json
+// @Param   id path int true "ID Pengguna"
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        utils.JSONError(w, http.StatusBadRequest, "ID pengguna tidak valid")
+        return
+    }
+
+    if err := h.guard.Unlock(r.Context(), id); err != nil {
+        if errors.Is(err, repositories.ErrUserNotFound) {
+            utils.JSONError(w, http.StatusNotFound, err.Error())
+            return
+        }
+        log.Printf("gagal membuka kunci user %d: %v", id, err)
+        utils.JSONError(w, http.StatusInternalServerError, "Gagal membuka kunci pengguna")
+        return
+    }
+
+    utils.JSONSuccess(w, "Kunci login pengguna berhasil dibuka", nil)
+}
+
+
+// userError memetakan error dari UserService ke status HTTP; error validasi dikembalikan apa adanya seperti Register
+func (h *UserHandler) userError(w http.ResponseWriter, err error) {
+    switch {
+    case errors.Is(err, repositories.ErrUserNotFound):
+        utils.JSONError(w, http.StatusNotFound, err.Error())
+    case errors.Is(err, repositories.ErrEmailExists):
+        utils.JSONError(w, http.StatusConflict, err.Error())
+    default:
+        utils.JSONError(w, http.StatusBadRequest, err.Error())
     }
-    
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(resp)
 }
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
     "strings"
+    "time"
+	"warehouse-api/auth"
 	"warehouse-api/config"
+	"warehouse-api/ctxkeys"
 	"warehouse-api/handlers"
 	"warehouse-api/middleware"
+	"warehouse-api/models"
 	"warehouse-api/repositories"
     "warehouse-api/services"
     
@@ -29,8 +34,11 @@ import (
 // @tag.name Barang
 // @tag.description Manajemen data barang inventaris
 
+// @tag.name Kategori
+// @tag.description Kategori, merek dan tag barang
+
 // @tag.name Stok
-// @tag.description Manajemen dan monitoring stok barang
+// @tag.description Manajemen dan monitoring stok barang, termasuk stock opname
 
 // @tag.name Pembelian
 // @tag.description Transaksi pembelian dan stok masuk
@@ -38,34 +46,105 @@ import (
 // @tag.name Penjualan
 // @tag.description Transaksi penjualan dan stok keluar
 
+// @tag.name Laporan
+// @tag.description Laporan penjualan, pembelian dan klasifikasi ABC/XYZ
+
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	// 1. Connect to Database
 	config.ConnectDB()
     defer config.DB.Close()
 
+    // Kunci JWT; di APP_ENV=production server tidak mau jalan tanpa kunci
+    authConfig := config.LoadAuth()
+    keys, err := auth.LoadKeySet(authConfig)
+    if err != nil {
+        log.Fatalf("Gagal memuat kunci JWT: %v", err)
+    }
+    log.Printf("Token ditandatangani dengan kunci %s", keys.SigningKeyID())
+
+    // Kunci HMAC hash chain riwayat stok; sama seperti kunci JWT, wajib di production
+    chainKey, err := config.HistoryChainKey()
+    if err != nil {
+        log.Fatalf("Gagal memuat kunci hash chain: %v", err)
+    }
+    repositories.SetHistoryChainKey(chainKey)
+
 	// 2. Initialize Repositories
 	userRepo := repositories.NewUserRepository(config.DB)
+	roleRepo := repositories.NewRoleRepository(config.DB)
+	tokenRepo := repositories.NewTokenRepository(config.DB)
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(config.DB)
+	twoFactorRepo := repositories.NewTwoFactorRepository(config.DB)
+	apiKeyRepo := repositories.NewAPIKeyRepository(config.DB)
+	auditRepo := repositories.NewAuditRepository(config.DB)
 	barangRepo := repositories.NewBarangRepository(config.DB)
 	stokRepo := repositories.NewStokRepository(config.DB)
 	pembelianRepo := repositories.NewPembelianRepository(config.DB)
     penjualanRepo := repositories.NewPenjualanRepository(config.DB)
     dashboardRepo := repositories.NewDashboardRepository(config.DB)
+    reportRepo := repositories.NewReportRepository(config.DB)
+    klasifikasiRepo := repositories.NewKlasifikasiRepository(config.DB)
+    kategoriRepo := repositories.NewKategoriRepository(config.DB)
+    merekRepo := repositories.NewMerekRepository(config.DB)
+    tagRepo := repositories.NewTagRepository(config.DB)
+    shareRepo := repositories.NewShareRepository(config.DB)
 
 	// 3. Initialize Services
-	userService := services.NewUserService(userRepo)
+	roleService := services.NewRoleService(roleRepo, time.Minute)
+	userService := services.NewUserService(userRepo, services.WithRoles(roleService), services.WithPasswordPolicy(config.LoadPasswordPolicy()))
+	tokenService := services.NewTokenService(tokenRepo, userRepo, keys, authConfig, services.WithTokenRoles(roleService))
+	loginGuard := services.NewLoginGuard(loginAttemptRepo, userRepo, config.LoadLoginLockout())
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, config.LoadTwoFactor())
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, roleService)
     penjualanService := services.NewPenjualanService(config.DB, penjualanRepo, stokRepo, barangRepo)
     pembelianService := services.NewPembelianService(config.DB, pembelianRepo, stokRepo, barangRepo)
+    saldoAwalService := services.NewSaldoAwalService(config.DB, stokRepo, barangRepo)
+    kartuStokService := services.NewKartuStokService(stokRepo, barangRepo)
+    reportService := services.NewReportService(reportRepo)
+    klasifikasiService := services.NewKlasifikasiService(klasifikasiRepo)
+    shareService := services.NewShareService(shareRepo, penjualanRepo, pembelianRepo, userRepo)
 
 	// 4. Initialize Handlers
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, tokenService, loginGuard, twoFactorService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	auditHandler := handlers.NewAuditHandler(auditRepo)
+	jwksHandler := handlers.NewJWKSHandler(keys)
+	authz := middleware.NewAuthorizer(roleService)
 	barangHandler := handlers.NewBarangHandler(barangRepo)
 	stokHandler := handlers.NewStokHandler(stokRepo)
+	saldoAwalHandler := handlers.NewSaldoAwalHandler(saldoAwalService)
 	pembelianHandler := handlers.NewPembelianHandler(pembelianService, pembelianRepo)
     penjualanHandler := handlers.NewPenjualanHandler(penjualanService, penjualanRepo)
+    shareHandler := handlers.NewShareHandler(shareService)
     dashboardHandler := handlers.NewDashboardHandler(dashboardRepo)
+    reportHandler := handlers.NewReportHandler(reportService)
+    klasifikasiHandler := handlers.NewKlasifikasiHandler(klasifikasiService)
+    kategoriHandler := handlers.NewKategoriHandler(kategoriRepo)
+    merekHandler := handlers.NewMerekHandler(merekRepo)
+    tagHandler := handlers.NewTagHandler(tagRepo)
+    labelHandler := handlers.NewLabelHandler(barangRepo)
+    company := config.LoadCompany()
+    dokumenHandler := handlers.NewDokumenHandler(penjualanRepo, pembelianRepo, company)
+    kartuStokHandler := handlers.NewKartuStokHandler(kartuStokService, company)
+
+    // Snapshot stok akhir bulan (diperiksa tiap jam, dibuat sekali setelah bulan berganti); scheduler
+    // bukan request pengguna, jadi ditandai system agar tidak dibatasi visibilitas transaksi
+    services.StartStokSnapshotScheduler(ctxkeys.WithSystem(context.Background()), stokRepo, time.Hour)
+
+    // Access token diverifikasi dengan key set yang sama; token yang sudah logout ditolak
+    middleware.SetTokenVerifier(keys)
+    middleware.SetTokenDenylist(tokenService)
+    // Integrasi (konektor e-commerce, printer label) memakai header X-API-Key
+    middleware.SetAPIKeyAuthenticator(apiKeyService)
 
 	// 5. Setup Router
 	mux := http.NewServeMux()
@@ -73,46 +152,116 @@ func main() {
     // Swagger Route
     mux.Handle("/swagger/", httpSwagger.WrapHandler)
 
+    // Kunci publik untuk service lain yang memverifikasi access token
+    mux.HandleFunc("GET /.well-known/jwks.json", jwksHandler.Get)
+
     // --- Routes Definition ---
+    // Setiap route dibungkus authz.Require dengan permission yang dibutuhkan,
+    // kecuali login/refresh (tanpa token) dan logout/profil (semua user yang login).
+    // middleware.UserOnly menutup endpoint milik akun manusia dari request ber-API key.
     // Auth
 	mux.HandleFunc("POST /api/login", userHandler.Login)
-	mux.HandleFunc("POST /api/register", userHandler.Register)
-    mux.HandleFunc("GET /api/users", userHandler.GetAll)
+	mux.HandleFunc("POST /api/login/2fa", userHandler.LoginTwoFactor)
+	mux.HandleFunc("POST /api/login/2fa/setup", userHandler.LoginTwoFactorSetup)
+	mux.HandleFunc("POST /api/refresh", userHandler.Refresh)
+	mux.HandleFunc("POST /api/logout", middleware.UserOnly(userHandler.Logout))
+	mux.HandleFunc("POST /api/register", authz.Require(models.PermUserManage, userHandler.Register))
+    mux.HandleFunc("GET /api/users", authz.Require(models.PermUserManage, userHandler.GetAll))
+	mux.HandleFunc("GET /api/me", middleware.UserOnly(userHandler.Me))
+	mux.HandleFunc("POST /api/me/password", middleware.UserOnly(userHandler.ChangePassword))
+	mux.HandleFunc("POST /api/me/2fa/setup", middleware.UserOnly(twoFactorHandler.Setup))
+	mux.HandleFunc("POST /api/me/2fa/enable", middleware.UserOnly(twoFactorHandler.Enable))
+	mux.HandleFunc("POST /api/me/2fa/disable", middleware.UserOnly(twoFactorHandler.Disable))
+	mux.HandleFunc("POST /api/me/2fa/recovery-codes", middleware.UserOnly(twoFactorHandler.RecoveryCodes))
+    mux.HandleFunc("PUT /api/users/{id}", authz.Require(models.PermUserManage, userHandler.Update))
+    mux.HandleFunc("POST /api/users/{id}/activate", authz.Require(models.PermUserManage, userHandler.Activate))
+    mux.HandleFunc("POST /api/users/{id}/deactivate", authz.Require(models.PermUserManage, userHandler.Deactivate))
+    mux.HandleFunc("POST /api/users/{id}/reset-password", authz.Require(models.PermUserManage, userHandler.ResetPassword))
+    mux.HandleFunc("POST /api/users/{id}/logout-all", authz.Require(models.PermUserManage, userHandler.LogoutAll))
+    mux.HandleFunc("POST /api/users/{id}/unlock", authz.Require(models.PermUserManage, userHandler.Unlock))
+    mux.HandleFunc("GET /api/roles", authz.Require(models.PermRoleManage, roleHandler.GetAll))
+    mux.HandleFunc("POST /api/roles", authz.Require(models.PermRoleManage, roleHandler.Create))
+    mux.HandleFunc("PUT /api/roles/{nama}/permissions", authz.Require(models.PermRoleManage, roleHandler.SetPermissions))
+    mux.HandleFunc("DELETE /api/roles/{nama}", authz.Require(models.PermRoleManage, roleHandler.Delete))
+    mux.HandleFunc("GET /api/permissions", authz.Require(models.PermRoleManage, roleHandler.GetPermissions))
+    mux.HandleFunc("GET /api/api-keys", middleware.UserOnly(authz.Require(models.PermAPIKeyManage, apiKeyHandler.GetAll)))
+    mux.HandleFunc("POST /api/api-keys", middleware.UserOnly(authz.Require(models.PermAPIKeyManage, apiKeyHandler.Create)))
+    mux.HandleFunc("DELETE /api/api-keys/{id}", middleware.UserOnly(authz.Require(models.PermAPIKeyManage, apiKeyHandler.Revoke)))
+    mux.HandleFunc("GET /api/audit", authz.Require(models.PermAuditRead, auditHandler.GetAll))
 
     // Barang
-	mux.HandleFunc("GET /api/barang", barangHandler.GetAll)
-    mux.HandleFunc("GET /api/barang/stok", barangHandler.GetAllWithStok)
-	mux.HandleFunc("GET /api/barang/{id}", barangHandler.GetByID)
-	mux.HandleFunc("POST /api/barang", barangHandler.Create)
-	mux.HandleFunc("PUT /api/barang/{id}", barangHandler.Update)
-	mux.HandleFunc("DELETE /api/barang/{id}", barangHandler.Delete)
+	mux.HandleFunc("GET /api/barang", authz.Require(models.PermBarangRead, barangHandler.GetAll))
+    mux.HandleFunc("GET /api/barang/stok", authz.Require(models.PermBarangRead, barangHandler.GetAllWithStok))
+	mux.HandleFunc("GET /api/barang/{id}", authz.Require(models.PermBarangRead, barangHandler.GetByID))
+	mux.HandleFunc("POST /api/barang", authz.Require(models.PermBarangWrite, barangHandler.Create))
+	mux.HandleFunc("POST /api/barang/import", authz.Require(models.PermBarangWrite, barangHandler.Import))
+	mux.HandleFunc("PUT /api/barang/{id}", authz.Require(models.PermBarangWrite, barangHandler.Update))
+	mux.HandleFunc("DELETE /api/barang/{id}", authz.Require(models.PermBarangDelete, barangHandler.Delete))
+	mux.HandleFunc("POST /api/barang/{id}/restore", authz.Require(models.PermBarangWrite, barangHandler.Restore))
+	mux.HandleFunc("GET /api/barang/barcode/{code}", authz.Require(models.PermBarangRead, barangHandler.GetByBarcode))
+	mux.HandleFunc("GET /api/barang/label", authz.Require(models.PermBarangRead, labelHandler.Print))
+	mux.HandleFunc("POST /api/barang/{id}/barcode", authz.Require(models.PermBarangWrite, barangHandler.AddBarcode))
+	mux.HandleFunc("DELETE /api/barang/{id}/barcode/{code}", authz.Require(models.PermBarangWrite, barangHandler.RemoveBarcode))
+
+    // Kategori, Merek & Tag
+    mux.HandleFunc("GET /api/kategori", authz.Require(models.PermBarangRead, kategoriHandler.GetAll))
+    mux.HandleFunc("POST /api/kategori", authz.Require(models.PermKategoriWrite, kategoriHandler.Create))
+    mux.HandleFunc("PUT /api/kategori/{id}", authz.Require(models.PermKategoriWrite, kategoriHandler.Update))
+    mux.HandleFunc("DELETE /api/kategori/{id}", authz.Require(models.PermKategoriWrite, kategoriHandler.Delete))
+    mux.HandleFunc("GET /api/merek", authz.Require(models.PermBarangRead, merekHandler.GetAll))
+    mux.HandleFunc("POST /api/merek", authz.Require(models.PermKategoriWrite, merekHandler.Create))
+    mux.HandleFunc("PUT /api/merek/{id}", authz.Require(models.PermKategoriWrite, merekHandler.Update))
+    mux.HandleFunc("DELETE /api/merek/{id}", authz.Require(models.PermKategoriWrite, merekHandler.Delete))
+    mux.HandleFunc("GET /api/tag", authz.Require(models.PermBarangRead, tagHandler.GetAll))
 
     // Stok
-	mux.HandleFunc("GET /api/stok", stokHandler.GetAll)
-	mux.HandleFunc("GET /api/stok/{id}", stokHandler.GetByBarangID)
-    mux.HandleFunc("GET /api/history-stok", stokHandler.GetHistory)
-	mux.HandleFunc("GET /api/history-stok/{id}", stokHandler.GetHistory)
+	mux.HandleFunc("GET /api/stok", authz.Require(models.PermStokRead, stokHandler.GetAll))
+	mux.HandleFunc("GET /api/stok/{id}", authz.Require(models.PermStokRead, stokHandler.GetByBarangID))
+	mux.HandleFunc("GET /api/stok/{id}/kartu", authz.Require(models.PermStokRead, kartuStokHandler.Get))
+	mux.HandleFunc("POST /api/stok/saldo-awal", authz.Require(models.PermStokAdjust, saldoAwalHandler.Load))
+	mux.HandleFunc("POST /api/stok/snapshot", authz.Require(models.PermStokAdjust, stokHandler.CreateSnapshot))
+	mux.HandleFunc("GET /api/stok-opname", authz.Require(models.PermStokRead, klasifikasiHandler.GetAllOpname))
+	mux.HandleFunc("POST /api/stok-opname", authz.Require(models.PermStokOpname, klasifikasiHandler.CreateOpname))
+	mux.HandleFunc("GET /api/stok-opname/{id}", authz.Require(models.PermStokRead, klasifikasiHandler.GetOpname))
+    mux.HandleFunc("GET /api/history-stok", authz.Require(models.PermStokRead, stokHandler.GetHistory))
+    mux.HandleFunc("GET /api/history-stok/verify", authz.Require(models.PermAuditRead, stokHandler.VerifyHistory))
+	mux.HandleFunc("GET /api/history-stok/{id}", authz.Require(models.PermStokRead, stokHandler.GetHistory))
 
     // Pembelian
-    mux.HandleFunc("POST /api/pembelian", pembelianHandler.Create)
-    mux.HandleFunc("GET /api/pembelian", pembelianHandler.GetAll)
-    mux.HandleFunc("GET /api/pembelian/{id}", pembelianHandler.GetByID)
+    mux.HandleFunc("POST /api/pembelian", authz.Require(models.PermPembelianCreate, pembelianHandler.Create))
+    mux.HandleFunc("GET /api/pembelian", authz.Require(models.PermPembelianRead, pembelianHandler.GetAll))
+    mux.HandleFunc("GET /api/pembelian/{id}", authz.Require(models.PermPembelianRead, pembelianHandler.GetByID))
+    mux.HandleFunc("GET /api/pembelian/{id}/pdf", authz.Require(models.PermPembelianRead, dokumenHandler.NotaPembelian))
+    mux.HandleFunc("GET /api/pembelian/{id}/share", authz.Require(models.PermPembelianRead, shareHandler.ListPembelian))
+    mux.HandleFunc("POST /api/pembelian/{id}/share", authz.Require(models.PermPembelianRead, shareHandler.SharePembelian))
+    mux.HandleFunc("DELETE /api/pembelian/{id}/share/{user_id}", authz.Require(models.PermPembelianRead, shareHandler.UnsharePembelian))
     
     // Penjualan
-    mux.HandleFunc("POST /api/penjualan", penjualanHandler.Create)
-    mux.HandleFunc("GET /api/penjualan", penjualanHandler.GetAll)
-    mux.HandleFunc("GET /api/penjualan/{id}", penjualanHandler.GetByID)
+    mux.HandleFunc("POST /api/penjualan", authz.Require(models.PermPenjualanCreate, penjualanHandler.Create))
+    mux.HandleFunc("GET /api/penjualan", authz.Require(models.PermPenjualanRead, penjualanHandler.GetAll))
+    mux.HandleFunc("GET /api/penjualan/{id}", authz.Require(models.PermPenjualanRead, penjualanHandler.GetByID))
+    mux.HandleFunc("GET /api/penjualan/{id}/pdf", authz.Require(models.PermPenjualanRead, dokumenHandler.FakturPenjualan))
+    mux.HandleFunc("GET /api/penjualan/{id}/surat-jalan", authz.Require(models.PermPenjualanRead, dokumenHandler.SuratJalan))
+    mux.HandleFunc("GET /api/penjualan/{id}/share", authz.Require(models.PermPenjualanRead, shareHandler.ListPenjualan))
+    mux.HandleFunc("POST /api/penjualan/{id}/share", authz.Require(models.PermPenjualanRead, shareHandler.SharePenjualan))
+    mux.HandleFunc("DELETE /api/penjualan/{id}/share/{user_id}", authz.Require(models.PermPenjualanRead, shareHandler.UnsharePenjualan))
 
     // Dashboard
-    mux.HandleFunc("GET /api/dashboard", dashboardHandler.GetStats)
+    mux.HandleFunc("GET /api/dashboard", authz.Require(models.PermReportView, dashboardHandler.GetStats))
+
+    // Laporan
+    mux.HandleFunc("GET /api/reports/penjualan", authz.Require(models.PermReportView, reportHandler.Penjualan))
+    mux.HandleFunc("GET /api/reports/pembelian", authz.Require(models.PermReportView, reportHandler.Pembelian))
+    mux.HandleFunc("GET /api/reports/abc-xyz", authz.Require(models.PermReportView, klasifikasiHandler.Get))
+    mux.HandleFunc("POST /api/reports/abc-xyz", authz.Require(models.PermReportManage, klasifikasiHandler.Run))
 
     // --- Middleware Chains ---
     
     // 1. Auth Middleware Wrapper
     // Kita buat wrapper agar hanya route tertentu yang dicek auth-nya
     authHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        // Skip auth untuk login dan swagger
-        if r.URL.Path == "/api/login" || strings.HasPrefix(r.URL.Path, "/swagger/") {
+        // Skip auth untuk login (termasuk langkah 2FA), refresh, JWKS dan swagger
+        if r.URL.Path == "/api/login" || r.URL.Path == "/api/login/2fa" || r.URL.Path == "/api/login/2fa/setup" || r.URL.Path == "/api/refresh" || r.URL.Path == "/.well-known/jwks.json" || strings.HasPrefix(r.URL.Path, "/swagger/") {
             mux.ServeHTTP(w, r)
             return
         }
@@ -125,7 +274,8 @@ func main() {
     corsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Access-Control-Allow-Origin", "*")
         w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID")
+        w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
         if r.Method == "OPTIONS" {
             w.WriteHeader(http.StatusOK)
@@ -135,9 +285,9 @@ func main() {
         authHandler.ServeHTTP(w, r)
     })
 
-    // 3. Logger & Rate Limit (Global)
-    // Urutan: Logger -> RateLimit -> CORS -> Auth -> Mux
-    finalHandler := middleware.Logger(middleware.RateLimitMiddleware(corsHandler))
+    // 3. Request ID, Logger & Rate Limit (Global)
+    // Urutan: RequestID -> Logger -> RateLimit -> CORS -> Auth -> Mux
+    finalHandler := middleware.RequestID(middleware.Logger(middleware.RateLimitMiddleware(corsHandler)))
 
 	// 6. Start Server
 	log.Println("Server starting on :8080")
@@ -2,21 +2,78 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"log"
 	"net/http"
 	"strings"
-    "os"
-
-	"github.com/golang-jwt/jwt/v5"
+	"time"
+	"warehouse-api/auth"
+	"warehouse-api/ctxkeys"
+	"warehouse-api/models"
 )
 
 type contextKey string
 
-const UserIDKey contextKey = "userID"
+// UserIDKey dan VisibilityKey juga dibaca repository, jadi didefinisikan di ctxkeys
+const UserIDKey = ctxkeys.UserIDKey
 const RoleKey contextKey = "role"
 
+// TokenIDKey (jti) dan TokenExpiresKey dipakai handler logout untuk mencabut token yang sedang dipakai
+const TokenIDKey contextKey = "tokenID"
+const TokenExpiresKey contextKey = "tokenExpires"
+
+// APIKeyIDKey dan ScopesKey hanya terisi untuk request yang diautentikasi dengan X-API-Key;
+// Authorizer memeriksa ScopesKey alih-alih permission role
+const APIKeyIDKey contextKey = "apiKeyID"
+const ScopesKey contextKey = "scopes"
+
+// VisibilityKey (models.VisibilityAll atau VisibilityOwn) dibaca repository untuk membatasi
+// penjualan, pembelian dan riwayat stok yang boleh dibaca request
+const VisibilityKey = ctxkeys.VisibilityKey
+
+// TokenVerifier memeriksa tanda tangan dan masa berlaku access token (lihat auth.KeySet)
+type TokenVerifier interface {
+	Verify(tokenString string) (*auth.Claims, error)
+}
+
+var tokenVerifier TokenVerifier
+
+// SetTokenVerifier dipanggil sekali saat startup; tanpa verifier semua token ditolak
+func SetTokenVerifier(v TokenVerifier) {
+	tokenVerifier = v
+}
+
+// TokenDenylist memeriksa apakah access token sudah dicabut (logout, logout semua sesi atau akun dinonaktifkan)
+type TokenDenylist interface {
+	IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+}
+
+var tokenDenylist TokenDenylist
+
+// SetTokenDenylist dipanggil sekali saat startup. Selama denylist terpasang, token tanpa jti ditolak.
+func SetTokenDenylist(d TokenDenylist) {
+	tokenDenylist = d
+}
+
+// APIKeyAuthenticator memeriksa header X-API-Key. Principal nil berarti key tidak valid,
+// sudah dicabut atau kedaluwarsa.
+type APIKeyAuthenticator interface {
+	Authenticate(key string) (*models.APIKeyPrincipal, error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator dipanggil sekali saat startup; tanpa authenticator header X-API-Key ditolak
+func SetAPIKeyAuthenticator(a APIKeyAuthenticator) {
+	apiKeyAuthenticator = a
+}
+
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+			serveWithAPIKey(w, r, apiKey, next)
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
             // Header Authorization diperlukan
@@ -24,47 +81,78 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-        secret := []byte(os.Getenv("JWT_SECRET"))
-        if len(secret) == 0 {
-            secret = []byte("supersecretkey") // Fallback
-        }
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return secret, nil
-		})
+		if tokenVerifier == nil {
+			log.Println("AuthMiddleware dipakai sebelum SetTokenVerifier")
+			http.Error(w, "Token tidak valid", http.StatusUnauthorized)
+			return
+		}
 
-		if err != nil || !token.Valid {
+		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
+		claims, err := tokenVerifier.Verify(tokenString)
+		if err != nil {
             // Token tidak valid
 			http.Error(w, "Token tidak valid", http.StatusUnauthorized)
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-            // Klaim token tidak valid
-			http.Error(w, "Klaim token tidak valid", http.StatusUnauthorized)
-			return
+		if tokenDenylist != nil {
+			if claims.ID == "" {
+				http.Error(w, "Token tidak valid", http.StatusUnauthorized)
+				return
+			}
+			revoked, err := tokenDenylist.IsRevoked(claims.ID, claims.UserID, claims.IssuedAt)
+			if err != nil {
+				log.Printf("cek denylist token gagal: %v", err)
+				http.Error(w, "Gagal memeriksa token", http.StatusInternalServerError)
+				return
+			}
+			if revoked {
+				http.Error(w, "Token sudah dicabut, silakan login ulang", http.StatusUnauthorized)
+				return
+			}
 		}
 
-		userIDFloat, ok := claims["user_id"].(float64)
-        if !ok {
-             http.Error(w, "User ID tidak valid dalam token", http.StatusUnauthorized)
-             return
-        }
-        userID := int(userIDFloat)
-        
-		role, ok := claims["role"].(string)
-        if !ok {
-             role = "staff"
-        }
-
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-		ctx = context.WithValue(ctx, RoleKey, role)
+		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, RoleKey, claims.Role)
+		ctx = context.WithValue(ctx, TokenIDKey, claims.ID)
+		ctx = context.WithValue(ctx, TokenExpiresKey, claims.ExpiresAt)
+		ctx = context.WithValue(ctx, VisibilityKey, tokenVisibility(claims))
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// serveWithAPIKey meneruskan request atas nama service principal pemilik key
+func serveWithAPIKey(w http.ResponseWriter, r *http.Request, key string, next http.Handler) {
+	if apiKeyAuthenticator == nil {
+		http.Error(w, "API key tidak valid", http.StatusUnauthorized)
+		return
+	}
+
+	principal, err := apiKeyAuthenticator.Authenticate(key)
+	if err != nil {
+		log.Printf("cek API key gagal: %v", err)
+		http.Error(w, "Gagal memeriksa API key", http.StatusInternalServerError)
+		return
+	}
+	if principal == nil {
+		http.Error(w, "API key tidak valid, sudah dicabut atau kedaluwarsa", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, principal.UserID)
+	ctx = context.WithValue(ctx, RoleKey, models.RoleService)
+	ctx = context.WithValue(ctx, APIKeyIDKey, principal.KeyID)
+	ctx = context.WithValue(ctx, ScopesKey, principal.Permissions)
+	ctx = context.WithValue(ctx, VisibilityKey, models.VisibilityFor(models.RoleService, principal.Permissions))
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// tokenVisibility membatasi token tanpa claim vis (terbit sebelum fitur visibilitas) ke data milik sendiri
+func tokenVisibility(claims *auth.Claims) string {
+	if claims.Visibility == models.VisibilityAll {
+		return models.VisibilityAll
+	}
+	return models.VisibilityOwn
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"warehouse-api/utils"
+)
+
+// PermissionChecker memeriksa apakah sebuah role memiliki permission tertentu
+type PermissionChecker interface {
+	HasPermission(role, permission string) (bool, error)
+}
+
+// Authorizer membungkus handler route dengan pengecekan permission.
+// Harus dipasang di belakang AuthMiddleware karena role dibaca dari context.
+type Authorizer struct {
+	checker PermissionChecker
+}
+
+func NewAuthorizer(checker PermissionChecker) *Authorizer {
+	return &Authorizer{checker}
+}
+
+// Require menolak request dengan 403 jika role pengguna tidak memiliki permission.
+// Request dengan API key hanya diperiksa terhadap scope key-nya.
+func (a *Authorizer) Require(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if scopes, ok := r.Context().Value(ScopesKey).([]string); ok {
+			if !slices.Contains(scopes, permission) {
+				utils.JSONError(w, http.StatusForbidden, "Akses ditolak: API key tidak memiliki izin "+permission)
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		role, _ := r.Context().Value(RoleKey).(string)
+		if role == "" {
+			utils.JSONError(w, http.StatusUnauthorized, "Otorisasi diperlukan")
+			return
+		}
+
+		allowed, err := a.checker.HasPermission(role, permission)
+		if err != nil {
+			log.Printf("cek permission %s untuk role %s gagal: %v", permission, role, err)
+			utils.JSONError(w, http.StatusInternalServerError, "Gagal memeriksa hak akses")
+			return
+		}
+		if !allowed {
+			utils.JSONError(w, http.StatusForbidden, "Akses ditolak: role "+role+" tidak memiliki izin "+permission)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// UserOnly menolak request dengan API key untuk endpoint milik akun manusia (sesi, profil, 2FA, kelola API key)
+func UserOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(APIKeyIDKey).(int); ok {
+			utils.JSONError(w, http.StatusForbidden, "Endpoint ini tidak bisa diakses dengan API key")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -18,8 +18,9 @@ func Logger(next http.Handler) http.Handler {
 
         duration := time.Since(start)
 
-        // Format Log: [METHOD] URL | Status | Duration
-        log.Printf("[%s] %s | %d | %v", r.Method, r.URL.Path, rw.status, duration)
+        // Format Log: [METHOD] URL | Status | Duration | Request ID
+        requestID, _ := r.Context().Value(RequestIDKey).(string)
+        log.Printf("[%s] %s | %d | %v | %s", r.Method, r.URL.Path, rw.status, duration, requestID)
     })
 }
 
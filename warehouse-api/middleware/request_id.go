@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"regexp"
+	"warehouse-api/ctxkeys"
+)
+
+// RequestIDKey dan ClientIPKey diisi RequestID untuk log dan audit log
+const RequestIDKey = ctxkeys.RequestIDKey
+const ClientIPKey = ctxkeys.ClientIPKey
+
+// requestIDPattern membatasi X-Request-ID dari proxy agar aman disimpan dan ditulis ke log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID memberi setiap request sebuah ID (dari header X-Request-ID bila valid, atau dibuat baru)
+// yang dikembalikan di header respons, dicatat Logger dan disimpan di audit log.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		ctx := context.WithValue(r.Context(), RequestIDKey, id)
+		ctx = context.WithValue(ctx, ClientIPKey, ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientIP mengambil IP dari RemoteAddr tanpa port. X-Forwarded-For sengaja tidak dipakai
+// karena bisa diisi bebas oleh klien bila server tidak berada di belakang proxy tepercaya.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// APIKey adalah kredensial integrasi mesin-ke-mesin. Key lengkap hanya ditampilkan sekali saat dibuat;
+// yang disimpan hanya hash-nya dan Prefix untuk mengenali key di daftar.
+type APIKey struct {
+	ID          int        `json:"id"`
+	Nama        string     `json:"nama"`
+	Prefix      string     `json:"prefix" example:"wh_1a2b3c4d"`
+	UserID      int        `json:"user_id"` // service principal yang tercatat di history_stok
+	Username    string     `json:"username"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedBy   int        `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// APIKeyPrincipal adalah identitas request yang diautentikasi dengan X-API-Key
+type APIKeyPrincipal struct {
+	KeyID       int
+	UserID      int
+	Permissions []string
+}
+
+type CreateAPIKeyRequest struct {
+	Nama        string   `json:"nama" example:"Konektor Tokopedia"`
+	Permissions []string `json:"permissions" example:"barang:read,stok:read,penjualan:create"`
+	// ExpiresInDays 0 berarti key tidak kedaluwarsa
+	ExpiresInDays int `json:"expires_in_days" example:"365"`
+}
+
+// CreateAPIKeyResponse berisi key lengkap; simpan segera karena tidak bisa ditampilkan lagi
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key" example:"wh_1a2b3c4d_Qk9x..."`
+}
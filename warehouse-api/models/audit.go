@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aksi audit log
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Entitas audit log
+const (
+	AuditBarang     = "barang"
+	AuditKategori   = "kategori"
+	AuditMerek      = "merek"
+	AuditUser       = "user"
+	AuditRole       = "role"
+	AuditAPIKey     = "api_key"
+	AuditPembelian  = "pembelian"
+	AuditPenjualan  = "penjualan"
+	AuditStokOpname = "stok_opname"
+	AuditSaldoAwal  = "saldo_awal"
+	AuditShare      = "transaksi_share"
+	AuditSnapshot   = "stok_snapshot"
+)
+
+// AuditLog adalah satu perubahan data. Sebelum/Sesudah hanya berisi field yang berubah;
+// create tidak punya Sebelum dan delete tidak punya Sesudah.
+type AuditLog struct {
+	ID        int64           `json:"id"`
+	UserID    *int            `json:"user_id"`
+	Username  string          `json:"username"`
+	Aksi      string          `json:"aksi" example:"update"`
+	Entitas   string          `json:"entitas" example:"barang"`
+	EntitasID string          `json:"entitas_id" example:"12"`
+	Sebelum   json.RawMessage `json:"sebelum" swaggertype:"object"`
+	Sesudah   json.RawMessage `json:"sesudah" swaggertype:"object"`
+	IP        string          `json:"ip"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type AuditFilter struct {
+	Entitas   string
+	EntitasID string
+	UserID    int
+	Aksi      string
+	StartDate string
+	EndDate   string
+}
@@ -1,24 +1,74 @@
 package models
 
+import "time"
+
 type Barang struct {
-	ID         int     `json:"id"`
-	KodeBarang string  `json:"kode_barang"`
-	NamaBarang string  `json:"nama_barang"`
-	Deskripsi  string  `json:"deskripsi"`
-	Satuan     string  `json:"satuan"`
-	HargaBeli  float64 `json:"harga_beli"`
-	HargaJual  float64 `json:"harga_jual"`
+	ID         int        `json:"id"`
+	KodeBarang string     `json:"kode_barang"`
+	NamaBarang string     `json:"nama_barang"`
+	Deskripsi  string     `json:"deskripsi"`
+	Satuan     string     `json:"satuan"`
+	HargaBeli  float64    `json:"harga_beli"`
+	HargaJual  float64    `json:"harga_jual"`
+	KategoriID *int       `json:"kategori_id"`
+	MerekID    *int       `json:"merek_id"`
+	Tags       []string   `json:"tags"`
+	Barcodes   []string   `json:"barcodes"`
+	IsActive   bool       `json:"is_active"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
 
 type BarangWithStok struct {
 	Barang
-	Stok int `json:"stok"`
+	Stok        int                `json:"stok"`
+	Klasifikasi *KlasifikasiBarang `json:"klasifikasi,omitempty"` // hanya diisi di detail barang
 }
 
 type CreateBarangRequest struct {
-	NamaBarang string  `json:"nama_barang"`
-	Deskripsi  string  `json:"deskripsi"`
-	Satuan     string  `json:"satuan"`
-	HargaBeli  float64 `json:"harga_beli"`
-	HargaJual  float64 `json:"harga_jual"`
+	NamaBarang string   `json:"nama_barang"`
+	Deskripsi  string   `json:"deskripsi"`
+	Satuan     string   `json:"satuan"`
+	HargaBeli  float64  `json:"harga_beli"`
+	HargaJual  float64  `json:"harga_jual"`
+	KategoriID *int     `json:"kategori_id"`
+	MerekID    *int     `json:"merek_id"`
+	Tags       []string `json:"tags"`     // Saat update: tidak dikirim = tag tidak diubah, [] = semua tag dihapus
+	Barcodes   []string `json:"barcodes"` // Hanya dipakai saat create, kelola lewat endpoint barcode
+}
+
+type BarcodeRequest struct {
+	Barcode string `json:"barcode" example:"8992761166014"`
+}
+
+// BarangFilter menampung filter untuk listing barang.
+// Barang yang diarsipkan (soft delete) tidak ikut kecuali IncludeArchived = true.
+// KategoriID ikut menyertakan semua sub-kategori di bawahnya.
+type BarangFilter struct {
+	Search          string
+	IncludeArchived bool
+	KategoriID      int
+	MerekID         int
+	Tag             string
+}
+
+// BarangImportItem adalah satu baris file import yang sudah lolos validasi format.
+// KodeBarang kosong berarti barang baru dengan kode otomatis; jika diisi dan sudah ada, barang diperbarui.
+type BarangImportItem struct {
+	Row    int
+	Barang Barang
+}
+
+type ImportRowError struct {
+	Row        int    `json:"row"`
+	KodeBarang string `json:"kode_barang,omitempty"`
+	Error      string `json:"error"`
+}
+
+type BarangImportResult struct {
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
 }
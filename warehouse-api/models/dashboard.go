@@ -6,9 +6,42 @@ type DashboardStats struct {
     TotalStok          int     `json:"total_stok"`
     TotalNilaiAset     float64 `json:"total_nilai_aset"`
     TopSellingProducts []TopProduct `json:"top_selling_products"`
+    KategoriStats      []KategoriStat `json:"kategori_stats"`
+
+    // KPI periode (semua waktu jika start_date/end_date kosong)
+    StartDate       string   `json:"start_date,omitempty"`
+    EndDate         string   `json:"end_date,omitempty"`
+    Pendapatan      float64  `json:"pendapatan"`
+    Pembelian       float64  `json:"pembelian"`
+    HPP             float64  `json:"hpp"`
+    LabaKotor       float64  `json:"laba_kotor"`
+    MarginKotor     *float64 `json:"margin_kotor"` // persen, null jika belum ada pendapatan
+    JumlahFaktur    int      `json:"jumlah_faktur"`
+    RataRataBelanja float64  `json:"rata_rata_belanja"`
+    DeadStock       int      `json:"dead_stock"`
+    LowStock        int      `json:"low_stock"`
+}
+
+// DashboardFilter: TopN jumlah produk terlaris, LowStockThreshold batas stok menipis
+type DashboardFilter struct {
+    StartDate         string
+    EndDate           string
+    TopN              int
+    LowStockThreshold int
 }
 
 type TopProduct struct {
-    NamaBarang   string `json:"nama_barang"`
-    TotalTerjual int    `json:"total_terjual"`
+    NamaBarang   string  `json:"nama_barang"`
+    TotalTerjual int     `json:"total_terjual"`
+    TotalNilai   float64 `json:"total_nilai"`
+}
+
+// KategoriStat adalah roll-up stok dan nilai per kategori, termasuk semua sub-kategorinya
+type KategoriStat struct {
+    KategoriID   int     `json:"kategori_id"`
+    NamaKategori string  `json:"nama_kategori"`
+    ParentID     *int    `json:"parent_id"`
+    TotalBarang  int     `json:"total_barang"`
+    TotalStok    int     `json:"total_stok"`
+    TotalNilai   float64 `json:"total_nilai"`
 }
@@ -11,7 +11,63 @@ type HistoryStok struct {
 	StokSebelum   int       `json:"stok_sebelum"`
 	StokSesudah   int       `json:"stok_sesudah"`
 	Keterangan    string    `json:"keterangan"`
+	// JualHeaderID / BeliHeaderID merujuk transaksi asal mutasi (0 untuk saldo awal, opname, dll.)
+	JualHeaderID  int       `json:"jual_header_id,omitempty"`
+	BeliHeaderID  int       `json:"beli_header_id,omitempty"`
 	CreatedAt     time.Time `json:"created_at"`
+	PrevHash      string    `json:"-"`
+	Hash          string    `json:"-"`
 	Barang        *Barang   `json:"barang,omitempty"`
 	User          *User     `json:"user,omitempty"`
 }
+
+type HistoryStokFilter struct {
+	Search         string
+	BarangID       int
+	UserID         int
+	JenisTransaksi string
+	StartDate      string
+	EndDate        string
+}
+
+// HistoryCursor adalah posisi keyset (created_at, id) pada riwayat stok
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// HistoryChainAnchor mencatat jumlah baris dan hash terakhir rantai satu barang di luar
+// history_stok, ditandatangani dengan kunci hash chain. Penghapusan baris paling akhir membuat
+// rantai tidak cocok lagi dengan anchor-nya. SealedAt adalah saat rantai barang pertama kali
+// dibuat atau di-seal dan tidak pernah berubah.
+type HistoryChainAnchor struct {
+	BarangID  int
+	Baris     int
+	LastID    int
+	LastHash  string
+	SealedAt  time.Time
+	Signature string
+}
+
+// HistoryChainSeal menandai bahwa seluruh riwayat lama sudah di-seal. Setelah ada, riwayat tanpa
+// anchor tidak pernah lagi dianggap riwayat lama yang belum di-seal.
+type HistoryChainSeal struct {
+	SealedAt  time.Time
+	Signature string
+}
+
+// HistoryChainReport adalah hasil pemeriksaan hash chain riwayat stok. Pemeriksaan berhenti
+// di link rusak pertama; BelumDiseal menghitung baris barang yang belum pernah di-seal.
+type HistoryChainReport struct {
+	Valid       bool               `json:"valid"`
+	Barang      int                `json:"barang_diperiksa"`
+	Baris       int                `json:"baris_diperiksa"`
+	BelumDiseal int                `json:"baris_belum_diseal"`
+	Rusak       *HistoryChainBreak `json:"rusak,omitempty"`
+}
+
+type HistoryChainBreak struct {
+	HistoryID int    `json:"history_id"`
+	BarangID  int    `json:"barang_id"`
+	Alasan    string `json:"alasan"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// StokMovement adalah satu baris riwayat stok beserta dokumen sumber dan harga pokok
+// per unit (jika diketahui) untuk keperluan kartu stok.
+type StokMovement struct {
+	ID             int
+	CreatedAt      time.Time
+	JenisTransaksi string
+	Jumlah         int
+	StokSebelum    int
+	StokSesudah    int
+	Keterangan     string
+	Petugas        string
+	Referensi      string
+	HargaSatuan    *float64
+	// Hidden menandai mutasi di luar visibilitas pengguna: tetap dihitung ke saldo, tapi tidak ditampilkan
+	Hidden bool
+}
+
+type KartuStokEntry struct {
+	HistoryID      int       `json:"history_id"`
+	Tanggal        time.Time `json:"tanggal"`
+	JenisTransaksi string    `json:"jenis_transaksi"`
+	Referensi      string    `json:"referensi"`
+	Keterangan     string    `json:"keterangan"`
+	Petugas        string    `json:"petugas"`
+	Masuk          int       `json:"masuk"`
+	Keluar         int       `json:"keluar"`
+	Saldo          int       `json:"saldo"`
+	HargaSatuan    *float64  `json:"harga_satuan"`
+	Nilai          *float64  `json:"nilai"`
+	NilaiSaldo     *float64  `json:"nilai_saldo"`
+}
+
+// KartuStok adalah mutasi satu barang dalam satu periode dengan saldo berjalan.
+// Nilai (rupiah) dihitung dengan metode rata-rata bergerak dan bernilai null jika
+// harga pokok salah satu barang masuk sebelumnya tidak diketahui.
+type KartuStok struct {
+	Barang          *Barang          `json:"barang"`
+	StartDate       string           `json:"start_date"`
+	EndDate         string           `json:"end_date"`
+	SaldoAwal       int              `json:"saldo_awal"`
+	NilaiSaldoAwal  *float64         `json:"nilai_saldo_awal"`
+	TotalMasuk      int              `json:"total_masuk"`
+	TotalKeluar     int              `json:"total_keluar"`
+	SaldoAkhir      int              `json:"saldo_akhir"`
+	NilaiSaldoAkhir *float64         `json:"nilai_saldo_akhir"`
+	Entries         []KartuStokEntry `json:"entries"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type Kategori struct {
+	ID        int        `json:"id"`
+	Nama      string     `json:"nama"`
+	ParentID  *int       `json:"parent_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Children  []Kategori `json:"children,omitempty"`
+}
+
+type CreateKategoriRequest struct {
+	Nama     string `json:"nama" example:"Elektronik"`
+	ParentID *int   `json:"parent_id"`
+}
+
+type Merek struct {
+	ID        int       `json:"id"`
+	Nama      string    `json:"nama"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateMerekRequest struct {
+	Nama string `json:"nama" example:"Logitech"`
+}
+
+type Tag struct {
+	ID          int    `json:"id"`
+	Nama        string `json:"nama"`
+	TotalBarang int    `json:"total_barang"`
+}
@@ -0,0 +1,122 @@
+package models
+
+import "time"
+
+// KlasifikasiParams adalah parameter proses klasifikasi ABC/XYZ.
+// BatasA/BatasB dalam persen kumulatif nilai penjualan (default 80/95);
+// BatasX/BatasY adalah batas koefisien variasi permintaan per Periode (default 0.5/1.0).
+type KlasifikasiParams struct {
+	StartDate string
+	EndDate   string
+	Periode   string // "month" atau "week"
+	BatasA    float64
+	BatasB    float64
+	BatasX    float64
+	BatasY    float64
+	UserID    int
+}
+
+type KlasifikasiRun struct {
+	ID           int       `json:"id"`
+	StartDate    string    `json:"start_date"`
+	EndDate      string    `json:"end_date"`
+	Periode      string    `json:"periode"`
+	BatasA       float64   `json:"batas_a"`
+	BatasB       float64   `json:"batas_b"`
+	BatasX       float64   `json:"batas_x"`
+	BatasY       float64   `json:"batas_y"`
+	JumlahBarang int       `json:"jumlah_barang"`
+	UserID       *int      `json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BarangDemand adalah penjualan satu barang aktif dalam rentang klasifikasi.
+// QtyPerPeriode dikunci dengan tanggal awal periode (YYYY-MM-DD); periode tanpa penjualan tidak ada.
+type BarangDemand struct {
+	BarangID      int
+	KodeBarang    string
+	NamaBarang    string
+	Nilai         float64
+	Qty           int
+	Frekuensi     int // jumlah faktur
+	QtyPerPeriode map[string]int
+}
+
+// BarangKlasifikasi adalah hasil klasifikasi satu barang. Kontribusi dan Kumulatif dalam persen
+// total nilai penjualan; CV nil jika barang tidak terjual sama sekali dalam rentang.
+type BarangKlasifikasi struct {
+	BarangID   int      `json:"barang_id"`
+	KodeBarang string   `json:"kode_barang"`
+	NamaBarang string   `json:"nama_barang"`
+	KelasABC   string   `json:"kelas_abc"`
+	KelasXYZ   string   `json:"kelas_xyz"`
+	Nilai      float64  `json:"nilai"`
+	Kontribusi float64  `json:"kontribusi"`
+	Kumulatif  float64  `json:"kumulatif"`
+	Qty        int      `json:"qty"`
+	Frekuensi  int      `json:"frekuensi"`
+	CV         *float64 `json:"cv"`
+}
+
+// KlasifikasiFilter membatasi barang per kelas, mis. KelasABC "AB" = kelas A dan B. Kosong = semua.
+type KlasifikasiFilter struct {
+	KelasABC string
+	KelasXYZ string
+}
+
+// KlasifikasiSel adalah ringkasan satu sel matriks ABC x XYZ
+type KlasifikasiSel struct {
+	KelasABC     string  `json:"kelas_abc"`
+	KelasXYZ     string  `json:"kelas_xyz"`
+	JumlahBarang int     `json:"jumlah_barang"`
+	Nilai        float64 `json:"nilai"`
+}
+
+// KlasifikasiReport adalah hasil satu run. Terbatas true berarti pengguna tanpa transaksi:read-all:
+// kelas tetap ditampilkan, tapi nilai, qty, kontribusi, frekuensi dan CV (dihitung dari seluruh
+// penjualan) dikosongkan.
+type KlasifikasiReport struct {
+	Run      KlasifikasiRun      `json:"run"`
+	Matriks  []KlasifikasiSel    `json:"matriks"`
+	Items    []BarangKlasifikasi `json:"items"`
+	Terbatas bool                `json:"terbatas"`
+}
+
+// KlasifikasiBarang adalah klasifikasi terakhir yang ditampilkan di detail barang
+type KlasifikasiBarang struct {
+	KelasABC string    `json:"kelas_abc"`
+	KelasXYZ string    `json:"kelas_xyz"`
+	RunID    int       `json:"run_id"`
+	RunAt    time.Time `json:"run_at"`
+}
+
+type CreateOpnameRequest struct {
+	KelasABC   string `json:"kelas_abc" example:"A"`
+	KelasXYZ   string `json:"kelas_xyz" example:"XY"`
+	Keterangan string `json:"keterangan"`
+}
+
+type StokOpname struct {
+	ID               int                `json:"id"`
+	NoOpname         string             `json:"no_opname"`
+	KlasifikasiRunID *int               `json:"klasifikasi_run_id"`
+	KelasABC         string             `json:"kelas_abc"`
+	KelasXYZ         string             `json:"kelas_xyz"`
+	Keterangan       string             `json:"keterangan"`
+	Status           string             `json:"status"`
+	UserID           *int               `json:"user_id"`
+	JumlahBarang     int                `json:"jumlah_barang"`
+	CreatedAt        time.Time          `json:"created_at"`
+	Items            []StokOpnameDetail `json:"items,omitempty"`
+}
+
+type StokOpnameDetail struct {
+	BarangID   int    `json:"barang_id"`
+	KodeBarang string `json:"kode_barang"`
+	NamaBarang string `json:"nama_barang"`
+	Satuan     string `json:"satuan"`
+	KelasABC   string `json:"kelas_abc"`
+	KelasXYZ   string `json:"kelas_xyz"`
+	StokSistem int    `json:"stok_sistem"`
+	StokFisik  *int   `json:"stok_fisik"`
+}
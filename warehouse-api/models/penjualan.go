@@ -33,6 +33,7 @@ type CreatePenjualanRequest struct {
 
 type CreatePenjualanDetail struct {
 	BarangID int     `json:"barang_id"`
+	Barcode  string  `json:"barcode,omitempty"` // Alternatif barang_id untuk alur scanner
 	Qty      int     `json:"qty"`
 	Harga    float64 `json:"harga"`
 }
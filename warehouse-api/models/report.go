@@ -0,0 +1,50 @@
+package models
+
+// ReportQuery adalah parameter laporan penjualan/pembelian. Jenis: "penjualan" atau "pembelian";
+// GroupBy: day, week, month, barang, customer/supplier atau user.
+type ReportQuery struct {
+	Jenis     string
+	GroupBy   string
+	StartDate string
+	EndDate   string
+	Limit     int
+}
+
+type ReportValues struct {
+	Total           float64 `json:"total"`
+	Qty             int     `json:"qty"`
+	JumlahTransaksi int     `json:"jumlah_transaksi"`
+}
+
+// ReportPoint adalah satu titik seri. Untuk pengelompokan waktu, Key adalah tanggal awal
+// periode (YYYY-MM-DD); untuk barang/user adalah ID dan untuk customer/supplier adalah namanya.
+type ReportPoint struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	ReportValues
+	Previous *ReportValues `json:"previous,omitempty"`
+}
+
+type ReportChange struct {
+	Total           *float64 `json:"total"`
+	Qty             *float64 `json:"qty"`
+	JumlahTransaksi *float64 `json:"jumlah_transaksi"`
+}
+
+type ReportComparison struct {
+	StartDate string       `json:"start_date"`
+	EndDate   string       `json:"end_date"`
+	Summary   ReportValues `json:"summary"`
+	// ChangePercent bernilai null jika nilai periode sebelumnya nol
+	ChangePercent ReportChange `json:"change_percent"`
+}
+
+type Report struct {
+	Jenis      string            `json:"jenis"`
+	GroupBy    string            `json:"group_by"`
+	StartDate  string            `json:"start_date"`
+	EndDate    string            `json:"end_date"`
+	Summary    ReportValues      `json:"summary"`
+	Series     []ReportPoint     `json:"series"`
+	Comparison *ReportComparison `json:"comparison,omitempty"`
+}
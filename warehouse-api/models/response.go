@@ -13,6 +13,20 @@ type Pagination struct {
 	Total int `json:"total"`
 }
 
+// CursorResponse adalah respons list mode cursor: total tidak dihitung dan next_cursor
+// kosong berarti halaman terakhir
+type CursorResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Data    interface{}       `json:"data,omitempty"`
+	Meta    *CursorPagination `json:"meta"`
+}
+
+type CursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 type ErrorResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
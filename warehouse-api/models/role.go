@@ -0,0 +1,53 @@
+package models
+
+// Kode permission yang dipakai route di main.go. Daftar yang sama di-seed oleh migration 009_rbac.sql.
+const (
+	PermBarangRead      = "barang:read"
+	PermBarangWrite     = "barang:write"
+	PermBarangDelete    = "barang:delete"
+	PermKategoriWrite   = "kategori:write"
+	PermStokRead        = "stok:read"
+	PermStokAdjust      = "stok:adjust"
+	PermStokOpname      = "stok:opname"
+	PermPembelianRead   = "pembelian:read"
+	PermPembelianCreate = "pembelian:create"
+	PermPenjualanRead   = "penjualan:read"
+	PermPenjualanCreate = "penjualan:create"
+	PermReportView      = "report:view"
+	PermReportManage    = "report:manage"
+	PermUserManage      = "user:manage"
+	PermRoleManage      = "role:manage"
+	PermAPIKeyManage    = "apikey:manage"
+	PermAuditRead       = "audit:read"
+	// PermTransaksiReadAll membuka penjualan, pembelian dan riwayat stok milik semua pengguna
+	PermTransaksiReadAll = "transaksi:read-all"
+)
+
+// RoleAdmin selalu memiliki semua permission dan tidak bisa diubah atau dihapus
+const RoleAdmin = "admin"
+
+// RoleService dipakai service principal API key; hak aksesnya diambil dari scope key, bukan dari role
+const RoleService = "service"
+
+type Permission struct {
+	Kode      string `json:"kode"`
+	Deskripsi string `json:"deskripsi"`
+}
+
+type Role struct {
+	Nama        string   `json:"nama"`
+	Deskripsi   string   `json:"deskripsi"`
+	IsSystem    bool     `json:"is_system"`
+	JumlahUser  int      `json:"jumlah_user"`
+	Permissions []string `json:"permissions"`
+}
+
+type CreateRoleRequest struct {
+	Nama        string   `json:"nama" example:"gudang"`
+	Deskripsi   string   `json:"deskripsi" example:"Petugas gudang"`
+	Permissions []string `json:"permissions" example:"barang:read,stok:read,stok:opname"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" example:"barang:read,stok:read"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// JenisSaldoAwal adalah jenis_transaksi riwayat stok untuk saldo awal go-live
+const JenisSaldoAwal = "saldo_awal"
+
+// SaldoAwalItem adalah satu baris file saldo awal. Harga kosong berarti memakai harga beli barang.
+type SaldoAwalItem struct {
+	Row        int
+	KodeBarang string
+	Qty        int
+	Harga      *float64
+}
+
+type SaldoAwalOptions struct {
+	UserID int
+	Force  bool
+	DryRun bool
+}
+
+type SaldoAwalLine struct {
+	Row         int     `json:"row"`
+	BarangID    int     `json:"barang_id"`
+	KodeBarang  string  `json:"kode_barang"`
+	NamaBarang  string  `json:"nama_barang"`
+	Qty         int     `json:"qty"`
+	Harga       float64 `json:"harga"`
+	Nilai       float64 `json:"nilai"`
+	StokSebelum int     `json:"stok_sebelum"`
+	StokSesudah int     `json:"stok_sesudah"`
+	Replaced    bool    `json:"replaced"`
+}
+
+type SaldoAwalResult struct {
+	DryRun     bool             `json:"dry_run"`
+	TotalRows  int              `json:"total_rows"`
+	Loaded     int              `json:"loaded"`
+	Failed     int              `json:"failed"`
+	TotalQty   int              `json:"total_qty"`
+	TotalNilai float64          `json:"total_nilai"`
+	Items      []SaldoAwalLine  `json:"items"`
+	Errors     []ImportRowError `json:"errors"`
+}
@@ -11,3 +11,22 @@ type Stok struct {
 }
 
 
+
+// StokAsOf adalah saldo stok satu barang pada tanggal tertentu
+type StokAsOf struct {
+	BarangID       int        `json:"barang_id"`
+	KodeBarang     string     `json:"kode_barang"`
+	NamaBarang     string     `json:"nama_barang"`
+	Satuan         string     `json:"satuan"`
+	StokAkhir      int        `json:"stok_akhir"`
+	LastMovementAt *time.Time `json:"last_movement_at,omitempty"`
+}
+
+// StokAsOfResult: Source "snapshot" berarti angka diambil dari snapshot akhir bulan yang
+// sudah dibekukan, "history" berarti direkonstruksi dari riwayat stok saat ini.
+type StokAsOfResult struct {
+	AsOf       string     `json:"as_of"`
+	Source     string     `json:"source"`
+	SnapshotAt *time.Time `json:"snapshot_at,omitempty"`
+	Items      []StokAsOf `json:"items"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// TransaksiFilter dipakai oleh listing penjualan dan pembelian.
+// Party berisi nama customer (penjualan) atau supplier (pembelian).
+type TransaksiFilter struct {
+	StartDate    string
+	EndDate      string
+	Party        string
+	UserID       int
+	Status       string
+	FakturPrefix string
+	MinTotal     *float64
+	MaxTotal     *float64
+}
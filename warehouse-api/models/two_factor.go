@@ -0,0 +1,39 @@
+package models
+
+// TwoFactorSetup dikirim saat pendaftaran 2FA dimulai; secret belum aktif sebelum dikonfirmasi dengan kode
+type TwoFactorSetup struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURL string `json:"otpauth_url" example:"otpauth://totp/Warehouse:admin?secret=JBSWY3DPEHPK3PXP&issuer=Warehouse"`
+	QRCode     string `json:"qr_code"` // data URL PNG dari otpauth_url
+}
+
+// TwoFactorChallenge dikembalikan POST /login (sebagai pengganti token) bila akun wajib 2FA
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	SetupRequired     bool   `json:"setup_required" example:"false"` // akun wajib 2FA tapi belum mendaftar
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in" example:"300"` // umur challenge token dalam detik
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code" example:"123456"` // kode TOTP atau kode pemulihan
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" example:"rahasia123"`
+	Code     string `json:"code" example:"123456"`
+}
+
+// RecoveryCodesResponse hanya ditampilkan sekali; yang disimpan server hanya hash-nya
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -3,14 +3,19 @@ package models
 import "time"
 
 type User struct {
-	ID        int       `json:"id"`
-	Username  string    `json:"username"`
-	Password  string    `json:"password,omitempty"`
-	Email     string    `json:"email"`
-	FullName  string    `json:"full_name"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	Role     string `json:"role"`
+	IsActive bool   `json:"is_active"`
+	// TwoFactorEnabled menandakan login butuh kode TOTP setelah password
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+	// Permissions hanya diisi saat login dan GET /me
+	Permissions []string  `json:"permissions,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type LoginRequest struct {
@@ -18,9 +23,58 @@ type LoginRequest struct {
 	Password string `json:"password" example:"admin"`
 }
 
+type UpdateUserRequest struct {
+	Email    string `json:"email" example:"staff@example.com"`
+	FullName string `json:"full_name" example:"Staff Gudang"`
+	Role     string `json:"role" example:"staff"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" example:"rahasia123"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" example:"staff123"`
+	NewPassword     string `json:"new_password" example:"rahasia123"`
+}
+
+// TokenResponse berisi access token (JWT) dan refresh token baru
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in" example:"900"` // umur access token dalam detik
+	Visibility   string `json:"visibility" example:"own"` // transaksi yang terlihat: all atau own
+}
+
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	TokenResponse
+	User User `json:"user"`
+	// RecoveryCodes hanya terisi bila 2FA baru didaftarkan pada langkah login
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`       // opsional, mencabut sesi (family) refresh token ini
+	All          bool   `json:"all" example:"false"` // cabut semua sesi milik user
+}
+
+// RefreshToken adalah baris refresh_tokens yang baru dirotasi
+type RefreshToken struct {
+	ID       int
+	UserID   int
+	FamilyID string
+}
+
+// LoginAttempt adalah satu login gagal yang dicatat untuk audit dan penguncian
+type LoginAttempt struct {
+	Username  string
+	IP        string
+	UserAgent string
+	Alasan    string
 }
 
 type RegisterRequest struct {
@@ -28,5 +82,5 @@ type RegisterRequest struct {
 	Password string `json:"password" binding:"required" example:"staff123"`
 	Email    string `json:"email" binding:"required,email" example:"staff@example.com"`
 	FullName string `json:"full_name" binding:"required" example:"New Staff Member"`
-	Role     string `json:"role" binding:"required" example:"staff"` // admin or staff
+	Role     string `json:"role" binding:"required" example:"staff"` // salah satu nama di tabel roles
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Visibilitas data transaksi yang dibawa access token (claim "vis")
+const (
+	VisibilityAll = "all" // semua penjualan, pembelian dan riwayat stok
+	VisibilityOwn = "own" // hanya milik sendiri dan yang dibagikan kepadanya
+)
+
+// VisibilityFor menentukan visibilitas dari role dan permission-nya
+func VisibilityFor(role string, permissions []string) string {
+	if role == RoleAdmin || slices.Contains(permissions, PermTransaksiReadAll) {
+		return VisibilityAll
+	}
+	return VisibilityOwn
+}
+
+// Jenis transaksi yang bisa dibagikan
+const (
+	TransaksiPenjualan = "penjualan"
+	TransaksiPembelian = "pembelian"
+)
+
+// TransaksiShare adalah izin lihat satu transaksi untuk pengguna selain pembuatnya
+type TransaksiShare struct {
+	Jenis       string    `json:"jenis" example:"penjualan"`
+	TransaksiID int       `json:"transaksi_id"`
+	UserID      int       `json:"user_id"`
+	Username    string    `json:"username"`
+	SharedBy    int       `json:"shared_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ShareTransaksiRequest struct {
+	UserID int `json:"user_id" example:"3"`
+}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"warehouse-api/models"
+
+	"github.com/lib/pq"
+)
+
+var ErrAPIKeyNotFound = errors.New("API key tidak ditemukan")
+
+type APIKeyRepository interface {
+	// Create membuat service principal dan key-nya dalam satu transaksi; ID, UserID dan CreatedAt diisi
+	Create(ctx context.Context, key *models.APIKey, keyHash string) error
+	GetAll() ([]models.APIKey, error)
+	// GetActiveByHash mengembalikan key yang belum dicabut, belum kedaluwarsa dan principal-nya masih aktif
+	GetActiveByHash(keyHash string) (*models.APIKey, error)
+	// Touch memperbarui last_used_at paling sering sekali per menit
+	Touch(id int) error
+	Revoke(ctx context.Context, id int) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+// expires_at ditulis lewat to_timestamp (detik unix, seperti token_repo.go) dan dibaca sebagai
+// timestamptz agar tidak bergeser bila zona waktu server aplikasi dan database berbeda
+const apiKeyColumns = `k.id, k.nama, k.prefix, k.user_id, u.username, k.permissions, k.expires_at::timestamptz,
+        k.last_used_at, k.revoked_at, COALESCE(k.created_by, 0), k.created_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*models.APIKey, error) {
+	var k models.APIKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&k.ID, &k.Nama, &k.Prefix, &k.UserID, &k.Username, pq.Array(&k.Permissions),
+		&expiresAt, &lastUsedAt, &revokedAt, &k.CreatedBy, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		k.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+	if k.Permissions == nil {
+		k.Permissions = []string{}
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Password "!" bukan hash bcrypt sehingga principal tidak pernah bisa login
+	err = tx.QueryRow(`
+        INSERT INTO users (username, password, email, full_name, role, is_service)
+        VALUES ($1, '!', $2, $3, $4, TRUE) RETURNING id`,
+		key.Username, key.Username+"@api-key.local", key.Nama, models.RoleService).Scan(&key.UserID)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt sql.NullInt64
+	if key.ExpiresAt != nil {
+		expiresAt = sql.NullInt64{Int64: key.ExpiresAt.Unix(), Valid: true}
+	}
+	err = tx.QueryRow(`
+        INSERT INTO api_keys (user_id, nama, prefix, key_hash, permissions, expires_at, created_by)
+        VALUES ($1, $2, $3, $4, $5, to_timestamp($6), NULLIF($7, 0)) RETURNING id, created_at`,
+		key.UserID, key.Nama, key.Prefix, keyHash, pq.Array(key.Permissions), expiresAt, key.CreatedBy).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditCreate, models.AuditAPIKey, key.ID, nil, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *apiKeyRepository) GetAll() ([]models.APIKey, error) {
+	rows, err := r.db.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys k JOIN users u ON u.id = k.user_id ORDER BY k.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepository) GetActiveByHash(keyHash string) (*models.APIKey, error) {
+	row := r.db.QueryRow(`
+        SELECT `+apiKeyColumns+` FROM api_keys k JOIN users u ON u.id = k.user_id
+        WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())
+          AND u.is_active`, keyHash)
+	k, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	return k, err
+}
+
+func (r *apiKeyRepository) Touch(id int) error {
+	_, err := r.db.Exec(`
+        UPDATE api_keys SET last_used_at = NOW()
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`, id)
+	return err
+}
+
+// Revoke mencabut key dan menonaktifkan principal-nya; baris tetap disimpan agar riwayat stok tetap terbaca
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := apiKeyForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ErrAPIKeyNotFound
+	}
+	if _, err := tx.Exec(`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE users SET is_active = FALSE, updated_at = NOW() WHERE id = $1`, before.UserID); err != nil {
+		return err
+	}
+	after, err := apiKeyForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditDelete, models.AuditAPIKey, id, before, after); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// apiKeyForAudit membaca key di dalam tx (dikunci); nil bila tidak ada.
+// last_used_at dikosongkan agar pemakaian key tidak tercatat sebagai perubahan.
+func apiKeyForAudit(tx *sql.Tx, id int) (*models.APIKey, error) {
+	k, err := scanAPIKey(tx.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys k JOIN users u ON u.id = k.user_id WHERE k.id = $1 FOR UPDATE OF k`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	k.LastUsedAt = nil
+	return k, nil
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"warehouse-api/ctxkeys"
+	"warehouse-api/models"
+)
+
+type AuditRepository interface {
+	GetAll(filter models.AuditFilter, limit, offset int) ([]models.AuditLog, int, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db}
+}
+
+// auditRedacted tidak pernah disimpan di audit log walaupun ikut ter-serialisasi
+var auditRedacted = []string{"password", "totp_secret", "key_hash"}
+
+// WriteAudit mencatat satu perubahan di tx yang sama dengan perubahannya; dipakai juga oleh service
+// yang mengelola transaksinya sendiri. Pelaku, IP dan request ID dibaca dari context request
+// (ctxkeys, diisi AuthMiddleware dan RequestID). Update tanpa perubahan tidak dicatat.
+func WriteAudit(ctx context.Context, tx *sql.Tx, aksi, entitas string, entitasID interface{}, before, after interface{}) error {
+	sebelum, sesudah, err := AuditDiff(before, after)
+	if err != nil {
+		return err
+	}
+	if sebelum == nil && sesudah == nil {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO audit_log (user_id, aksi, entitas, entitas_id, sebelum, sesudah, ip, request_id)
+        VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''))`,
+		ctxkeys.UserID(ctx), aksi, entitas, fmt.Sprint(entitasID), nullJSON(sebelum), nullJSON(sesudah),
+		ctxkeys.ClientIP(ctx), ctxkeys.RequestID(ctx))
+	return err
+}
+
+// AuditDiff mengembalikan field yang berbeda antara before dan after dalam bentuk JSON.
+// before nil (create) menghasilkan seluruh after, after nil (delete) menghasilkan seluruh before.
+// Keduanya nil bila tidak ada field yang berubah.
+func AuditDiff(before, after interface{}) (sebelum, sesudah json.RawMessage, err error) {
+	b, err := auditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := auditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if b != nil && a != nil {
+		changedBefore := map[string]interface{}{}
+		changedAfter := map[string]interface{}{}
+		for k, v := range b {
+			if !reflect.DeepEqual(v, a[k]) {
+				changedBefore[k] = v
+				changedAfter[k] = a[k]
+			}
+		}
+		for k, v := range a {
+			if _, ok := b[k]; !ok {
+				changedBefore[k] = nil
+				changedAfter[k] = v
+			}
+		}
+		if len(changedAfter) == 0 {
+			return nil, nil, nil
+		}
+		b, a = changedBefore, changedAfter
+	}
+
+	if sebelum, err = marshalAudit(b); err != nil {
+		return nil, nil, err
+	}
+	if sesudah, err = marshalAudit(a); err != nil {
+		return nil, nil, err
+	}
+	return sebelum, sesudah, nil
+}
+
+// auditFields mengubah struct menjadi map field JSON-nya tanpa field rahasia
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for _, k := range auditRedacted {
+		delete(fields, k)
+	}
+	return fields, nil
+}
+
+func marshalAudit(fields map[string]interface{}) (json.RawMessage, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
+
+func nullJSON(raw json.RawMessage) interface{} {
+	if raw == nil {
+		return nil
+	}
+	return string(raw)
+}
+
+func buildAuditWhere(filter models.AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Entitas != "" {
+		add("a.entitas = $%d", filter.Entitas)
+	}
+	if filter.EntitasID != "" {
+		add("a.entitas_id = $%d", filter.EntitasID)
+	}
+	if filter.UserID != 0 {
+		add("a.user_id = $%d", filter.UserID)
+	}
+	if filter.Aksi != "" {
+		add("a.aksi = $%d", filter.Aksi)
+	}
+	if filter.StartDate != "" {
+		add("a.created_at >= $%d::date", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		// end_date inklusif: seluruh hari terakhir ikut terhitung
+		add("a.created_at < $%d::date + 1", filter.EndDate)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// GetAll mengembalikan audit log terbaru dulu beserta jumlah total baris yang cocok dengan filter
+func (r *auditRepository) GetAll(filter models.AuditFilter, limit, offset int) ([]models.AuditLog, int, error) {
+	where, args := buildAuditWhere(filter)
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM audit_log a "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+        SELECT a.id, a.user_id, COALESCE(u.username, ''), a.aksi, a.entitas, a.entitas_id, a.sebelum, a.sesudah,
+               COALESCE(a.ip, ''), COALESCE(a.request_id, ''), a.created_at
+        FROM audit_log a
+        LEFT JOIN users u ON u.id = a.user_id
+        %s
+        ORDER BY a.created_at DESC, a.id DESC
+        LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []models.AuditLog{}
+	for rows.Next() {
+		var l models.AuditLog
+		var userID sql.NullInt64
+		var sebelum, sesudah []byte
+		if err := rows.Scan(&l.ID, &userID, &l.Username, &l.Aksi, &l.Entitas, &l.EntitasID, &sebelum, &sesudah,
+			&l.IP, &l.RequestID, &l.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if userID.Valid {
+			id := int(userID.Int64)
+			l.UserID = &id
+		}
+		if sebelum != nil {
+			l.Sebelum = sebelum
+		}
+		if sesudah != nil {
+			l.Sesudah = sesudah
+		}
+		logs = append(logs, l)
+	}
+	return logs, total, rows.Err()
+}
@@ -1,19 +1,45 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"warehouse-api/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrKategoriNotFound = errors.New("kategori tidak ditemukan")
+	ErrMerekNotFound    = errors.New("merek tidak ditemukan")
+	ErrBarcodeExists    = errors.New("barcode sudah dipakai barang lain")
+	ErrBarcodeNotFound  = errors.New("barcode tidak ditemukan")
+	ErrKodeBarangSistem = errors.New("kode_barang BRG-<nomor> dibuat otomatis oleh sistem, kosongkan untuk barang baru")
 )
 
+// kodeBarangSistem adalah pola kode yang dibuat insertBarang dari sequence (BRG-001)
+var kodeBarangSistem = regexp.MustCompile(`^BRG-[0-9]+$`)
+
 type BarangRepository interface {
-	Create(barang *models.Barang) error
-	Update(barang *models.Barang) error
-	Delete(id int) error
+	Create(ctx context.Context, barang *models.Barang) error
+	Update(ctx context.Context, barang *models.Barang) error
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
 	GetByID(id int) (*models.BarangWithStok, error)
-	GetAll(search string, limit, offset int, sortBy, order string) ([]models.Barang, int, error) // Returns data, total count, error
-	GetAllWithStok(search string, limit, offset int, sortBy, order string) ([]models.BarangWithStok, int, error)
+	GetByBarcode(code string) (*models.BarangWithStok, error)
+	GetByKode(kode string) (*models.Barang, error)
+	AddBarcode(ctx context.Context, barangID int, code string) error
+	RemoveBarcode(ctx context.Context, barangID int, code string) error
+	GetAll(filter models.BarangFilter, limit, offset int, sortBy, order string) ([]models.Barang, int, error) // Returns data, total count, error
+	GetAllWithStok(filter models.BarangFilter, limit, offset int, sortBy, order string) ([]models.BarangWithStok, int, error)
+	StreamWithStok(ctx context.Context, filter models.BarangFilter, sortBy, order string, fn func(models.BarangWithStok) error) error
+	Import(ctx context.Context, items []models.BarangImportItem, dryRun bool) (*models.BarangImportResult, error)
     Exists(id int) (bool, error)
+	// LockActive mengunci baris barang sampai tx selesai dan mengembalikan status aktifnya
+	LockActive(tx *sql.Tx, id int) (bool, error)
 }
 
 type barangRepository struct {
@@ -24,86 +50,518 @@ func NewBarangRepository(db *sql.DB) BarangRepository {
 	return &barangRepository{db}
 }
 
-func (r *barangRepository) Create(barang *models.Barang) error {
+func (r *barangRepository) Create(ctx context.Context, barang *models.Barang) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	barang.KodeBarang = ""
+	if err := insertBarang(tx, barang); err != nil {
+		return err
+	}
+	if err := auditBarangCreate(ctx, tx, barang.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertBarang menyimpan barang baru beserta tag dan barcode-nya di dalam tx.
+// Jika KodeBarang kosong, kode dibuat otomatis dari ID (BRG-001).
+func insertBarang(tx *sql.Tx, barang *models.Barang) error {
+	if err := validateBarangRelations(tx, barang); err != nil {
+		return err
+	}
 
 	var nextID int
-	err = tx.QueryRow("SELECT nextval(pg_get_serial_sequence('master_barang','id'))").Scan(&nextID)
+	err := tx.QueryRow("SELECT nextval(pg_get_serial_sequence('master_barang','id'))").Scan(&nextID)
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
-	kode := fmt.Sprintf("BRG-%03d", nextID)
+	kode := barang.KodeBarang
+	if kode == "" {
+		kode = fmt.Sprintf("BRG-%03d", nextID)
+	}
 
-	query := `INSERT INTO master_barang (id, kode_barang, nama_barang, deskripsi, satuan, harga_beli, harga_jual)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err = tx.Exec(query, nextID, kode, barang.NamaBarang, barang.Deskripsi, barang.Satuan, barang.HargaBeli, barang.HargaJual)
+	query := `INSERT INTO master_barang (id, kode_barang, nama_barang, deskripsi, satuan, harga_beli, harga_jual, kategori_id, merek_id)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err = tx.Exec(query, nextID, kode, barang.NamaBarang, barang.Deskripsi, barang.Satuan, barang.HargaBeli, barang.HargaJual, barang.KategoriID, barang.MerekID)
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	barang.Tags = normalizeTags(barang.Tags)
+	if err := replaceBarangTags(tx, nextID, barang.Tags); err != nil {
 		return err
 	}
 
+	if barang.Barcodes == nil {
+		barang.Barcodes = []string{}
+	}
+	for _, code := range barang.Barcodes {
+		if err := insertBarcode(tx, nextID, code); err != nil {
+			return err
+		}
+	}
+
 	barang.ID = nextID
 	barang.KodeBarang = kode
+	barang.IsActive = true
 	return nil
 }
 
-func (r *barangRepository) Update(barang *models.Barang) error {
-	query := `UPDATE master_barang SET kode_barang=$1, nama_barang=$2, deskripsi=$3, satuan=$4, harga_beli=$5, harga_jual=$6 WHERE id=$7`
-	_, err := r.db.Exec(query, barang.KodeBarang, barang.NamaBarang, barang.Deskripsi, barang.Satuan, barang.HargaBeli, barang.HargaJual, barang.ID)
-	return err
+func (r *barangRepository) Update(ctx context.Context, barang *models.Barang) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = auditBarangChange(ctx, tx, models.AuditUpdate, barang.ID, func() error {
+		return updateBarang(tx, barang)
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func updateBarang(tx *sql.Tx, barang *models.Barang) error {
+	if err := validateBarangRelations(tx, barang); err != nil {
+		return err
+	}
+
+	query := `UPDATE master_barang SET kode_barang=$1, nama_barang=$2, deskripsi=$3, satuan=$4, harga_beli=$5, harga_jual=$6, kategori_id=$7, merek_id=$8, updated_at=CURRENT_TIMESTAMP WHERE id=$9`
+	_, err := tx.Exec(query, barang.KodeBarang, barang.NamaBarang, barang.Deskripsi, barang.Satuan, barang.HargaBeli, barang.HargaJual, barang.KategoriID, barang.MerekID, barang.ID)
+	if err != nil {
+		return err
+	}
+
+	// Tags nil berarti tidak diubah, slice kosong berarti semua tag dihapus
+	if barang.Tags != nil {
+		barang.Tags = normalizeTags(barang.Tags)
+		if err := replaceBarangTags(tx, barang.ID, barang.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (r *barangRepository) Delete(id int) error {
-	query := `DELETE FROM master_barang WHERE id=$1`
-	_, err := r.db.Exec(query, id)
+// Import menyimpan hasil import dalam satu transaksi: barang dengan kode_barang yang sudah ada
+// diperbarui, sisanya dibuat baru. Setiap baris dijalankan di savepoint agar semua error
+// per baris bisa dilaporkan. Jika ada satu baris gagal atau dryRun = true, seluruh transaksi
+// di-rollback sehingga import bersifat all-or-nothing.
+func (r *barangRepository) Import(ctx context.Context, items []models.BarangImportItem, dryRun bool) (*models.BarangImportResult, error) {
+	result := &models.BarangImportResult{DryRun: dryRun, Errors: []models.ImportRowError{}}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		if _, err := tx.Exec("SAVEPOINT import_row"); err != nil {
+			return nil, err
+		}
+
+		created, err := importBarangRow(ctx, tx, item.Barang)
+		if err != nil {
+			if !isImportRowError(err) {
+				return nil, err
+			}
+			if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT import_row"); rbErr != nil {
+				return nil, rbErr
+			}
+			result.Errors = append(result.Errors, models.ImportRowError{Row: item.Row, KodeBarang: item.Barang.KodeBarang, Error: err.Error()})
+			continue
+		}
+
+		if _, err := tx.Exec("RELEASE SAVEPOINT import_row"); err != nil {
+			return nil, err
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+	return result, tx.Commit()
+}
+
+// importBarangRow melakukan upsert satu barang berdasarkan kode_barang. Audit log ikut savepoint baris.
+func importBarangRow(ctx context.Context, tx *sql.Tx, barang models.Barang) (created bool, err error) {
+	var id int
+	if barang.KodeBarang != "" {
+		err = tx.QueryRow("SELECT id FROM master_barang WHERE kode_barang = $1 FOR UPDATE", barang.KodeBarang).Scan(&id)
+		if err != nil && err != sql.ErrNoRows {
+			return false, err
+		}
+	}
+
+	if id == 0 {
+		// Kode pola sistem untuk barang baru akan bentrok dengan kode yang nanti dibuat Create
+		if kodeBarangSistem.MatchString(barang.KodeBarang) {
+			return true, ErrKodeBarangSistem
+		}
+		if err := insertBarang(tx, &barang); err != nil {
+			return true, err
+		}
+		return true, auditBarangCreate(ctx, tx, barang.ID)
+	}
+
+	barang.ID = id
+	return false, auditBarangChange(ctx, tx, models.AuditUpdate, id, func() error {
+		if err := updateBarang(tx, &barang); err != nil {
+			return err
+		}
+		// Barcode baru ditambahkan, barcode yang sudah milik barang ini dilewati
+		for _, code := range barang.Barcodes {
+			var owner int
+			err := tx.QueryRow("SELECT barang_id FROM barang_barcode WHERE barcode = $1", code).Scan(&owner)
+			if err == nil && owner == id {
+				continue
+			}
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if err := insertBarcode(tx, id, code); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// isImportRowError menandai error yang berasal dari data baris (dilaporkan per baris),
+// bukan kegagalan database yang harus menghentikan import
+func isImportRowError(err error) bool {
+	if errors.Is(err, ErrKategoriNotFound) || errors.Is(err, ErrMerekNotFound) || errors.Is(err, ErrBarcodeExists) ||
+		errors.Is(err, ErrKodeBarangSistem) {
+		return true
+	}
+	// Pelanggaran constraint (unik, panjang kolom, dll) juga kesalahan data baris
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code.Class() == "23" || pqErr.Code.Class() == "22"
+	}
+	return false
+}
+
+// validateBarangRelations memastikan kategori dan merek yang direferensikan ada
+func validateBarangRelations(tx *sql.Tx, barang *models.Barang) error {
+	var exists bool
+	if barang.KategoriID != nil {
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM kategori WHERE id=$1)", *barang.KategoriID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrKategoriNotFound
+		}
+	}
+	if barang.MerekID != nil {
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM merek WHERE id=$1)", *barang.MerekID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrMerekNotFound
+		}
+	}
+	return nil
+}
+
+// normalizeTags membuat tag lowercase, tanpa spasi di ujung, dan tanpa duplikat
+func normalizeTags(tags []string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+// replaceBarangTags mengganti seluruh tag milik barang. Tag baru dibuat otomatis.
+func replaceBarangTags(tx *sql.Tx, barangID int, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM barang_tag WHERE barang_id = $1", barangID); err != nil {
+		return err
+	}
+
+	for _, t := range tags {
+		var tagID int
+		err := tx.QueryRow(`INSERT INTO tag (nama) VALUES ($1)
+                            ON CONFLICT (nama) DO UPDATE SET nama = EXCLUDED.nama
+                            RETURNING id`, t).Scan(&tagID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO barang_tag (barang_id, tag_id) VALUES ($1, $2)", barangID, tagID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertBarcode menyimpan satu barcode untuk barang, gagal jika sudah dipakai
+func insertBarcode(tx *sql.Tx, barangID int, code string) error {
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM barang_barcode WHERE barcode=$1)", code).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrBarcodeExists
+	}
+
+	_, err := tx.Exec("INSERT INTO barang_barcode (barang_id, barcode) VALUES ($1, $2)", barangID, code)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrBarcodeExists
+	}
 	return err
 }
 
+func (r *barangRepository) AddBarcode(ctx context.Context, barangID int, code string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		return auditBarangChange(ctx, tx, models.AuditUpdate, barangID, func() error {
+			return insertBarcode(tx, barangID, code)
+		})
+	})
+}
+
+func (r *barangRepository) RemoveBarcode(ctx context.Context, barangID int, code string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		return auditBarangChange(ctx, tx, models.AuditUpdate, barangID, func() error {
+			res, err := tx.Exec("DELETE FROM barang_barcode WHERE barang_id=$1 AND barcode=$2", barangID, code)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected == 0 {
+				return ErrBarcodeNotFound
+			}
+			return nil
+		})
+	})
+}
+
+// Delete melakukan soft delete (arsip). Baris tetap ada agar history_stok,
+// beli_detail dan jual_detail yang mereferensikannya tidak rusak.
+func (r *barangRepository) Delete(ctx context.Context, id int) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		return auditBarangChange(ctx, tx, models.AuditDelete, id, func() error {
+			query := `UPDATE master_barang SET is_active = FALSE, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id=$1`
+			_, err := tx.Exec(query, id)
+			return err
+		})
+	})
+}
+
+// Restore mengaktifkan kembali barang yang sudah diarsipkan
+func (r *barangRepository) Restore(ctx context.Context, id int) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		return auditBarangChange(ctx, tx, models.AuditUpdate, id, func() error {
+			query := `UPDATE master_barang SET is_active = TRUE, deleted_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id=$1`
+			_, err := tx.Exec(query, id)
+			return err
+		})
+	})
+}
+
+func (r *barangRepository) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// barangForAudit mengunci lalu membaca barang di dalam tx; nil bila barang tidak ada
+func barangForAudit(tx *sql.Tx, id int) (*models.Barang, error) {
+	var locked int
+	err := tx.QueryRow("SELECT id FROM master_barang WHERE id = $1 FOR UPDATE", id).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var barang models.Barang
+	err = tx.QueryRow(`SELECT `+barangColumns+` FROM master_barang b WHERE b.id = $1`, id).Scan(barangScanDest(&barang)...)
+	if err != nil {
+		return nil, err
+	}
+	return &barang, nil
+}
+
+// auditBarangChange menjalankan fn lalu mencatat keadaan barang sebelum dan sesudahnya ke audit log
+func auditBarangChange(ctx context.Context, tx *sql.Tx, aksi string, id int, fn func() error) error {
+	before, err := barangForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	after, err := barangForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+	return WriteAudit(ctx, tx, aksi, models.AuditBarang, id, before, after)
+}
+
+func auditBarangCreate(ctx context.Context, tx *sql.Tx, id int) error {
+	after, err := barangForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+	return WriteAudit(ctx, tx, models.AuditCreate, models.AuditBarang, id, nil, after)
+}
+
 func (r *barangRepository) GetByID(id int) (*models.BarangWithStok, error) {
 	query := `
-        SELECT b.id, b.kode_barang, b.nama_barang, b.deskripsi, b.satuan, b.harga_beli, b.harga_jual, COALESCE(s.stok_akhir, 0)
+        SELECT ` + barangColumns + `, COALESCE(s.stok_akhir, 0)
         FROM master_barang b
         LEFT JOIN mstok s ON b.id = s.barang_id
         WHERE b.id = $1`
 	var barang models.BarangWithStok
-	err := r.db.QueryRow(query, id).Scan(
-		&barang.ID, &barang.KodeBarang, &barang.NamaBarang, &barang.Deskripsi, &barang.Satuan, &barang.HargaBeli, &barang.HargaJual, &barang.Stok,
-	)
+	err := r.db.QueryRow(query, id).Scan(append(barangScanDest(&barang.Barang), &barang.Stok)...)
 	if err != nil {
 		return nil, err
 	}
+
+	// Klasifikasi ABC/XYZ dari run terakhir; barang yang tidak ikut run tersebut tidak punya kelas
+	var k models.KlasifikasiBarang
+	err = r.db.QueryRow(`
+        SELECT k.kelas_abc, k.kelas_xyz, kr.id, kr.created_at
+        FROM barang_klasifikasi k
+        JOIN klasifikasi_run kr ON kr.id = k.run_id
+        WHERE k.barang_id = $1 AND k.run_id = (SELECT MAX(id) FROM klasifikasi_run)`, id).
+		Scan(&k.KelasABC, &k.KelasXYZ, &k.RunID, &k.RunAt)
+	if err == nil {
+		barang.Klasifikasi = &k
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
 	return &barang, nil
 }
 
-func (r *barangRepository) GetAll(search string, limit, offset int, sortBy, order string) ([]models.Barang, int, error) {
-	var whereClause string
+// GetByBarcode mencari barang (termasuk yang diarsipkan) berdasarkan salah satu barcode-nya
+func (r *barangRepository) GetByBarcode(code string) (*models.BarangWithStok, error) {
+	query := `
+        SELECT ` + barangColumns + `, COALESCE(s.stok_akhir, 0)
+        FROM barang_barcode bc
+        JOIN master_barang b ON b.id = bc.barang_id
+        LEFT JOIN mstok s ON b.id = s.barang_id
+        WHERE bc.barcode = $1`
+	var barang models.BarangWithStok
+	err := r.db.QueryRow(query, code).Scan(append(barangScanDest(&barang.Barang), &barang.Stok)...)
+	if err != nil {
+		return nil, err
+	}
+	return &barang, nil
+}
+
+// GetByKode mencari barang (termasuk yang diarsipkan) berdasarkan kode_barang
+func (r *barangRepository) GetByKode(kode string) (*models.Barang, error) {
+	query := `SELECT ` + barangColumns + ` FROM master_barang b WHERE b.kode_barang = $1`
+	var barang models.Barang
+	if err := r.db.QueryRow(query, kode).Scan(barangScanDest(&barang)...); err != nil {
+		return nil, err
+	}
+	return &barang, nil
+}
+
+// barangColumns adalah kolom standar master_barang (alias b) beserta tag-nya.
+// Urutannya harus sama dengan barangScanDest.
+const barangColumns = `b.id, b.kode_barang, b.nama_barang, COALESCE(b.deskripsi, ''), b.satuan, b.harga_beli, b.harga_jual,
+        b.kategori_id, b.merek_id,
+        COALESCE((SELECT array_agg(t.nama ORDER BY t.nama) FROM barang_tag bt JOIN tag t ON t.id = bt.tag_id WHERE bt.barang_id = b.id), '{}'),
+        COALESCE((SELECT array_agg(bc.barcode ORDER BY bc.id) FROM barang_barcode bc WHERE bc.barang_id = b.id), '{}'),
+        b.is_active, b.deleted_at`
+
+func barangScanDest(b *models.Barang) []interface{} {
+	return []interface{}{
+		&b.ID, &b.KodeBarang, &b.NamaBarang, &b.Deskripsi, &b.Satuan, &b.HargaBeli, &b.HargaJual,
+		&b.KategoriID, &b.MerekID, pq.Array(&b.Tags), pq.Array(&b.Barcodes), &b.IsActive, &b.DeletedAt,
+	}
+}
+
+// buildBarangWhere menyusun klausa WHERE untuk listing barang (alias tabel b) berdasarkan filter.
+func buildBarangWhere(filter models.BarangFilter) (string, []interface{}) {
+	var conditions []string
 	var args []interface{}
-	idx := 1
 
-	if search != "" {
-		whereClause = "WHERE kode_barang ILIKE $1 OR nama_barang ILIKE $1"
-		args = append(args, "%"+search+"%")
-		idx++
+	if !filter.IncludeArchived {
+		conditions = append(conditions, "b.is_active = TRUE")
 	}
 
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		conditions = append(conditions, fmt.Sprintf("(b.kode_barang ILIKE $%d OR b.nama_barang ILIKE $%d)", len(args), len(args)))
+	}
+
+	if filter.KategoriID != 0 {
+		// Termasuk semua sub-kategori
+		args = append(args, filter.KategoriID)
+		conditions = append(conditions, fmt.Sprintf(`b.kategori_id IN (
+            WITH RECURSIVE sub AS (
+                SELECT id FROM kategori WHERE id = $%d
+                UNION
+                SELECT k.id FROM kategori k JOIN sub ON k.parent_id = sub.id
+            )
+            SELECT id FROM sub)`, len(args)))
+	}
+
+	if filter.MerekID != 0 {
+		args = append(args, filter.MerekID)
+		conditions = append(conditions, fmt.Sprintf("b.merek_id = $%d", len(args)))
+	}
+
+	if filter.Tag != "" {
+		args = append(args, strings.ToLower(strings.TrimSpace(filter.Tag)))
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (
+            SELECT 1 FROM barang_tag bt JOIN tag t ON t.id = bt.tag_id
+            WHERE bt.barang_id = b.id AND t.nama = $%d)`, len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (r *barangRepository) GetAll(filter models.BarangFilter, limit, offset int, sortBy, order string) ([]models.Barang, int, error) {
+	whereClause, args := buildBarangWhere(filter)
+	idx := len(args) + 1
+
     // Default Sorting
-    orderByClause := "ORDER BY id ASC"
+    orderByClause := "ORDER BY b.id ASC"
     if sortBy != "" {
         // Whitelist allowed columns to prevent SQL Injection
         allowedSorts := map[string]string{
-            "harga_beli": "harga_beli",
-            "harga_jual": "harga_jual",
-            "nama":       "nama_barang",
-            "id":         "id",
+            "harga_beli": "b.harga_beli",
+            "harga_jual": "b.harga_jual",
+            "nama":       "b.nama_barang",
+            "id":         "b.id",
         }
         
         if col, ok := allowedSorts[sortBy]; ok {
@@ -116,7 +574,7 @@ func (r *barangRepository) GetAll(search string, limit, offset int, sortBy, orde
     }
 
 	// Get Total Count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM master_barang %s", whereClause)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM master_barang b %s", whereClause)
 	var total int
 	// Re-construct args for count query (without limit/offset)
     countArgs := make([]interface{}, len(args))
@@ -128,7 +586,7 @@ func (r *barangRepository) GetAll(search string, limit, offset int, sortBy, orde
 	}
 
 	// Get Data
-	query := fmt.Sprintf("SELECT id, kode_barang, nama_barang, deskripsi, satuan, harga_beli, harga_jual FROM master_barang %s %s LIMIT $%d OFFSET $%d", whereClause, orderByClause, idx, idx+1)
+	query := fmt.Sprintf("SELECT %s FROM master_barang b %s %s LIMIT $%d OFFSET $%d", barangColumns, whereClause, orderByClause, idx, idx+1)
 	args = append(args, limit, offset)
 
 	rows, err := r.db.Query(query, args...)
@@ -140,7 +598,7 @@ func (r *barangRepository) GetAll(search string, limit, offset int, sortBy, orde
 	var barangs []models.Barang
 	for rows.Next() {
 		var b models.Barang
-		if err := rows.Scan(&b.ID, &b.KodeBarang, &b.NamaBarang, &b.Deskripsi, &b.Satuan, &b.HargaBeli, &b.HargaJual); err != nil {
+		if err := rows.Scan(barangScanDest(&b)...); err != nil {
 			return nil, 0, err
 		}
 		barangs = append(barangs, b)
@@ -149,35 +607,10 @@ func (r *barangRepository) GetAll(search string, limit, offset int, sortBy, orde
 	return barangs, total, nil
 }
 
-func (r *barangRepository) GetAllWithStok(search string, limit, offset int, sortBy, order string) ([]models.BarangWithStok, int, error) {
-	var whereClause string
-	var args []interface{}
-	idx := 1
-
-	if search != "" {
-		whereClause = "WHERE b.kode_barang ILIKE $1 OR b.nama_barang ILIKE $1"
-		args = append(args, "%"+search+"%")
-		idx++
-	}
-
-	orderByClause := "ORDER BY b.id ASC"
-	if sortBy != "" {
-		allowedSorts := map[string]string{
-			"harga_beli": "b.harga_beli",
-			"harga_jual": "b.harga_jual",
-			"nama":       "b.nama_barang",
-			"id":         "b.id",
-			"stok":       "COALESCE(s.stok_akhir, 0)",
-		}
-
-		if col, ok := allowedSorts[sortBy]; ok {
-			ord := "ASC"
-			if order == "desc" || order == "DESC" {
-				ord = "DESC"
-			}
-			orderByClause = fmt.Sprintf("ORDER BY %s %s", col, ord)
-		}
-	}
+func (r *barangRepository) GetAllWithStok(filter models.BarangFilter, limit, offset int, sortBy, order string) ([]models.BarangWithStok, int, error) {
+	whereClause, args := buildBarangWhere(filter)
+	idx := len(args) + 1
+	orderByClause := barangWithStokOrderBy(sortBy, order)
 
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM master_barang b %s", whereClause)
 	var total int
@@ -190,12 +623,12 @@ func (r *barangRepository) GetAllWithStok(search string, limit, offset int, sort
 	}
 
 	query := fmt.Sprintf(`
-		SELECT b.id, b.kode_barang, b.nama_barang, b.deskripsi, b.satuan, b.harga_beli, b.harga_jual, COALESCE(s.stok_akhir, 0)
+		SELECT %s, COALESCE(s.stok_akhir, 0)
 		FROM master_barang b
 		LEFT JOIN mstok s ON b.id = s.barang_id
 		%s
 		%s
-		LIMIT $%d OFFSET $%d`, whereClause, orderByClause, idx, idx+1)
+		LIMIT $%d OFFSET $%d`, barangColumns, whereClause, orderByClause, idx, idx+1)
 	args = append(args, limit, offset)
 
 	rows, err := r.db.Query(query, args...)
@@ -207,7 +640,7 @@ func (r *barangRepository) GetAllWithStok(search string, limit, offset int, sort
 	var barangs []models.BarangWithStok
 	for rows.Next() {
 		var b models.BarangWithStok
-		if err := rows.Scan(&b.ID, &b.KodeBarang, &b.NamaBarang, &b.Deskripsi, &b.Satuan, &b.HargaBeli, &b.HargaJual, &b.Stok); err != nil {
+		if err := rows.Scan(append(barangScanDest(&b.Barang), &b.Stok)...); err != nil {
 			return nil, 0, err
 		}
 		barangs = append(barangs, b)
@@ -216,9 +649,66 @@ func (r *barangRepository) GetAllWithStok(search string, limit, offset int, sort
 	return barangs, total, nil
 }
 
+func barangWithStokOrderBy(sortBy, order string) string {
+	allowedSorts := map[string]string{
+		"harga_beli": "b.harga_beli",
+		"harga_jual": "b.harga_jual",
+		"nama":       "b.nama_barang",
+		"id":         "b.id",
+		"stok":       "COALESCE(s.stok_akhir, 0)",
+	}
+
+	if col, ok := allowedSorts[sortBy]; ok {
+		ord := "ASC"
+		if order == "desc" || order == "DESC" {
+			ord = "DESC"
+		}
+		return fmt.Sprintf("ORDER BY %s %s", col, ord)
+	}
+	return "ORDER BY b.id ASC"
+}
+
+// StreamWithStok memanggil fn untuk setiap barang (beserta stok) yang cocok dengan filter,
+// langsung dari cursor database tanpa menampung seluruh hasil. Berhenti jika fn mengembalikan error.
+func (r *barangRepository) StreamWithStok(ctx context.Context, filter models.BarangFilter, sortBy, order string, fn func(models.BarangWithStok) error) error {
+	whereClause, args := buildBarangWhere(filter)
+	query := fmt.Sprintf(`
+		SELECT %s, COALESCE(s.stok_akhir, 0)
+		FROM master_barang b
+		LEFT JOIN mstok s ON b.id = s.barang_id
+		%s
+		%s`, barangColumns, whereClause, barangWithStokOrderBy(sortBy, order))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b models.BarangWithStok
+		if err := rows.Scan(append(barangScanDest(&b.Barang), &b.Stok)...); err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *barangRepository) Exists(id int) (bool, error) {
     var exists bool
     query := "SELECT EXISTS(SELECT 1 FROM master_barang WHERE id=$1)"
     err := r.db.QueryRow(query, id).Scan(&exists)
     return exists, err
 }
+
+// LockActive memakai FOR UPDATE, bukan FOR SHARE: CreateHistory mengunci baris yang sama dengan
+// FOR UPDATE, dan menaikkan kunci share di tx yang sama bisa deadlock dengan transaksi lain.
+// Arsip barang (UPDATE is_active) menunggu sampai tx pemegang kunci selesai.
+func (r *barangRepository) LockActive(tx *sql.Tx, id int) (bool, error) {
+	var active bool
+	err := tx.QueryRow("SELECT is_active FROM master_barang WHERE id = $1 FOR UPDATE", id).Scan(&active)
+	return active, err
+}
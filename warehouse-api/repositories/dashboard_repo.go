@@ -1,12 +1,16 @@
 package repositories
 
 import (
+    "context"
     "database/sql"
+    "fmt"
+    "math"
+    "sync"
     "warehouse-api/models"
 )
 
 type DashboardRepository interface {
-    GetStats() (*models.DashboardStats, error)
+    GetStats(ctx context.Context, filter models.DashboardFilter) (*models.DashboardStats, error)
 }
 
 type dashboardRepository struct {
@@ -17,55 +21,197 @@ func NewDashboardRepository(db *sql.DB) DashboardRepository {
     return &dashboardRepository{db}
 }
 
-func (r *dashboardRepository) GetStats() (*models.DashboardStats, error) {
-    stats := &models.DashboardStats{}
-
-    // 1. Total User
-    err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&stats.TotalUser)
-    if err != nil { return nil, err }
-
-    // 2. Total Barang
-    err = r.db.QueryRow("SELECT COUNT(*) FROM master_barang").Scan(&stats.TotalBarang)
-    if err != nil { return nil, err }
-
-    // 3. Total Stok
-    // Asumsi tabel mstok memiliki semua barang
-    err = r.db.QueryRow("SELECT COALESCE(SUM(stok_akhir), 0) FROM mstok").Scan(&stats.TotalStok)
-    if err != nil { return nil, err }
-
-    // 4. Total Nilai Aset (Harga Beli * Stok)
-    // Join master_barang dan mstok
-    queryAset := `
-        SELECT COALESCE(SUM(b.harga_beli * s.stok_akhir), 0)
-        FROM master_barang b
-        JOIN mstok s ON b.id = s.barang_id
-    `
-    err = r.db.QueryRow(queryAset).Scan(&stats.TotalNilaiAset)
-    if err != nil { return nil, err }
-
-    // 5. Top 5 Barang Terlaris (Berdasarkan table jual_detail)
-    queryTop := `
-        SELECT b.nama_barang, COALESCE(SUM(d.qty), 0) as total_terjual
-        FROM jual_detail d
-        JOIN master_barang b ON d.barang_id = b.id
-        GROUP BY b.id, b.nama_barang
-        ORDER BY total_terjual DESC
-        LIMIT 5
-    `
-    rows, err := r.db.Query(queryTop)
-    if err != nil {
-        // Log error di sini jika perlu, tapi kembalikan slice kosong agar dashboard tetap jalan
-        stats.TopSellingProducts = []models.TopProduct{}
-    } else {
+// dashboardRange adalah kondisi rentang tanggal untuk alias h ($1 = start, $2 = end, inklusif).
+// Parameter NULL berarti tidak dibatasi di sisi tersebut.
+const dashboardRange = `($1::date IS NULL OR h.created_at >= $1::date) AND ($2::date IS NULL OR h.created_at < $2::date + 1)`
+
+// GetStats menjalankan query yang saling independen secara paralel. Query berhenti begitu
+// ctx dibatalkan (mis. timeout) dan error pertama yang terjadi dikembalikan.
+// Angka penjualan/pembelian (pendapatan, HPP, faktur, pembelian, barang terlaris) hanya dari
+// transaksi yang terlihat oleh pengguna (VisibleTo); angka stok tetap untuk seluruh gudang.
+func (r *dashboardRepository) GetStats(ctx context.Context, filter models.DashboardFilter) (*models.DashboardStats, error) {
+    stats := &models.DashboardStats{
+        StartDate:          filter.StartDate,
+        EndDate:            filter.EndDate,
+        TopSellingProducts: []models.TopProduct{},
+        KategoriStats:      []models.KategoriStat{},
+    }
+    start, end := nullableDate(filter.StartDate), nullableDate(filter.EndDate)
+
+    // visible menambahkan batas visibilitas transaksi jenis (alias h); user ID menjadi argumen terakhir
+    userID, restricted := VisibleTo(ctx)
+    visible := func(jenis string, args []interface{}) (string, []interface{}) {
+        if !restricted {
+            return "", args
+        }
+        args = append(args, userID)
+        return " AND " + fmt.Sprintf(transaksiVisibleCond(jenis), len(args)), args
+    }
+
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    var (
+        wg       sync.WaitGroup
+        once     sync.Once
+        firstErr error
+    )
+    run := func(fn func() error) {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if err := fn(); err != nil {
+                once.Do(func() {
+                    firstErr = err
+                    cancel()
+                })
+            }
+        }()
+    }
+
+    // Total User (principal API key bukan pengguna)
+    run(func() error {
+        return r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE NOT is_service").Scan(&stats.TotalUser)
+    })
+
+    // Total Barang, Total Stok, Nilai Aset dan stok menipis (barang yang diarsipkan tidak dihitung)
+    run(func() error {
+        query := `
+            SELECT COUNT(*),
+                   COALESCE(SUM(s.stok_akhir), 0),
+                   COALESCE(SUM(b.harga_beli * s.stok_akhir), 0),
+                   COUNT(*) FILTER (WHERE COALESCE(s.stok_akhir, 0) <= $1)
+            FROM master_barang b
+            LEFT JOIN mstok s ON s.barang_id = b.id
+            WHERE b.is_active = TRUE
+        `
+        return r.db.QueryRowContext(ctx, query, filter.LowStockThreshold).
+            Scan(&stats.TotalBarang, &stats.TotalStok, &stats.TotalNilaiAset, &stats.LowStock)
+    })
+
+    // Pendapatan, jumlah faktur dan HPP (qty terjual x harga beli master barang)
+    run(func() error {
+        query := `
+            SELECT COALESCE(SUM(h.total), 0), COUNT(*),
+                   COALESCE(SUM((SELECT SUM(d.qty * b.harga_beli)
+                                 FROM jual_detail d
+                                 JOIN master_barang b ON b.id = d.barang_id
+                                 WHERE d.jual_header_id = h.id)), 0)
+            FROM jual_header h
+            WHERE ` + dashboardRange
+        cond, args := visible(models.TransaksiPenjualan, []interface{}{start, end})
+        return r.db.QueryRowContext(ctx, query+cond, args...).Scan(&stats.Pendapatan, &stats.JumlahFaktur, &stats.HPP)
+    })
+
+    // Pembelian
+    run(func() error {
+        query := `SELECT COALESCE(SUM(h.total), 0) FROM beli_header h WHERE ` + dashboardRange
+        cond, args := visible(models.TransaksiPembelian, []interface{}{start, end})
+        return r.db.QueryRowContext(ctx, query+cond, args...).Scan(&stats.Pembelian)
+    })
+
+    // Dead stock: barang aktif yang masih punya stok tapi tidak terjual dalam periode
+    run(func() error {
+        query := `
+            SELECT COUNT(*)
+            FROM master_barang b
+            JOIN mstok s ON s.barang_id = b.id
+            WHERE b.is_active = TRUE AND s.stok_akhir > 0
+              AND NOT EXISTS (
+                  SELECT 1 FROM jual_detail d
+                  JOIN jual_header h ON h.id = d.jual_header_id
+                  WHERE d.barang_id = b.id AND ` + dashboardRange + `
+              )
+        `
+        return r.db.QueryRowContext(ctx, query, start, end).Scan(&stats.DeadStock)
+    })
+
+    // Top N barang terlaris dalam periode
+    run(func() error {
+        cond, args := visible(models.TransaksiPenjualan, []interface{}{start, end, filter.TopN})
+        query := `
+            SELECT b.nama_barang, COALESCE(SUM(d.qty), 0) AS total_terjual, COALESCE(SUM(d.subtotal), 0)
+            FROM jual_detail d
+            JOIN jual_header h ON h.id = d.jual_header_id
+            JOIN master_barang b ON d.barang_id = b.id
+            WHERE ` + dashboardRange + cond + `
+            GROUP BY b.id, b.nama_barang
+            ORDER BY total_terjual DESC, b.nama_barang
+            LIMIT $3
+        `
+        rows, err := r.db.QueryContext(ctx, query, args...)
+        if err != nil {
+            return err
+        }
         defer rows.Close()
+        top := []models.TopProduct{}
         for rows.Next() {
             var p models.TopProduct
-            if err := rows.Scan(&p.NamaBarang, &p.TotalTerjual); err != nil {
-                continue
+            if err := rows.Scan(&p.NamaBarang, &p.TotalTerjual, &p.TotalNilai); err != nil {
+                return err
+            }
+            top = append(top, p)
+        }
+        stats.TopSellingProducts = top
+        return rows.Err()
+    })
+
+    // Roll-up stok dan nilai per kategori (termasuk sub-kategori)
+    run(func() error {
+        query := `
+            WITH RECURSIVE tree AS (
+                SELECT id AS root_id, id AS kategori_id FROM kategori
+                UNION
+                SELECT t.root_id, k.id FROM kategori k JOIN tree t ON k.parent_id = t.kategori_id
+            )
+            SELECT k.id, k.nama, k.parent_id,
+                   COUNT(b.id),
+                   COALESCE(SUM(s.stok_akhir), 0),
+                   COALESCE(SUM(b.harga_beli * s.stok_akhir), 0)
+            FROM kategori k
+            JOIN tree t ON t.root_id = k.id
+            LEFT JOIN master_barang b ON b.kategori_id = t.kategori_id AND b.is_active = TRUE
+            LEFT JOIN mstok s ON s.barang_id = b.id
+            GROUP BY k.id, k.nama, k.parent_id
+            ORDER BY k.nama
+        `
+        rows, err := r.db.QueryContext(ctx, query)
+        if err != nil {
+            return err
+        }
+        defer rows.Close()
+        kategori := []models.KategoriStat{}
+        for rows.Next() {
+            var k models.KategoriStat
+            if err := rows.Scan(&k.KategoriID, &k.NamaKategori, &k.ParentID, &k.TotalBarang, &k.TotalStok, &k.TotalNilai); err != nil {
+                return err
             }
-            stats.TopSellingProducts = append(stats.TopSellingProducts, p)
+            kategori = append(kategori, k)
         }
+        stats.KategoriStats = kategori
+        return rows.Err()
+    })
+
+    wg.Wait()
+    if firstErr != nil {
+        return nil, firstErr
+    }
+
+    stats.LabaKotor = stats.Pendapatan - stats.HPP
+    if stats.Pendapatan > 0 {
+        margin := math.Round(stats.LabaKotor/stats.Pendapatan*10000) / 100
+        stats.MarginKotor = &margin
+    }
+    if stats.JumlahFaktur > 0 {
+        stats.RataRataBelanja = math.Round(stats.Pendapatan/float64(stats.JumlahFaktur)*100) / 100
     }
-    
     return stats, nil
 }
+
+// nullableDate mengubah tanggal kosong menjadi NULL agar rentang tidak dibatasi
+func nullableDate(s string) interface{} {
+    if s == "" {
+        return nil
+    }
+    return s
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-api/models"
+)
+
+var (
+	// ErrHistoryBelumDiseal: riwayat lama barang belum masuk hash chain; jalankan cmd/history-chain -seal
+	ErrHistoryBelumDiseal = errors.New("riwayat stok barang belum di-seal, jalankan go run ./cmd/history-chain -seal")
+	// ErrHistoryChainRusak: rantai barang tidak cocok dengan anchor-nya, atau barang yang pernah
+	// di-seal ditandai belum di-seal lagi
+	ErrHistoryChainRusak = errors.New("hash chain riwayat stok rusak")
+)
+
+// historyHashTime memakai jam dinding created_at (kolom TIMESTAMP tanpa zona) sampai mikrodetik
+const historyHashTime = "2006-01-02 15:04:05.000000"
+
+// historyChainKey adalah kunci HMAC hash chain; tidak pernah disimpan di database
+var historyChainKey []byte
+
+// SetHistoryChainKey dipanggil sekali saat startup (server, seeder dan CLI) sebelum riwayat stok
+// ditulis atau diverifikasi; lihat config.HistoryChainKey
+func SetHistoryChainKey(key []byte) {
+	historyChainKey = key
+}
+
+// historyMAC menghitung HMAC-SHA256 payload dengan kunci hash chain dalam hex
+func historyMAC(payload interface{}) string {
+	b, _ := json.Marshal(payload)
+	mac := hmac.New(sha256.New, historyChainKey)
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HistoryHash menghitung hash satu baris history_stok: HMAC-SHA256 (kunci server) dari isi baris
+// beserta prevHash (kosong untuk baris pertama sebuah barang) dalam JSON dengan urutan field tetap.
+// Tanpa kunci, rantai yang barisnya diubah tidak bisa dihitung ulang dari isi database saja.
+func HistoryHash(h models.HistoryStok, prevHash string) string {
+	return historyMAC(struct {
+		ID             int    `json:"id"`
+		BarangID       int    `json:"barang_id"`
+		UserID         int    `json:"user_id"`
+		JenisTransaksi string `json:"jenis_transaksi"`
+		Jumlah         int    `json:"jumlah"`
+		StokSebelum    int    `json:"stok_sebelum"`
+		StokSesudah    int    `json:"stok_sesudah"`
+		Keterangan     string `json:"keterangan"`
+		CreatedAt      string `json:"created_at"`
+		PrevHash       string `json:"prev_hash"`
+	}{h.ID, h.BarangID, h.UserID, h.JenisTransaksi, h.Jumlah, h.StokSebelum, h.StokSesudah, h.Keterangan,
+		h.CreatedAt.Format(historyHashTime), prevHash})
+}
+
+// HistoryAnchorSignature menandatangani anchor rantai satu barang
+func HistoryAnchorSignature(a models.HistoryChainAnchor) string {
+	return historyMAC(struct {
+		BarangID int    `json:"barang_id"`
+		Baris    int    `json:"baris"`
+		LastID   int    `json:"last_id"`
+		LastHash string `json:"last_hash"`
+		SealedAt string `json:"sealed_at"`
+	}{a.BarangID, a.Baris, a.LastID, a.LastHash, a.SealedAt.Format(historyHashTime)})
+}
+
+// HistorySealSignature menandatangani penanda seal riwayat lama
+func HistorySealSignature(sealedAt time.Time) string {
+	return historyMAC(struct {
+		Seal     string `json:"seal"`
+		SealedAt string `json:"sealed_at"`
+	}{"history_stok", sealedAt.Format(historyHashTime)})
+}
+
+const historyChainSelect = `
+        SELECT id, barang_id, COALESCE(user_id, 0), jenis_transaksi, jumlah, stok_sebelum, stok_sesudah,
+               COALESCE(keterangan, ''), created_at, COALESCE(prev_hash, ''), COALESCE(hash, '')
+        FROM history_stok`
+
+func scanHistoryChain(rows *sql.Rows) (models.HistoryStok, error) {
+	var h models.HistoryStok
+	err := rows.Scan(&h.ID, &h.BarangID, &h.UserID, &h.JenisTransaksi, &h.Jumlah, &h.StokSebelum, &h.StokSesudah,
+		&h.Keterangan, &h.CreatedAt, &h.PrevHash, &h.Hash)
+	return h, err
+}
+
+const historyAnchorSelect = `SELECT barang_id, baris, last_id, last_hash, sealed_at, signature FROM history_chain_anchor`
+
+func scanHistoryAnchor(row interface{ Scan(...any) error }) (models.HistoryChainAnchor, error) {
+	var a models.HistoryChainAnchor
+	err := row.Scan(&a.BarangID, &a.Baris, &a.LastID, &a.LastHash, &a.SealedAt, &a.Signature)
+	return a, err
+}
+
+// lockHistoryChain mengunci barang lalu mengembalikan anchor rantainya (nil untuk barang yang belum
+// punya riwayat). Riwayat tidak pernah di-seal di sini: barang yang belum di-seal ditolak, dan rantai
+// yang tidak cocok dengan anchor-nya (anchor dipalsukan, baris terakhir dihapus atau hash-nya
+// dikosongkan) berarti data diubah di luar aplikasi sehingga rantai tidak disambung.
+func lockHistoryChain(tx *sql.Tx, barangID int) (*models.HistoryChainAnchor, error) {
+	var sealed bool
+	if err := tx.QueryRow("SELECT history_sealed FROM master_barang WHERE id = $1 FOR UPDATE", barangID).Scan(&sealed); err != nil {
+		return nil, err
+	}
+
+	anchor, err := scanHistoryAnchor(tx.QueryRow(historyAnchorSelect+" WHERE barang_id = $1", barangID))
+	hasAnchor := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if !sealed {
+		if hasAnchor {
+			return nil, fmt.Errorf("%w: barang ID %d sudah pernah di-seal", ErrHistoryChainRusak, barangID)
+		}
+		return nil, fmt.Errorf("%w (barang ID %d)", ErrHistoryBelumDiseal, barangID)
+	}
+
+	var lastID int
+	var last sql.NullString
+	err = tx.QueryRow("SELECT id, hash FROM history_stok WHERE barang_id = $1 ORDER BY id DESC LIMIT 1", barangID).Scan(&lastID, &last)
+	if errors.Is(err, sql.ErrNoRows) {
+		if hasAnchor {
+			return nil, fmt.Errorf("%w: riwayat barang ID %d dihapus", ErrHistoryChainRusak, barangID)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !hasAnchor {
+		return nil, fmt.Errorf("%w: riwayat barang ID %d tanpa anchor", ErrHistoryChainRusak, barangID)
+	}
+	if !hmac.Equal([]byte(HistoryAnchorSignature(anchor)), []byte(anchor.Signature)) {
+		return nil, fmt.Errorf("%w: anchor barang ID %d tidak valid", ErrHistoryChainRusak, barangID)
+	}
+	if lastID != anchor.LastID || last.String != anchor.LastHash {
+		return nil, fmt.Errorf("%w: history_stok id %d tidak cocok dengan anchor (barang ID %d)", ErrHistoryChainRusak, lastID, barangID)
+	}
+	return &anchor, nil
+}
+
+// saveHistoryAnchor menyimpan anchor baru rantai barang setelah baris terakhirnya ditulis
+func saveHistoryAnchor(tx *sql.Tx, a models.HistoryChainAnchor) error {
+	a.Signature = HistoryAnchorSignature(a)
+	_, err := tx.Exec(`
+        INSERT INTO history_chain_anchor (barang_id, baris, last_id, last_hash, sealed_at, signature)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (barang_id) DO UPDATE
+        SET baris = EXCLUDED.baris, last_id = EXCLUDED.last_id, last_hash = EXCLUDED.last_hash, signature = EXCLUDED.signature`,
+		a.BarangID, a.Baris, a.LastID, a.LastHash, a.SealedAt, a.Signature)
+	return err
+}
+
+// markHistorySealed menulis penanda seal bila belum ada dan tidak ada lagi riwayat lama yang menunggu
+// di-seal. Penanda tidak pernah diubah atau dihapus oleh aplikasi.
+func markHistorySealed(tx *sql.Tx, at time.Time) error {
+	_, err := tx.Exec(`
+        INSERT INTO history_chain_seal (id, sealed_at, signature)
+        SELECT 1, $1, $2
+        WHERE NOT EXISTS (SELECT 1 FROM master_barang WHERE NOT history_sealed)
+        ON CONFLICT (id) DO NOTHING`, at, HistorySealSignature(at))
+	return err
+}
+
+// sealHistory menghitung hash seluruh riwayat barang urut id, menyimpan anchor-nya lalu menandai
+// barang sudah di-seal. Hanya dipanggil SealHistory (cmd/history-chain -seal). Mengembalikan jumlah
+// baris yang di-seal.
+func sealHistory(tx *sql.Tx, barangID int) (int, error) {
+	rows, err := tx.Query(historyChainSelect+" WHERE barang_id = $1 ORDER BY id", barangID)
+	if err != nil {
+		return 0, err
+	}
+	var history []models.HistoryStok
+	for rows.Next() {
+		h, err := scanHistoryChain(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		history = append(history, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	prev := ""
+	for _, h := range history {
+		hash := HistoryHash(h, prev)
+		if _, err := tx.Exec("UPDATE history_stok SET prev_hash = NULLIF($1, ''), hash = $2 WHERE id = $3", prev, hash, h.ID); err != nil {
+			return 0, err
+		}
+		prev = hash
+	}
+	if len(history) > 0 {
+		var now time.Time
+		if err := tx.QueryRow("SELECT LOCALTIMESTAMP").Scan(&now); err != nil {
+			return 0, err
+		}
+		last := history[len(history)-1]
+		anchor := models.HistoryChainAnchor{BarangID: barangID, Baris: len(history), LastID: last.ID, LastHash: prev, SealedAt: now}
+		if err := saveHistoryAnchor(tx, anchor); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.Exec("UPDATE master_barang SET history_sealed = TRUE WHERE id = $1", barangID); err != nil {
+		return 0, err
+	}
+	return len(history), nil
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"warehouse-api/models"
+)
+
+var (
+	ErrKategoriInUse  = errors.New("kategori masih memiliki sub-kategori atau barang")
+	ErrKategoriSiklus = errors.New("kategori induk tidak boleh kategori itu sendiri atau turunannya")
+)
+
+type KategoriRepository interface {
+	Create(ctx context.Context, kategori *models.Kategori) error
+	Update(ctx context.Context, kategori *models.Kategori) error
+	Delete(ctx context.Context, id int) error
+	GetByID(id int) (*models.Kategori, error)
+	GetAll() ([]models.Kategori, error)
+}
+
+type kategoriRepository struct {
+	db *sql.DB
+}
+
+func NewKategoriRepository(db *sql.DB) KategoriRepository {
+	return &kategoriRepository{db}
+}
+
+func (r *kategoriRepository) Create(ctx context.Context, kategori *models.Kategori) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO kategori (nama, parent_id) VALUES ($1, $2) RETURNING id, created_at`
+	if err := tx.QueryRow(query, kategori.Nama, kategori.ParentID).Scan(&kategori.ID, &kategori.CreatedAt); err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditCreate, models.AuditKategori, kategori.ID, nil, kategori); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *kategoriRepository) Update(ctx context.Context, kategori *models.Kategori) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if kategori.ParentID != nil {
+		if err := checkKategoriParent(tx, kategori.ID, *kategori.ParentID); err != nil {
+			return err
+		}
+	}
+	before, err := kategoriForAudit(tx, kategori.ID)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE kategori SET nama=$1, parent_id=$2, updated_at=CURRENT_TIMESTAMP WHERE id=$3`
+	if _, err := tx.Exec(query, kategori.Nama, kategori.ParentID, kategori.ID); err != nil {
+		return err
+	}
+	after, err := kategoriForAudit(tx, kategori.ID)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditUpdate, models.AuditKategori, kategori.ID, before, after); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete hanya diizinkan jika kategori tidak punya sub-kategori dan tidak dipakai barang
+func (r *kategoriRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := kategoriForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+
+	var inUse bool
+	query := `SELECT EXISTS(SELECT 1 FROM kategori WHERE parent_id=$1)
+              OR EXISTS(SELECT 1 FROM master_barang WHERE kategori_id=$1)`
+	if err := tx.QueryRow(query, id).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return ErrKategoriInUse
+	}
+
+	if _, err := tx.Exec(`DELETE FROM kategori WHERE id=$1`, id); err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditDelete, models.AuditKategori, id, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// kategoriForAudit membaca kategori di dalam tx (dikunci); nil bila tidak ada
+func kategoriForAudit(tx *sql.Tx, id int) (*models.Kategori, error) {
+	var k models.Kategori
+	err := tx.QueryRow(`SELECT id, nama, parent_id, created_at FROM kategori WHERE id = $1 FOR UPDATE`, id).
+		Scan(&k.ID, &k.Nama, &k.ParentID, &k.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *kategoriRepository) GetByID(id int) (*models.Kategori, error) {
+	query := `SELECT id, nama, parent_id, created_at FROM kategori WHERE id = $1`
+	var k models.Kategori
+	err := r.db.QueryRow(query, id).Scan(&k.ID, &k.Nama, &k.ParentID, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *kategoriRepository) GetAll() ([]models.Kategori, error) {
+	query := `SELECT id, nama, parent_id, created_at FROM kategori ORDER BY nama ASC`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var kategori []models.Kategori
+	for rows.Next() {
+		var k models.Kategori
+		if err := rows.Scan(&k.ID, &k.Nama, &k.ParentID, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		kategori = append(kategori, k)
+	}
+	return kategori, nil
+}
+
+// checkKategoriParent mengunci kategori yang dipindah beserta seluruh leluhur induk barunya (urut id),
+// lalu memastikan kategori itu bukan induk barunya sendiri atau leluhurnya. Dua pemindahan yang
+// bisa membentuk siklus (A ke bawah B dan B ke bawah A) saling menunggu kunci, sehingga pemeriksaan
+// kedua melihat hasil yang pertama.
+func checkKategoriParent(tx *sql.Tx, id, parentID int) error {
+	const ancestors = `
+        WITH RECURSIVE up AS (
+            SELECT id, parent_id FROM kategori WHERE id = $1
+            UNION
+            SELECT k.id, k.parent_id FROM kategori k JOIN up ON k.id = up.parent_id
+        )`
+	rows, err := tx.Query(ancestors+`
+        SELECT id FROM kategori WHERE id = $2 OR id IN (SELECT id FROM up) ORDER BY id FOR UPDATE`, parentID, id)
+	if err != nil {
+		return err
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var cyclic bool
+	if err := tx.QueryRow(ancestors+` SELECT EXISTS(SELECT 1 FROM up WHERE id = $2)`, parentID, id).Scan(&cyclic); err != nil {
+		return err
+	}
+	if cyclic {
+		return ErrKategoriSiklus
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"warehouse-api/models"
+)
+
+var (
+	ErrKlasifikasiNotFound = errors.New("klasifikasi ABC/XYZ belum pernah dijalankan")
+	ErrOpnameKosong        = errors.New("tidak ada barang pada kelas yang dipilih")
+)
+
+type KlasifikasiRepository interface {
+	Demand(startDate, endDate, periode string) ([]models.BarangDemand, error)
+	SaveRun(run *models.KlasifikasiRun, items []models.BarangKlasifikasi) error
+	GetLatestRun() (*models.KlasifikasiRun, error)
+	GetItems(runID int) ([]models.BarangKlasifikasi, error)
+	CreateOpname(ctx context.Context, opname *models.StokOpname) error
+	GetOpnameByID(id int) (*models.StokOpname, error)
+	GetAllOpname(limit, offset int) ([]models.StokOpname, int, error)
+}
+
+type klasifikasiRepository struct {
+	db *sql.DB
+}
+
+func NewKlasifikasiRepository(db *sql.DB) KlasifikasiRepository {
+	return &klasifikasiRepository{db}
+}
+
+// Demand mengambil semua barang aktif beserta penjualannya dalam rentang (inklusif),
+// termasuk barang yang tidak terjual sama sekali. periode: "month" atau "week".
+func (r *klasifikasiRepository) Demand(startDate, endDate, periode string) ([]models.BarangDemand, error) {
+	if periode != "month" && periode != "week" {
+		return nil, fmt.Errorf("periode tidak valid: %s", periode)
+	}
+
+	rows, err := r.db.Query(`SELECT id, kode_barang, nama_barang FROM master_barang WHERE is_active = TRUE ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	var demand []models.BarangDemand
+	index := map[int]int{}
+	for rows.Next() {
+		d := models.BarangDemand{QtyPerPeriode: map[string]int{}}
+		if err := rows.Scan(&d.BarangID, &d.KodeBarang, &d.NamaBarang); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index[d.BarangID] = len(demand)
+		demand = append(demand, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+        SELECT d.barang_id, to_char(date_trunc('%s', h.created_at), 'YYYY-MM-DD'),
+               SUM(d.qty), SUM(d.subtotal), COUNT(DISTINCT h.id)
+        FROM jual_detail d
+        JOIN jual_header h ON h.id = d.jual_header_id
+        WHERE h.created_at >= $1::date AND h.created_at < $2::date + 1
+        GROUP BY 1, 2`, periode)
+	rows, err = r.db.Query(query, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			barangID, qty, frekuensi int
+			key                      string
+			nilai                    float64
+		)
+		if err := rows.Scan(&barangID, &key, &qty, &nilai, &frekuensi); err != nil {
+			return nil, err
+		}
+		i, ok := index[barangID]
+		if !ok {
+			continue // barang sudah diarsipkan
+		}
+		demand[i].QtyPerPeriode[key] += qty
+		demand[i].Qty += qty
+		demand[i].Nilai += nilai
+		demand[i].Frekuensi += frekuensi
+	}
+	return demand, rows.Err()
+}
+
+// SaveRun menyimpan run beserta seluruh hasilnya dalam satu transaksi dan mengisi run.ID
+func (r *klasifikasiRepository) SaveRun(run *models.KlasifikasiRun, items []models.BarangKlasifikasi) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+        INSERT INTO klasifikasi_run (start_date, end_date, periode, batas_a, batas_b, batas_x, batas_y, jumlah_barang, user_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at`,
+		run.StartDate, run.EndDate, run.Periode, run.BatasA, run.BatasB, run.BatasX, run.BatasY, len(items), run.UserID,
+	).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		return err
+	}
+	run.JumlahBarang = len(items)
+
+	stmt, err := tx.Prepare(`
+        INSERT INTO barang_klasifikasi (run_id, barang_id, kelas_abc, kelas_xyz, nilai, kontribusi, kumulatif, qty, frekuensi, cv)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, it := range items {
+		if _, err := stmt.Exec(run.ID, it.BarangID, it.KelasABC, it.KelasXYZ, it.Nilai, it.Kontribusi, it.Kumulatif, it.Qty, it.Frekuensi, it.CV); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *klasifikasiRepository) GetLatestRun() (*models.KlasifikasiRun, error) {
+	var run models.KlasifikasiRun
+	err := r.db.QueryRow(`
+        SELECT id, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), periode,
+               batas_a, batas_b, batas_x, batas_y, jumlah_barang, user_id, created_at
+        FROM klasifikasi_run ORDER BY id DESC LIMIT 1`,
+	).Scan(&run.ID, &run.StartDate, &run.EndDate, &run.Periode, &run.BatasA, &run.BatasB, &run.BatasX, &run.BatasY,
+		&run.JumlahBarang, &run.UserID, &run.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrKlasifikasiNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// klasifikasiWhere menyusun filter kelas (alias k) mulai dari parameter ke-n
+func klasifikasiWhere(filter models.KlasifikasiFilter, n int) (string, []interface{}) {
+	where := ""
+	var args []interface{}
+	if filter.KelasABC != "" {
+		args = append(args, filter.KelasABC)
+		where += fmt.Sprintf(" AND strpos($%d, k.kelas_abc) > 0", n+len(args)-1)
+	}
+	if filter.KelasXYZ != "" {
+		args = append(args, filter.KelasXYZ)
+		where += fmt.Sprintf(" AND strpos($%d, k.kelas_xyz) > 0", n+len(args)-1)
+	}
+	return where, args
+}
+
+// GetItems mengambil hasil run, diurutkan dari kontribusi nilai terbesar
+func (r *klasifikasiRepository) GetItems(runID int) ([]models.BarangKlasifikasi, error) {
+	query := `
+        SELECT k.barang_id, b.kode_barang, b.nama_barang, k.kelas_abc, k.kelas_xyz,
+               k.nilai, k.kontribusi, k.kumulatif, k.qty, k.frekuensi, k.cv
+        FROM barang_klasifikasi k
+        JOIN master_barang b ON b.id = k.barang_id
+        WHERE k.run_id = $1
+        ORDER BY k.kumulatif, b.kode_barang`
+	rows, err := r.db.Query(query, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.BarangKlasifikasi{}
+	for rows.Next() {
+		var it models.BarangKlasifikasi
+		if err := rows.Scan(&it.BarangID, &it.KodeBarang, &it.NamaBarang, &it.KelasABC, &it.KelasXYZ,
+			&it.Nilai, &it.Kontribusi, &it.Kumulatif, &it.Qty, &it.Frekuensi, &it.CV); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// CreateOpname membuat sesi opname untuk barang aktif pada run klasifikasi opname.KlasifikasiRunID
+// yang masuk kelas terpilih. Stok sistem dibekukan dari mstok saat itu juga.
+func (r *klasifikasiRepository) CreateOpname(ctx context.Context, opname *models.StokOpname) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+        INSERT INTO stok_opname (no_opname, klasifikasi_run_id, kelas_abc, kelas_xyz, keterangan, status, user_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		opname.NoOpname, opname.KlasifikasiRunID, opname.KelasABC, opname.KelasXYZ, opname.Keterangan, opname.Status, opname.UserID,
+	).Scan(&opname.ID, &opname.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	where, args := klasifikasiWhere(models.KlasifikasiFilter{KelasABC: opname.KelasABC, KelasXYZ: opname.KelasXYZ}, 3)
+	query := `
+        INSERT INTO stok_opname_detail (stok_opname_id, barang_id, kelas_abc, kelas_xyz, stok_sistem)
+        SELECT $1, k.barang_id, k.kelas_abc, k.kelas_xyz, COALESCE(s.stok_akhir, 0)
+        FROM barang_klasifikasi k
+        JOIN master_barang b ON b.id = k.barang_id AND b.is_active = TRUE
+        LEFT JOIN mstok s ON s.barang_id = k.barang_id
+        WHERE k.run_id = $2` + where
+	res, err := tx.Exec(query, append([]interface{}{opname.ID, opname.KlasifikasiRunID}, args...)...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrOpnameKosong
+	}
+	opname.JumlahBarang = int(n)
+	if err := WriteAudit(ctx, tx, models.AuditCreate, models.AuditStokOpname, opname.ID, nil, opname); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const opnameColumns = `o.id, o.no_opname, o.klasifikasi_run_id, o.kelas_abc, o.kelas_xyz, COALESCE(o.keterangan, ''),
+        o.status, o.user_id, o.created_at,
+        (SELECT COUNT(*) FROM stok_opname_detail d WHERE d.stok_opname_id = o.id)`
+
+func opnameScanDest(o *models.StokOpname) []interface{} {
+	return []interface{}{
+		&o.ID, &o.NoOpname, &o.KlasifikasiRunID, &o.KelasABC, &o.KelasXYZ, &o.Keterangan,
+		&o.Status, &o.UserID, &o.CreatedAt, &o.JumlahBarang,
+	}
+}
+
+func (r *klasifikasiRepository) GetOpnameByID(id int) (*models.StokOpname, error) {
+	var o models.StokOpname
+	err := r.db.QueryRow(`SELECT `+opnameColumns+` FROM stok_opname o WHERE o.id = $1`, id).Scan(opnameScanDest(&o)...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+        SELECT d.barang_id, b.kode_barang, b.nama_barang, b.satuan, d.kelas_abc, d.kelas_xyz, d.stok_sistem, d.stok_fisik
+        FROM stok_opname_detail d
+        JOIN master_barang b ON b.id = d.barang_id
+        WHERE d.stok_opname_id = $1
+        ORDER BY d.kelas_abc, d.kelas_xyz, b.kode_barang`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	o.Items = []models.StokOpnameDetail{}
+	for rows.Next() {
+		var d models.StokOpnameDetail
+		if err := rows.Scan(&d.BarangID, &d.KodeBarang, &d.NamaBarang, &d.Satuan, &d.KelasABC, &d.KelasXYZ, &d.StokSistem, &d.StokFisik); err != nil {
+			return nil, err
+		}
+		o.Items = append(o.Items, d)
+	}
+	return &o, rows.Err()
+}
+
+func (r *klasifikasiRepository) GetAllOpname(limit, offset int) ([]models.StokOpname, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM stok_opname`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(`SELECT `+opnameColumns+` FROM stok_opname o ORDER BY o.id DESC LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	list := []models.StokOpname{}
+	for rows.Next() {
+		var o models.StokOpname
+		if err := rows.Scan(opnameScanDest(&o)...); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, o)
+	}
+	return list, total, rows.Err()
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"warehouse-api/models"
+)
+
+// LoginAttemptRepository menyimpan hitungan login gagal per username dan jejak audit-nya
+type LoginAttemptRepository interface {
+	// LockedFor mengembalikan sisa waktu kunci username, 0 bila tidak terkunci
+	LockedFor(username string) (time.Duration, error)
+	// RecordFailure mencatat login gagal dan mengembalikan jumlah gagal berturut-turut dalam window
+	RecordFailure(attempt *models.LoginAttempt, window time.Duration) (int, error)
+	Lock(username string, d time.Duration) error
+	Reset(username string) error
+	// Unlock menghapus kunci username atas perintah admin dan mencatatnya di audit log user
+	Unlock(ctx context.Context, userID int, username string) error
+}
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (r *loginAttemptRepository) LockedFor(username string) (time.Duration, error) {
+	var seconds float64
+	err := r.db.QueryRow(`
+        SELECT COALESCE(EXTRACT(EPOCH FROM terkunci_sampai - NOW()), 0)
+        FROM login_lockout WHERE username = $1`, username).Scan(&seconds)
+	if errors.Is(err, sql.ErrNoRows) || seconds <= 0 {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (r *loginAttemptRepository) RecordFailure(attempt *models.LoginAttempt, window time.Duration) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+        INSERT INTO login_gagal (username, ip, user_agent, alasan) VALUES ($1, $2, $3, $4)`,
+		attempt.Username, attempt.IP, attempt.UserAgent, attempt.Alasan); err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = tx.QueryRow(`
+        INSERT INTO login_lockout (username, gagal, terakhir_gagal) VALUES ($1, 1, NOW())
+        ON CONFLICT (username) DO UPDATE SET
+            gagal = CASE WHEN login_lockout.terakhir_gagal < NOW() - make_interval(secs => $2)
+                         THEN 1 ELSE login_lockout.gagal + 1 END,
+            terakhir_gagal = NOW()
+        RETURNING gagal`, attempt.Username, window.Seconds()).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+func (r *loginAttemptRepository) Lock(username string, d time.Duration) error {
+	_, err := r.db.Exec(`
+        UPDATE login_lockout SET terkunci_sampai = NOW() + make_interval(secs => $2)
+        WHERE username = $1`, username, d.Seconds())
+	return err
+}
+
+func (r *loginAttemptRepository) Reset(username string) error {
+	_, err := r.db.Exec(`DELETE FROM login_lockout WHERE username = $1`, username)
+	return err
+}
+
+func (r *loginAttemptRepository) Unlock(ctx context.Context, userID int, username string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var gagal int
+	var sampai sql.NullTime
+	err = tx.QueryRow(`
+        DELETE FROM login_lockout WHERE username = $1
+        RETURNING gagal, terkunci_sampai`, username).Scan(&gagal, &sampai)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	before := map[string]interface{}{"login_gagal": gagal, "terkunci_sampai": nil}
+	if sampai.Valid {
+		before["terkunci_sampai"] = sampai.Time
+	}
+	err = WriteAudit(ctx, tx, models.AuditUpdate, models.AuditUser, userID,
+		before, map[string]interface{}{"login_gagal": 0, "terkunci_sampai": nil})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
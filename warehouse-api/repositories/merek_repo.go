@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"warehouse-api/models"
+)
+
+var ErrMerekInUse = errors.New("merek masih dipakai oleh barang")
+
+type MerekRepository interface {
+	Create(ctx context.Context, merek *models.Merek) error
+	Update(ctx context.Context, merek *models.Merek) error
+	Delete(ctx context.Context, id int) error
+	GetByID(id int) (*models.Merek, error)
+	GetAll() ([]models.Merek, error)
+}
+
+type merekRepository struct {
+	db *sql.DB
+}
+
+func NewMerekRepository(db *sql.DB) MerekRepository {
+	return &merekRepository{db}
+}
+
+func (r *merekRepository) Create(ctx context.Context, merek *models.Merek) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO merek (nama) VALUES ($1) RETURNING id, created_at`
+	if err := tx.QueryRow(query, merek.Nama).Scan(&merek.ID, &merek.CreatedAt); err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditCreate, models.AuditMerek, merek.ID, nil, merek); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *merekRepository) Update(ctx context.Context, merek *models.Merek) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := merekForAudit(tx, merek.ID)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE merek SET nama=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2`
+	if _, err := tx.Exec(query, merek.Nama, merek.ID); err != nil {
+		return err
+	}
+	after, err := merekForAudit(tx, merek.ID)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditUpdate, models.AuditMerek, merek.ID, before, after); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *merekRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := merekForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+
+	var inUse bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM master_barang WHERE merek_id=$1)`, id).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return ErrMerekInUse
+	}
+
+	if _, err := tx.Exec(`DELETE FROM merek WHERE id=$1`, id); err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditDelete, models.AuditMerek, id, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// merekForAudit membaca merek di dalam tx (dikunci); nil bila tidak ada
+func merekForAudit(tx *sql.Tx, id int) (*models.Merek, error) {
+	var m models.Merek
+	err := tx.QueryRow(`SELECT id, nama, created_at FROM merek WHERE id = $1 FOR UPDATE`, id).Scan(&m.ID, &m.Nama, &m.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *merekRepository) GetByID(id int) (*models.Merek, error) {
+	query := `SELECT id, nama, created_at FROM merek WHERE id = $1`
+	var m models.Merek
+	err := r.db.QueryRow(query, id).Scan(&m.ID, &m.Nama, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *merekRepository) GetAll() ([]models.Merek, error) {
+	query := `SELECT id, nama, created_at FROM merek ORDER BY nama ASC`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merek []models.Merek
+	for rows.Next() {
+		var m models.Merek
+		if err := rows.Scan(&m.ID, &m.Nama, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		merek = append(merek, m)
+	}
+	return merek, nil
+}
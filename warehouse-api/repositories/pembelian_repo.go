@@ -1,14 +1,17 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"warehouse-api/models"
 )
 
 type PembelianRepository interface {
 	Create(tx *sql.Tx, header *models.BeliHeader, details []models.BeliDetail) error
-	GetAll(startDate, endDate string) ([]models.BeliHeader, error)
-	GetByID(id int) (*models.BeliHeader, error)
+	GetAll(ctx context.Context, filter models.TransaksiFilter, limit, offset int, sortBy, order string) ([]models.BeliHeader, int, error) // Returns data, total count, error
+	Stream(ctx context.Context, filter models.TransaksiFilter, sortBy, order string, fn func(models.BeliHeader) error) error
+	GetByID(ctx context.Context, id int) (*models.BeliHeader, error)
 }
 
 type pembelianRepository struct {
@@ -41,44 +44,70 @@ func (r *pembelianRepository) Create(tx *sql.Tx, header *models.BeliHeader, deta
 	return nil
 }
 
-func (r *pembelianRepository) GetAll(startDate, endDate string) ([]models.BeliHeader, error) {
-	query := `SELECT h.id, h.no_faktur, h.supplier, h.total, h.user_id, h.status, h.created_at, u.username 
+func (r *pembelianRepository) GetAll(ctx context.Context, filter models.TransaksiFilter, limit, offset int, sortBy, order string) ([]models.BeliHeader, int, error) {
+	whereClause, args := buildTransaksiWhere(ctx, filter, models.TransaksiPembelian, "supplier")
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM beli_header h %s", whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	idx := len(args) + 1
+	query := fmt.Sprintf("%s %s %s LIMIT $%d OFFSET $%d", pembelianListSelect, whereClause, transaksiOrderBy(sortBy, order, "supplier"), idx, idx+1)
+	args = append(args, limit, offset)
+
+	var headers []models.BeliHeader
+	err := r.eachHeader(ctx, query, args, func(h models.BeliHeader) error {
+		headers = append(headers, h)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return headers, total, nil
+}
+
+// Stream memanggil fn untuk setiap header transaksi yang cocok dengan filter langsung dari cursor database
+func (r *pembelianRepository) Stream(ctx context.Context, filter models.TransaksiFilter, sortBy, order string, fn func(models.BeliHeader) error) error {
+	whereClause, args := buildTransaksiWhere(ctx, filter, models.TransaksiPembelian, "supplier")
+	query := fmt.Sprintf("%s %s %s", pembelianListSelect, whereClause, transaksiOrderBy(sortBy, order, "supplier"))
+	return r.eachHeader(ctx, query, args, fn)
+}
+
+const pembelianListSelect = `SELECT h.id, h.no_faktur, h.supplier, h.total, h.user_id, h.status, h.created_at, u.username
               FROM beli_header h
               JOIN users u ON h.user_id = u.id`
-	
-	var args []interface{}
-	if startDate != "" && endDate != "" {
-		query += " WHERE h.created_at BETWEEN $1 AND $2"
-		args = append(args, startDate, endDate)
-	}
-	query += " ORDER BY h.created_at DESC"
 
-	rows, err := r.db.Query(query, args...)
+func (r *pembelianRepository) eachHeader(ctx context.Context, query string, args []interface{}, fn func(models.BeliHeader) error) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var headers []models.BeliHeader
 	for rows.Next() {
 		var h models.BeliHeader
 		h.User = &models.User{}
 		if err := rows.Scan(&h.ID, &h.NoFaktur, &h.Supplier, &h.Total, &h.UserID, &h.Status, &h.CreatedAt, &h.User.Username); err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(h); err != nil {
+			return err
 		}
-		headers = append(headers, h)
 	}
-	return headers, nil
+	return rows.Err()
 }
 
-func (r *pembelianRepository) GetByID(id int) (*models.BeliHeader, error) {
+// GetByID mengembalikan sql.ErrNoRows juga untuk transaksi di luar visibilitas request
+func (r *pembelianRepository) GetByID(ctx context.Context, id int) (*models.BeliHeader, error) {
 	queryHeader := `SELECT h.id, h.no_faktur, h.supplier, h.total, h.user_id, h.status, h.created_at, u.username 
                     FROM beli_header h
-                    JOIN users u ON h.user_id = u.id
-                    WHERE h.id = $1`
+                    JOIN users u ON h.user_id = u.id `
 	var h models.BeliHeader
 	h.User = &models.User{}
-	err := r.db.QueryRow(queryHeader, id).Scan(&h.ID, &h.NoFaktur, &h.Supplier, &h.Total, &h.UserID, &h.Status, &h.CreatedAt, &h.User.Username)
+	where, args := transaksiByIDWhere(ctx, models.TransaksiPembelian, id)
+	err := r.db.QueryRow(queryHeader+where, args...).Scan(&h.ID, &h.NoFaktur, &h.Supplier, &h.Total, &h.UserID, &h.Status, &h.CreatedAt, &h.User.Username)
 	if err != nil {
 		return nil, err
 	}
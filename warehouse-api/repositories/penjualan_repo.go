@@ -1,14 +1,17 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"warehouse-api/models"
 )
 
 type PenjualanRepository interface {
 	Create(tx *sql.Tx, header *models.JualHeader, details []models.JualDetail) error
-	GetAll(startDate, endDate string) ([]models.JualHeader, error)
-	GetByID(id int) (*models.JualHeader, error)
+	GetAll(ctx context.Context, filter models.TransaksiFilter, limit, offset int, sortBy, order string) ([]models.JualHeader, int, error) // Returns data, total count, error
+	Stream(ctx context.Context, filter models.TransaksiFilter, sortBy, order string, fn func(models.JualHeader) error) error
+	GetByID(ctx context.Context, id int) (*models.JualHeader, error)
 }
 
 type penjualanRepository struct {
@@ -41,44 +44,70 @@ func (r *penjualanRepository) Create(tx *sql.Tx, header *models.JualHeader, deta
     return nil
 }
 
-func (r *penjualanRepository) GetAll(startDate, endDate string) ([]models.JualHeader, error) {
-    query := `SELECT h.id, h.no_faktur, h.customer, h.total, h.user_id, h.status, h.created_at, u.username 
+func (r *penjualanRepository) GetAll(ctx context.Context, filter models.TransaksiFilter, limit, offset int, sortBy, order string) ([]models.JualHeader, int, error) {
+	whereClause, args := buildTransaksiWhere(ctx, filter, models.TransaksiPenjualan, "customer")
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM jual_header h %s", whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	idx := len(args) + 1
+	query := fmt.Sprintf("%s %s %s LIMIT $%d OFFSET $%d", penjualanListSelect, whereClause, transaksiOrderBy(sortBy, order, "customer"), idx, idx+1)
+	args = append(args, limit, offset)
+
+	var headers []models.JualHeader
+	err := r.eachHeader(ctx, query, args, func(h models.JualHeader) error {
+		headers = append(headers, h)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return headers, total, nil
+}
+
+// Stream memanggil fn untuk setiap header transaksi yang cocok dengan filter langsung dari cursor database
+func (r *penjualanRepository) Stream(ctx context.Context, filter models.TransaksiFilter, sortBy, order string, fn func(models.JualHeader) error) error {
+	whereClause, args := buildTransaksiWhere(ctx, filter, models.TransaksiPenjualan, "customer")
+	query := fmt.Sprintf("%s %s %s", penjualanListSelect, whereClause, transaksiOrderBy(sortBy, order, "customer"))
+	return r.eachHeader(ctx, query, args, fn)
+}
+
+const penjualanListSelect = `SELECT h.id, h.no_faktur, h.customer, h.total, h.user_id, h.status, h.created_at, u.username
               FROM jual_header h
               JOIN users u ON h.user_id = u.id`
-    
-    var args []interface{}
-    if startDate != "" && endDate != "" {
-        query += " WHERE h.created_at BETWEEN $1 AND $2"
-        args = append(args, startDate, endDate)
-    }
-    query += " ORDER BY h.created_at DESC"
 
-    rows, err := r.db.Query(query, args...)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
+func (r *penjualanRepository) eachHeader(ctx context.Context, query string, args []interface{}, fn func(models.JualHeader) error) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
-    var headers []models.JualHeader
-    for rows.Next() {
-        var h models.JualHeader
-        h.User = &models.User{}
-        if err := rows.Scan(&h.ID, &h.NoFaktur, &h.Customer, &h.Total, &h.UserID, &h.Status, &h.CreatedAt, &h.User.Username); err != nil {
-            return nil, err
-        }
-        headers = append(headers, h)
-    }
-    return headers, nil
+	for rows.Next() {
+		var h models.JualHeader
+		h.User = &models.User{}
+		if err := rows.Scan(&h.ID, &h.NoFaktur, &h.Customer, &h.Total, &h.UserID, &h.Status, &h.CreatedAt, &h.User.Username); err != nil {
+			return err
+		}
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
-func (r *penjualanRepository) GetByID(id int) (*models.JualHeader, error) {
+// GetByID mengembalikan sql.ErrNoRows juga untuk transaksi di luar visibilitas request
+func (r *penjualanRepository) GetByID(ctx context.Context, id int) (*models.JualHeader, error) {
     queryHeader := `SELECT h.id, h.no_faktur, h.customer, h.total, h.user_id, h.status, h.created_at, u.username 
                     FROM jual_header h
-                    JOIN users u ON h.user_id = u.id
-                    WHERE h.id = $1`
+                    JOIN users u ON h.user_id = u.id `
     var h models.JualHeader
     h.User = &models.User{}
-    err := r.db.QueryRow(queryHeader, id).Scan(&h.ID, &h.NoFaktur, &h.Customer, &h.Total, &h.UserID, &h.Status, &h.CreatedAt, &h.User.Username)
+    where, args := transaksiByIDWhere(ctx, models.TransaksiPenjualan, id)
+    err := r.db.QueryRow(queryHeader+where, args...).Scan(&h.ID, &h.NoFaktur, &h.Customer, &h.Total, &h.UserID, &h.Status, &h.CreatedAt, &h.User.Username)
     if err != nil {
         return nil, err
     }
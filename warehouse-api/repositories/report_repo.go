@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"warehouse-api/models"
+)
+
+var ErrInvalidReport = errors.New("jenis laporan atau group_by tidak valid")
+
+type ReportRepository interface {
+	Aggregate(ctx context.Context, q models.ReportQuery) ([]models.ReportPoint, error)
+	Summary(ctx context.Context, jenis, startDate, endDate string) (models.ReportValues, error)
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db}
+}
+
+// reportSource adalah tabel header/detail untuk tiap jenis laporan
+type reportSource struct {
+	header, detail, foreignKey, party string
+}
+
+var reportSources = map[string]reportSource{
+	"penjualan": {"jual_header", "jual_detail", "jual_header_id", "customer"},
+	"pembelian": {"beli_header", "beli_detail", "beli_header_id", "supplier"},
+}
+
+// reportGroup adalah ekspresi key/label untuk tiap group_by (alias h = header, d = detail)
+type reportGroup struct {
+	key, label, join string
+	timeSeries       bool
+}
+
+func reportGroupFor(src reportSource, groupBy string) (reportGroup, bool) {
+	switch groupBy {
+	case "day", "week", "month":
+		trunc := fmt.Sprintf("date_trunc('%s', h.created_at)", groupBy)
+		label := "to_char(" + trunc + ", 'YYYY-MM-DD')"
+		if groupBy == "month" {
+			label = "to_char(" + trunc + ", 'YYYY-MM')"
+		}
+		return reportGroup{key: "to_char(" + trunc + ", 'YYYY-MM-DD')", label: label, timeSeries: true}, true
+	case "barang":
+		return reportGroup{key: "b.id::text", label: "b.kode_barang || ' - ' || b.nama_barang", join: "JOIN master_barang b ON b.id = d.barang_id"}, true
+	case "user":
+		return reportGroup{key: "COALESCE(h.user_id::text, '')", label: "COALESCE(u.username, '-')", join: "LEFT JOIN users u ON u.id = h.user_id"}, true
+	case src.party:
+		return reportGroup{key: "h." + src.party, label: "h." + src.party}, true
+	}
+	return reportGroup{}, false
+}
+
+// reportVisibleWhere menambahkan batas visibilitas (lihat VisibleTo) ke kondisi tanggal laporan
+func reportVisibleWhere(ctx context.Context, jenis string, args []interface{}) (string, []interface{}) {
+	where := "h.created_at >= $1::date AND h.created_at < $2::date + 1"
+	if userID, restricted := VisibleTo(ctx); restricted {
+		args = append(args, userID)
+		where += " AND " + fmt.Sprintf(transaksiVisibleCond(jenis), len(args))
+	}
+	return where, args
+}
+
+// Aggregate menghitung total, qty dan jumlah transaksi per kelompok dalam rentang tanggal (inklusif).
+// Seri waktu diurutkan kronologis; pengelompokan lain diurutkan dari total terbesar dan dibatasi q.Limit.
+// Hanya transaksi yang terlihat oleh pengguna (VisibleTo) yang dihitung.
+func (r *reportRepository) Aggregate(ctx context.Context, q models.ReportQuery) ([]models.ReportPoint, error) {
+	src, ok := reportSources[q.Jenis]
+	if !ok {
+		return nil, ErrInvalidReport
+	}
+	group, ok := reportGroupFor(src, q.GroupBy)
+	if !ok {
+		return nil, ErrInvalidReport
+	}
+
+	where, args := reportVisibleWhere(ctx, q.Jenis, []interface{}{q.StartDate, q.EndDate})
+	query := fmt.Sprintf(`
+        SELECT %s, %s, COALESCE(SUM(d.subtotal), 0), COALESCE(SUM(d.qty), 0), COUNT(DISTINCT h.id)
+        FROM %s h
+        JOIN %s d ON d.%s = h.id
+        %s
+        WHERE %s
+        GROUP BY 1, 2`, group.key, group.label, src.header, src.detail, src.foreignKey, group.join, where)
+	if group.timeSeries {
+		query += " ORDER BY 1"
+	} else {
+		query += " ORDER BY 3 DESC, 2"
+		if q.Limit > 0 {
+			args = append(args, q.Limit)
+			query += fmt.Sprintf(" LIMIT $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.ReportPoint{}
+	for rows.Next() {
+		var p models.ReportPoint
+		if err := rows.Scan(&p.Key, &p.Label, &p.Total, &p.Qty, &p.JumlahTransaksi); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// Summary menghitung total seluruh transaksi yang terlihat dalam rentang tanggal (tidak terpengaruh limit)
+func (r *reportRepository) Summary(ctx context.Context, jenis, startDate, endDate string) (models.ReportValues, error) {
+	var v models.ReportValues
+	src, ok := reportSources[jenis]
+	if !ok {
+		return v, ErrInvalidReport
+	}
+
+	where, args := reportVisibleWhere(ctx, jenis, []interface{}{startDate, endDate})
+	query := fmt.Sprintf(`
+        SELECT COALESCE(SUM(d.subtotal), 0), COALESCE(SUM(d.qty), 0), COUNT(DISTINCT h.id)
+        FROM %s h
+        JOIN %s d ON d.%s = h.id
+        WHERE %s`, src.header, src.detail, src.foreignKey, where)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&v.Total, &v.Qty, &v.JumlahTransaksi)
+	return v, err
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"warehouse-api/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role tidak ditemukan")
+	ErrRoleExists         = errors.New("role sudah ada")
+	ErrRoleInUse          = errors.New("role masih dipakai pengguna")
+	ErrRoleSystem         = errors.New("role sistem tidak bisa dihapus")
+	ErrPermissionNotFound = errors.New("permission tidak dikenal")
+)
+
+type RoleRepository interface {
+	GetAll() ([]models.Role, error)
+	GetPermissions() ([]models.Permission, error)
+	Exists(nama string) (bool, error)
+	Create(ctx context.Context, role *models.Role) error
+	SetPermissions(ctx context.Context, nama string, permissions []string) error
+	Delete(ctx context.Context, nama string) error
+	PermissionMap() (map[string][]string, error)
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) GetAll() ([]models.Role, error) {
+	query := `
+        SELECT r.nama, COALESCE(r.deskripsi, ''), r.is_system,
+               (SELECT COUNT(*) FROM users u WHERE u.role = r.nama),
+               COALESCE((SELECT array_agg(p.kode ORDER BY p.kode)
+                         FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id
+                         WHERE rp.role_id = r.id), '{}')
+        FROM roles r
+        ORDER BY r.is_system DESC, r.nama`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []models.Role{}
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.Nama, &role.Deskripsi, &role.IsSystem, &role.JumlahUser, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *roleRepository) GetPermissions() ([]models.Permission, error) {
+	rows, err := r.db.Query(`SELECT kode, COALESCE(deskripsi, '') FROM permissions ORDER BY kode`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []models.Permission{}
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.Kode, &p.Deskripsi); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+	return permissions, rows.Err()
+}
+
+func (r *roleRepository) Exists(nama string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM roles WHERE nama = $1)`, nama).Scan(&exists)
+	return exists, err
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var roleID int
+	err = tx.QueryRow(`INSERT INTO roles (nama, deskripsi) VALUES ($1, $2) ON CONFLICT (nama) DO NOTHING RETURNING id`,
+		role.Nama, role.Deskripsi).Scan(&roleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRoleExists
+	}
+	if err != nil {
+		return err
+	}
+	if err := replaceRolePermissions(tx, roleID, role.Permissions); err != nil {
+		return err
+	}
+	after, err := roleForAudit(tx, role.Nama)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditCreate, models.AuditRole, role.Nama, nil, after); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetPermissions mengganti seluruh permission role
+func (r *roleRepository) SetPermissions(ctx context.Context, nama string, permissions []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := roleForAudit(tx, nama)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ErrRoleNotFound
+	}
+	var roleID int
+	if err := tx.QueryRow(`SELECT id FROM roles WHERE nama = $1`, nama).Scan(&roleID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return err
+	}
+	if err := replaceRolePermissions(tx, roleID, permissions); err != nil {
+		return err
+	}
+	after, err := roleForAudit(tx, nama)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditUpdate, models.AuditRole, nama, before, after); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceRolePermissions menambahkan permission ke role; kode yang tidak dikenal membatalkan transaksi
+func replaceRolePermissions(tx *sql.Tx, roleID int, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	res, err := tx.Exec(`
+        INSERT INTO role_permissions (role_id, permission_id)
+        SELECT $1, id FROM permissions WHERE kode = ANY($2)
+        ON CONFLICT DO NOTHING`, roleID, pq.Array(permissions))
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if int(n) != len(permissions) {
+		return ErrPermissionNotFound
+	}
+	return nil
+}
+
+func (r *roleRepository) Delete(ctx context.Context, nama string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := roleForAudit(tx, nama)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ErrRoleNotFound
+	}
+	if before.IsSystem {
+		return ErrRoleSystem
+	}
+	var inUse bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE role = $1)`, nama).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return ErrRoleInUse
+	}
+	if _, err := tx.Exec(`DELETE FROM roles WHERE nama = $1`, nama); err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditDelete, models.AuditRole, nama, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// roleForAudit mengunci lalu membaca role beserta permission-nya di dalam tx; nil bila tidak ada
+func roleForAudit(tx *sql.Tx, nama string) (*models.Role, error) {
+	var roleID int
+	err := tx.QueryRow(`SELECT id FROM roles WHERE nama = $1 FOR UPDATE`, nama).Scan(&roleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.Role{Nama: nama}
+	err = tx.QueryRow(`
+        SELECT COALESCE(deskripsi, ''), is_system,
+               COALESCE((SELECT array_agg(p.kode ORDER BY p.kode) FROM role_permissions rp
+                         JOIN permissions p ON p.id = rp.permission_id WHERE rp.role_id = $1), '{}')
+        FROM roles WHERE id = $1`, roleID).Scan(&role.Deskripsi, &role.IsSystem, pq.Array(&role.Permissions))
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// PermissionMap mengembalikan permission setiap role (role tanpa permission tetap ada dengan slice kosong)
+func (r *roleRepository) PermissionMap() (map[string][]string, error) {
+	rows, err := r.db.Query(`
+        SELECT r.nama, p.kode
+        FROM roles r
+        LEFT JOIN role_permissions rp ON rp.role_id = r.id
+        LEFT JOIN permissions p ON p.id = rp.permission_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[string][]string{}
+	for rows.Next() {
+		var nama string
+		var kode sql.NullString
+		if err := rows.Scan(&nama, &kode); err != nil {
+			return nil, err
+		}
+		if _, ok := result[nama]; !ok {
+			result[nama] = []string{}
+		}
+		if kode.Valid {
+			result[nama] = append(result[nama], kode.String)
+		}
+	}
+	return result, rows.Err()
+}
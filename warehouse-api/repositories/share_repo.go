@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"warehouse-api/ctxkeys"
+	"warehouse-api/models"
+)
+
+var ErrShareNotFound = errors.New("transaksi tidak dibagikan ke pengguna tersebut")
+
+type ShareRepository interface {
+	GetAll(jenis string, transaksiID int) ([]models.TransaksiShare, error)
+	// Add mengisi SharedBy dari pelaku request dan tidak mengubah apa pun bila transaksi
+	// sudah dibagikan ke pengguna tersebut
+	Add(ctx context.Context, share *models.TransaksiShare) error
+	Remove(ctx context.Context, jenis string, transaksiID, userID int) error
+}
+
+type shareRepository struct {
+	db *sql.DB
+}
+
+func NewShareRepository(db *sql.DB) ShareRepository {
+	return &shareRepository{db}
+}
+
+func (r *shareRepository) GetAll(jenis string, transaksiID int) ([]models.TransaksiShare, error) {
+	rows, err := r.db.Query(`
+        SELECT s.jenis, s.transaksi_id, s.user_id, u.username, COALESCE(s.shared_by, 0), s.created_at
+        FROM transaksi_share s
+        JOIN users u ON u.id = s.user_id
+        WHERE s.jenis = $1 AND s.transaksi_id = $2
+        ORDER BY u.username`, jenis, transaksiID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []models.TransaksiShare{}
+	for rows.Next() {
+		var s models.TransaksiShare
+		if err := rows.Scan(&s.Jenis, &s.TransaksiID, &s.UserID, &s.Username, &s.SharedBy, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		shares = append(shares, s)
+	}
+	return shares, rows.Err()
+}
+
+func (r *shareRepository) Add(ctx context.Context, share *models.TransaksiShare) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	share.SharedBy = ctxkeys.UserID(ctx)
+	err = tx.QueryRow(`
+        INSERT INTO transaksi_share (jenis, transaksi_id, user_id, shared_by)
+        VALUES ($1, $2, $3, NULLIF($4, 0))
+        ON CONFLICT (jenis, transaksi_id, user_id) DO NOTHING
+        RETURNING created_at`, share.Jenis, share.TransaksiID, share.UserID, share.SharedBy).Scan(&share.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditCreate, models.AuditShare, shareAuditID(share.Jenis, share.TransaksiID, share.UserID), nil, share); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *shareRepository) Remove(ctx context.Context, jenis string, transaksiID, userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var before models.TransaksiShare
+	err = tx.QueryRow(`
+        DELETE FROM transaksi_share
+        WHERE jenis = $1 AND transaksi_id = $2 AND user_id = $3
+        RETURNING jenis, transaksi_id, user_id, COALESCE(shared_by, 0), created_at`, jenis, transaksiID, userID).
+		Scan(&before.Jenis, &before.TransaksiID, &before.UserID, &before.SharedBy, &before.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrShareNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditDelete, models.AuditShare, shareAuditID(jenis, transaksiID, userID), &before, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// shareAuditID menjadi entitas_id audit log, mis. "penjualan/12/5" (transaksi 12 dibagikan ke user 5)
+func shareAuditID(jenis string, transaksiID, userID int) string {
+	return fmt.Sprintf("%s/%d/%d", jenis, transaksiID, userID)
+}
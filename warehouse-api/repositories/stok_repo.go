@@ -1,7 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 	"warehouse-api/models"
 )
 
@@ -10,8 +15,19 @@ type StokRepository interface {
 	GetByBarangID(barangID int) (*models.Stok, error)
 	GetByBarangIDWithTx(tx *sql.Tx, barangID int) (*models.Stok, error)
     CreateOrUpdate(tx *sql.Tx, barangID, qtyChange int) error
-	GetHistory(barangID int) ([]models.HistoryStok, error)
+	GetHistory(ctx context.Context, filter models.HistoryStokFilter, limit, offset int, sortBy, order string) ([]models.HistoryStok, int, error)
+	GetHistoryAfter(ctx context.Context, filter models.HistoryStokFilter, cursor *models.HistoryCursor, limit int) ([]models.HistoryStok, *models.HistoryCursor, error)
+	StreamHistory(ctx context.Context, filter models.HistoryStokFilter, fn func(models.HistoryStok) error) error
     CreateHistory(tx *sql.Tx, history *models.HistoryStok) error
+	EachHistoryChain(barangID int, fn func(h models.HistoryStok, sealed bool) error) error
+	HistoryChainAnchors(barangID int) (map[int]models.HistoryChainAnchor, *models.HistoryChainSeal, error)
+	SealHistory() (barang int, baris int, err error)
+	GetSaldoAwal(tx *sql.Tx, barangID int) (qty int, loaded bool, err error)
+	EachMovement(ctx context.Context, barangID int, endDate string, fn func(models.StokMovement) error) error
+	GetAsOf(date string) ([]models.StokAsOf, error)
+	GetSnapshot(periode string) ([]models.StokAsOf, *time.Time, error)
+	HasSnapshot(periode string) (bool, error)
+	CreateSnapshot(ctx context.Context, periode string) (int, error)
 }
 
 type stokRepository struct {
@@ -122,49 +138,522 @@ func (r *stokRepository) CreateOrUpdate(tx *sql.Tx, barangID, qtyChange int) err
     return err
 }
 
-func (r *stokRepository) GetHistory(barangID int) ([]models.HistoryStok, error) {
-    query := `
+// buildHistoryWhere menyusun klausa WHERE riwayat stok termasuk batas visibilitas request.
+// Jika cursor diisi, hanya baris setelah posisi (created_at, id) tersebut yang diambil.
+func buildHistoryWhere(ctx context.Context, filter models.HistoryStokFilter, cursor *models.HistoryCursor) (string, []interface{}) {
+    var conditions []string
+    var args []interface{}
+    add := func(cond string, arg interface{}) {
+        args = append(args, arg)
+        conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+    }
+
+    if filter.Search != "" {
+        add("(b.nama_barang ILIKE $%[1]d OR b.kode_barang ILIKE $%[1]d)", "%"+likeEscaper.Replace(filter.Search)+"%")
+    }
+    if filter.BarangID != 0 {
+        add("h.barang_id = $%d", filter.BarangID)
+    }
+    if filter.UserID != 0 {
+        add("h.user_id = $%d", filter.UserID)
+    }
+    if filter.JenisTransaksi != "" {
+        add("h.jenis_transaksi = $%d", filter.JenisTransaksi)
+    }
+    if filter.StartDate != "" {
+        add("h.created_at >= $%d::date", filter.StartDate)
+    }
+    if filter.EndDate != "" {
+        add("h.created_at < $%d::date + 1", filter.EndDate)
+    }
+    if userID, restricted := VisibleTo(ctx); restricted {
+        add(historyVisibleCond, userID)
+    }
+    if cursor != nil {
+        args = append(args, cursor.CreatedAt, cursor.ID)
+        conditions = append(conditions, fmt.Sprintf("(h.created_at, h.id) < ($%d, $%d)", len(args)-1, len(args)))
+    }
+
+    if len(conditions) == 0 {
+        return "", args
+    }
+    return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+const historySelect = `
         SELECT h.id, h.barang_id, h.user_id, h.jenis_transaksi, h.jumlah, h.stok_sebelum, h.stok_sesudah, h.keterangan, h.created_at,
-               b.nama_barang, u.username
+               COALESCE(h.jual_header_id, 0), COALESCE(h.beli_header_id, 0), b.nama_barang, b.kode_barang, u.username
         FROM history_stok h
         JOIN master_barang b ON h.barang_id = b.id
-        JOIN users u ON h.user_id = u.id
-    `
+        JOIN users u ON h.user_id = u.id`
+
+func (r *stokRepository) queryHistory(ctx context.Context, query string, args ...interface{}) ([]models.HistoryStok, error) {
+    var history []models.HistoryStok
+    err := r.eachHistory(ctx, query, args, func(h models.HistoryStok) error {
+        history = append(history, h)
+        return nil
+    })
+    return history, err
+}
+
+func (r *stokRepository) eachHistory(ctx context.Context, query string, args []interface{}, fn func(models.HistoryStok) error) error {
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var h models.HistoryStok
+        h.Barang = &models.Barang{}
+        h.User = &models.User{}
+        if err := rows.Scan(&h.ID, &h.BarangID, &h.UserID, &h.JenisTransaksi, &h.Jumlah, &h.StokSebelum, &h.StokSesudah, &h.Keterangan, &h.CreatedAt,
+            &h.JualHeaderID, &h.BeliHeaderID, &h.Barang.NamaBarang, &h.Barang.KodeBarang, &h.User.Username); err != nil {
+            return err
+        }
+        if err := fn(h); err != nil {
+            return err
+        }
+    }
+    return rows.Err()
+}
+
+// StreamHistory memanggil fn untuk setiap baris riwayat (terbaru dulu) langsung dari cursor database
+func (r *stokRepository) StreamHistory(ctx context.Context, filter models.HistoryStokFilter, fn func(models.HistoryStok) error) error {
+    whereClause, args := buildHistoryWhere(ctx, filter, nil)
+    query := fmt.Sprintf("%s %s ORDER BY h.created_at DESC, h.id DESC", historySelect, whereClause)
+    return r.eachHistory(ctx, query, args, fn)
+}
+
+func (r *stokRepository) GetHistory(ctx context.Context, filter models.HistoryStokFilter, limit, offset int, sortBy, order string) ([]models.HistoryStok, int, error) {
+    whereClause, args := buildHistoryWhere(ctx, filter, nil)
+
+    // Whitelist kolom sorting, default terbaru dulu
+    orderByClause := "ORDER BY h.created_at DESC, h.id DESC"
+    allowedSorts := map[string]string{
+        "tanggal": "h.created_at",
+        "jumlah":  "h.jumlah",
+        "id":      "h.id",
+    }
+    if col, ok := allowedSorts[sortBy]; ok {
+        ord := "ASC"
+        if order == "desc" || order == "DESC" {
+            ord = "DESC"
+        }
+        orderByClause = fmt.Sprintf("ORDER BY %s %s, h.id %s", col, ord, ord)
+    }
+
+    var total int
+    if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM history_stok h JOIN master_barang b ON h.barang_id = b.id "+whereClause, args...).Scan(&total); err != nil {
+        return nil, 0, err
+    }
+
+    idx := len(args) + 1
+    query := fmt.Sprintf("%s %s %s LIMIT $%d OFFSET $%d", historySelect, whereClause, orderByClause, idx, idx+1)
+    args = append(args, limit, offset)
+
+    history, err := r.queryHistory(ctx, query, args...)
+    if err != nil {
+        return nil, 0, err
+    }
+    return history, total, nil
+}
+
+// GetHistoryAfter mengambil riwayat stok dengan keyset pagination (created_at DESC, id DESC).
+// Tidak ada COUNT(*) dan OFFSET sehingga biayanya tetap walau tabel sangat besar.
+// next bernilai nil jika tidak ada halaman berikutnya.
+func (r *stokRepository) GetHistoryAfter(ctx context.Context, filter models.HistoryStokFilter, cursor *models.HistoryCursor, limit int) ([]models.HistoryStok, *models.HistoryCursor, error) {
+    whereClause, args := buildHistoryWhere(ctx, filter, cursor)
+
+    // Ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya
+    query := fmt.Sprintf("%s %s ORDER BY h.created_at DESC, h.id DESC LIMIT $%d", historySelect, whereClause, len(args)+1)
+    args = append(args, limit+1)
+
+    history, err := r.queryHistory(ctx, query, args...)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    var next *models.HistoryCursor
+    if len(history) > limit {
+        history = history[:limit]
+        last := history[limit-1]
+        next = &models.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+    }
+    return history, next, nil
+}
+
+// CreateHistory mencatat riwayat stok dan menyambungkannya ke hash chain barang tersebut.
+// Barang dikunci sampai transaksi selesai agar dua transaksi tidak menyambung ke baris yang sama.
+func (r *stokRepository) CreateHistory(tx *sql.Tx, h *models.HistoryStok) error {
+    if tx == nil {
+        own, err := r.db.Begin()
+        if err != nil {
+            return err
+        }
+        defer own.Rollback()
+        if err := r.CreateHistory(own, h); err != nil {
+            return err
+        }
+        return own.Commit()
+    }
+
+    anchor, err := lockHistoryChain(tx, h.BarangID)
+    if err != nil {
+        return err
+    }
+    prev := ""
+    if anchor != nil {
+        prev = anchor.LastHash
+    }
+
+    query := `INSERT INTO history_stok (barang_id, user_id, jenis_transaksi, jumlah, stok_sebelum, stok_sesudah, keterangan, jual_header_id, beli_header_id) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), NULLIF($9, 0))
+              RETURNING id, created_at`
+    if err := tx.QueryRow(query, h.BarangID, h.UserID, h.JenisTransaksi, h.Jumlah, h.StokSebelum, h.StokSesudah, h.Keterangan,
+        h.JualHeaderID, h.BeliHeaderID).Scan(&h.ID, &h.CreatedAt); err != nil {
+        return err
+    }
+
+    h.PrevHash = prev
+    h.Hash = HistoryHash(*h, prev)
+    if _, err := tx.Exec("UPDATE history_stok SET prev_hash = NULLIF($1, ''), hash = $2 WHERE id = $3", h.PrevHash, h.Hash, h.ID); err != nil {
+        return err
+    }
+
+    // Rantai baru mulai di baris pertama barang; sealed_at-nya tidak pernah berubah
+    if anchor == nil {
+        anchor = &models.HistoryChainAnchor{BarangID: h.BarangID, SealedAt: h.CreatedAt}
+        if err := markHistorySealed(tx, h.CreatedAt); err != nil {
+            return err
+        }
+    }
+    anchor.Baris++
+    anchor.LastID, anchor.LastHash = h.ID, h.Hash
+    return saveHistoryAnchor(tx, *anchor)
+}
+
+// EachHistoryChain membaca riwayat stok urut barang lalu id (urutan hash chain) beserta hash-nya
+// dan status seal barangnya. barangID 0 berarti semua barang.
+func (r *stokRepository) EachHistoryChain(barangID int, fn func(h models.HistoryStok, sealed bool) error) error {
+    query := `
+        SELECT h.id, h.barang_id, COALESCE(h.user_id, 0), h.jenis_transaksi, h.jumlah, h.stok_sebelum, h.stok_sesudah,
+               COALESCE(h.keterangan, ''), h.created_at, COALESCE(h.prev_hash, ''), COALESCE(h.hash, ''), b.history_sealed
+        FROM history_stok h
+        JOIN master_barang b ON b.id = h.barang_id`
     var args []interface{}
     if barangID != 0 {
         query += " WHERE h.barang_id = $1"
         args = append(args, barangID)
     }
-    query += " ORDER BY h.created_at DESC"
+    rows, err := r.db.Query(query+" ORDER BY h.barang_id, h.id", args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var h models.HistoryStok
+        var sealed bool
+        err := rows.Scan(&h.ID, &h.BarangID, &h.UserID, &h.JenisTransaksi, &h.Jumlah, &h.StokSebelum, &h.StokSesudah,
+            &h.Keterangan, &h.CreatedAt, &h.PrevHash, &h.Hash, &sealed)
+        if err != nil {
+            return err
+        }
+        if err := fn(h, sealed); err != nil {
+            return err
+        }
+    }
+    return rows.Err()
+}
 
+// HistoryChainAnchors membaca anchor rantai (barangID 0 berarti semua barang) beserta penanda seal;
+// penanda nil bila riwayat lama belum pernah di-seal.
+func (r *stokRepository) HistoryChainAnchors(barangID int) (map[int]models.HistoryChainAnchor, *models.HistoryChainSeal, error) {
+    query := historyAnchorSelect
+    var args []interface{}
+    if barangID != 0 {
+        query += " WHERE barang_id = $1"
+        args = append(args, barangID)
+    }
     rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer rows.Close()
+
+    anchors := map[int]models.HistoryChainAnchor{}
+    for rows.Next() {
+        a, err := scanHistoryAnchor(rows)
+        if err != nil {
+            return nil, nil, err
+        }
+        anchors[a.BarangID] = a
+    }
+    if err := rows.Err(); err != nil {
+        return nil, nil, err
+    }
+
+    var seal models.HistoryChainSeal
+    err = r.db.QueryRow("SELECT sealed_at, signature FROM history_chain_seal WHERE id = 1").Scan(&seal.SealedAt, &seal.Signature)
+    if errors.Is(err, sql.ErrNoRows) {
+        return anchors, nil, nil
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    return anchors, &seal, nil
+}
+
+// SealHistory memberi hash chain dan anchor pada riwayat barang yang belum di-seal (data sebelum
+// migrasi 020) lalu menandainya sudah di-seal; setelah semuanya selesai penanda seal ditulis.
+// Barang yang sudah punya anchor, atau riwayat yang ditandai belum di-seal setelah penanda seal ada,
+// tidak pernah di-seal ulang: keduanya berarti penanda seal diubah di luar aplikasi.
+func (r *stokRepository) SealHistory() (int, int, error) {
+    rows, err := r.db.Query(`SELECT id FROM master_barang WHERE NOT history_sealed ORDER BY id`)
+    if err != nil {
+        return 0, 0, err
+    }
+    var ids []int
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            rows.Close()
+            return 0, 0, err
+        }
+        ids = append(ids, id)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, 0, err
+    }
+
+    var barang, baris int
+    for _, id := range ids {
+        sealed, n, err := r.sealBarangHistory(id)
+        if err != nil {
+            return barang, baris, err
+        }
+        if sealed {
+            barang++
+            baris += n
+        }
+    }
+
+    tx, err := r.db.Begin()
+    if err != nil {
+        return barang, baris, err
+    }
+    defer tx.Rollback()
+    var now time.Time
+    if err := tx.QueryRow("SELECT LOCALTIMESTAMP").Scan(&now); err != nil {
+        return barang, baris, err
+    }
+    if err := markHistorySealed(tx, now); err != nil {
+        return barang, baris, err
+    }
+    return barang, baris, tx.Commit()
+}
+
+func (r *stokRepository) sealBarangHistory(barangID int) (bool, int, error) {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return false, 0, err
+    }
+    defer tx.Rollback()
+
+    // Periksa ulang setelah barang dikunci: proses seal lain mungkin sudah men-seal-nya
+    var sealed bool
+    if err := tx.QueryRow("SELECT history_sealed FROM master_barang WHERE id = $1 FOR UPDATE", barangID).Scan(&sealed); err != nil || sealed {
+        return false, 0, err
+    }
+
+    var anchored, marked, hasHistory bool
+    err = tx.QueryRow(`
+        SELECT EXISTS(SELECT 1 FROM history_chain_anchor WHERE barang_id = $1),
+               EXISTS(SELECT 1 FROM history_chain_seal),
+               EXISTS(SELECT 1 FROM history_stok WHERE barang_id = $1)`, barangID).Scan(&anchored, &marked, &hasHistory)
+    if err != nil {
+        return false, 0, err
+    }
+    if anchored || (marked && hasHistory) {
+        return false, 0, fmt.Errorf("%w: barang ID %d sudah pernah di-seal, penanda seal diubah", ErrHistoryChainRusak, barangID)
+    }
+
+    n, err := sealHistory(tx, barangID)
+    if err != nil {
+        return false, 0, err
+    }
+    return true, n, tx.Commit()
+}
+
+// GetSaldoAwal mengembalikan total saldo awal yang pernah dimuat untuk barang dan apakah
+// saldo awal sudah pernah dimuat. Baris master_barang dikunci sampai transaksi selesai
+// agar dua proses muat saldo awal untuk barang yang sama tidak berjalan bersamaan.
+func (r *stokRepository) GetSaldoAwal(tx *sql.Tx, barangID int) (int, bool, error) {
+    var id int
+    if err := tx.QueryRow("SELECT id FROM master_barang WHERE id = $1 FOR UPDATE", barangID).Scan(&id); err != nil {
+        return 0, false, err
+    }
+
+    var qty, count int
+    query := "SELECT COALESCE(SUM(jumlah), 0), COUNT(*) FROM history_stok WHERE barang_id = $1 AND jenis_transaksi = $2"
+    if err := tx.QueryRow(query, barangID, models.JenisSaldoAwal).Scan(&qty, &count); err != nil {
+        return 0, false, err
+    }
+    return qty, count > 0, nil
+}
+
+// EachMovement membaca seluruh riwayat stok satu barang dari awal sampai endDate (inklusif,
+// kosong berarti sampai sekarang) secara kronologis. Nomor faktur diambil dari transaksi yang
+// dirujuk (jual_header_id / beli_header_id); harga pokok diketahui untuk pembelian (rata-rata harga
+// di faktur) dan saldo awal (harga beli barang). Saldo butuh seluruh mutasi, jadi baris di luar
+// visibilitas pengguna (VisibleTo) tetap dibaca tapi ditandai Hidden.
+func (r *stokRepository) EachMovement(ctx context.Context, barangID int, endDate string, fn func(models.StokMovement) error) error {
+    args := []interface{}{barangID, models.JenisSaldoAwal}
+    hidden := "FALSE"
+    if userID, restricted := VisibleTo(ctx); restricted {
+        args = append(args, userID)
+        hidden = "NOT " + fmt.Sprintf(historyVisibleCond, len(args))
+    }
+    query := `
+        SELECT h.id, h.created_at, h.jenis_transaksi, h.jumlah, h.stok_sebelum, h.stok_sesudah,
+               COALESCE(h.keterangan, ''), COALESCE(u.username, ''),
+               COALESCE(bh.no_faktur, jh.no_faktur, ''),
+               CASE
+                   WHEN bh.id IS NOT NULL THEN (
+                       SELECT SUM(d.subtotal) / NULLIF(SUM(d.qty), 0) FROM beli_detail d
+                       WHERE d.beli_header_id = bh.id AND d.barang_id = h.barang_id)
+                   WHEN h.jenis_transaksi = $2 THEN b.harga_beli
+               END,
+               ` + hidden + `
+        FROM history_stok h
+        JOIN master_barang b ON b.id = h.barang_id
+        LEFT JOIN users u ON u.id = h.user_id
+        LEFT JOIN beli_header bh ON bh.id = h.beli_header_id
+        LEFT JOIN jual_header jh ON jh.id = h.jual_header_id
+        WHERE h.barang_id = $1`
+    if endDate != "" {
+        args = append(args, endDate)
+        query += fmt.Sprintf(" AND h.created_at < $%d::date + 1", len(args))
+    }
+    query += " ORDER BY h.created_at ASC, h.id ASC"
+
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var m models.StokMovement
+        var harga sql.NullFloat64
+        if err := rows.Scan(&m.ID, &m.CreatedAt, &m.JenisTransaksi, &m.Jumlah, &m.StokSebelum, &m.StokSesudah,
+            &m.Keterangan, &m.Petugas, &m.Referensi, &harga, &m.Hidden); err != nil {
+            return err
+        }
+        if harga.Valid {
+            m.HargaSatuan = &harga.Float64
+        }
+        if err := fn(m); err != nil {
+            return err
+        }
+    }
+    return rows.Err()
+}
+
+// stokAsOfSelect merekonstruksi saldo per barang pada tanggal $1 (inklusif) dari stok_sesudah
+// riwayat terakhir sebelum akhir hari tersebut. Barang yang dibuat setelah tanggal itu tidak ikut.
+const stokAsOfSelect = `
+        SELECT b.id, b.kode_barang, b.nama_barang, b.satuan, COALESCE(h.stok_sesudah, 0), h.created_at
+        FROM master_barang b
+        LEFT JOIN LATERAL (
+            SELECT stok_sesudah, created_at FROM history_stok
+            WHERE barang_id = b.id AND created_at < $1::date + 1
+            ORDER BY created_at DESC, id DESC
+            LIMIT 1
+        ) h ON TRUE
+        WHERE b.created_at < $1::date + 1`
+
+func (r *stokRepository) GetAsOf(date string) ([]models.StokAsOf, error) {
+    rows, err := r.db.Query(stokAsOfSelect+" ORDER BY b.kode_barang", date)
     if err != nil {
         return nil, err
     }
     defer rows.Close()
 
-    var history []models.HistoryStok
+    items := []models.StokAsOf{}
     for rows.Next() {
-        var h models.HistoryStok
-        h.Barang = &models.Barang{}
-        h.User = &models.User{}
-        if err := rows.Scan(&h.ID, &h.BarangID, &h.UserID, &h.JenisTransaksi, &h.Jumlah, &h.StokSebelum, &h.StokSesudah, &h.Keterangan, &h.CreatedAt, &h.Barang.NamaBarang, &h.User.Username); err != nil {
+        var s models.StokAsOf
+        if err := rows.Scan(&s.BarangID, &s.KodeBarang, &s.NamaBarang, &s.Satuan, &s.StokAkhir, &s.LastMovementAt); err != nil {
             return nil, err
         }
-        history = append(history, h)
+        items = append(items, s)
     }
-    return history, nil
+    return items, rows.Err()
 }
 
-func (r *stokRepository) CreateHistory(tx *sql.Tx, h *models.HistoryStok) error {
-    query := `INSERT INTO history_stok (barang_id, user_id, jenis_transaksi, jumlah, stok_sebelum, stok_sesudah, keterangan) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7)`
-    
-    var err error
-    if tx != nil {
-        _, err = tx.Exec(query, h.BarangID, h.UserID, h.JenisTransaksi, h.Jumlah, h.StokSebelum, h.StokSesudah, h.Keterangan)
-    } else {
-        _, err = r.db.Exec(query, h.BarangID, h.UserID, h.JenisTransaksi, h.Jumlah, h.StokSebelum, h.StokSesudah, h.Keterangan)
+// GetSnapshot mengembalikan snapshot periode beserta waktu pembuatannya (nil jika belum ada)
+func (r *stokRepository) GetSnapshot(periode string) ([]models.StokAsOf, *time.Time, error) {
+    query := `
+        SELECT b.id, b.kode_barang, b.nama_barang, b.satuan, ss.stok, ss.created_at
+        FROM stok_snapshot ss
+        JOIN master_barang b ON b.id = ss.barang_id
+        WHERE ss.periode = $1
+        ORDER BY b.kode_barang`
+    rows, err := r.db.Query(query, periode)
+    if err != nil {
+        return nil, nil, err
     }
-    return err
+    defer rows.Close()
+
+    var items []models.StokAsOf
+    var createdAt *time.Time
+    for rows.Next() {
+        var s models.StokAsOf
+        var t time.Time
+        if err := rows.Scan(&s.BarangID, &s.KodeBarang, &s.NamaBarang, &s.Satuan, &s.StokAkhir, &t); err != nil {
+            return nil, nil, err
+        }
+        createdAt = &t
+        items = append(items, s)
+    }
+    return items, createdAt, rows.Err()
+}
+
+func (r *stokRepository) HasSnapshot(periode string) (bool, error) {
+    var exists bool
+    err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM stok_snapshot WHERE periode = $1)", periode).Scan(&exists)
+    return exists, err
+}
+
+// CreateSnapshot membekukan saldo semua barang pada periode dalam satu statement dan mencatatnya di
+// audit log. Snapshot yang sudah ada tidak ditimpa; jumlah baris yang ditulis dikembalikan.
+func (r *stokRepository) CreateSnapshot(ctx context.Context, periode string) (int, error) {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback()
+
+    query := `
+        INSERT INTO stok_snapshot (periode, barang_id, stok)
+        SELECT $1::date, s.id, s.stok FROM (` + stokAsOfSelect + `
+        ) AS s(id, kode_barang, nama_barang, satuan, stok, last_movement_at)
+        WHERE NOT EXISTS (SELECT 1 FROM stok_snapshot WHERE periode = $1::date)
+        ON CONFLICT (periode, barang_id) DO NOTHING`
+    res, err := tx.Exec(query, periode)
+    if err != nil {
+        return 0, err
+    }
+    n, err := res.RowsAffected()
+    if err != nil || n == 0 {
+        return 0, err
+    }
+    err = WriteAudit(ctx, tx, models.AuditCreate, models.AuditSnapshot, periode, nil,
+        map[string]interface{}{"periode": periode, "jumlah_barang": n})
+    if err != nil {
+        return 0, err
+    }
+    return int(n), tx.Commit()
 }
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"database/sql"
+	"warehouse-api/models"
+)
+
+type TagRepository interface {
+	GetAll(search string) ([]models.Tag, error)
+}
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db}
+}
+
+// GetAll mengembalikan semua tag beserta jumlah barang aktif yang memakainya
+func (r *tagRepository) GetAll(search string) ([]models.Tag, error) {
+	query := `
+        SELECT t.id, t.nama, COUNT(b.id)
+        FROM tag t
+        LEFT JOIN barang_tag bt ON bt.tag_id = t.id
+        LEFT JOIN master_barang b ON b.id = bt.barang_id AND b.is_active = TRUE`
+	var args []interface{}
+	if search != "" {
+		query += " WHERE t.nama ILIKE $1"
+		args = append(args, "%"+search+"%")
+	}
+	query += " GROUP BY t.id, t.nama ORDER BY t.nama ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.ID, &t.Nama, &t.TotalBarang); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
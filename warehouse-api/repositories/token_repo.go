@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"warehouse-api/models"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token tidak ditemukan")
+	ErrRefreshTokenReused   = errors.New("refresh token sudah pernah dipakai")
+)
+
+type TokenRepository interface {
+	CreateRefresh(userID int, tokenHash, familyID string, ttl time.Duration) error
+	RotateRefresh(oldHash, newHash string, ttl time.Duration) (*models.RefreshToken, error)
+	RevokeRefreshFamily(userID int, tokenHash string) error
+	RevokeAccess(jti string, userID int, expiresAt time.Time) error
+	RevokeAllForUser(ctx context.Context, userID int) error
+	IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+}
+
+type tokenRepository struct {
+	db *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) TokenRepository {
+	return &tokenRepository{db}
+}
+
+// Waktu dikirim sebagai detik unix dan dibandingkan dengan NOW() di database agar kolom
+// TIMESTAMP tidak bergantung pada zona waktu server aplikasi.
+
+func (r *tokenRepository) CreateRefresh(userID int, tokenHash, familyID string, ttl time.Duration) error {
+	_, err := r.db.Exec(`
+        INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+        VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')`,
+		userID, tokenHash, familyID, int64(ttl.Seconds()))
+	return err
+}
+
+// RotateRefresh menandai refresh token lama terpakai dan menerbitkan penggantinya di family yang sama.
+// Token yang sudah pernah dirotasi dianggap dicuri: seluruh family dicabut dan ErrRefreshTokenReused dikembalikan.
+func (r *tokenRepository) RotateRefresh(oldHash, newHash string, ttl time.Duration) (*models.RefreshToken, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rt models.RefreshToken
+	var used, revoked, expired bool
+	err = tx.QueryRow(`
+        SELECT id, user_id, family_id, used_at IS NOT NULL, revoked_at IS NOT NULL, expires_at <= NOW()
+        FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`, oldHash).
+		Scan(&rt.ID, &rt.UserID, &rt.FamilyID, &used, &revoked, &expired)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if revoked || expired {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if used {
+		if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`, rt.FamilyID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, rt.ID); err != nil {
+		return nil, err
+	}
+	err = tx.QueryRow(`
+        INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+        VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second') RETURNING id`,
+		rt.UserID, newHash, rt.FamilyID, int64(ttl.Seconds())).Scan(&rt.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &rt, tx.Commit()
+}
+
+// RevokeRefreshFamily mencabut sesi milik refresh token; token milik user lain diabaikan
+func (r *tokenRepository) RevokeRefreshFamily(userID int, tokenHash string) error {
+	_, err := r.db.Exec(`
+        UPDATE refresh_tokens SET revoked_at = NOW()
+        WHERE revoked_at IS NULL AND family_id = (
+            SELECT family_id FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2)`,
+		tokenHash, userID)
+	return err
+}
+
+// RevokeAccess memasukkan jti ke denylist sekaligus membersihkan jti yang sudah kedaluwarsa
+func (r *tokenRepository) RevokeAccess(jti string, userID int, expiresAt time.Time) error {
+	if _, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`
+        INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, to_timestamp($3))
+        ON CONFLICT (jti) DO NOTHING`, jti, userID, expiresAt.Unix())
+	return err
+}
+
+// RevokeAllForUser mencabut semua refresh token user dan menolak access token yang sudah terbit.
+// Pencabutan dicatat di audit log user.
+func (r *tokenRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := revokeSessions(ctx, tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// revokeSessions mencabut semua access dan refresh token user di tx pemanggil, sehingga perubahan
+// yang mewajibkan login ulang (role, password, nonaktif) dan pencabutannya commit bersama.
+// sql.ErrNoRows bila user tidak ada.
+func revokeSessions(ctx context.Context, tx *sql.Tx, userID int) error {
+	res, err := tx.Exec(`UPDATE users SET sesi_dicabut_at = date_trunc('second', NOW()) WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID); err != nil {
+		return err
+	}
+	return WriteAudit(ctx, tx, models.AuditUpdate, models.AuditUser, userID,
+		map[string]bool{"sesi_dicabut": false}, map[string]bool{"sesi_dicabut": true})
+}
+
+// IsRevoked juga menolak token milik user yang sudah dinonaktifkan
+func (r *tokenRepository) IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(`
+        SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)
+            OR EXISTS(SELECT 1 FROM users WHERE id = $2 AND (NOT is_active OR sesi_dicabut_at >= to_timestamp($3)))`,
+		jti, userID, issuedAt.Unix()).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"warehouse-api/models"
+)
+
+// likeEscaper meng-escape wildcard LIKE agar input user dicocokkan apa adanya
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// buildTransaksiWhere menyusun klausa WHERE untuk jual_header/beli_header (alias h) termasuk
+// batas visibilitas request. partyColumn adalah kolom customer atau supplier.
+func buildTransaksiWhere(ctx context.Context, filter models.TransaksiFilter, jenis, partyColumn string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.StartDate != "" {
+		add("h.created_at >= $%d::date", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		// end_date inklusif: seluruh hari terakhir ikut terhitung
+		add("h.created_at < $%d::date + 1", filter.EndDate)
+	}
+	if filter.Party != "" {
+		add("h."+partyColumn+" ILIKE $%d", "%"+likeEscaper.Replace(filter.Party)+"%")
+	}
+	if filter.UserID != 0 {
+		add("h.user_id = $%d", filter.UserID)
+	}
+	if filter.Status != "" {
+		add("h.status = $%d", filter.Status)
+	}
+	if filter.FakturPrefix != "" {
+		add("h.no_faktur ILIKE $%d", likeEscaper.Replace(filter.FakturPrefix)+"%")
+	}
+	if filter.MinTotal != nil {
+		add("h.total >= $%d", *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		add("h.total <= $%d", *filter.MaxTotal)
+	}
+	if userID, restricted := VisibleTo(ctx); restricted {
+		add(transaksiVisibleCond(jenis), userID)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// transaksiOrderBy memetakan sort_by ke kolom yang diizinkan. Default: terbaru dulu.
+func transaksiOrderBy(sortBy, order, partyColumn string) string {
+	allowedSorts := map[string]string{
+		"tanggal":   "h.created_at",
+		"no_faktur": "h.no_faktur",
+		"total":     "h.total",
+		partyColumn: "h." + partyColumn,
+		"id":        "h.id",
+	}
+
+	col, ok := allowedSorts[sortBy]
+	if !ok {
+		return "ORDER BY h.created_at DESC, h.id DESC"
+	}
+	ord := "ASC"
+	if order == "desc" || order == "DESC" {
+		ord = "DESC"
+	}
+	return fmt.Sprintf("ORDER BY %s %s, h.id %s", col, ord, ord)
+}
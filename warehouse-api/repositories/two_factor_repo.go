@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"warehouse-api/models"
+)
+
+var ErrChallengeNotFound = errors.New("challenge login tidak ditemukan")
+
+// TwoFactorRepository menyimpan secret TOTP, kode pemulihan dan challenge login dua langkah
+type TwoFactorRepository interface {
+	// GetSecret mengembalikan secret (kosong bila belum pernah setup) dan status aktif
+	GetSecret(userID int) (secret string, enabled bool, err error)
+	SetPendingSecret(userID int, secret string) error
+	// Enable mengaktifkan secret yang sedang menunggu konfirmasi sekaligus menyimpan kode pemulihan.
+	// Enable, Disable dan ReplaceRecoveryCodes dicatat di audit log user.
+	Enable(ctx context.Context, userID int, codeHashes []string) error
+	Disable(ctx context.Context, userID int) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	// UseRecoveryCode menandai kode terpakai; false bila kode tidak ada atau sudah dipakai
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	// UseStep mencatat langkah waktu TOTP yang dipakai; false bila langkah itu (atau yang lebih baru) sudah dipakai
+	UseStep(userID int, step int64) (bool, error)
+
+	CreateChallenge(userID int, tokenHash string, ttl time.Duration) error
+	// GetChallenge mengembalikan pemilik challenge yang belum kedaluwarsa dan percobaannya belum habis
+	GetChallenge(tokenHash string, maxAttempts int) (int, error)
+	FailChallenge(tokenHash string) error
+	DeleteChallenge(tokenHash string) error
+}
+
+type twoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &twoFactorRepository{db}
+}
+
+func (r *twoFactorRepository) GetSecret(userID int) (string, bool, error) {
+	var secret sql.NullString
+	var enabled bool
+	err := r.db.QueryRow(`SELECT totp_secret, totp_enabled FROM users WHERE id = $1`, userID).Scan(&secret, &enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, ErrUserNotFound
+	}
+	return secret.String, enabled, err
+}
+
+func (r *twoFactorRepository) SetPendingSecret(userID int, secret string) error {
+	res, err := r.db.Exec(`
+        UPDATE users SET totp_secret = $2, totp_last_step = NULL
+        WHERE id = $1 AND NOT totp_enabled`, userID, secret)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) Enable(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET totp_enabled = TRUE, updated_at = NOW() WHERE id = $1`, userID); err != nil {
+		return err
+	}
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+	err = WriteAudit(ctx, tx, models.AuditUpdate, models.AuditUser, userID,
+		map[string]bool{"totp_enabled": false}, map[string]bool{"totp_enabled": true})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *twoFactorRepository) Disable(ctx context.Context, userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+        UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = NULL, updated_at = NOW()
+        WHERE id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	err = WriteAudit(ctx, tx, models.AuditUpdate, models.AuditUser, userID,
+		map[string]bool{"totp_enabled": true}, map[string]bool{"totp_enabled": false})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+	// Hash kode pemulihan tidak pernah masuk audit log; yang dicatat hanya bahwa kodenya diganti
+	err = WriteAudit(ctx, tx, models.AuditUpdate, models.AuditUser, userID,
+		map[string]bool{"recovery_codes_diganti": false}, map[string]bool{"recovery_codes_diganti": true})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.Exec(`INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	res, err := r.db.Exec(`
+        UPDATE recovery_codes SET used_at = NOW()
+        WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *twoFactorRepository) UseStep(userID int, step int64) (bool, error) {
+	res, err := r.db.Exec(`
+        UPDATE users SET totp_last_step = $2
+        WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *twoFactorRepository) CreateChallenge(userID int, tokenHash string, ttl time.Duration) error {
+	_, err := r.db.Exec(`
+        INSERT INTO login_challenges (user_id, token_hash, expires_at)
+        VALUES ($1, $2, NOW() + make_interval(secs => $3))`, userID, tokenHash, ttl.Seconds())
+	return err
+}
+
+func (r *twoFactorRepository) GetChallenge(tokenHash string, maxAttempts int) (int, error) {
+	var userID int
+	err := r.db.QueryRow(`
+        SELECT user_id FROM login_challenges
+        WHERE token_hash = $1 AND expires_at > NOW() AND percobaan < $2`, tokenHash, maxAttempts).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrChallengeNotFound
+	}
+	return userID, err
+}
+
+func (r *twoFactorRepository) FailChallenge(tokenHash string) error {
+	_, err := r.db.Exec(`UPDATE login_challenges SET percobaan = percobaan + 1 WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+func (r *twoFactorRepository) DeleteChallenge(tokenHash string) error {
+	// Challenge kedaluwarsa ikut dibersihkan di sini agar tabel tidak menumpuk
+	_, err := r.db.Exec(`DELETE FROM login_challenges WHERE token_hash = $1 OR expires_at < NOW()`, tokenHash)
+	return err
+}
@@ -1,15 +1,28 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"warehouse-api/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound = errors.New("pengguna tidak ditemukan")
+	ErrEmailExists  = errors.New("email sudah dipakai pengguna lain")
 )
 
 type UserRepository interface {
 	GetByUsername(username string) (*models.User, error)
 	GetByID(id int) (*models.User, error)
 	GetAll() ([]models.User, error)
-	Create(user *models.User) error
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	SetActive(ctx context.Context, id int, active bool) error
+	GetPasswordHash(id int) (string, error)
+	UpdatePassword(ctx context.Context, id int, hash string) error
 }
 
 type userRepository struct {
@@ -20,8 +33,9 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db}
 }
 
+// GetAll tidak menyertakan service principal API key (lihat api_key_repo.go)
 func (r *userRepository) GetAll() ([]models.User, error) {
-	query := `SELECT id, username, email, full_name, role FROM users ORDER BY id DESC`
+	query := `SELECT id, username, email, full_name, role, is_active, totp_enabled FROM users WHERE NOT is_service ORDER BY id DESC`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -31,7 +45,7 @@ func (r *userRepository) GetAll() ([]models.User, error) {
 	var users []models.User
 	for rows.Next() {
 		var user models.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.FullName, &user.Role); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.FullName, &user.Role, &user.IsActive, &user.TwoFactorEnabled); err != nil {
 			return nil, err
 		}
 		users = append(users, user)
@@ -39,17 +53,34 @@ func (r *userRepository) GetAll() ([]models.User, error) {
 	return users, nil
 }
 
-func (r *userRepository) Create(user *models.User) error {
-	query := `INSERT INTO users (username, password, email, full_name, role) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	return r.db.QueryRow(query, user.Username, user.Password, user.Email, user.FullName, user.Role).Scan(&user.ID)
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO users (username, password, email, full_name, role) VALUES ($1, $2, $3, $4, $5) RETURNING id, is_active`
+	if err := tx.QueryRow(query, user.Username, user.Password, user.Email, user.FullName, user.Role).Scan(&user.ID, &user.IsActive); err != nil {
+		return err
+	}
+	after, err := userForAudit(tx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditCreate, models.AuditUser, user.ID, nil, after); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// GetByUsername dipakai login; service principal tidak bisa login sehingga tidak ikut dicari
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
-	query := `SELECT id, username, password, email, full_name, role FROM users WHERE username = $1`
+	query := `SELECT id, username, password, email, full_name, role, is_active, totp_enabled FROM users WHERE username = $1 AND NOT is_service`
 	row := r.db.QueryRow(query, username)
 
 	var user models.User
-	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.FullName, &user.Role)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.FullName, &user.Role, &user.IsActive, &user.TwoFactorEnabled)
 	if err != nil {
 		return nil, err
 	}
@@ -57,11 +88,138 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 }
 
 func (r *userRepository) GetByID(id int) (*models.User, error) {
-	query := `SELECT id, username, email, full_name, role FROM users WHERE id = $1`
+	query := `SELECT id, username, email, full_name, role, is_active, totp_enabled FROM users WHERE id = $1`
 	row := r.db.QueryRow(query, id)
 
 	var user models.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.FullName, &user.Role)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.FullName, &user.Role, &user.IsActive, &user.TwoFactorEnabled)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update mengubah email, nama lengkap dan role; username tidak bisa diubah.
+// Principal API key (is_service) tidak bisa diubah lewat sini dan dianggap tidak ada.
+// Claim role dan vis ada di access token, jadi pergantian role mencabut semua sesi di tx yang sama.
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	return r.auditChange(ctx, user.ID, func(tx *sql.Tx) error {
+		var role string
+		if err := tx.QueryRow(`SELECT role FROM users WHERE id = $1`, user.ID).Scan(&role); err != nil {
+			return err
+		}
+		query := `
+        UPDATE users SET email = $1, full_name = $2, role = $3, updated_at = NOW()
+        WHERE id = $4 AND NOT is_service
+        RETURNING username, is_active, totp_enabled`
+		err := tx.QueryRow(query, user.Email, user.FullName, user.Role, user.ID).Scan(&user.Username, &user.IsActive, &user.TwoFactorEnabled)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrEmailExists
+		}
+		if err != nil || user.Role == role {
+			return err
+		}
+		return revokeSessions(ctx, tx, user.ID)
+	})
+}
+
+// SetActive mengaktifkan/menonaktifkan login user; menonaktifkan juga mencabut semua sesinya.
+// Principal API key dicabut lewat API key-nya.
+func (r *userRepository) SetActive(ctx context.Context, id int, active bool) error {
+	return r.auditChange(ctx, id, func(tx *sql.Tx) error {
+		res, err := tx.Exec(`UPDATE users SET is_active = $1, updated_at = NOW() WHERE id = $2 AND NOT is_service`, active, id)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrUserNotFound
+		}
+		if active {
+			return nil
+		}
+		return revokeSessions(ctx, tx, id)
+	})
+}
+
+func (r *userRepository) GetPasswordHash(id int) (string, error) {
+	var hash string
+	err := r.db.QueryRow(`SELECT password FROM users WHERE id = $1`, id).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
+	return hash, err
+}
+
+// UpdatePassword mengganti password dan mencabut semua sesi user di tx yang sama
+func (r *userRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2 AND NOT is_service`, hash, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	// Hash password tidak pernah masuk audit log; yang dicatat hanya bahwa password diganti
+	err = WriteAudit(ctx, tx, models.AuditUpdate, models.AuditUser, id,
+		map[string]bool{"password_diubah": false}, map[string]bool{"password_diubah": true})
+	if err != nil {
+		return err
+	}
+	if err := revokeSessions(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// auditChange menjalankan fn di tx dan mencatat keadaan user sebelum dan sesudahnya.
+// ErrUserNotFound dikembalikan bila user tidak ada.
+func (r *userRepository) auditChange(ctx context.Context, id int, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := userForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ErrUserNotFound
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	after, err := userForAudit(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := WriteAudit(ctx, tx, models.AuditUpdate, models.AuditUser, id, before, after); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// userForAudit membaca user di dalam tx (dikunci) tanpa password; nil bila tidak ada
+func userForAudit(tx *sql.Tx, id int) (*models.User, error) {
+	var user models.User
+	err := tx.QueryRow(`
+        SELECT id, username, email, full_name, role, is_active, totp_enabled, created_at, updated_at
+        FROM users WHERE id = $1 FOR UPDATE`, id).
+		Scan(&user.ID, &user.Username, &user.Email, &user.FullName, &user.Role, &user.IsActive, &user.TwoFactorEnabled,
+			&user.CreatedAt, &user.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
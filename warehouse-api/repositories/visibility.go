@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"warehouse-api/ctxkeys"
+	"warehouse-api/models"
+)
+
+// VisibleTo mengembalikan pengguna yang datanya boleh dibaca request. restricted false hanya untuk
+// token/API key dengan visibilitas all dan pekerjaan internal (ctxkeys.WithSystem: CLI, scheduler,
+// seeder). Context tanpa visibilitas selalu dibatasi ke pelakunya (user 0 bila tidak ada), jadi
+// pemanggil yang lupa meneruskan context request tidak membuka data pengguna lain.
+func VisibleTo(ctx context.Context) (userID int, restricted bool) {
+	if ctxkeys.IsSystem(ctx) {
+		return 0, false
+	}
+	if visibility, _ := ctxkeys.Visibility(ctx); visibility == models.VisibilityAll {
+		return 0, false
+	}
+	return ctxkeys.UserID(ctx), true
+}
+
+// transaksiVisibleCond membatasi jual_header/beli_header (alias h) ke transaksi milik pengguna
+// atau yang dibagikan kepadanya. Placeholder $%[1]d diisi nomor argumen user ID.
+func transaksiVisibleCond(jenis string) string {
+	return fmt.Sprintf(`(h.user_id = $%%[1]d OR EXISTS (
+            SELECT 1 FROM transaksi_share s
+            WHERE s.jenis = '%s' AND s.transaksi_id = h.id AND s.user_id = $%%[1]d))`, jenis)
+}
+
+// historyVisibleCond membatasi history_stok (alias h) ke baris yang dicatat pengguna atau yang berasal
+// dari transaksi yang dibagikan kepadanya (lewat jual_header_id / beli_header_id).
+const historyVisibleCond = `(h.user_id = $%[1]d OR EXISTS (
+            SELECT 1 FROM transaksi_share s
+            WHERE s.user_id = $%[1]d AND s.jenis = 'penjualan' AND s.transaksi_id = h.jual_header_id
+        ) OR EXISTS (
+            SELECT 1 FROM transaksi_share s
+            WHERE s.user_id = $%[1]d AND s.jenis = 'pembelian' AND s.transaksi_id = h.beli_header_id
+        ))`
+
+// transaksiByIDWhere adalah klausa WHERE GetByID; transaksi di luar visibilitas tidak ditemukan (sql.ErrNoRows)
+func transaksiByIDWhere(ctx context.Context, jenis string, id int) (string, []interface{}) {
+	where, args := "WHERE h.id = $1", []interface{}{id}
+	if userID, restricted := VisibleTo(ctx); restricted {
+		args = append(args, userID)
+		where += " AND " + fmt.Sprintf(transaksiVisibleCond(jenis), len(args))
+	}
+	return where, args
+}
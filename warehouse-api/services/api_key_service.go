@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+)
+
+var (
+	ErrAPIKeyNamaKosong    = errors.New("nama API key wajib diisi")
+	ErrAPIKeyScopeKosong   = errors.New("API key harus memiliki minimal satu permission")
+	ErrAPIKeyMasaBerlaku   = errors.New("masa berlaku API key harus 0 (tanpa batas) sampai 3650 hari")
+	ErrAPIKeyScopeDitolak  = errors.New("tidak bisa memberikan permission yang tidak Anda miliki")
+	ErrAPIKeyScopeTidakAda = errors.New("permission tidak dikenal")
+)
+
+// apiKeyPrefix menandai key milik aplikasi ini agar mudah dikenali (mis. oleh secret scanner)
+const apiKeyPrefix = "wh_"
+
+const maxAPIKeyDays = 3650
+
+// ScopeChecker dipakai APIKeyService untuk memastikan scope key terdaftar dan dimiliki pembuatnya
+type ScopeChecker interface {
+	GetPermissions() ([]models.Permission, error)
+	HasPermission(role, permission string) (bool, error)
+}
+
+// APIKeyService mengelola API key integrasi. Setiap key berjalan sebagai service principal sendiri
+// dengan hak akses sebatas permission (scope) yang diberikan saat key dibuat.
+type APIKeyService interface {
+	Create(ctx context.Context, creatorID int, creatorRole string, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error)
+	GetAll() ([]models.APIKey, error)
+	Revoke(ctx context.Context, id int) error
+	// Authenticate mengembalikan principal pemilik key, atau nil bila key tidak valid, dicabut atau kedaluwarsa
+	Authenticate(key string) (*models.APIKeyPrincipal, error)
+}
+
+type apiKeyService struct {
+	repo   repositories.APIKeyRepository
+	scopes ScopeChecker
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepository, scopes ScopeChecker) APIKeyService {
+	return &apiKeyService{repo: repo, scopes: scopes}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, creatorID int, creatorRole string, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	nama := strings.TrimSpace(req.Nama)
+	if nama == "" {
+		return nil, ErrAPIKeyNamaKosong
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAPIKeyDays {
+		return nil, ErrAPIKeyMasaBerlaku
+	}
+	permissions := normalizePermissions(req.Permissions)
+	if len(permissions) == 0 {
+		return nil, ErrAPIKeyScopeKosong
+	}
+	if err := s.checkScopes(creatorRole, permissions); err != nil {
+		return nil, err
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, _, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	prefix := apiKeyPrefix + id
+	key := prefix + "_" + secret
+
+	apiKey := &models.APIKey{
+		Nama:        nama,
+		Prefix:      prefix,
+		Username:    "apikey-" + id,
+		Permissions: permissions,
+		CreatedBy:   creatorID,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(ctx, apiKey, HashToken(key)); err != nil {
+		return nil, err
+	}
+	return &models.CreateAPIKeyResponse{APIKey: *apiKey, Key: key}, nil
+}
+
+// checkScopes menolak permission yang tidak ada di katalog atau tidak dimiliki role pembuat key
+func (s *apiKeyService) checkScopes(creatorRole string, permissions []string) error {
+	catalog, err := s.scopes.GetPermissions()
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, p := range catalog {
+		known[p.Kode] = true
+	}
+	for _, p := range permissions {
+		if !known[p] {
+			return fmt.Errorf("%w: %s", ErrAPIKeyScopeTidakAda, p)
+		}
+		allowed, err := s.scopes.HasPermission(creatorRole, p)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s", ErrAPIKeyScopeDitolak, p)
+		}
+	}
+	return nil
+}
+
+func (s *apiKeyService) GetAll() ([]models.APIKey, error) {
+	return s.repo.GetAll()
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id int) error {
+	return s.repo.Revoke(ctx, id)
+}
+
+func (s *apiKeyService) Authenticate(key string) (*models.APIKeyPrincipal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, nil
+	}
+	apiKey, err := s.repo.GetActiveByHash(HashToken(key))
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// last_used_at hanya informasi; kegagalannya tidak boleh menolak request
+	if err := s.repo.Touch(apiKey.ID); err != nil {
+		log.Printf("gagal memperbarui last_used_at API key %d: %v", apiKey.ID, err)
+	}
+	return &models.APIKeyPrincipal{KeyID: apiKey.ID, UserID: apiKey.UserID, Permissions: apiKey.Permissions}, nil
+}
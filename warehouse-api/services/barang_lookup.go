@@ -0,0 +1,60 @@
+package services
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "slices"
+    "warehouse-api/repositories"
+    "warehouse-api/utils"
+)
+
+// Kesalahan input pada detail transaksi; handler membalasnya dengan 400
+var (
+    ErrBarangWajib          = errors.New("barang_id atau barcode wajib diisi")
+    ErrBarangTidakDitemukan = errors.New("barang tidak ditemukan")
+    ErrBarangDiarsipkan     = errors.New("barang sudah diarsipkan dan tidak bisa ditransaksikan")
+    ErrStokTidakCukup       = errors.New("stok tidak mencukupi")
+    ErrBarcodeBedaBarang    = errors.New("barcode bukan milik barang_id yang dikirim")
+)
+
+// resolveBarangID mengembalikan barang_id dari detail transaksi.
+// Jika barang_id kosong, barang dicari berdasarkan barcode (alur scanner). Jika keduanya dikirim,
+// barcode harus milik barang tersebut.
+func resolveBarangID(barangRepo repositories.BarangRepository, barangID int, barcode string) (int, error) {
+    if barcode == "" {
+        if barangID == 0 {
+            return 0, ErrBarangWajib
+        }
+        return barangID, nil
+    }
+
+    barang, err := barangRepo.GetByBarcode(utils.NormalizeBarcode(barcode))
+    if err != nil || barang == nil {
+        return 0, fmt.Errorf("%w: barcode %s", ErrBarangTidakDitemukan, barcode)
+    }
+    if barangID != 0 && barang.ID != barangID {
+        return 0, fmt.Errorf("%w: barcode %s milik barang ID %d, bukan %d", ErrBarcodeBedaBarang, barcode, barang.ID, barangID)
+    }
+    return barang.ID, nil
+}
+
+// lockActiveBarang mengunci barang di detail transaksi (urut id agar dua transaksi tidak saling
+// menunggu) lalu memeriksa ulang is_active, karena barang bisa diarsipkan setelah validasi awal
+func lockActiveBarang(tx *sql.Tx, barangRepo repositories.BarangRepository, ids []int) error {
+    ids = slices.Clone(ids)
+    slices.Sort(ids)
+    for _, id := range slices.Compact(ids) {
+        active, err := barangRepo.LockActive(tx, id)
+        if errors.Is(err, sql.ErrNoRows) {
+            return fmt.Errorf("%w: barang ID %d", ErrBarangTidakDitemukan, id)
+        }
+        if err != nil {
+            return fmt.Errorf("gagal mengunci barang ID %d: %v", id, err)
+        }
+        if !active {
+            return fmt.Errorf("%w: barang ID %d", ErrBarangDiarsipkan, id)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"crypto/hmac"
+	"errors"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+)
+
+// errChainBroken menghentikan pembacaan riwayat begitu link rusak pertama ditemukan
+var errChainBroken = errors.New("hash chain rusak")
+
+// VerifyHistoryChain menelusuri hash chain history_stok per barang (barangID 0 = semua barang)
+// dan melaporkan link rusak pertama: isi baris yang tidak cocok dengan hash-nya, prev_hash yang
+// tidak sama dengan hash baris sebelumnya (baris dihapus/disisipkan), rantai yang tidak cocok
+// dengan anchor-nya (baris terakhir dihapus, anchor dipalsukan atau dihapus), atau baris tanpa
+// hash di barang yang sudah di-seal. Riwayat tanpa anchor hanya dihitung di BelumDiseal selama
+// barangnya tercatat belum di-seal dan penanda seal belum ada.
+func VerifyHistoryChain(repo repositories.StokRepository, barangID int) (*models.HistoryChainReport, error) {
+	report := &models.HistoryChainReport{}
+	anchors, seal, err := repo.HistoryChainAnchors(barangID)
+	if err != nil {
+		return nil, err
+	}
+	if seal != nil && !hmac.Equal([]byte(repositories.HistorySealSignature(seal.SealedAt)), []byte(seal.Signature)) {
+		report.Rusak = &models.HistoryChainBreak{Alasan: "Penanda seal riwayat stok tidak valid (dipalsukan)"}
+		return report, nil
+	}
+
+	broken := func(id, barang int, alasan string) error {
+		report.Rusak = &models.HistoryChainBreak{HistoryID: id, BarangID: barang, Alasan: alasan}
+		return errChainBroken
+	}
+
+	var (
+		currentBarang int
+		anchor        *models.HistoryChainAnchor
+		prev          models.HistoryStok
+		baris         int
+	)
+	visited := map[int]bool{}
+	// endBarang mencocokkan rantai barang yang selesai dibaca dengan anchor-nya
+	endBarang := func() error {
+		if anchor == nil {
+			return nil
+		}
+		if baris != anchor.Baris || prev.ID != anchor.LastID || prev.Hash != anchor.LastHash {
+			return broken(prev.ID, currentBarang, "Rantai tidak cocok dengan anchor (baris terakhir dihapus atau disisipkan)")
+		}
+		return nil
+	}
+
+	err = repo.EachHistoryChain(barangID, func(h models.HistoryStok, sealed bool) error {
+		if h.BarangID != currentBarang {
+			if err := endBarang(); err != nil {
+				return err
+			}
+			currentBarang, prev, baris, anchor = h.BarangID, models.HistoryStok{}, 0, nil
+			visited[h.BarangID] = true
+			report.Barang++
+			if a, ok := anchors[h.BarangID]; ok {
+				anchor = &a
+				if !hmac.Equal([]byte(repositories.HistoryAnchorSignature(a)), []byte(a.Signature)) {
+					return broken(h.ID, h.BarangID, "Anchor rantai tidak valid (dipalsukan)")
+				}
+			}
+		}
+		report.Baris++
+		baris++
+
+		if anchor == nil {
+			if seal == nil && !sealed && h.Hash == "" {
+				report.BelumDiseal++
+				return nil
+			}
+			return broken(h.ID, h.BarangID, "Riwayat tanpa anchor pada barang yang sudah di-seal (anchor atau penanda seal dihapus)")
+		}
+		if !sealed {
+			return broken(h.ID, h.BarangID, "Barang yang sudah di-seal ditandai belum di-seal (penanda seal diubah)")
+		}
+		if h.Hash == "" {
+			return broken(h.ID, h.BarangID, "Hash kosong pada barang yang sudah di-seal")
+		}
+		if repositories.HistoryHash(h, h.PrevHash) != h.Hash {
+			return broken(h.ID, h.BarangID, "Isi baris tidak cocok dengan hash (baris diubah)")
+		}
+		if h.PrevHash != prev.Hash {
+			return broken(h.ID, h.BarangID, "prev_hash tidak cocok dengan baris sebelumnya (baris dihapus, disisipkan atau diubah)")
+		}
+		prev = h
+		return nil
+	})
+	if err == nil {
+		err = endBarang()
+	}
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+
+	// Anchor tanpa satu pun baris riwayat berarti seluruh riwayat barang itu dihapus
+	if report.Rusak == nil {
+		for id, a := range anchors {
+			if !visited[id] && (report.Rusak == nil || id < report.Rusak.BarangID) {
+				report.Rusak = &models.HistoryChainBreak{HistoryID: a.LastID, BarangID: id, Alasan: "Seluruh riwayat barang dihapus (anchor masih ada)"}
+			}
+		}
+	}
+
+	report.Valid = report.Rusak == nil
+	return report, nil
+}
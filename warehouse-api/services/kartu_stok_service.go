@@ -0,0 +1,156 @@
+package services
+
+import (
+    "context"
+    "math"
+    "time"
+    "warehouse-api/models"
+    "warehouse-api/repositories"
+)
+
+type KartuStokService interface {
+    Get(ctx context.Context, barangID int, startDate, endDate string) (*models.KartuStok, error)
+}
+
+type kartuStokService struct {
+    stokRepo   repositories.StokRepository
+    barangRepo repositories.BarangRepository
+}
+
+func NewKartuStokService(stokRepo repositories.StokRepository, barangRepo repositories.BarangRepository) KartuStokService {
+    return &kartuStokService{stokRepo, barangRepo}
+}
+
+// Get menyusun kartu stok barang untuk periode startDate s/d endDate (format YYYY-MM-DD, inklusif).
+// Seluruh riwayat sebelum startDate tetap dibaca agar saldo awal dan harga rata-rata benar.
+// Mutasi di luar visibilitas pengguna ikut menggerakkan saldo tapi tidak muncul sebagai baris
+// dan tidak dihitung di total masuk/keluar.
+func (s *kartuStokService) Get(ctx context.Context, barangID int, startDate, endDate string) (*models.KartuStok, error) {
+    barang, err := s.barangRepo.GetByID(barangID)
+    if err != nil {
+        return nil, err
+    }
+
+    var start time.Time
+    if startDate != "" {
+        if start, err = time.Parse("2006-01-02", startDate); err != nil {
+            return nil, err
+        }
+    }
+
+    kartu := &models.KartuStok{
+        Barang:    &barang.Barang,
+        StartDate: startDate,
+        EndDate:   endDate,
+        Entries:   []models.KartuStokEntry{},
+    }
+    v := &stokValuation{}
+    first := true
+    inPeriod := false
+
+    err = s.stokRepo.EachMovement(ctx, barangID, endDate, func(m models.StokMovement) error {
+        if first {
+            // Stok yang sudah ada sebelum riwayat pertama (mis. data lama tanpa riwayat) tidak diketahui nilainya
+            v.start(m.StokSebelum)
+            first = false
+        }
+        if !inPeriod && !m.CreatedAt.Before(start) {
+            inPeriod = true
+            kartu.SaldoAwal, kartu.NilaiSaldoAwal = v.qty, v.valuePtr()
+        }
+
+        delta := m.StokSesudah - m.StokSebelum
+        harga := v.apply(delta, m.HargaSatuan)
+        if !inPeriod || m.Hidden {
+            return nil
+        }
+
+        entry := models.KartuStokEntry{
+            HistoryID:      m.ID,
+            Tanggal:        m.CreatedAt,
+            JenisTransaksi: m.JenisTransaksi,
+            Referensi:      m.Referensi,
+            Keterangan:     m.Keterangan,
+            Petugas:        m.Petugas,
+            Saldo:          v.qty,
+            HargaSatuan:    harga,
+            NilaiSaldo:     v.valuePtr(),
+        }
+        if delta >= 0 {
+            entry.Masuk = delta
+        } else {
+            entry.Keluar = -delta
+        }
+        if harga != nil {
+            nilai := roundRupiah(math.Abs(float64(delta)) * *harga)
+            entry.Nilai = &nilai
+        }
+        kartu.TotalMasuk += entry.Masuk
+        kartu.TotalKeluar += entry.Keluar
+        kartu.Entries = append(kartu.Entries, entry)
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if !inPeriod {
+        // Tidak ada mutasi dalam periode, saldo awal sama dengan saldo akhir
+        kartu.SaldoAwal, kartu.NilaiSaldoAwal = v.qty, v.valuePtr()
+    }
+    kartu.SaldoAkhir, kartu.NilaiSaldoAkhir = v.qty, v.valuePtr()
+    return kartu, nil
+}
+
+// stokValuation menghitung saldo dan nilai persediaan dengan metode rata-rata bergerak
+type stokValuation struct {
+    qty   int
+    value float64
+    known bool
+}
+
+func (v *stokValuation) start(qty int) {
+    v.qty, v.value, v.known = qty, 0, qty == 0
+}
+
+// apply membukukan satu mutasi dan mengembalikan harga per unit yang dipakai (nil jika tidak diketahui)
+func (v *stokValuation) apply(delta int, cost *float64) *float64 {
+    var harga *float64
+    switch {
+    case delta > 0:
+        if cost == nil {
+            v.known = false
+        } else {
+            harga = cost
+            v.value += float64(delta) * *cost
+        }
+    case delta < 0:
+        if v.known && v.qty > 0 {
+            avg := v.value / float64(v.qty)
+            v.value += float64(delta) * avg
+            display := roundRupiah(avg)
+            harga = &display
+        } else {
+            v.known = false
+        }
+    }
+
+    v.qty += delta
+    if v.qty == 0 {
+        // Stok habis: nilai kembali pasti nol, sisa pembulatan dibuang
+        v.value, v.known = 0, true
+    }
+    return harga
+}
+
+func (v *stokValuation) valuePtr() *float64 {
+    if !v.known {
+        return nil
+    }
+    value := roundRupiah(v.value)
+    return &value
+}
+
+func roundRupiah(v float64) float64 {
+    return math.Round(v*100) / 100
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "math"
+    "sort"
+    "strings"
+    "warehouse-api/models"
+    "warehouse-api/repositories"
+    "warehouse-api/utils"
+)
+
+// ErrKelasTidakValid menandai filter kelas yang berisi huruf selain A/B/C atau X/Y/Z
+var ErrKelasTidakValid = errors.New("kelas tidak valid")
+
+type KlasifikasiService interface {
+    Run(params models.KlasifikasiParams) (*models.KlasifikasiReport, error)
+    Latest(ctx context.Context, filter models.KlasifikasiFilter) (*models.KlasifikasiReport, error)
+    CreateOpname(ctx context.Context, req models.CreateOpnameRequest, userID int) (*models.StokOpname, error)
+    GetOpname(id int) (*models.StokOpname, error)
+    ListOpname(limit, offset int) ([]models.StokOpname, int, error)
+}
+
+type klasifikasiService struct {
+    repo repositories.KlasifikasiRepository
+}
+
+func NewKlasifikasiService(repo repositories.KlasifikasiRepository) KlasifikasiService {
+    return &klasifikasiService{repo}
+}
+
+// Run menghitung klasifikasi semua barang aktif dan menyimpannya sebagai run baru
+func (s *klasifikasiService) Run(params models.KlasifikasiParams) (*models.KlasifikasiReport, error) {
+    demand, err := s.repo.Demand(params.StartDate, params.EndDate, params.Periode)
+    if err != nil {
+        return nil, err
+    }
+
+    var buckets []string
+    for _, b := range TimeBuckets(params.Periode, params.StartDate, params.EndDate) {
+        buckets = append(buckets, b.Format("2006-01-02"))
+    }
+    items := KlasifikasiABCXYZ(demand, buckets, params)
+
+    run := &models.KlasifikasiRun{
+        StartDate: params.StartDate,
+        EndDate:   params.EndDate,
+        Periode:   params.Periode,
+        BatasA:    params.BatasA,
+        BatasB:    params.BatasB,
+        BatasX:    params.BatasX,
+        BatasY:    params.BatasY,
+    }
+    if params.UserID > 0 {
+        run.UserID = &params.UserID
+    }
+    if err := s.repo.SaveRun(run, items); err != nil {
+        return nil, err
+    }
+    return &models.KlasifikasiReport{Run: *run, Matriks: klasifikasiMatriks(items), Items: items}, nil
+}
+
+// Latest mengembalikan run terakhir. Matriks selalu dihitung dari semua barang,
+// filter hanya membatasi daftar Items. Run dihitung dari seluruh penjualan, jadi pengguna yang
+// dibatasi visibilitasnya (VisibleTo) hanya menerima kelas tanpa angka.
+func (s *klasifikasiService) Latest(ctx context.Context, filter models.KlasifikasiFilter) (*models.KlasifikasiReport, error) {
+    if err := normalizeKelasFilter(&filter); err != nil {
+        return nil, err
+    }
+    run, err := s.repo.GetLatestRun()
+    if err != nil {
+        return nil, err
+    }
+    items, err := s.repo.GetItems(run.ID)
+    if err != nil {
+        return nil, err
+    }
+
+    report := &models.KlasifikasiReport{Run: *run, Matriks: klasifikasiMatriks(items), Items: []models.BarangKlasifikasi{}}
+    for _, it := range items {
+        if matchKelas(filter.KelasABC, it.KelasABC) && matchKelas(filter.KelasXYZ, it.KelasXYZ) {
+            report.Items = append(report.Items, it)
+        }
+    }
+    if _, restricted := repositories.VisibleTo(ctx); restricted {
+        hideKlasifikasiAngka(report)
+    }
+    return report, nil
+}
+
+// hideKlasifikasiAngka mengosongkan angka yang berasal dari penjualan semua pengguna
+func hideKlasifikasiAngka(report *models.KlasifikasiReport) {
+    report.Terbatas = true
+    for i := range report.Matriks {
+        report.Matriks[i].Nilai = 0
+    }
+    for i := range report.Items {
+        it := &report.Items[i]
+        it.Nilai, it.Kontribusi, it.Kumulatif, it.Qty, it.Frekuensi, it.CV = 0, 0, 0, 0, 0, nil
+    }
+}
+
+// CreateOpname membuat sesi stock opname dari barang pada kelas terpilih di run klasifikasi terakhir
+func (s *klasifikasiService) CreateOpname(ctx context.Context, req models.CreateOpnameRequest, userID int) (*models.StokOpname, error) {
+    filter := models.KlasifikasiFilter{KelasABC: req.KelasABC, KelasXYZ: req.KelasXYZ}
+    if err := normalizeKelasFilter(&filter); err != nil {
+        return nil, err
+    }
+    run, err := s.repo.GetLatestRun()
+    if err != nil {
+        return nil, err
+    }
+
+    opname := &models.StokOpname{
+        NoOpname:         utils.GenerateCode("OPN"),
+        KlasifikasiRunID: &run.ID,
+        KelasABC:         filter.KelasABC,
+        KelasXYZ:         filter.KelasXYZ,
+        Keterangan:       strings.TrimSpace(req.Keterangan),
+        Status:           "draft",
+    }
+    if userID > 0 {
+        opname.UserID = &userID
+    }
+    if err := s.repo.CreateOpname(ctx, opname); err != nil {
+        return nil, err
+    }
+    return s.repo.GetOpnameByID(opname.ID)
+}
+
+func (s *klasifikasiService) GetOpname(id int) (*models.StokOpname, error) {
+    return s.repo.GetOpnameByID(id)
+}
+
+func (s *klasifikasiService) ListOpname(limit, offset int) ([]models.StokOpname, int, error) {
+    return s.repo.GetAllOpname(limit, offset)
+}
+
+// KlasifikasiABCXYZ mengelompokkan barang.
+// ABC: barang diurutkan dari nilai penjualan terbesar; barang masuk A selama kumulatif kontribusi
+// sebelum barang tersebut masih di bawah BatasA, B di bawah BatasB, sisanya C. Barang tanpa penjualan selalu C.
+// XYZ: koefisien variasi (simpangan baku / rata-rata) qty per periode, termasuk periode tanpa penjualan;
+// X jika <= BatasX, Y jika <= BatasY, selain itu Z. Barang tanpa penjualan masuk Z dengan CV nil.
+func KlasifikasiABCXYZ(demand []models.BarangDemand, buckets []string, params models.KlasifikasiParams) []models.BarangKlasifikasi {
+    sorted := make([]models.BarangDemand, len(demand))
+    copy(sorted, demand)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        if sorted[i].Nilai != sorted[j].Nilai {
+            return sorted[i].Nilai > sorted[j].Nilai
+        }
+        return sorted[i].KodeBarang < sorted[j].KodeBarang
+    })
+
+    var total float64
+    for _, d := range sorted {
+        total += d.Nilai
+    }
+
+    items := make([]models.BarangKlasifikasi, 0, len(sorted))
+    var kumulatif float64
+    for _, d := range sorted {
+        it := models.BarangKlasifikasi{
+            BarangID:   d.BarangID,
+            KodeBarang: d.KodeBarang,
+            NamaBarang: d.NamaBarang,
+            Nilai:      d.Nilai,
+            Qty:        d.Qty,
+            Frekuensi:  d.Frekuensi,
+            KelasABC:   "C",
+        }
+        if total > 0 && d.Nilai > 0 {
+            switch {
+            case kumulatif < params.BatasA:
+                it.KelasABC = "A"
+            case kumulatif < params.BatasB:
+                it.KelasABC = "B"
+            }
+            it.Kontribusi = d.Nilai / total * 100
+            kumulatif += it.Kontribusi
+        }
+        it.Kontribusi = round4(it.Kontribusi)
+        it.Kumulatif = round4(kumulatif)
+
+        it.KelasXYZ = "Z"
+        if cv, ok := coefficientOfVariation(d.QtyPerPeriode, buckets); ok {
+            it.CV = &cv
+            switch {
+            case cv <= params.BatasX:
+                it.KelasXYZ = "X"
+            case cv <= params.BatasY:
+                it.KelasXYZ = "Y"
+            }
+        }
+        items = append(items, it)
+    }
+    return items
+}
+
+// coefficientOfVariation memakai simpangan baku populasi; false jika tidak ada permintaan sama sekali
+func coefficientOfVariation(qtyPerPeriode map[string]int, buckets []string) (float64, bool) {
+    if len(buckets) == 0 {
+        return 0, false
+    }
+    var sum float64
+    for _, b := range buckets {
+        sum += float64(qtyPerPeriode[b])
+    }
+    mean := sum / float64(len(buckets))
+    if mean <= 0 {
+        return 0, false
+    }
+    var variance float64
+    for _, b := range buckets {
+        diff := float64(qtyPerPeriode[b]) - mean
+        variance += diff * diff
+    }
+    variance /= float64(len(buckets))
+    return round4(math.Sqrt(variance) / mean), true
+}
+
+func klasifikasiMatriks(items []models.BarangKlasifikasi) []models.KlasifikasiSel {
+    var matriks []models.KlasifikasiSel
+    for _, abc := range "ABC" {
+        for _, xyz := range "XYZ" {
+            sel := models.KlasifikasiSel{KelasABC: string(abc), KelasXYZ: string(xyz)}
+            for _, it := range items {
+                if it.KelasABC == sel.KelasABC && it.KelasXYZ == sel.KelasXYZ {
+                    sel.JumlahBarang++
+                    sel.Nilai += it.Nilai
+                }
+            }
+            sel.Nilai = roundRupiah(sel.Nilai)
+            matriks = append(matriks, sel)
+        }
+    }
+    return matriks
+}
+
+// normalizeKelasFilter menyeragamkan filter kelas menjadi huruf besar terurut tanpa duplikat, mis. "ba" -> "AB"
+func normalizeKelasFilter(filter *models.KlasifikasiFilter) error {
+    var err error
+    if filter.KelasABC, err = normalizeKelas(filter.KelasABC, "ABC"); err != nil {
+        return err
+    }
+    filter.KelasXYZ, err = normalizeKelas(filter.KelasXYZ, "XYZ")
+    return err
+}
+
+func normalizeKelas(value, allowed string) (string, error) {
+    value = strings.ToUpper(strings.NewReplacer(",", "", " ", "").Replace(value))
+    for _, c := range value {
+        if !strings.ContainsRune(allowed, c) {
+            return "", ErrKelasTidakValid
+        }
+    }
+    normalized := ""
+    for _, c := range allowed {
+        if strings.ContainsRune(value, c) {
+            normalized += string(c)
+        }
+    }
+    return normalized, nil
+}
+
+func matchKelas(filter, kelas string) bool {
+    return filter == "" || strings.Contains(filter, kelas)
+}
+
+func round4(v float64) float64 {
+    return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"warehouse-api/config"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+)
+
+// LoginGuard melindungi login dari tebakan password: setelah MaxAttempts gagal berturut-turut
+// username dikunci sementara, dan lama kuncinya berlipat dua pada setiap gagal berikutnya.
+type LoginGuard interface {
+	// Check mengembalikan sisa waktu kunci username, 0 bila boleh mencoba login
+	Check(username string) (time.Duration, error)
+	Failed(attempt *models.LoginAttempt) error
+	Succeeded(username string) error
+	// Unlock dipakai admin untuk membuka kunci user sebelum waktunya
+	Unlock(ctx context.Context, userID int) error
+}
+
+type loginGuard struct {
+	repo  repositories.LoginAttemptRepository
+	users repositories.UserRepository
+	cfg   config.LoginLockout
+}
+
+func NewLoginGuard(repo repositories.LoginAttemptRepository, users repositories.UserRepository, cfg config.LoginLockout) LoginGuard {
+	return &loginGuard{repo: repo, users: users, cfg: cfg}
+}
+
+func (g *loginGuard) Check(username string) (time.Duration, error) {
+	return g.repo.LockedFor(username)
+}
+
+func (g *loginGuard) Failed(attempt *models.LoginAttempt) error {
+	count, err := g.repo.RecordFailure(attempt, g.cfg.Window)
+	if err != nil {
+		return err
+	}
+	if d := g.lockDuration(count); d > 0 {
+		return g.repo.Lock(attempt.Username, d)
+	}
+	return nil
+}
+
+func (g *loginGuard) Succeeded(username string) error {
+	return g.repo.Reset(username)
+}
+
+func (g *loginGuard) Unlock(ctx context.Context, userID int) error {
+	user, err := g.users.GetByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repositories.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return g.repo.Unlock(ctx, userID, user.Username)
+}
+
+// lockDuration: 0 sebelum batas, lalu Duration, 2x Duration, 4x Duration, ... maksimal MaxDuration
+func (g *loginGuard) lockDuration(failures int) time.Duration {
+	if failures < g.cfg.MaxAttempts {
+		return 0
+	}
+	d := g.cfg.Duration
+	for i := g.cfg.MaxAttempts; i < failures && d < g.cfg.MaxDuration; i++ {
+		d *= 2
+	}
+	if d > g.cfg.MaxDuration {
+		d = g.cfg.MaxDuration
+	}
+	return d
+}
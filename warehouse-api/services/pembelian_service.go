@@ -1,6 +1,7 @@
 package services
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "warehouse-api/models"
@@ -9,7 +10,7 @@ import (
 )
 
 type PembelianService interface {
-    Create(req models.CreatePembelianRequest) (*models.BeliHeader, error)
+    Create(ctx context.Context, req models.CreatePembelianRequest) (*models.BeliHeader, error)
 }
 
 type pembelianService struct {
@@ -23,19 +24,25 @@ func NewPembelianService(db *sql.DB, repo repositories.PembelianRepository, stok
     return &pembelianService{db, repo, stokRepo, barangRepo}
 }
 
-func (s *pembelianService) Create(req models.CreatePembelianRequest) (*models.BeliHeader, error) {
+func (s *pembelianService) Create(ctx context.Context, req models.CreatePembelianRequest) (*models.BeliHeader, error) {
     // 1. Input data pembelian - Validate barang exists
     var totalTrans float64
     var details []models.BeliDetail
 
     for _, d := range req.Details {
+        barangID, err := resolveBarangID(s.barangRepo, d.BarangID, d.Barcode)
+        if err != nil {
+            return nil, err
+        }
+        d.BarangID = barangID
+
         // Validasi: cek apakah barang exists
         barang, err := s.barangRepo.GetByID(d.BarangID)
-        if err != nil {
-            return nil, fmt.Errorf("barang ID %d tidak ditemukan: %v", d.BarangID, err)
+        if err != nil || barang == nil {
+            return nil, fmt.Errorf("%w: barang ID %d", ErrBarangTidakDitemukan, d.BarangID)
         }
-        if barang == nil {
-            return nil, fmt.Errorf("barang ID %d tidak ditemukan", d.BarangID)
+        if !barang.IsActive {
+            return nil, fmt.Errorf("%w: barang ID %d", ErrBarangDiarsipkan, d.BarangID)
         }
 
         // 2. Calculate total
@@ -57,6 +64,15 @@ func (s *pembelianService) Create(req models.CreatePembelianRequest) (*models.Be
     }
     defer tx.Rollback()
 
+    // Barang bisa diarsipkan request lain setelah validasi di atas
+    barangIDs := make([]int, 0, len(details))
+    for _, d := range details {
+        barangIDs = append(barangIDs, d.BarangID)
+    }
+    if err := lockActiveBarang(tx, s.barangRepo, barangIDs); err != nil {
+        return nil, err
+    }
+
     // Auto Generate No Faktur
     if req.NoFaktur == "" {
         req.NoFaktur = utils.GenerateNoFakturBeli(s.db)
@@ -70,7 +86,12 @@ func (s *pembelianService) Create(req models.CreatePembelianRequest) (*models.Be
         Status:   "selesai",
     }
 
-    // 3. Update Stok & 4. Record History (SEBELUM save transaction)
+    // 3. Save transaction (beli_header & beli_detail) - dulu agar riwayat stok bisa merujuk beli_header.id
+    if err := s.repo.Create(tx, header, details); err != nil {
+        return nil, fmt.Errorf("gagal membuat transaksi: %v", err)
+    }
+
+    // 4. Update Stok & 5. Record History
     for _, d := range details {
         // Ambil stok sebelum update dari DALAM transaksi untuk consistency
         currentStok, err := s.stokRepo.GetByBarangIDWithTx(tx, d.BarangID)
@@ -98,15 +119,17 @@ func (s *pembelianService) Create(req models.CreatePembelianRequest) (*models.Be
             StokSebelum:    stokSebelum,
             StokSesudah:    stokSesudah,
             Keterangan:     "Pembelian " + header.NoFaktur,
+            BeliHeaderID:   header.ID,
         }
         if err := s.stokRepo.CreateHistory(tx, history); err != nil {
              return nil, fmt.Errorf("gagal membuat riwayat stok: %v", err)
         }
     }
 
-    // 5. Save transaction (beli_header & beli_detail) - PALING AKHIR sebelum commit
-    if err := s.repo.Create(tx, header, details); err != nil {
-        return nil, fmt.Errorf("gagal membuat transaksi: %v", err)
+    audited := *header
+    audited.Details = details
+    if err := repositories.WriteAudit(ctx, tx, models.AuditCreate, models.AuditPembelian, header.ID, nil, audited); err != nil {
+        return nil, fmt.Errorf("gagal mencatat audit log: %v", err)
     }
 
     // Commit transaksi
@@ -1,6 +1,7 @@
 package services
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "warehouse-api/models"
@@ -9,7 +10,7 @@ import (
 )
 
 type PenjualanService interface {
-    Create(req models.CreatePenjualanRequest) (*models.JualHeader, error)
+    Create(ctx context.Context, req models.CreatePenjualanRequest) (*models.JualHeader, error)
 }
 
 type penjualanService struct {
@@ -23,25 +24,37 @@ func NewPenjualanService(db *sql.DB, repo repositories.PenjualanRepository, stok
     return &penjualanService{db, repo, stokRepo, barangRepo}
 }
 
-func (s *penjualanService) Create(req models.CreatePenjualanRequest) (*models.JualHeader, error) {
+func (s *penjualanService) Create(ctx context.Context, req models.CreatePenjualanRequest) (*models.JualHeader, error) {
     // 1. Input data penjualan - Validate barang exists & Check stock availability
     var totalTrans float64
     var details []models.JualDetail
     var stockData map[int]int = make(map[int]int) // barangID -> stokSebelum
 
     for _, d := range req.Details {
-        // Validasi: cek apakah barang exists dan dapatkan stok
-        currentStok, err := s.stokRepo.GetByBarangID(d.BarangID)
+        barangID, err := resolveBarangID(s.barangRepo, d.BarangID, d.Barcode)
         if err != nil {
-            return nil, fmt.Errorf("barang ID %d tidak ditemukan dalam stok", d.BarangID)
+            return nil, err
+        }
+        d.BarangID = barangID
+
+        // Validasi: barang yang diarsipkan tidak boleh dijual
+        barang, err := s.barangRepo.GetByID(d.BarangID)
+        if err != nil || barang == nil {
+            return nil, fmt.Errorf("%w: barang ID %d", ErrBarangTidakDitemukan, d.BarangID)
+        }
+        if !barang.IsActive {
+            return nil, fmt.Errorf("%w: barang ID %d", ErrBarangDiarsipkan, d.BarangID)
         }
-        if currentStok == nil {
-            return nil, fmt.Errorf("barang ID %d tidak ditemukan dalam stok", d.BarangID)
+
+        // Validasi: cek apakah barang exists dan dapatkan stok
+        currentStok, err := s.stokRepo.GetByBarangID(d.BarangID)
+        if err != nil || currentStok == nil {
+            return nil, fmt.Errorf("%w: barang ID %d belum memiliki stok", ErrStokTidakCukup, d.BarangID)
         }
         
         // Check stock availability
         if currentStok.StokAkhir < d.Qty {
-            return nil, fmt.Errorf("stok tidak mencukupi untuk Barang ID %d. Tersedia: %d, Diminta: %d", d.BarangID, currentStok.StokAkhir, d.Qty)
+            return nil, fmt.Errorf("%w untuk Barang ID %d. Tersedia: %d, Diminta: %d", ErrStokTidakCukup, d.BarangID, currentStok.StokAkhir, d.Qty)
         }
 
         stokSebelum := currentStok.StokAkhir
@@ -66,6 +79,15 @@ func (s *penjualanService) Create(req models.CreatePenjualanRequest) (*models.Ju
     }
     defer tx.Rollback()
 
+    // Barang bisa diarsipkan request lain setelah validasi di atas
+    barangIDs := make([]int, 0, len(details))
+    for _, d := range details {
+        barangIDs = append(barangIDs, d.BarangID)
+    }
+    if err := lockActiveBarang(tx, s.barangRepo, barangIDs); err != nil {
+        return nil, err
+    }
+
     // Auto Generate No Faktur
     if req.NoFaktur == "" {
         req.NoFaktur = utils.GenerateNoFakturJual(s.db)
@@ -79,7 +101,12 @@ func (s *penjualanService) Create(req models.CreatePenjualanRequest) (*models.Ju
         Status:   "selesai",
     }
 
-    // 3. Update Stok & 4. Record History (SEBELUM save transaction)
+    // 3. Save transaction (jual_header & jual_detail) - dulu agar riwayat stok bisa merujuk jual_header.id
+    if err := s.repo.Create(tx, header, details); err != nil {
+        return nil, fmt.Errorf("gagal membuat transaksi: %v", err)
+    }
+
+    // 4. Update Stok & 5. Record History
     for _, d := range details {
         // Ambil stok sebelum update dari DALAM transaksi untuk consistency
         currentStok, err := s.stokRepo.GetByBarangIDWithTx(tx, d.BarangID)
@@ -107,15 +134,17 @@ func (s *penjualanService) Create(req models.CreatePenjualanRequest) (*models.Ju
             StokSebelum:    stokSebelum,
             StokSesudah:    stokSesudah,
             Keterangan:     "Penjualan " + header.NoFaktur,
+            JualHeaderID:   header.ID,
         }
         if err := s.stokRepo.CreateHistory(tx, history); err != nil {
              return nil, fmt.Errorf("gagal membuat riwayat stok: %v", err)
         }
     }
 
-    // 5. Save transaction (jual_header & jual_detail) - PALING AKHIR sebelum commit
-    if err := s.repo.Create(tx, header, details); err != nil {
-        return nil, fmt.Errorf("gagal membuat transaksi: %v", err)
+    audited := *header
+    audited.Details = details
+    if err := repositories.WriteAudit(ctx, tx, models.AuditCreate, models.AuditPenjualan, header.ID, nil, audited); err != nil {
+        return nil, fmt.Errorf("gagal mencatat audit log: %v", err)
     }
 
     // Commit transaksi
@@ -0,0 +1,176 @@
+package services
+
+import (
+    "context"
+    "time"
+    "warehouse-api/models"
+    "warehouse-api/repositories"
+)
+
+type ReportService interface {
+    Get(ctx context.Context, q models.ReportQuery, compare bool) (*models.Report, error)
+}
+
+type reportService struct {
+    repo repositories.ReportRepository
+}
+
+func NewReportService(repo repositories.ReportRepository) ReportService {
+    return &reportService{repo}
+}
+
+// IsTimeGrouping menandai group_by yang menghasilkan seri waktu
+func IsTimeGrouping(groupBy string) bool {
+    return groupBy == "day" || groupBy == "week" || groupBy == "month"
+}
+
+// Get menyusun laporan. Seri waktu diisi nol untuk periode tanpa transaksi agar langsung bisa
+// dipakai grafik. Dengan compare, periode sebelumnya (panjang sama, tepat sebelum start_date)
+// ikut dihitung: untuk seri waktu dipasangkan menurut urutan periode, selain itu menurut key.
+// Angka hanya mencakup transaksi yang terlihat oleh pengguna request.
+func (s *reportService) Get(ctx context.Context, q models.ReportQuery, compare bool) (*models.Report, error) {
+    series, err := s.repo.Aggregate(ctx, q)
+    if err != nil {
+        return nil, err
+    }
+    summary, err := s.repo.Summary(ctx, q.Jenis, q.StartDate, q.EndDate)
+    if err != nil {
+        return nil, err
+    }
+
+    timeSeries := IsTimeGrouping(q.GroupBy)
+    if timeSeries {
+        series = fillTimeSeries(series, q.GroupBy, q.StartDate, q.EndDate)
+    }
+
+    report := &models.Report{
+        Jenis:     q.Jenis,
+        GroupBy:   q.GroupBy,
+        StartDate: q.StartDate,
+        EndDate:   q.EndDate,
+        Summary:   summary,
+        Series:    series,
+    }
+    if !compare {
+        return report, nil
+    }
+
+    prev := q
+    prev.StartDate, prev.EndDate = PreviousPeriod(q.StartDate, q.EndDate)
+    // Key di luar top-N periode ini tidak dibutuhkan, tapi top-N periode lalu bisa berbeda
+    prev.Limit = 0
+    prevSeries, err := s.repo.Aggregate(ctx, prev)
+    if err != nil {
+        return nil, err
+    }
+    prevSummary, err := s.repo.Summary(ctx, prev.Jenis, prev.StartDate, prev.EndDate)
+    if err != nil {
+        return nil, err
+    }
+
+    if timeSeries {
+        prevSeries = fillTimeSeries(prevSeries, prev.GroupBy, prev.StartDate, prev.EndDate)
+        for i := range report.Series {
+            values := models.ReportValues{}
+            if i < len(prevSeries) {
+                values = prevSeries[i].ReportValues
+            }
+            report.Series[i].Previous = &values
+        }
+    } else {
+        byKey := make(map[string]models.ReportValues, len(prevSeries))
+        for _, p := range prevSeries {
+            byKey[p.Key] = p.ReportValues
+        }
+        for i := range report.Series {
+            values := byKey[report.Series[i].Key]
+            report.Series[i].Previous = &values
+        }
+    }
+
+    report.Comparison = &models.ReportComparison{
+        StartDate: prev.StartDate,
+        EndDate:   prev.EndDate,
+        Summary:   prevSummary,
+        ChangePercent: models.ReportChange{
+            Total:           changePercent(summary.Total, prevSummary.Total),
+            Qty:             changePercent(float64(summary.Qty), float64(prevSummary.Qty)),
+            JumlahTransaksi: changePercent(float64(summary.JumlahTransaksi), float64(prevSummary.JumlahTransaksi)),
+        },
+    }
+    return report, nil
+}
+
+// PreviousPeriod mengembalikan rentang dengan jumlah hari yang sama tepat sebelum startDate
+func PreviousPeriod(startDate, endDate string) (string, string) {
+    start, _ := time.Parse("2006-01-02", startDate)
+    end, _ := time.Parse("2006-01-02", endDate)
+    days := int(end.Sub(start).Hours()/24) + 1
+    prevEnd := start.AddDate(0, 0, -1)
+    prevStart := start.AddDate(0, 0, -days)
+    return prevStart.Format("2006-01-02"), prevEnd.Format("2006-01-02")
+}
+
+// TimeBuckets menghitung awal setiap periode (day/week/month) yang beririsan dengan rentang.
+// Minggu dimulai hari Senin, sama dengan date_trunc('week') PostgreSQL.
+func TimeBuckets(groupBy, startDate, endDate string) []time.Time {
+    start, err1 := time.Parse("2006-01-02", startDate)
+    end, err2 := time.Parse("2006-01-02", endDate)
+    if err1 != nil || err2 != nil || end.Before(start) {
+        return nil
+    }
+
+    cur := start
+    switch groupBy {
+    case "week":
+        offset := (int(start.Weekday()) + 6) % 7 // Senin = 0
+        cur = start.AddDate(0, 0, -offset)
+    case "month":
+        cur = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+    }
+
+    var buckets []time.Time
+    for !cur.After(end) {
+        buckets = append(buckets, cur)
+        switch groupBy {
+        case "week":
+            cur = cur.AddDate(0, 0, 7)
+        case "month":
+            cur = cur.AddDate(0, 1, 0)
+        default:
+            cur = cur.AddDate(0, 0, 1)
+        }
+    }
+    return buckets
+}
+
+func fillTimeSeries(points []models.ReportPoint, groupBy, startDate, endDate string) []models.ReportPoint {
+    byKey := make(map[string]models.ReportPoint, len(points))
+    for _, p := range points {
+        byKey[p.Key] = p
+    }
+
+    buckets := TimeBuckets(groupBy, startDate, endDate)
+    filled := make([]models.ReportPoint, 0, len(buckets))
+    for _, b := range buckets {
+        key := b.Format("2006-01-02")
+        if p, ok := byKey[key]; ok {
+            filled = append(filled, p)
+            continue
+        }
+        label := key
+        if groupBy == "month" {
+            label = b.Format("2006-01")
+        }
+        filled = append(filled, models.ReportPoint{Key: key, Label: label})
+    }
+    return filled
+}
+
+func changePercent(current, previous float64) *float64 {
+    if previous == 0 {
+        return nil
+    }
+    change := roundRupiah((current - previous) / previous * 100)
+    return &change
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+    "warehouse-api/models"
+    "warehouse-api/repositories"
+)
+
+var (
+    ErrRoleNamaTidakValid = errors.New("nama role hanya boleh huruf kecil, angka, '_' atau '-' (2-50 karakter)")
+    ErrRoleAdminTetap     = errors.New("permission role admin tidak bisa diubah")
+)
+
+var roleNamaPattern = regexp.MustCompile(`^[a-z0-9_-]{2,50}$`)
+
+// RoleLookup dipakai UserService untuk memvalidasi role dan mengisi permission saat login
+type RoleLookup interface {
+    RoleExists(nama string) (bool, error)
+    Permissions(role string) ([]string, error)
+}
+
+type RoleService interface {
+    RoleLookup
+    GetAll() ([]models.Role, error)
+    GetPermissions() ([]models.Permission, error)
+    Create(ctx context.Context, req models.CreateRoleRequest) (*models.Role, error)
+    SetPermissions(ctx context.Context, nama string, permissions []string) error
+    Delete(ctx context.Context, nama string) error
+    HasPermission(role, permission string) (bool, error)
+}
+
+// roleService menyimpan peta role -> permission di memori agar middleware tidak query database
+// di setiap request. Cache dimuat ulang setelah perubahan lewat service ini atau setelah ttl habis
+// (untuk perubahan dari instance lain).
+type roleService struct {
+    repo repositories.RoleRepository
+    ttl  time.Duration
+
+    mu       sync.RWMutex
+    cache    map[string]map[string]bool
+    loadedAt time.Time
+}
+
+func NewRoleService(repo repositories.RoleRepository, ttl time.Duration) RoleService {
+    return &roleService{repo: repo, ttl: ttl}
+}
+
+func (s *roleService) permissionMap() (map[string]map[string]bool, error) {
+    s.mu.RLock()
+    cache, loadedAt := s.cache, s.loadedAt
+    s.mu.RUnlock()
+    if cache != nil && time.Since(loadedAt) < s.ttl {
+        return cache, nil
+    }
+
+    raw, err := s.repo.PermissionMap()
+    if err != nil {
+        return nil, err
+    }
+    cache = make(map[string]map[string]bool, len(raw))
+    for role, perms := range raw {
+        set := make(map[string]bool, len(perms))
+        for _, p := range perms {
+            set[p] = true
+        }
+        cache[role] = set
+    }
+
+    s.mu.Lock()
+    s.cache, s.loadedAt = cache, time.Now()
+    s.mu.Unlock()
+    return cache, nil
+}
+
+func (s *roleService) invalidate() {
+    s.mu.Lock()
+    s.cache = nil
+    s.mu.Unlock()
+}
+
+// HasPermission: role admin selalu diizinkan, role yang tidak terdaftar tidak punya permission apa pun
+func (s *roleService) HasPermission(role, permission string) (bool, error) {
+    if role == models.RoleAdmin {
+        return true, nil
+    }
+    cache, err := s.permissionMap()
+    if err != nil {
+        return false, err
+    }
+    return cache[role][permission], nil
+}
+
+func (s *roleService) RoleExists(nama string) (bool, error) {
+    cache, err := s.permissionMap()
+    if err != nil {
+        return false, err
+    }
+    if _, ok := cache[nama]; ok {
+        return true, nil
+    }
+    // Role bisa saja baru dibuat instance lain setelah cache dimuat
+    return s.repo.Exists(nama)
+}
+
+// Permissions mengembalikan daftar permission role secara terurut; admin mendapat semua permission
+func (s *roleService) Permissions(role string) ([]string, error) {
+    var perms []string
+    if role == models.RoleAdmin {
+        all, err := s.repo.GetPermissions()
+        if err != nil {
+            return nil, err
+        }
+        for _, p := range all {
+            perms = append(perms, p.Kode)
+        }
+        return perms, nil
+    }
+
+    cache, err := s.permissionMap()
+    if err != nil {
+        return nil, err
+    }
+    perms = []string{}
+    for p := range cache[role] {
+        perms = append(perms, p)
+    }
+    sort.Strings(perms)
+    return perms, nil
+}
+
+func (s *roleService) GetAll() ([]models.Role, error) {
+    return s.repo.GetAll()
+}
+
+func (s *roleService) GetPermissions() ([]models.Permission, error) {
+    return s.repo.GetPermissions()
+}
+
+func (s *roleService) Create(ctx context.Context, req models.CreateRoleRequest) (*models.Role, error) {
+    nama := strings.TrimSpace(req.Nama)
+    if !roleNamaPattern.MatchString(nama) {
+        return nil, ErrRoleNamaTidakValid
+    }
+    role := &models.Role{
+        Nama:        nama,
+        Deskripsi:   strings.TrimSpace(req.Deskripsi),
+        Permissions: normalizePermissions(req.Permissions),
+    }
+    if err := s.repo.Create(ctx, role); err != nil {
+        return nil, err
+    }
+    s.invalidate()
+    return role, nil
+}
+
+func (s *roleService) SetPermissions(ctx context.Context, nama string, permissions []string) error {
+    if nama == models.RoleAdmin {
+        return ErrRoleAdminTetap
+    }
+    if err := s.repo.SetPermissions(ctx, nama, normalizePermissions(permissions)); err != nil {
+        return err
+    }
+    s.invalidate()
+    return nil
+}
+
+func (s *roleService) Delete(ctx context.Context, nama string) error {
+    if err := s.repo.Delete(ctx, nama); err != nil {
+        return err
+    }
+    s.invalidate()
+    return nil
+}
+
+func normalizePermissions(permissions []string) []string {
+    seen := map[string]bool{}
+    normalized := []string{}
+    for _, p := range permissions {
+        p = strings.TrimSpace(p)
+        if p == "" || seen[p] {
+            continue
+        }
+        seen[p] = true
+        normalized = append(normalized, p)
+    }
+    sort.Strings(normalized)
+    return normalized
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+    "warehouse-api/documents"
+    "warehouse-api/models"
+    "warehouse-api/repositories"
+)
+
+// ErrSaldoAwalFile menandai file saldo awal yang tidak bisa diproses sama sekali (bukan error per baris)
+var ErrSaldoAwalFile = errors.New("file saldo awal tidak valid")
+
+type SaldoAwalService interface {
+    Load(ctx context.Context, rows [][]string, format string, opts models.SaldoAwalOptions) (*models.SaldoAwalResult, error)
+}
+
+type saldoAwalService struct {
+    db         *sql.DB
+    stokRepo   repositories.StokRepository
+    barangRepo repositories.BarangRepository
+}
+
+func NewSaldoAwalService(db *sql.DB, stokRepo repositories.StokRepository, barangRepo repositories.BarangRepository) SaldoAwalService {
+    return &saldoAwalService{db, stokRepo, barangRepo}
+}
+
+// ParseSaldoAwal membaca baris file saldo awal dengan kolom kode_barang, qty dan harga (opsional).
+// Baris yang tidak valid dikumpulkan sebagai error per baris; error hanya dikembalikan jika
+// file kosong atau judul kolom wajib tidak ada.
+func ParseSaldoAwal(rows [][]string, format string) ([]models.SaldoAwalItem, []models.ImportRowError, error) {
+    if len(rows) == 0 {
+        return nil, nil, fmt.Errorf("%w: file kosong", ErrSaldoAwalFile)
+    }
+
+    columns := map[string]int{}
+    for i, name := range rows[0] {
+        columns[documents.ColumnKey(name)] = i
+    }
+    for _, required := range []string{"kode_barang", "qty"} {
+        if _, ok := columns[required]; !ok {
+            return nil, nil, fmt.Errorf("%w: kolom %s wajib ada di baris judul", ErrSaldoAwalFile, required)
+        }
+    }
+
+    var items []models.SaldoAwalItem
+    var rowErrors []models.ImportRowError
+    seen := map[string]int{}
+    for i, row := range rows[1:] {
+        rowNum := i + 2 // baris 1 adalah judul
+        get := func(col string) string {
+            idx, ok := columns[col]
+            if !ok || idx >= len(row) {
+                return ""
+            }
+            return strings.TrimSpace(row[idx])
+        }
+        if strings.TrimSpace(strings.Join(row, "")) == "" {
+            continue
+        }
+
+        kode := get("kode_barang")
+        fail := func(msg string) {
+            rowErrors = append(rowErrors, models.ImportRowError{Row: rowNum, KodeBarang: kode, Error: msg})
+        }
+        if kode == "" {
+            fail("kode_barang wajib diisi")
+            continue
+        }
+        if first, ok := seen[kode]; ok {
+            fail(fmt.Sprintf("kode_barang sudah ada di baris %d", first))
+            continue
+        }
+        seen[kode] = rowNum
+
+        qty, err := documents.ParseCellNumber(get("qty"), format)
+        if get("qty") == "" || err != nil || qty < 0 || qty != math.Trunc(qty) {
+            fail("qty harus bilangan bulat tidak negatif")
+            continue
+        }
+
+        item := models.SaldoAwalItem{Row: rowNum, KodeBarang: kode, Qty: int(qty)}
+        if s := get("harga"); s != "" {
+            harga, err := documents.ParseCellNumber(s, format)
+            if err != nil || harga < 0 {
+                fail("harga tidak valid")
+                continue
+            }
+            item.Harga = &harga
+        }
+        items = append(items, item)
+    }
+    return items, rowErrors, nil
+}
+
+// Load memuat saldo awal stok dalam satu transaksi. Setiap barang hanya boleh dimuat sekali;
+// dengan Force, saldo awal lama diganti dan hanya selisihnya yang dibukukan sehingga transaksi
+// setelah go-live tidak ikut terhapus. Jika ada baris gagal atau DryRun, tidak ada data yang disimpan.
+func (s *saldoAwalService) Load(ctx context.Context, rows [][]string, format string, opts models.SaldoAwalOptions) (*models.SaldoAwalResult, error) {
+    items, rowErrors, err := ParseSaldoAwal(rows, format)
+    if err != nil {
+        return nil, err
+    }
+
+    result := &models.SaldoAwalResult{
+        DryRun:    opts.DryRun,
+        TotalRows: len(items) + len(rowErrors),
+        Items:     []models.SaldoAwalLine{},
+    }
+
+    type resolved struct {
+        item   models.SaldoAwalItem
+        barang *models.Barang
+    }
+    var valid []resolved
+    for _, item := range items {
+        barang, err := s.barangRepo.GetByKode(item.KodeBarang)
+        if err != nil && !errors.Is(err, sql.ErrNoRows) {
+            return nil, fmt.Errorf("gagal mencari barang %s: %v", item.KodeBarang, err)
+        }
+        if barang == nil {
+            rowErrors = append(rowErrors, models.ImportRowError{Row: item.Row, KodeBarang: item.KodeBarang, Error: "barang tidak ditemukan"})
+            continue
+        }
+        if !barang.IsActive {
+            rowErrors = append(rowErrors, models.ImportRowError{Row: item.Row, KodeBarang: item.KodeBarang, Error: "barang sudah diarsipkan"})
+            continue
+        }
+        valid = append(valid, resolved{item, barang})
+    }
+
+    if len(valid) > 0 {
+        tx, err := s.db.Begin()
+        if err != nil {
+            return nil, fmt.Errorf("gagal memulai transaksi database: %v", err)
+        }
+        defer tx.Rollback()
+
+        for _, v := range valid {
+            line, rowErr, err := s.loadItem(ctx, tx, v.item, v.barang, opts)
+            if err != nil {
+                return nil, fmt.Errorf("gagal memuat saldo awal %s: %v", v.item.KodeBarang, err)
+            }
+            if rowErr != "" {
+                rowErrors = append(rowErrors, models.ImportRowError{Row: v.item.Row, KodeBarang: v.item.KodeBarang, Error: rowErr})
+                continue
+            }
+            result.Items = append(result.Items, *line)
+            result.TotalQty += line.Qty
+            result.TotalNilai += line.Nilai
+        }
+
+        if len(rowErrors) == 0 && !opts.DryRun {
+            if err := tx.Commit(); err != nil {
+                return nil, fmt.Errorf("gagal commit transaksi: %v", err)
+            }
+        }
+    }
+
+    sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+    result.Errors = rowErrors
+    if result.Errors == nil {
+        result.Errors = []models.ImportRowError{}
+    }
+    result.Failed = len(result.Errors)
+    result.Loaded = len(result.Items)
+    return result, nil
+}
+
+// loadItem membukukan saldo awal satu barang. rowErr diisi untuk kesalahan data yang
+// dilaporkan per baris, err untuk kegagalan database yang menghentikan seluruh proses.
+func (s *saldoAwalService) loadItem(ctx context.Context, tx *sql.Tx, item models.SaldoAwalItem, barang *models.Barang, opts models.SaldoAwalOptions) (line *models.SaldoAwalLine, rowErr string, err error) {
+    previous, loaded, err := s.stokRepo.GetSaldoAwal(tx, barang.ID)
+    if err != nil {
+        return nil, "", err
+    }
+    if loaded && !opts.Force {
+        return nil, "saldo awal sudah pernah dimuat, gunakan force untuk menimpa", nil
+    }
+
+    var stokSebelum int
+    if current, err := s.stokRepo.GetByBarangIDWithTx(tx, barang.ID); err == nil && current != nil {
+        stokSebelum = current.StokAkhir
+    }
+
+    delta := item.Qty - previous
+    stokSesudah := stokSebelum + delta
+    if stokSesudah < 0 {
+        return nil, fmt.Sprintf("stok akhir menjadi negatif (%d), periksa transaksi setelah saldo awal", stokSesudah), nil
+    }
+
+    harga := barang.HargaBeli
+    if item.Harga != nil {
+        harga = *item.Harga
+    }
+    line = &models.SaldoAwalLine{
+        Row:         item.Row,
+        BarangID:    barang.ID,
+        KodeBarang:  barang.KodeBarang,
+        NamaBarang:  barang.NamaBarang,
+        Qty:         item.Qty,
+        Harga:       harga,
+        Nilai:       float64(item.Qty) * harga,
+        StokSebelum: stokSebelum,
+        StokSesudah: stokSesudah,
+        Replaced:    loaded,
+    }
+
+    // Muat ulang dengan qty yang sama tidak perlu dibukukan lagi
+    if loaded && delta == 0 {
+        return line, "", nil
+    }
+
+    if err := s.stokRepo.CreateOrUpdate(tx, barang.ID, delta); err != nil {
+        return nil, "", err
+    }
+    keterangan := "Saldo awal"
+    if loaded {
+        keterangan = fmt.Sprintf("Koreksi saldo awal (%d -> %d)", previous, item.Qty)
+    }
+    history := &models.HistoryStok{
+        BarangID:       barang.ID,
+        UserID:         opts.UserID,
+        JenisTransaksi: models.JenisSaldoAwal,
+        Jumlah:         delta,
+        StokSebelum:    stokSebelum,
+        StokSesudah:    stokSesudah,
+        Keterangan:     keterangan,
+    }
+    if err := s.stokRepo.CreateHistory(tx, history); err != nil {
+        return nil, "", err
+    }
+
+    // Saldo awal pertama dicatat sebagai create, force sebagai update qty lama -> baru
+    aksi, before := models.AuditCreate, interface{}(nil)
+    if loaded {
+        aksi, before = models.AuditUpdate, map[string]int{"qty": previous}
+    }
+    after := map[string]interface{}{"qty": item.Qty, "harga": harga}
+    if err := repositories.WriteAudit(ctx, tx, aksi, models.AuditSaldoAwal, barang.ID, before, after); err != nil {
+        return nil, "", err
+    }
+    return line, "", nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+)
+
+var (
+	ErrTransaksiNotFound     = errors.New("transaksi tidak ditemukan")
+	ErrShareDitolak          = errors.New("hanya pembuat transaksi yang bisa mengatur pembagiannya")
+	ErrSharePenggunaTidakAda = errors.New("pengguna tujuan tidak ditemukan")
+	ErrShareKePemilik        = errors.New("transaksi sudah milik pengguna tersebut")
+)
+
+// ShareService membagikan penjualan/pembelian ke pengguna lain yang visibilitasnya hanya milik
+// sendiri. Transaksi yang tidak terlihat oleh pemanggil diperlakukan sebagai tidak ada.
+type ShareService interface {
+	List(ctx context.Context, jenis string, transaksiID int) ([]models.TransaksiShare, error)
+	Share(ctx context.Context, jenis string, transaksiID, userID int) (*models.TransaksiShare, error)
+	Unshare(ctx context.Context, jenis string, transaksiID, userID int) error
+}
+
+type shareService struct {
+	repo      repositories.ShareRepository
+	penjualan repositories.PenjualanRepository
+	pembelian repositories.PembelianRepository
+	users     repositories.UserRepository
+}
+
+func NewShareService(repo repositories.ShareRepository, penjualan repositories.PenjualanRepository, pembelian repositories.PembelianRepository, users repositories.UserRepository) ShareService {
+	return &shareService{repo: repo, penjualan: penjualan, pembelian: pembelian, users: users}
+}
+
+func (s *shareService) List(ctx context.Context, jenis string, transaksiID int) ([]models.TransaksiShare, error) {
+	if _, err := s.owner(ctx, jenis, transaksiID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(jenis, transaksiID)
+}
+
+func (s *shareService) Share(ctx context.Context, jenis string, transaksiID, userID int) (*models.TransaksiShare, error) {
+	owner, err := s.manageable(ctx, jenis, transaksiID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == owner {
+		return nil, ErrShareKePemilik
+	}
+	user, err := s.users.GetByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSharePenggunaTidakAda
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	share := &models.TransaksiShare{Jenis: jenis, TransaksiID: transaksiID, UserID: user.ID, Username: user.Username}
+	if err := s.repo.Add(ctx, share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+func (s *shareService) Unshare(ctx context.Context, jenis string, transaksiID, userID int) error {
+	if _, err := s.manageable(ctx, jenis, transaksiID); err != nil {
+		return err
+	}
+	return s.repo.Remove(ctx, jenis, transaksiID, userID)
+}
+
+// manageable mengembalikan pemilik transaksi bila pemanggil boleh mengatur pembagiannya:
+// pembuatnya sendiri, atau pengguna dengan visibilitas semua transaksi
+func (s *shareService) manageable(ctx context.Context, jenis string, transaksiID int) (int, error) {
+	owner, err := s.owner(ctx, jenis, transaksiID)
+	if err != nil {
+		return 0, err
+	}
+	if userID, restricted := repositories.VisibleTo(ctx); restricted && userID != owner {
+		return 0, ErrShareDitolak
+	}
+	return owner, nil
+}
+
+// owner membaca transaksi lewat repository sehingga batas visibilitas pemanggil ikut berlaku
+func (s *shareService) owner(ctx context.Context, jenis string, transaksiID int) (int, error) {
+	var owner int
+	var err error
+	switch jenis {
+	case models.TransaksiPenjualan:
+		var h *models.JualHeader
+		if h, err = s.penjualan.GetByID(ctx, transaksiID); err == nil {
+			owner = h.UserID
+		}
+	case models.TransaksiPembelian:
+		var h *models.BeliHeader
+		if h, err = s.pembelian.GetByID(ctx, transaksiID); err == nil {
+			owner = h.UserID
+		}
+	default:
+		return 0, ErrTransaksiNotFound
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrTransaksiNotFound
+	}
+	return owner, err
+}
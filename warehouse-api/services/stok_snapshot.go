@@ -0,0 +1,56 @@
+package services
+
+import (
+    "context"
+    "log"
+    "time"
+    "warehouse-api/repositories"
+)
+
+// PreviousMonthEnd mengembalikan tanggal terakhir bulan sebelum t (format YYYY-MM-DD)
+func PreviousMonthEnd(t time.Time) string {
+    firstOfMonth := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+    return firstOfMonth.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// SnapshotPreviousMonth membuat snapshot stok akhir bulan lalu jika belum ada.
+// Mengembalikan periode dan jumlah baris yang ditulis (0 jika snapshot sudah ada).
+func SnapshotPreviousMonth(ctx context.Context, repo repositories.StokRepository, now time.Time) (string, int, error) {
+    periode := PreviousMonthEnd(now)
+    exists, err := repo.HasSnapshot(periode)
+    if err != nil || exists {
+        return periode, 0, err
+    }
+    n, err := repo.CreateSnapshot(ctx, periode)
+    return periode, n, err
+}
+
+// StartStokSnapshotScheduler memeriksa setiap interval apakah snapshot bulan lalu sudah dibuat.
+// Pemeriksaan pertama dilakukan langsung saat start agar server yang mati saat pergantian
+// bulan tetap membuat snapshot begitu menyala kembali. Berhenti saat ctx dibatalkan.
+func StartStokSnapshotScheduler(ctx context.Context, repo repositories.StokRepository, interval time.Duration) {
+    run := func() {
+        periode, n, err := SnapshotPreviousMonth(ctx, repo, time.Now())
+        if err != nil {
+            log.Printf("snapshot stok %s gagal: %v", periode, err)
+            return
+        }
+        if n > 0 {
+            log.Printf("snapshot stok %s dibuat untuk %d barang", periode, n)
+        }
+    }
+
+    go func() {
+        run()
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+                run()
+            }
+        }
+    }()
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+	"warehouse-api/auth"
+	"warehouse-api/config"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token tidak valid atau sudah kedaluwarsa")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah pernah dipakai, sesi dicabut; silakan login ulang")
+)
+
+// TokenService menerbitkan access token (JWT berumur pendek dengan jti) dan refresh token
+// yang dirotasi setiap dipakai, serta mencabutnya saat logout.
+type TokenService interface {
+	Issue(user *models.User) (*models.TokenResponse, error)
+	Refresh(refreshToken string) (*models.TokenResponse, error)
+	Logout(userID int, jti string, expiresAt time.Time, refreshToken string) error
+	LogoutAll(ctx context.Context, userID int) error
+	IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+}
+
+type tokenService struct {
+	repo  repositories.TokenRepository
+	users repositories.UserRepository
+	keys  *auth.KeySet
+	cfg   config.Auth
+	roles RoleLookup
+}
+
+// TokenServiceOption mengatur dependensi opsional TokenService
+type TokenServiceOption func(*tokenService)
+
+// WithTokenRoles membaca permission role untuk claim visibilitas (vis) access token.
+// Tanpa opsi ini hanya admin yang mendapat visibilitas semua transaksi.
+func WithTokenRoles(roles RoleLookup) TokenServiceOption {
+	return func(s *tokenService) {
+		s.roles = roles
+	}
+}
+
+func NewTokenService(repo repositories.TokenRepository, users repositories.UserRepository, keys *auth.KeySet, cfg config.Auth, opts ...TokenServiceOption) TokenService {
+	s := &tokenService{repo: repo, users: users, keys: keys, cfg: cfg}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Issue membuka sesi (family refresh token) baru untuk user yang baru login
+func (s *tokenService) Issue(user *models.User) (*models.TokenResponse, error) {
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	familyID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRefresh(user.ID, refreshHash, familyID, s.cfg.RefreshTTL); err != nil {
+		return nil, err
+	}
+	return s.tokenResponse(user, refreshToken)
+}
+
+func (s *tokenService) Refresh(refreshToken string) (*models.TokenResponse, error) {
+	if refreshToken == "" {
+		return nil, ErrRefreshTokenInvalid
+	}
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := s.repo.RotateRefresh(HashToken(refreshToken), newHash, s.cfg.RefreshTTL)
+	switch {
+	case errors.Is(err, repositories.ErrRefreshTokenNotFound):
+		return nil, ErrRefreshTokenInvalid
+	case errors.Is(err, repositories.ErrRefreshTokenReused):
+		return nil, ErrRefreshTokenReused
+	case err != nil:
+		return nil, err
+	}
+
+	// Role dibaca ulang agar perubahan role berlaku pada access token berikutnya
+	user, err := s.users.GetByID(rt.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrAkunNonaktif
+	}
+	return s.tokenResponse(user, newToken)
+}
+
+// Logout mencabut access token yang sedang dipakai dan, jika dikirim, sesi refresh token-nya
+func (s *tokenService) Logout(userID int, jti string, expiresAt time.Time, refreshToken string) error {
+	if err := s.repo.RevokeAccess(jti, userID, expiresAt); err != nil {
+		return err
+	}
+	if refreshToken != "" {
+		return s.repo.RevokeRefreshFamily(userID, HashToken(refreshToken))
+	}
+	return nil
+}
+
+func (s *tokenService) LogoutAll(ctx context.Context, userID int) error {
+	return s.repo.RevokeAllForUser(ctx, userID)
+}
+
+func (s *tokenService) IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	return s.repo.IsRevoked(jti, userID, issuedAt)
+}
+
+func (s *tokenService) tokenResponse(user *models.User, refreshToken string) (*models.TokenResponse, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	visibility, err := s.visibility(user)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tokenString, err := s.keys.Sign(auth.Claims{
+		UserID:     user.ID,
+		Username:   user.Username,
+		Role:       user.Role,
+		Visibility: visibility,
+		ID:         jti,
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(s.cfg.AccessTTL),
+	})
+	if err != nil {
+		return nil, errors.New("gagal membuat token")
+	}
+	return &models.TokenResponse{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.cfg.AccessTTL.Seconds()),
+		Visibility:   visibility,
+	}, nil
+}
+
+// visibility memakai permission yang sudah diisi saat login; saat refresh permission dibaca ulang
+// agar perubahan role berlaku pada access token berikutnya
+func (s *tokenService) visibility(user *models.User) (string, error) {
+	permissions := user.Permissions
+	if permissions == nil && s.roles != nil {
+		var err error
+		if permissions, err = s.roles.Permissions(user.Role); err != nil {
+			return "", err
+		}
+	}
+	return models.VisibilityFor(user.Role, permissions), nil
+}
+
+// HashToken adalah bentuk refresh token yang disimpan di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
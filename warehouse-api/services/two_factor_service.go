@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"warehouse-api/auth"
+	"warehouse-api/config"
+	"warehouse-api/documents"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrKode2FASalah     = errors.New("kode 2FA salah")
+	ErrChallengeInvalid = errors.New("sesi login 2FA tidak valid atau sudah kedaluwarsa, silakan login ulang")
+	Err2FASudahAktif    = errors.New("2FA sudah aktif")
+	Err2FABelumAktif    = errors.New("2FA belum aktif")
+	Err2FABelumSetup    = errors.New("mulai pendaftaran 2FA terlebih dahulu")
+	Err2FAWajib         = errors.New("2FA wajib untuk role admin dan tidak bisa dinonaktifkan")
+)
+
+const (
+	// maxChallengeAttempts membatasi tebakan kode per challenge; gagal juga dihitung LoginGuard
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+// TwoFactorService mengelola pendaftaran TOTP (RFC 6238), kode pemulihan dan langkah kedua login.
+type TwoFactorService interface {
+	// Required menentukan apakah login user harus melewati langkah kode
+	Required(user *models.User) bool
+	StartChallenge(user *models.User) (*models.TwoFactorChallenge, error)
+	// ChallengeSetup memulai pendaftaran untuk user yang wajib 2FA tapi belum mendaftar (setup_required)
+	ChallengeSetup(challengeToken string) (*models.TwoFactorSetup, error)
+	// CompleteChallenge memeriksa kode dan mengembalikan user pemilik challenge. Bila 2FA baru
+	// didaftarkan pada langkah ini, kode pemulihan ikut dikembalikan. User tetap dikembalikan
+	// saat kode salah agar kegagalannya bisa dicatat.
+	CompleteChallenge(ctx context.Context, challengeToken, code string) (*models.User, []string, error)
+
+	Setup(userID int) (*models.TwoFactorSetup, error)
+	Enable(ctx context.Context, userID int, code string) ([]string, error)
+	Disable(ctx context.Context, userID int, password, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+}
+
+type twoFactorService struct {
+	repo  repositories.TwoFactorRepository
+	users repositories.UserRepository
+	cfg   config.TwoFactor
+	now   func() time.Time
+}
+
+func NewTwoFactorService(repo repositories.TwoFactorRepository, users repositories.UserRepository, cfg config.TwoFactor) TwoFactorService {
+	return &twoFactorService{repo: repo, users: users, cfg: cfg, now: time.Now}
+}
+
+func (s *twoFactorService) Required(user *models.User) bool {
+	return user.TwoFactorEnabled || s.forced(user)
+}
+
+func (s *twoFactorService) forced(user *models.User) bool {
+	return s.cfg.RequireAdmin && user.Role == "admin"
+}
+
+func (s *twoFactorService) StartChallenge(user *models.User) (*models.TwoFactorChallenge, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateChallenge(user.ID, hash, s.cfg.ChallengeTTL); err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorChallenge{
+		TwoFactorRequired: true,
+		SetupRequired:     !user.TwoFactorEnabled,
+		ChallengeToken:    token,
+		ExpiresIn:         int(s.cfg.ChallengeTTL.Seconds()),
+	}, nil
+}
+
+func (s *twoFactorService) ChallengeSetup(challengeToken string) (*models.TwoFactorSetup, error) {
+	userID, err := s.challengeOwner(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.Setup(userID)
+}
+
+func (s *twoFactorService) CompleteChallenge(ctx context.Context, challengeToken, code string) (*models.User, []string, error) {
+	userID, err := s.challengeOwner(challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.user(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var recovery []string
+	if user.TwoFactorEnabled {
+		err = s.verify(userID, code)
+	} else {
+		recovery, err = s.Enable(ctx, userID, code)
+	}
+	if errors.Is(err, ErrKode2FASalah) {
+		if fErr := s.repo.FailChallenge(HashToken(challengeToken)); fErr != nil {
+			return user, nil, fErr
+		}
+		return user, nil, err
+	}
+	if err != nil {
+		return user, nil, err
+	}
+
+	if err := s.repo.DeleteChallenge(HashToken(challengeToken)); err != nil {
+		return nil, nil, err
+	}
+	user.TwoFactorEnabled = true
+	return user, recovery, nil
+}
+
+func (s *twoFactorService) Setup(userID int) (*models.TwoFactorSetup, error) {
+	user, err := s.user(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, Err2FASudahAktif
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPendingSecret(userID, secret); err != nil {
+		return nil, err
+	}
+
+	uri := auth.TOTPURI(s.cfg.Issuer, user.Username, secret)
+	png, err := documents.RenderQRPNG(uri, 256)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURL: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Enable mengonfirmasi pendaftaran dengan kode pertama dari aplikasi authenticator
+func (s *twoFactorService) Enable(ctx context.Context, userID int, code string) ([]string, error) {
+	secret, enabled, err := s.repo.GetSecret(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, Err2FASudahAktif
+	}
+	if secret == "" {
+		return nil, Err2FABelumSetup
+	}
+	if err := s.verifyTOTP(userID, secret, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID int, password, code string) error {
+	user, err := s.user(userID)
+	if err != nil {
+		return err
+	}
+	if s.forced(user) {
+		return Err2FAWajib
+	}
+	if !user.TwoFactorEnabled {
+		return Err2FABelumAktif
+	}
+
+	hash, err := s.users.GetPasswordHash(userID)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return ErrPasswordSalah
+	}
+	if err := s.verify(userID, code); err != nil {
+		return err
+	}
+	return s.repo.Disable(ctx, userID)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	if err := s.verify(userID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verify menerima kode TOTP 6 digit atau salah satu kode pemulihan (sekali pakai)
+func (s *twoFactorService) verify(userID int, code string) error {
+	secret, enabled, err := s.repo.GetSecret(userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return Err2FABelumAktif
+	}
+
+	code = normalizeCode(code)
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		return s.verifyTOTP(userID, secret, code)
+	}
+	used, err := s.repo.UseRecoveryCode(userID, HashToken(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrKode2FASalah
+	}
+	return nil
+}
+
+func (s *twoFactorService) verifyTOTP(userID int, secret, code string) error {
+	step, ok := auth.ValidateTOTP(secret, normalizeCode(code), s.now())
+	if !ok {
+		return ErrKode2FASalah
+	}
+	// Kode yang sudah pernah dipakai ditolak walau masih dalam jendela waktunya
+	fresh, err := s.repo.UseStep(userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrKode2FASalah
+	}
+	return nil
+}
+
+func (s *twoFactorService) challengeOwner(challengeToken string) (int, error) {
+	if challengeToken == "" {
+		return 0, ErrChallengeInvalid
+	}
+	userID, err := s.repo.GetChallenge(HashToken(challengeToken), maxChallengeAttempts)
+	if errors.Is(err, repositories.ErrChallengeNotFound) {
+		return 0, ErrChallengeInvalid
+	}
+	return userID, err
+}
+
+func (s *twoFactorService) user(userID int) (*models.User, error) {
+	user, err := s.users.GetByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repositories.ErrUserNotFound
+	}
+	return user, err
+}
+
+// newRecoveryCodes membuat kode berformat xxxxx-xxxxx (hex); yang disimpan hanya hash bentuk tanpa tanda hubung
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := randomHex(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
@@ -1,26 +1,66 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"warehouse-api/config"
 	"warehouse-api/models"
 	"warehouse-api/repositories"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrAkunNonaktif  = errors.New("akun dinonaktifkan, hubungi admin")
+	ErrPasswordSalah = errors.New("password saat ini salah")
+)
+
 type UserService interface {
-	Register(req *models.RegisterRequest) (*models.User, error)
+	Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error)
 	ValidateCredentials(username, password string) (*models.User, error)
 	GetAll() ([]models.User, error)
 	HashPassword(password string) (string, error)
+	Profile(id int) (*models.User, error)
+	Update(ctx context.Context, id int, req *models.UpdateUserRequest) (*models.User, error)
+	SetActive(ctx context.Context, id int, active bool) error
+	ResetPassword(ctx context.Context, id int, password string) error
+	ChangePassword(ctx context.Context, id int, currentPassword, newPassword string) error
 }
 
 type userService struct {
-	repo repositories.UserRepository
+	repo     repositories.UserRepository
+	roles    RoleLookup
+	password config.PasswordPolicy
+}
+
+// UserServiceOption mengatur dependensi opsional UserService
+type UserServiceOption func(*userService)
+
+// WithRoles memvalidasi role terhadap tabel roles dan mengisi permission user saat login.
+// Tanpa opsi ini hanya role bawaan admin dan staff yang diterima.
+func WithRoles(roles RoleLookup) UserServiceOption {
+	return func(s *userService) {
+		s.roles = roles
+	}
+}
+
+// WithPasswordPolicy mengganti aturan password bawaan (minimal 6 karakter)
+func WithPasswordPolicy(policy config.PasswordPolicy) UserServiceOption {
+	return func(s *userService) {
+		s.password = policy
+	}
 }
 
-func NewUserService(repo repositories.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(repo repositories.UserRepository, opts ...UserServiceOption) UserService {
+	s := &userService{repo: repo, password: config.PasswordPolicy{MinLength: 6, MinClasses: 1}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *userService) GetAll() ([]models.User, error) {
@@ -28,18 +68,18 @@ func (s *userService) GetAll() ([]models.User, error) {
 }
 
 // Register handles user registration with password hashing
-func (s *userService) Register(req *models.RegisterRequest) (*models.User, error) {
+func (s *userService) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
 	// Validate input
 	if req.Username == "" || req.Password == "" {
 		return nil, errors.New("username dan password harus diisi")
 	}
 
-	if len(req.Password) < 6 {
-		return nil, errors.New("password minimal 6 karakter")
+	if err := s.validatePassword(req.Username, req.Password); err != nil {
+		return nil, err
 	}
 
-	if req.Role != "admin" && req.Role != "staff" {
-		return nil, errors.New("role harus 'admin' atau 'staff'")
+	if err := s.validateRole(req.Role); err != nil {
+		return nil, err
 	}
 
 	// Hash password
@@ -58,7 +98,7 @@ func (s *userService) Register(req *models.RegisterRequest) (*models.User, error
 	}
 
 	// Save to database
-	if err := s.repo.Create(user); err != nil {
+	if err := s.repo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
@@ -79,9 +119,142 @@ func (s *userService) ValidateCredentials(username, password string) (*models.Us
 		return nil, errors.New("username atau password salah")
 	}
 
+	// Dicek setelah password agar status akun tidak bocor ke orang yang tidak tahu passwordnya
+	if !user.IsActive {
+		return nil, ErrAkunNonaktif
+	}
+
+	if s.roles != nil {
+		if user.Permissions, err = s.roles.Permissions(user.Role); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// Profile mengembalikan data user beserta permission role-nya (untuk GET /me)
+func (s *userService) Profile(id int) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repositories.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.roles != nil {
+		if user.Permissions, err = s.roles.Permissions(user.Role); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+func (s *userService) Update(ctx context.Context, id int, req *models.UpdateUserRequest) (*models.User, error) {
+	req.Email = strings.TrimSpace(req.Email)
+	req.FullName = strings.TrimSpace(req.FullName)
+	if req.Email == "" || req.FullName == "" {
+		return nil, errors.New("email dan nama lengkap harus diisi")
+	}
+	if err := s.validateRole(req.Role); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{ID: id, Email: req.Email, FullName: req.FullName, Role: req.Role}
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
+func (s *userService) SetActive(ctx context.Context, id int, active bool) error {
+	return s.repo.SetActive(ctx, id, active)
+}
+
+// ResetPassword dipakai admin untuk mengganti password user lain tanpa password lama
+func (s *userService) ResetPassword(ctx context.Context, id int, password string) error {
+	user, err := s.repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repositories.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.validatePassword(user.Username, password); err != nil {
+		return err
+	}
+	hash, err := s.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(ctx, id, hash)
+}
+
+// ChangePassword dipakai user untuk mengganti password sendiri setelah memasukkan password lama
+func (s *userService) ChangePassword(ctx context.Context, id int, currentPassword, newPassword string) error {
+	hash, err := s.repo.GetPasswordHash(id)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(currentPassword)) != nil {
+		return ErrPasswordSalah
+	}
+	if newPassword == currentPassword {
+		return errors.New("password baru harus berbeda dengan password lama")
+	}
+	return s.ResetPassword(ctx, id, newPassword)
+}
+
+func (s *userService) validatePassword(username, password string) error {
+	if len([]rune(password)) < s.password.MinLength {
+		return fmt.Errorf("password minimal %d karakter", s.password.MinLength)
+	}
+	if strings.EqualFold(password, username) {
+		return errors.New("password tidak boleh sama dengan username")
+	}
+	if passwordClasses(password) < s.password.MinClasses {
+		return fmt.Errorf("password harus mengandung minimal %d dari: huruf kecil, huruf besar, angka, simbol", s.password.MinClasses)
+	}
+	return nil
+}
+
+// passwordClasses menghitung jenis karakter yang dipakai: huruf kecil, huruf besar, angka dan simbol
+func passwordClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+func (s *userService) validateRole(role string) error {
+	if s.roles == nil {
+		if role != "admin" && role != "staff" {
+			return errors.New("role harus 'admin' atau 'staff'")
+		}
+		return nil
+	}
+	if role == "" {
+		return errors.New("role harus diisi")
+	}
+	exists, err := s.roles.RoleExists(role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("role harus salah satu role yang terdaftar, '%s' tidak ditemukan", role)
+	}
+	return nil
+}
+
 // HashPassword generates bcrypt hash from plain password
 func (s *userService) HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,8 @@ import (
 	"os"
 	"testing"
 
+	"warehouse-api/auth"
+	"warehouse-api/config"
 	"warehouse-api/handlers"
 	"warehouse-api/models"
 	"warehouse-api/repositories"
@@ -65,6 +68,41 @@ func setupTestSchema(db *sql.DB) {
 			email VARCHAR(100),
 			full_name VARCHAR(100),
 			role VARCHAR(20) NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT TRUE,
+			totp_secret VARCHAR(64),
+			totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+			totp_last_step BIGINT,
+			is_service BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id SERIAL PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash CHAR(64) UNIQUE NOT NULL,
+			family_id VARCHAR(32) NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			revoked_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS login_lockout (
+			username VARCHAR(100) PRIMARY KEY,
+			gagal INTEGER NOT NULL DEFAULT 0,
+			terakhir_gagal TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			terkunci_sampai TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS login_gagal (
+			id SERIAL PRIMARY KEY,
+			username VARCHAR(100) NOT NULL,
+			ip VARCHAR(64),
+			user_agent TEXT,
+			alasan VARCHAR(255),
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 	`)
@@ -80,10 +118,36 @@ func setupTestSchema(db *sql.DB) {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 	`)
+
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id BIGSERIAL PRIMARY KEY,
+			user_id INTEGER REFERENCES users(id),
+			aksi VARCHAR(30) NOT NULL,
+			entitas VARCHAR(50) NOT NULL,
+			entitas_id VARCHAR(100) NOT NULL,
+			sebelum JSONB,
+			sesudah JSONB,
+			ip VARCHAR(64),
+			request_id VARCHAR(64),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaksi_share (
+			jenis VARCHAR(20) NOT NULL CHECK (jenis IN ('penjualan', 'pembelian')),
+			transaksi_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			shared_by INTEGER REFERENCES users(id),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (jenis, transaksi_id, user_id)
+		);
+	`)
 }
 
 func cleanupTestSchema(db *sql.DB) {
-	_, _ = db.Exec("TRUNCATE users, barang CASCADE")
+	_, _ = db.Exec("TRUNCATE users, barang, login_lockout, login_gagal, audit_log, transaksi_share CASCADE")
 }
 
 func getEnv(key, fallback string) string {
@@ -108,11 +172,15 @@ func TestAuthIntegration(t *testing.T) {
 		t.Skip("Database not available")
 	}
 
-	testDB.Exec("TRUNCATE users CASCADE")
+	testDB.Exec("TRUNCATE users, login_lockout CASCADE")
 
 	userRepo := repositories.NewUserRepository(testDB)
 	userService := services.NewUserService(userRepo)
-	userHandler := handlers.NewUserHandler(userService)
+	keys := auth.NewKeySet(auth.NewHMACKey([]byte("integration-secret")))
+	tokenService := services.NewTokenService(repositories.NewTokenRepository(testDB), userRepo, keys, config.LoadAuth())
+	loginGuard := services.NewLoginGuard(repositories.NewLoginAttemptRepository(testDB), userRepo, config.LoadLoginLockout())
+	twoFactorService := services.NewTwoFactorService(repositories.NewTwoFactorRepository(testDB), userRepo, config.LoadTwoFactor())
+	userHandler := handlers.NewUserHandler(userService, tokenService, loginGuard, twoFactorService)
 
 	registerReq := models.RegisterRequest{
 		Username: "testuser",
@@ -122,7 +190,7 @@ func TestAuthIntegration(t *testing.T) {
 		Role:     "staff",
 	}
 
-	user, err := userService.Register(&registerReq)
+	user, err := userService.Register(context.Background(), &registerReq)
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
 
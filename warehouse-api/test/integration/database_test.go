@@ -1,11 +1,17 @@
 package integration
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
+	"warehouse-api/ctxkeys"
+	"warehouse-api/middleware"
 	"warehouse-api/models"
 	"warehouse-api/repositories"
+	"warehouse-api/services"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -27,7 +33,7 @@ func TestUserRepositoryIntegration(t *testing.T) {
 		Role:     "staff",
 	}
 
-	err := repo.Create(user)
+	err := repo.Create(context.Background(), user)
 	assert.NoError(t, err)
 	assert.Greater(t, user.ID, 0)
 
@@ -52,7 +58,7 @@ func TestBarangRepositoryIntegration(t *testing.T) {
 		HargaJual:  7000,
 	}
 
-	err := repo.Create(barang)
+	err := repo.Create(context.Background(), barang)
 	assert.NoError(t, err)
 	assert.Greater(t, barang.ID, 0)
 
@@ -60,7 +66,15 @@ func TestBarangRepositoryIntegration(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, retrieved.KodeBarang)
 
-	err = repo.Delete(barang.ID)
+	err = repo.Delete(context.Background(), barang.ID)
+	assert.NoError(t, err)
+
+	archived, err := repo.GetByID(barang.ID)
+	assert.NoError(t, err)
+	assert.False(t, archived.IsActive)
+	assert.NotNil(t, archived.DeletedAt)
+
+	err = repo.Restore(context.Background(), barang.ID)
 	assert.NoError(t, err)
 }
 
@@ -80,11 +94,399 @@ func TestSearchPaginationIntegration(t *testing.T) {
 			HargaBeli:  1000 * float64(i),
 			HargaJual:  1500 * float64(i),
 		}
-		repo.Create(barang)
+		repo.Create(context.Background(), barang)
 	}
 
-	items, total, err := repo.GetAll("", 10, 0, "", "")
+	items, total, err := repo.GetAll(models.BarangFilter{}, 10, 0, "", "")
 	assert.NoError(t, err)
 	assert.Equal(t, 10, len(items))
 	assert.Equal(t, 15, total)
 }
+
+func TestTransaksiVisibilityIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE users, jual_header, transaksi_share CASCADE")
+
+	users := repositories.NewUserRepository(testDB)
+	pemilik := &models.User{Username: "pemilik", Password: "x", Role: "staff"}
+	lain := &models.User{Username: "lain", Password: "x", Role: "staff"}
+	assert.NoError(t, users.Create(context.Background(), pemilik))
+	assert.NoError(t, users.Create(context.Background(), lain))
+
+	var jualID int
+	err := testDB.QueryRow(`INSERT INTO jual_header (no_faktur, customer, total, user_id) VALUES ('JUAL-VIS-1', 'Toko A', 1000, $1) RETURNING id`, pemilik.ID).Scan(&jualID)
+	assert.NoError(t, err)
+
+	asUser := func(u *models.User) context.Context {
+		ctx := context.WithValue(context.Background(), middleware.UserIDKey, u.ID)
+		return context.WithValue(ctx, middleware.VisibilityKey, models.VisibilityOwn)
+	}
+	repo := repositories.NewPenjualanRepository(testDB)
+
+	_, err = repo.GetByID(asUser(pemilik), jualID)
+	assert.NoError(t, err)
+
+	_, err = repo.GetByID(asUser(lain), jualID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	// Context tanpa visibilitas (pemanggil lupa meneruskan context request) tidak membuka data
+	_, err = repo.GetByID(context.Background(), jualID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	list, total, err := repo.GetAll(asUser(lain), models.TransaksiFilter{}, 10, 0, "id", "desc")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, list)
+
+	shares := repositories.NewShareRepository(testDB)
+	assert.NoError(t, shares.Add(asUser(pemilik), &models.TransaksiShare{Jenis: models.TransaksiPenjualan, TransaksiID: jualID, UserID: lain.ID}))
+
+	_, err = repo.GetByID(asUser(lain), jualID)
+	assert.NoError(t, err)
+	_, total, err = repo.GetAll(asUser(lain), models.TransaksiFilter{}, 10, 0, "id", "desc")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestAggregateVisibilityIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE users, master_barang, jual_header, transaksi_share CASCADE")
+
+	users := repositories.NewUserRepository(testDB)
+	pemilik := &models.User{Username: "pemilik", Password: "x", Role: "staff"}
+	lain := &models.User{Username: "lain", Password: "x", Role: "staff"}
+	assert.NoError(t, users.Create(context.Background(), pemilik))
+	assert.NoError(t, users.Create(context.Background(), lain))
+
+	barang := &models.Barang{NamaBarang: "Rahasia", Satuan: "pcs", HargaBeli: 600, HargaJual: 1000}
+	assert.NoError(t, repositories.NewBarangRepository(testDB).Create(context.Background(), barang))
+
+	var jualID int
+	err := testDB.QueryRow(`INSERT INTO jual_header (no_faktur, customer, total, user_id) VALUES ('JUAL-VIS-2', 'Toko A', 1000, $1) RETURNING id`, pemilik.ID).Scan(&jualID)
+	assert.NoError(t, err)
+	_, err = testDB.Exec(`INSERT INTO jual_detail (jual_header_id, barang_id, qty, harga, subtotal) VALUES ($1, $2, 1, 1000, 1000)`, jualID, barang.ID)
+	assert.NoError(t, err)
+	_, err = testDB.Exec(`INSERT INTO history_stok (barang_id, user_id, jenis_transaksi, jumlah, stok_sebelum, stok_sesudah, keterangan, jual_header_id)
+		VALUES ($1, $2, 'keluar', 1, 1, 0, 'Penjualan JUAL-VIS-2', $3)`, barang.ID, pemilik.ID, jualID)
+	assert.NoError(t, err)
+	// Keterangan yang meniru nomor faktur tidak ikut terlihat lewat share; hanya jual_header_id yang dipakai
+	_, err = testDB.Exec(`INSERT INTO history_stok (barang_id, user_id, jenis_transaksi, jumlah, stok_sebelum, stok_sesudah, keterangan)
+		VALUES ($1, $2, 'keluar', 0, 0, 0, 'Penjualan JUAL-VIS-2')`, barang.ID, pemilik.ID)
+	assert.NoError(t, err)
+
+	asUser := func(u *models.User) context.Context {
+		ctx := context.WithValue(context.Background(), middleware.UserIDKey, u.ID)
+		return context.WithValue(ctx, middleware.VisibilityKey, models.VisibilityOwn)
+	}
+	reports := repositories.NewReportRepository(testDB)
+	dashboard := repositories.NewDashboardRepository(testDB)
+	stok := repositories.NewStokRepository(testDB)
+
+	hidden := func(ctx context.Context) []bool {
+		var flags []bool
+		assert.NoError(t, stok.EachMovement(ctx, barang.ID, "", func(m models.StokMovement) error {
+			flags = append(flags, m.Hidden)
+			return nil
+		}))
+		return flags
+	}
+
+	for _, ctx := range []context.Context{ctxkeys.WithSystem(context.Background()), asUser(pemilik)} {
+		summary, err := reports.Summary(ctx, models.TransaksiPenjualan, "2000-01-01", "2999-12-31")
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, summary.Total)
+		stats, err := dashboard.GetStats(ctx, models.DashboardFilter{TopN: 5})
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, stats.Pendapatan)
+		assert.Len(t, stats.TopSellingProducts, 1)
+		assert.Equal(t, []bool{false, false}, hidden(ctx))
+	}
+
+	summary, err := reports.Summary(asUser(lain), models.TransaksiPenjualan, "2000-01-01", "2999-12-31")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, summary.Total)
+	assert.Equal(t, 0, summary.JumlahTransaksi)
+	points, err := reports.Aggregate(asUser(lain), models.ReportQuery{Jenis: models.TransaksiPenjualan, GroupBy: "customer", StartDate: "2000-01-01", EndDate: "2999-12-31"})
+	assert.NoError(t, err)
+	assert.Empty(t, points)
+	stats, err := dashboard.GetStats(asUser(lain), models.DashboardFilter{TopN: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, stats.Pendapatan)
+	assert.Equal(t, 0, stats.JumlahFaktur)
+	assert.Empty(t, stats.TopSellingProducts)
+	assert.Equal(t, []bool{true, true}, hidden(asUser(lain)))
+
+	shares := repositories.NewShareRepository(testDB)
+	assert.NoError(t, shares.Add(asUser(pemilik), &models.TransaksiShare{Jenis: models.TransaksiPenjualan, TransaksiID: jualID, UserID: lain.ID}))
+
+	summary, err = reports.Summary(asUser(lain), models.TransaksiPenjualan, "2000-01-01", "2999-12-31")
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.0, summary.Total)
+	assert.Equal(t, []bool{false, true}, hidden(asUser(lain)))
+}
+
+func TestHistoryTransaksiRefIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE users, master_barang, beli_header, history_stok, history_chain_anchor, history_chain_seal CASCADE")
+
+	user := &models.User{Username: "pembeli", Password: "x", Role: "staff"}
+	assert.NoError(t, repositories.NewUserRepository(testDB).Create(context.Background(), user))
+	barangRepo := repositories.NewBarangRepository(testDB)
+	barang := &models.Barang{NamaBarang: "Ref", Satuan: "pcs", HargaBeli: 1000, HargaJual: 1500}
+	assert.NoError(t, barangRepo.Create(context.Background(), barang))
+
+	service := services.NewPembelianService(testDB, repositories.NewPembelianRepository(testDB), repositories.NewStokRepository(testDB), barangRepo)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, user.ID)
+	header, err := service.Create(ctx, models.CreatePembelianRequest{
+		Supplier: "Pemasok", UserID: user.ID,
+		Details: []models.CreatePembelianDetail{{BarangID: barang.ID, Qty: 2, Harga: 1000}},
+	})
+	assert.NoError(t, err)
+
+	var beliID sql.NullInt64
+	assert.NoError(t, testDB.QueryRow(`SELECT beli_header_id FROM history_stok WHERE barang_id = $1`, barang.ID).Scan(&beliID))
+	assert.Equal(t, int64(header.ID), beliID.Int64)
+}
+
+func TestKategoriReparentCycleIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE kategori CASCADE")
+
+	repo := repositories.NewKategoriRepository(testDB)
+	induk := &models.Kategori{Nama: "Elektronik"}
+	assert.NoError(t, repo.Create(context.Background(), induk))
+	anak := &models.Kategori{Nama: "Laptop", ParentID: &induk.ID}
+	assert.NoError(t, repo.Create(context.Background(), anak))
+
+	induk.ParentID = &anak.ID
+	assert.ErrorIs(t, repo.Update(context.Background(), induk), repositories.ErrKategoriSiklus)
+	induk.ParentID = &induk.ID
+	assert.ErrorIs(t, repo.Update(context.Background(), induk), repositories.ErrKategoriSiklus)
+
+	lain := &models.Kategori{Nama: "ATK"}
+	assert.NoError(t, repo.Create(context.Background(), lain))
+	anak.ParentID = &lain.ID
+	assert.NoError(t, repo.Update(context.Background(), anak))
+}
+
+func TestBarangImportKodeSistemIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE master_barang CASCADE")
+
+	repo := repositories.NewBarangRepository(testDB)
+	existing := &models.Barang{NamaBarang: "Sudah Ada", Satuan: "pcs", HargaBeli: 1000, HargaJual: 1500}
+	assert.NoError(t, repo.Create(context.Background(), existing))
+
+	result, err := repo.Import(context.Background(), []models.BarangImportItem{
+		{Row: 2, Barang: models.Barang{KodeBarang: existing.KodeBarang, NamaBarang: "Sudah Ada v2", Satuan: "pcs", HargaBeli: 1000, HargaJual: 1600}},
+		{Row: 3, Barang: models.Barang{KodeBarang: "BRG-999", NamaBarang: "Baru", Satuan: "pcs", HargaBeli: 1000, HargaJual: 1500}},
+		{Row: 4, Barang: models.Barang{KodeBarang: "SUP-01", NamaBarang: "Kode Supplier", Satuan: "pcs", HargaBeli: 1000, HargaJual: 1500}},
+	}, true)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, 3, result.Errors[0].Row)
+		assert.Contains(t, result.Errors[0].Error, "BRG-")
+	}
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Created)
+}
+
+func TestServicePrincipalNotManagedAsUserIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE users CASCADE")
+
+	var id int
+	err := testDB.QueryRow(`
+        INSERT INTO users (username, password, email, full_name, role, is_service, is_active)
+        VALUES ('apikey-test', '!', 'apikey-test@service', 'API key', 'staff', TRUE, FALSE) RETURNING id`).Scan(&id)
+	assert.NoError(t, err)
+
+	repo := repositories.NewUserRepository(testDB)
+	err = repo.Update(context.Background(), &models.User{ID: id, Email: "x@example.com", FullName: "X", Role: "admin"})
+	assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+	assert.ErrorIs(t, repo.SetActive(context.Background(), id, true), repositories.ErrUserNotFound)
+
+	var active bool
+	var role string
+	assert.NoError(t, testDB.QueryRow(`SELECT is_active, role FROM users WHERE id = $1`, id).Scan(&active, &role))
+	assert.False(t, active)
+	assert.Equal(t, "staff", role)
+}
+
+func TestAPIKeyExpiryIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE users, api_keys CASCADE")
+
+	repo := repositories.NewAPIKeyRepository(testDB)
+	create := func(nama string, expiresAt time.Time) {
+		key := &models.APIKey{Nama: nama, Prefix: "wh_" + nama, Username: "apikey-" + nama, Permissions: []string{}, ExpiresAt: &expiresAt}
+		assert.NoError(t, repo.Create(context.Background(), key, "hash-"+nama))
+	}
+	berlaku := time.Now().Add(time.Hour)
+	create("berlaku", berlaku)
+	create("kedaluwarsa", time.Now().Add(-time.Minute))
+
+	key, err := repo.GetActiveByHash("hash-berlaku")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, berlaku, *key.ExpiresAt, time.Second)
+
+	_, err = repo.GetActiveByHash("hash-kedaluwarsa")
+	assert.ErrorIs(t, err, repositories.ErrAPIKeyNotFound)
+}
+
+func TestSecurityActionsAuditedIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE users, login_lockout, audit_log CASCADE")
+
+	users := repositories.NewUserRepository(testDB)
+	admin := &models.User{Username: "admin-audit", Password: "x", Role: "admin"}
+	target := &models.User{Username: "target-audit", Password: "x", Role: "staff"}
+	assert.NoError(t, users.Create(context.Background(), admin))
+	assert.NoError(t, users.Create(context.Background(), target))
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, admin.ID)
+
+	auditCount := func() int {
+		var n int
+		testDB.QueryRow(`
+            SELECT COUNT(*) FROM audit_log
+            WHERE entitas = $1 AND entitas_id = $2 AND user_id = $3 AND aksi = $4`,
+			models.AuditUser, fmt.Sprint(target.ID), admin.ID, models.AuditUpdate).Scan(&n)
+		return n
+	}
+
+	_, err := testDB.Exec(`
+        INSERT INTO login_lockout (username, gagal, terkunci_sampai)
+        VALUES ($1, 5, NOW() + INTERVAL '15 minutes')`, target.Username)
+	assert.NoError(t, err)
+	assert.NoError(t, repositories.NewLoginAttemptRepository(testDB).Unlock(ctx, target.ID, target.Username))
+	assert.Equal(t, 1, auditCount())
+
+	assert.NoError(t, repositories.NewTokenRepository(testDB).RevokeAllForUser(ctx, target.ID))
+	assert.Equal(t, 2, auditCount())
+
+	twoFactor := repositories.NewTwoFactorRepository(testDB)
+	assert.NoError(t, twoFactor.SetPendingSecret(target.ID, "JBSWY3DPEHPK3PXP"))
+	assert.NoError(t, twoFactor.Enable(ctx, target.ID, []string{"hash-1"}))
+	assert.NoError(t, twoFactor.ReplaceRecoveryCodes(ctx, target.ID, []string{"hash-2"}))
+	assert.NoError(t, twoFactor.Disable(ctx, target.ID))
+	assert.Equal(t, 5, auditCount())
+}
+
+func TestUserChangeRevokesSessionsIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE users CASCADE")
+
+	ctx := context.Background()
+	users := repositories.NewUserRepository(testDB)
+	tokens := repositories.NewTokenRepository(testDB)
+	user := &models.User{Username: "sesi", Password: "x", Role: "staff"}
+	assert.NoError(t, users.Create(ctx, user))
+
+	// revoked memastikan sesi_dicabut_at terisi dan refresh token aktif dicabut, lalu mereset keduanya
+	revoked := func() bool {
+		var dicabut bool
+		assert.NoError(t, testDB.QueryRow(`
+            SELECT sesi_dicabut_at IS NOT NULL
+                AND NOT EXISTS(SELECT 1 FROM refresh_tokens WHERE user_id = $1 AND revoked_at IS NULL)
+            FROM users WHERE id = $1`, user.ID).Scan(&dicabut))
+		testDB.Exec(`UPDATE users SET sesi_dicabut_at = NULL WHERE id = $1`, user.ID)
+		testDB.Exec(`DELETE FROM refresh_tokens WHERE user_id = $1`, user.ID)
+		assert.NoError(t, tokens.CreateRefresh(user.ID, fmt.Sprintf("hash-%d", time.Now().UnixNano()), "family", time.Hour))
+		return dicabut
+	}
+	revoked()
+
+	user.FullName = "Nama Baru"
+	assert.NoError(t, users.Update(ctx, user))
+	assert.False(t, revoked())
+
+	user.Role = "admin"
+	assert.NoError(t, users.Update(ctx, user))
+	assert.True(t, revoked())
+
+	assert.NoError(t, users.UpdatePassword(ctx, user.ID, "hash-baru"))
+	assert.True(t, revoked())
+
+	assert.NoError(t, users.SetActive(ctx, user.ID, false))
+	assert.True(t, revoked())
+}
+
+func TestHistoryChainSealIntegration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Database not available")
+	}
+
+	testDB.Exec("TRUNCATE master_barang, history_stok, history_chain_anchor, history_chain_seal CASCADE")
+
+	barangRepo := repositories.NewBarangRepository(testDB)
+	barang := &models.Barang{NamaBarang: "Rantai", Satuan: "pcs", HargaBeli: 1000, HargaJual: 1500}
+	assert.NoError(t, barangRepo.Create(context.Background(), barang))
+
+	repo := repositories.NewStokRepository(testDB)
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, repo.CreateHistory(nil, &models.HistoryStok{
+			BarangID: barang.ID, JenisTransaksi: "masuk", Jumlah: 1, StokSebelum: i - 1, StokSesudah: i,
+		}))
+	}
+	report, err := services.VerifyHistoryChain(repo, barang.ID)
+	assert.NoError(t, err)
+	assert.True(t, report.Valid)
+
+	// Menghapus baris terbaru terdeteksi lewat anchor
+	_, err = testDB.Exec(`DELETE FROM history_stok WHERE id = (SELECT MAX(id) FROM history_stok WHERE barang_id = $1)`, barang.ID)
+	assert.NoError(t, err)
+
+	report, err = services.VerifyHistoryChain(repo, barang.ID)
+	assert.NoError(t, err)
+	assert.False(t, report.Valid)
+
+	err = repo.CreateHistory(nil, &models.HistoryStok{BarangID: barang.ID, JenisTransaksi: "masuk", Jumlah: 1, StokSebelum: 2, StokSesudah: 3})
+	assert.ErrorIs(t, err, repositories.ErrHistoryChainRusak)
+
+	// Mengembalikan penanda history_sealed, mengosongkan hash dan menghapus anchor tetap terdeteksi
+	// karena penanda seal sudah tercatat
+	_, err = testDB.Exec(`UPDATE history_stok SET jumlah = 99, hash = NULL, prev_hash = NULL WHERE barang_id = $1`, barang.ID)
+	assert.NoError(t, err)
+	_, err = testDB.Exec(`UPDATE master_barang SET history_sealed = FALSE WHERE id = $1`, barang.ID)
+	assert.NoError(t, err)
+	_, err = testDB.Exec(`DELETE FROM history_chain_anchor WHERE barang_id = $1`, barang.ID)
+	assert.NoError(t, err)
+
+	report, err = services.VerifyHistoryChain(repo, barang.ID)
+	assert.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Zero(t, report.BelumDiseal)
+
+	// -seal tidak mau menyegel ulang riwayat yang sudah pernah di-seal
+	_, _, err = repo.SealHistory()
+	assert.ErrorIs(t, err, repositories.ErrHistoryChainRusak)
+	report, err = services.VerifyHistoryChain(repo, barang.ID)
+	assert.NoError(t, err)
+	assert.False(t, report.Valid)
+}
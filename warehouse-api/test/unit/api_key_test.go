@@ -0,0 +1,333 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	args := m.Called(key, keyHash)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetAll() ([]models.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetActiveByHash(keyHash string) (*models.APIKey, error) {
+	args := m.Called(keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Touch(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// stubScopes menggabungkan permission role staff dengan katalog permission dari migration 009_rbac.sql
+type stubScopes struct {
+	stubPermissions
+}
+
+func (s stubScopes) GetPermissions() ([]models.Permission, error) {
+	var catalog []models.Permission
+	for _, p := range []string{
+		models.PermBarangRead, models.PermBarangWrite, models.PermStokRead, models.PermStokOpname,
+		models.PermPembelianRead, models.PermPenjualanRead, models.PermPenjualanCreate, models.PermReportView,
+		models.PermUserManage, models.PermRoleManage, models.PermAPIKeyManage,
+	} {
+		catalog = append(catalog, models.Permission{Kode: p})
+	}
+	return catalog, nil
+}
+
+type stubAPIKeyAuthenticator map[string]*models.APIKeyPrincipal
+
+func (s stubAPIKeyAuthenticator) Authenticate(key string) (*models.APIKeyPrincipal, error) {
+	if key == "wh_rusak" {
+		return nil, errors.New("db down")
+	}
+	return s[key], nil
+}
+
+func TestAPIKeyServiceCreate(t *testing.T) {
+	scopes := stubScopes{staffPermissions()}
+
+	t.Run("Success - key only returned once and stored hashed", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := services.NewAPIKeyService(mockRepo, scopes)
+
+		var stored *models.APIKey
+		var storedHash string
+		mockRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*models.APIKey)
+			storedHash = args.String(1)
+			stored.ID = 7
+			stored.UserID = 42
+		}).Return(nil)
+
+		res, err := service.Create(context.Background(), 3, "staff", models.CreateAPIKeyRequest{
+			Nama:          " Konektor Tokopedia ",
+			Permissions:   []string{models.PermStokRead, models.PermBarangRead, models.PermStokRead},
+			ExpiresInDays: 30,
+		})
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(res.Key, res.Prefix+"_"))
+		assert.Regexp(t, `^wh_[0-9a-f]{8}$`, res.Prefix)
+		assert.Equal(t, services.HashToken(res.Key), storedHash)
+		assert.NotContains(t, storedHash, res.Key)
+		assert.Equal(t, "Konektor Tokopedia", stored.Nama)
+		assert.Equal(t, "apikey-"+strings.TrimPrefix(res.Prefix, "wh_"), stored.Username)
+		assert.Equal(t, []string{models.PermBarangRead, models.PermStokRead}, stored.Permissions)
+		assert.Equal(t, 3, stored.CreatedBy)
+		require.NotNil(t, stored.ExpiresAt)
+		assert.Equal(t, 42, res.UserID)
+	})
+
+	t.Run("Success - zero days means no expiry", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := services.NewAPIKeyService(mockRepo, scopes)
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		res, err := service.Create(context.Background(), 1, models.RoleAdmin, models.CreateAPIKeyRequest{Nama: "Printer", Permissions: []string{models.PermUserManage}})
+
+		require.NoError(t, err)
+		assert.Nil(t, res.ExpiresAt)
+	})
+
+	tests := []struct {
+		name     string
+		role     string
+		req      models.CreateAPIKeyRequest
+		expected error
+	}{
+		{"Empty name", "staff", models.CreateAPIKeyRequest{Nama: " ", Permissions: []string{models.PermStokRead}}, services.ErrAPIKeyNamaKosong},
+		{"No scope", "staff", models.CreateAPIKeyRequest{Nama: "Printer"}, services.ErrAPIKeyScopeKosong},
+		{"Negative expiry", "staff", models.CreateAPIKeyRequest{Nama: "Printer", Permissions: []string{models.PermStokRead}, ExpiresInDays: -1}, services.ErrAPIKeyMasaBerlaku},
+		{"Unknown permission", "staff", models.CreateAPIKeyRequest{Nama: "Printer", Permissions: []string{"gudang:terbang"}}, services.ErrAPIKeyScopeTidakAda},
+		{"Permission creator does not hold", "staff", models.CreateAPIKeyRequest{Nama: "Printer", Permissions: []string{models.PermUserManage}}, services.ErrAPIKeyScopeDitolak},
+	}
+	for _, tt := range tests {
+		t.Run("Fail - "+tt.name, func(t *testing.T) {
+			mockRepo := new(MockAPIKeyRepository)
+			service := services.NewAPIKeyService(mockRepo, scopes)
+
+			_, err := service.Create(context.Background(), 3, tt.role, tt.req)
+
+			assert.ErrorIs(t, err, tt.expected)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAPIKeyServiceAuthenticate(t *testing.T) {
+	const key = "wh_1a2b3c4d_rahasia"
+
+	t.Run("Success - returns principal and touches last_used_at", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := services.NewAPIKeyService(mockRepo, stubScopes{})
+		mockRepo.On("GetActiveByHash", services.HashToken(key)).Return(&models.APIKey{ID: 7, UserID: 42, Permissions: []string{models.PermStokRead}}, nil)
+		mockRepo.On("Touch", 7).Return(errors.New("db lambat"))
+
+		principal, err := service.Authenticate(key)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.APIKeyPrincipal{KeyID: 7, UserID: 42, Permissions: []string{models.PermStokRead}}, principal)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - unknown, revoked or expired key", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := services.NewAPIKeyService(mockRepo, stubScopes{})
+		mockRepo.On("GetActiveByHash", services.HashToken(key)).Return(nil, repositories.ErrAPIKeyNotFound)
+
+		principal, err := service.Authenticate(key)
+
+		assert.NoError(t, err)
+		assert.Nil(t, principal)
+		mockRepo.AssertNotCalled(t, "Touch", mock.Anything)
+	})
+
+	t.Run("Fail - foreign prefix is rejected without query", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := services.NewAPIKeyService(mockRepo, stubScopes{})
+
+		principal, err := service.Authenticate("sk_live_123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, principal)
+		mockRepo.AssertNotCalled(t, "GetActiveByHash", mock.Anything)
+	})
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	middleware.SetAPIKeyAuthenticator(stubAPIKeyAuthenticator{
+		"wh_valid": {KeyID: 7, UserID: 42, Permissions: []string{models.PermStokRead}},
+	})
+	defer middleware.SetAPIKeyAuthenticator(nil)
+
+	var ctx context.Context
+	handler := middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Run("Success - request runs as service principal", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/stok", nil)
+		req.Header.Set("X-API-Key", "wh_valid")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, 42, ctx.Value(middleware.UserIDKey))
+		assert.Equal(t, models.RoleService, ctx.Value(middleware.RoleKey))
+		assert.Equal(t, 7, ctx.Value(middleware.APIKeyIDKey))
+		assert.Equal(t, []string{models.PermStokRead}, ctx.Value(middleware.ScopesKey))
+		assert.Equal(t, models.VisibilityOwn, ctx.Value(middleware.VisibilityKey))
+	})
+
+	t.Run("Fail - invalid key", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/stok", nil)
+		req.Header.Set("X-API-Key", "wh_salah")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Fail - lookup error", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/stok", nil)
+		req.Header.Set("X-API-Key", "wh_rusak")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func withAPIKey(req *http.Request, keyID, userID int, scopes ...string) *http.Request {
+	req = withRole(req, userID, models.RoleService)
+	ctx := context.WithValue(req.Context(), middleware.APIKeyIDKey, keyID)
+	ctx = context.WithValue(ctx, middleware.ScopesKey, scopes)
+	return req.WithContext(ctx)
+}
+
+func TestAuthorizerAPIKeyScopes(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	// Role service tidak punya permission apa pun; hanya scope key yang menentukan
+	authz := middleware.NewAuthorizer(staffPermissions())
+
+	t.Run("Allowed by scope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		authz.Require(models.PermStokRead, ok)(w, withAPIKey(httptest.NewRequest("GET", "/api/stok", nil), 7, 42, models.PermStokRead))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Forbidden outside scope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		authz.Require(models.PermBarangRead, ok)(w, withAPIKey(httptest.NewRequest("GET", "/api/barang", nil), 7, 42, models.PermStokRead))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("UserOnly rejects API key", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		middleware.UserOnly(ok)(w, withAPIKey(httptest.NewRequest("GET", "/api/me", nil), 7, 42, models.PermStokRead))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("UserOnly allows bearer token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		middleware.UserOnly(ok)(w, withRole(httptest.NewRequest("GET", "/api/me", nil), 1, "staff"))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	t.Run("Create - scope not held by creator returns 403", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		handler := handlers.NewAPIKeyHandler(services.NewAPIKeyService(mockRepo, stubScopes{staffPermissions()}))
+
+		body := `{"nama": "Printer", "permissions": ["user:manage"]}`
+		req := withRole(httptest.NewRequest("POST", "/api/api-keys", bytes.NewBufferString(body)), 3, "staff")
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Create - returns key", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		handler := handlers.NewAPIKeyHandler(services.NewAPIKeyService(mockRepo, stubScopes{staffPermissions()}))
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		body := `{"nama": "Printer", "permissions": ["barang:read"], "expires_in_days": 90}`
+		req := withRole(httptest.NewRequest("POST", "/api/api-keys", bytes.NewBufferString(body)), 3, "staff")
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"key":"wh_`)
+	})
+
+	t.Run("Revoke - not found", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		handler := handlers.NewAPIKeyHandler(services.NewAPIKeyService(mockRepo, stubScopes{}))
+		mockRepo.On("Revoke", 9).Return(repositories.ErrAPIKeyNotFound)
+
+		req := httptest.NewRequest("DELETE", "/api/api-keys/9", nil)
+		req.SetPathValue("id", "9")
+		w := httptest.NewRecorder()
+
+		handler.Revoke(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Revoke - invalid id", func(t *testing.T) {
+		handler := handlers.NewAPIKeyHandler(services.NewAPIKeyService(new(MockAPIKeyRepository), stubScopes{}))
+
+		req := httptest.NewRequest("DELETE", "/api/api-keys/abc", nil)
+		req.SetPathValue("id", "abc")
+		w := httptest.NewRecorder()
+
+		handler.Revoke(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,182 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) GetAll(filter models.AuditFilter, limit, offset int) ([]models.AuditLog, int, error) {
+	args := m.Called(filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]models.AuditLog), args.Int(1), args.Error(2)
+}
+
+func decodeAudit(t *testing.T, raw json.RawMessage) map[string]interface{} {
+	t.Helper()
+	fields := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(raw, &fields))
+	return fields
+}
+
+func TestAuditDiff(t *testing.T) {
+	t.Run("Create - full after, no before", func(t *testing.T) {
+		sebelum, sesudah, err := repositories.AuditDiff(nil, &models.Kategori{ID: 1, Nama: "ATK"})
+
+		require.NoError(t, err)
+		assert.Nil(t, sebelum)
+		assert.Equal(t, "ATK", decodeAudit(t, sesudah)["nama"])
+	})
+
+	t.Run("Update - only changed fields", func(t *testing.T) {
+		before := &models.Barang{ID: 5, KodeBarang: "BRG-1", NamaBarang: "Pulpen", HargaJual: 3000}
+		after := &models.Barang{ID: 5, KodeBarang: "BRG-1", NamaBarang: "Pulpen", HargaJual: 3500}
+
+		sebelum, sesudah, err := repositories.AuditDiff(before, after)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"harga_jual": float64(3000)}, decodeAudit(t, sebelum))
+		assert.Equal(t, map[string]interface{}{"harga_jual": float64(3500)}, decodeAudit(t, sesudah))
+	})
+
+	t.Run("Update - no change is skipped", func(t *testing.T) {
+		k := &models.Kategori{ID: 1, Nama: "ATK"}
+
+		sebelum, sesudah, err := repositories.AuditDiff(k, k)
+
+		require.NoError(t, err)
+		assert.Nil(t, sebelum)
+		assert.Nil(t, sesudah)
+	})
+
+	t.Run("Delete - full before, no after", func(t *testing.T) {
+		var none *models.Merek
+		sebelum, sesudah, err := repositories.AuditDiff(&models.Merek{ID: 2, Nama: "Joyko"}, none)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Joyko", decodeAudit(t, sebelum)["nama"])
+		assert.Nil(t, sesudah)
+	})
+
+	t.Run("Secrets are never stored", func(t *testing.T) {
+		before := map[string]interface{}{"username": "andi", "password": "lama"}
+		after := map[string]interface{}{"username": "andi", "password": "baru", "totp_secret": "X"}
+
+		sebelum, sesudah, err := repositories.AuditDiff(before, after)
+
+		require.NoError(t, err)
+		assert.Nil(t, sebelum)
+		assert.Nil(t, sesudah)
+	})
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var gotID, gotIP string
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID, _ = r.Context().Value(middleware.RequestIDKey).(string)
+		gotIP, _ = r.Context().Value(middleware.ClientIPKey).(string)
+	}))
+
+	t.Run("Generates ID when header is missing", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/barang", nil)
+		req.RemoteAddr = "10.0.0.7:51234"
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Len(t, gotID, 16)
+		assert.Equal(t, gotID, w.Header().Get("X-Request-ID"))
+		assert.Equal(t, "10.0.0.7", gotIP)
+	})
+
+	t.Run("Keeps valid upstream ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/barang", nil)
+		req.Header.Set("X-Request-ID", "lb-3f2a.91")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, "lb-3f2a.91", gotID)
+		assert.Equal(t, "lb-3f2a.91", w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("Replaces unsafe upstream ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/barang", nil)
+		req.Header.Set("X-Request-ID", "abc\ninjected log line")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Len(t, gotID, 16)
+		assert.NotContains(t, gotID, "injected")
+	})
+}
+
+func TestAuditHandlerGetAll(t *testing.T) {
+	t.Run("Success - filters are passed to repository", func(t *testing.T) {
+		mockRepo := new(MockAuditRepository)
+		handler := handlers.NewAuditHandler(mockRepo)
+		filter := models.AuditFilter{Entitas: models.AuditBarang, EntitasID: "5", UserID: 2, Aksi: models.AuditUpdate, StartDate: "2026-01-01", EndDate: "2026-01-31"}
+		mockRepo.On("GetAll", filter, 10, 10).Return([]models.AuditLog{{ID: 1, Entitas: models.AuditBarang, EntitasID: "5"}}, 11, nil)
+
+		req := httptest.NewRequest("GET", "/api/audit?entitas=barang&entitas_id=5&user_id=2&aksi=update&start_date=2026-01-01&end_date=2026-01-31&page=2&limit=10", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total":11`)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - invalid date", func(t *testing.T) {
+		handler := handlers.NewAuditHandler(new(MockAuditRepository))
+
+		req := httptest.NewRequest("GET", "/api/audit?start_date=01-01-2026", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Fail - invalid user_id", func(t *testing.T) {
+		handler := handlers.NewAuditHandler(new(MockAuditRepository))
+
+		req := httptest.NewRequest("GET", "/api/audit?user_id=abc", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Fail - repository error", func(t *testing.T) {
+		mockRepo := new(MockAuditRepository)
+		handler := handlers.NewAuditHandler(mockRepo)
+		mockRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(nil, 0, errors.New("db down"))
+
+		req := httptest.NewRequest("GET", "/api/audit", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
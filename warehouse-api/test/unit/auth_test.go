@@ -0,0 +1,240 @@
+package unit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"warehouse-api/auth"
+	"warehouse-api/config"
+	"warehouse-api/handlers"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func rsaKeyFiles(t *testing.T) (privPath, pubPath string) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	return writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv)),
+		writePEM(t, "rsa.pub.pem", "PUBLIC KEY", pub)
+}
+
+func ed25519KeyFile(t *testing.T) string {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	return writePEM(t, "ed25519.pem", "PRIVATE KEY", der)
+}
+
+func testClaims() auth.Claims {
+	now := time.Now()
+	return auth.Claims{UserID: 5, Username: "andi", Role: "gudang", ID: "jti-5", IssuedAt: now, ExpiresAt: now.Add(time.Minute)}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	t.Run("RS256 from PKCS#1 file", func(t *testing.T) {
+		priv, _ := rsaKeyFiles(t)
+		keys, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: priv})
+		require.NoError(t, err)
+
+		token, err := keys.Sign(testClaims())
+		require.NoError(t, err)
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, "RS256", parsed.Method.Alg())
+		assert.Equal(t, keys.SigningKeyID(), parsed.Header["kid"])
+
+		claims, err := keys.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, 5, claims.UserID)
+		assert.Equal(t, "gudang", claims.Role)
+		assert.Equal(t, "jti-5", claims.ID)
+	})
+
+	t.Run("EdDSA from PKCS#8 file", func(t *testing.T) {
+		keys, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: ed25519KeyFile(t)})
+		require.NoError(t, err)
+
+		token, err := keys.Sign(testClaims())
+		require.NoError(t, err)
+		parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+		assert.Equal(t, "EdDSA", parsed.Method.Alg())
+		_, err = keys.Verify(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Public key cannot be the signing key", func(t *testing.T) {
+		_, pub := rsaKeyFiles(t)
+		_, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: pub})
+		assert.Error(t, err)
+	})
+
+	t.Run("Production without key fails", func(t *testing.T) {
+		_, err := auth.LoadKeySet(config.Auth{Production: true})
+		assert.ErrorIs(t, err, auth.ErrNoSigningKey)
+	})
+
+	t.Run("Production rejects weak secrets", func(t *testing.T) {
+		for _, secret := range []string{"supersecretkey", "short"} {
+			_, err := auth.LoadKeySet(config.Auth{Production: true, Secret: []byte(secret)})
+			assert.ErrorIs(t, err, auth.ErrWeakSecret, secret)
+		}
+		_, err := auth.LoadKeySet(config.Auth{Production: true, Secret: []byte("0123456789abcdef0123456789abcdef")})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Development without key falls back to HS256", func(t *testing.T) {
+		keys, err := auth.LoadKeySet(config.Auth{})
+		require.NoError(t, err)
+		token, _ := keys.Sign(testClaims())
+		_, err = keys.Verify(token)
+		assert.NoError(t, err)
+		assert.Empty(t, keys.JWKS().Keys)
+	})
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldPriv, oldPub := rsaKeyFiles(t)
+	oldKeys, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: oldPriv})
+	require.NoError(t, err)
+	oldToken, _ := oldKeys.Sign(testClaims())
+
+	newPriv := ed25519KeyFile(t)
+	rotated, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: newPriv, VerifyKeyFiles: []string{oldPub}})
+	require.NoError(t, err)
+	assert.NotEqual(t, oldKeys.SigningKeyID(), rotated.SigningKeyID())
+
+	t.Run("Token from previous key still verifies", func(t *testing.T) {
+		_, err := rotated.Verify(oldToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Token from removed key is rejected", func(t *testing.T) {
+		withoutOld, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: newPriv})
+		require.NoError(t, err)
+		_, err = withoutOld.Verify(oldToken)
+		assert.ErrorIs(t, err, auth.ErrTokenInvalid)
+	})
+
+	t.Run("JWKS lists active key first", func(t *testing.T) {
+		jwks := rotated.JWKS()
+		require.Len(t, jwks.Keys, 2)
+		assert.Equal(t, rotated.SigningKeyID(), jwks.Keys[0].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+		assert.Equal(t, oldKeys.SigningKeyID(), jwks.Keys[1].Kid)
+		assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+	})
+
+	legacy := auth.NewKeySet(auth.NewHMACKey([]byte("legacy-secret")))
+	legacyToken, _ := legacy.Sign(testClaims())
+
+	t.Run("HS256 tokens accepted during migration window", func(t *testing.T) {
+		until := time.Now().Add(300 * time.Millisecond)
+		migrated, err := auth.LoadKeySet(config.Auth{
+			PrivateKeyFile:    newPriv,
+			Secret:            []byte("legacy-secret"),
+			LegacySecretUntil: until,
+			RefreshTTL:        time.Hour,
+		})
+		require.NoError(t, err)
+		_, err = migrated.Verify(legacyToken)
+		assert.NoError(t, err)
+
+		// Kunci HMAC hanya untuk verifikasi dan tidak dipublikasikan
+		assert.Len(t, migrated.JWKS().Keys, 1)
+
+		time.Sleep(time.Until(until) + 10*time.Millisecond)
+		_, err = migrated.Verify(legacyToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("Secret ignored without migration window", func(t *testing.T) {
+		migrated, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: newPriv, Secret: []byte("legacy-secret")})
+		require.NoError(t, err)
+		_, err = migrated.Verify(legacyToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("Migration window capped at refresh TTL", func(t *testing.T) {
+		_, err := auth.LoadKeySet(config.Auth{
+			PrivateKeyFile:    newPriv,
+			Secret:            []byte("legacy-secret"),
+			LegacySecretUntil: time.Now().Add(48 * time.Hour),
+			RefreshTTL:        24 * time.Hour,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifyRejectsForgedTokens(t *testing.T) {
+	priv, pub := rsaKeyFiles(t)
+	keys, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: priv})
+	require.NoError(t, err)
+
+	t.Run("HS256 signed with the public key (algorithm confusion)", func(t *testing.T) {
+		pubPEM, _ := os.ReadFile(pub)
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1, "role": "admin", "exp": time.Now().Add(time.Minute).Unix()})
+		token.Header["kid"] = keys.SigningKeyID()
+		forged, _ := token.SignedString(pubPEM)
+
+		_, err := keys.Verify(forged)
+		assert.ErrorIs(t, err, auth.ErrTokenInvalid)
+	})
+
+	t.Run("Unknown kid", func(t *testing.T) {
+		other, _ := rsaKeyFiles(t)
+		otherKeys, _ := auth.LoadKeySet(config.Auth{PrivateKeyFile: other})
+		token, _ := otherKeys.Sign(testClaims())
+
+		_, err := keys.Verify(token)
+		assert.ErrorIs(t, err, auth.ErrTokenInvalid)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		c := testClaims()
+		c.ExpiresAt = time.Now().Add(-time.Minute)
+		token, _ := keys.Sign(c)
+
+		_, err := keys.Verify(token)
+		assert.ErrorIs(t, err, auth.ErrTokenInvalid)
+	})
+}
+
+func TestJWKSHandler(t *testing.T) {
+	keys, err := auth.LoadKeySet(config.Auth{PrivateKeyFile: ed25519KeyFile(t)})
+	require.NoError(t, err)
+	handler := handlers.NewJWKSHandler(keys)
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	handler.Get(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+	var jwks auth.JWKS
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, keys.SigningKeyID(), jwks.Keys[0].Kid)
+	assert.Equal(t, "sig", jwks.Keys[0].Use)
+}
@@ -1,8 +1,11 @@
 package unit
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"warehouse-api/handlers"
 	"warehouse-api/models"
@@ -16,22 +19,42 @@ type MockBarangRepositoryHandler struct {
 	mock.Mock
 }
 
-func (m *MockBarangRepositoryHandler) GetAll(search string, limit, offset int, sortBy, order string) ([]models.Barang, int, error) {
-	args := m.Called(search, limit, offset, sortBy, order)
+func (m *MockBarangRepositoryHandler) GetAll(filter models.BarangFilter, limit, offset int, sortBy, order string) ([]models.Barang, int, error) {
+	args := m.Called(filter, limit, offset, sortBy, order)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
 	return args.Get(0).([]models.Barang), args.Int(1), args.Error(2)
 }
 
-func (m *MockBarangRepositoryHandler) GetAllWithStok(search string, limit, offset int, sortBy, order string) ([]models.BarangWithStok, int, error) {
-	args := m.Called(search, limit, offset, sortBy, order)
+func (m *MockBarangRepositoryHandler) GetAllWithStok(filter models.BarangFilter, limit, offset int, sortBy, order string) ([]models.BarangWithStok, int, error) {
+	args := m.Called(filter, limit, offset, sortBy, order)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
 	return args.Get(0).([]models.BarangWithStok), args.Int(1), args.Error(2)
 }
 
+func (m *MockBarangRepositoryHandler) StreamWithStok(ctx context.Context, filter models.BarangFilter, sortBy, order string, fn func(models.BarangWithStok) error) error {
+	args := m.Called(filter, sortBy, order, fn)
+	if rows, ok := args.Get(0).([]models.BarangWithStok); ok {
+		for _, b := range rows {
+			if err := fn(b); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockBarangRepositoryHandler) Import(ctx context.Context, items []models.BarangImportItem, dryRun bool) (*models.BarangImportResult, error) {
+	args := m.Called(items, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BarangImportResult), args.Error(1)
+}
+
 func (m *MockBarangRepositoryHandler) GetByID(id int) (*models.BarangWithStok, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -40,6 +63,24 @@ func (m *MockBarangRepositoryHandler) GetByID(id int) (*models.BarangWithStok, e
 	return args.Get(0).(*models.BarangWithStok), args.Error(1)
 }
 
+func (m *MockBarangRepositoryHandler) GetByBarcode(code string) (*models.BarangWithStok, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BarangWithStok), args.Error(1)
+}
+
+func (m *MockBarangRepositoryHandler) AddBarcode(ctx context.Context, barangID int, code string) error {
+	args := m.Called(barangID, code)
+	return args.Error(0)
+}
+
+func (m *MockBarangRepositoryHandler) RemoveBarcode(ctx context.Context, barangID int, code string) error {
+	args := m.Called(barangID, code)
+	return args.Error(0)
+}
+
 func (m *MockBarangRepositoryHandler) GetByKode(kode string) (*models.Barang, error) {
 	args := m.Called(kode)
 	if args.Get(0) == nil {
@@ -48,17 +89,22 @@ func (m *MockBarangRepositoryHandler) GetByKode(kode string) (*models.Barang, er
 	return args.Get(0).(*models.Barang), args.Error(1)
 }
 
-func (m *MockBarangRepositoryHandler) Create(barang *models.Barang) error {
+func (m *MockBarangRepositoryHandler) Create(ctx context.Context, barang *models.Barang) error {
 	args := m.Called(barang)
 	return args.Error(0)
 }
 
-func (m *MockBarangRepositoryHandler) Update(barang *models.Barang) error {
+func (m *MockBarangRepositoryHandler) Update(ctx context.Context, barang *models.Barang) error {
 	args := m.Called(barang)
 	return args.Error(0)
 }
 
-func (m *MockBarangRepositoryHandler) Delete(id int) error {
+func (m *MockBarangRepositoryHandler) Delete(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockBarangRepositoryHandler) Restore(ctx context.Context, id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -68,6 +114,11 @@ func (m *MockBarangRepositoryHandler) Exists(id int) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockBarangRepositoryHandler) LockActive(tx *sql.Tx, id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestBarangHandlerGetAll(t *testing.T) {
 	t.Run("Success - Get all barang with pagination", func(t *testing.T) {
 		mockRepo := new(MockBarangRepositoryHandler)
@@ -78,7 +129,7 @@ func TestBarangHandlerGetAll(t *testing.T) {
 			{ID: 2, KodeBarang: "BRG-002", NamaBarang: "Item 2", Satuan: "pcs", HargaBeli: 2000, HargaJual: 2500},
 		}
 
-		mockRepo.On("GetAll", "", 10, 0, "", "").Return(expectedBarang, 2, nil)
+		mockRepo.On("GetAll", models.BarangFilter{}, 10, 0, "", "").Return(expectedBarang, 2, nil)
 
 		req := httptest.NewRequest("GET", "/api/barang?page=1&limit=10", nil)
 		w := httptest.NewRecorder()
@@ -97,7 +148,7 @@ func TestBarangHandlerGetAll(t *testing.T) {
 			{ID: 1, KodeBarang: "BRG-001", NamaBarang: "Laptop", Satuan: "unit", HargaBeli: 5000000, HargaJual: 6000000},
 		}
 
-		mockRepo.On("GetAll", "Laptop", 10, 0, "", "").Return(expectedBarang, 1, nil)
+		mockRepo.On("GetAll", models.BarangFilter{Search: "Laptop"}, 10, 0, "", "").Return(expectedBarang, 1, nil)
 
 		req := httptest.NewRequest("GET", "/api/barang?search=Laptop&page=1&limit=10", nil)
 		w := httptest.NewRecorder()
@@ -107,6 +158,37 @@ func TestBarangHandlerGetAll(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Success - Include archived barang", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		mockRepo.On("GetAll", models.BarangFilter{IncludeArchived: true}, 10, 0, "", "").Return([]models.Barang{}, 0, nil)
+
+		req := httptest.NewRequest("GET", "/api/barang?include_archived=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Filter by kategori, merek and tag", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		filter := models.BarangFilter{KategoriID: 2, MerekID: 5, Tag: "promo"}
+		mockRepo.On("GetAll", filter, 10, 0, "", "").Return([]models.Barang{}, 0, nil)
+
+		req := httptest.NewRequest("GET", "/api/barang?kategori_id=2&merek_id=5&tag=promo", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestBarangHandlerGetByID(t *testing.T) {
@@ -151,3 +233,152 @@ func TestBarangHandlerGetByID(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+func TestBarangHandlerDelete(t *testing.T) {
+	t.Run("Success - Archive active barang", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		existing := &models.BarangWithStok{Barang: models.Barang{ID: 1, NamaBarang: "Item", IsActive: true}}
+		mockRepo.On("GetByID", 1).Return(existing, nil)
+		mockRepo.On("Delete", 1).Return(nil)
+
+		req := httptest.NewRequest("DELETE", "/api/barang/1", nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Barang already archived", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		existing := &models.BarangWithStok{Barang: models.Barang{ID: 1, NamaBarang: "Item", IsActive: false}}
+		mockRepo.On("GetByID", 1).Return(existing, nil)
+
+		req := httptest.NewRequest("DELETE", "/api/barang/1", nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "Delete", 1)
+	})
+}
+
+func TestBarangHandlerRestore(t *testing.T) {
+	t.Run("Success - Restore archived barang", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		existing := &models.BarangWithStok{Barang: models.Barang{ID: 1, NamaBarang: "Item", IsActive: false}}
+		mockRepo.On("GetByID", 1).Return(existing, nil)
+		mockRepo.On("Restore", 1).Return(nil)
+
+		req := httptest.NewRequest("POST", "/api/barang/1/restore", nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Restore(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Barang not archived", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		existing := &models.BarangWithStok{Barang: models.Barang{ID: 1, NamaBarang: "Item", IsActive: true}}
+		mockRepo.On("GetByID", 1).Return(existing, nil)
+
+		req := httptest.NewRequest("POST", "/api/barang/1/restore", nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Restore(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "Restore", 1)
+	})
+}
+
+func TestBarangHandlerGetByBarcode(t *testing.T) {
+	t.Run("Success - Lookup by EAN-13", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		expected := &models.BarangWithStok{
+			Barang: models.Barang{ID: 7, NamaBarang: "Teh Botol", Barcodes: []string{"8992761166014"}, IsActive: true},
+			Stok:   24,
+		}
+		mockRepo.On("GetByBarcode", "8992761166014").Return(expected, nil)
+
+		req := httptest.NewRequest("GET", "/api/barang/barcode/8992761166014", nil)
+		req.SetPathValue("code", "8992761166014")
+		w := httptest.NewRecorder()
+
+		handler.GetByBarcode(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Invalid check digit", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		req := httptest.NewRequest("GET", "/api/barang/barcode/8992761166015", nil)
+		req.SetPathValue("code", "8992761166015")
+		w := httptest.NewRecorder()
+
+		handler.GetByBarcode(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "GetByBarcode", mock.Anything)
+	})
+}
+
+func TestBarangHandlerUpdateTags(t *testing.T) {
+	existing := &models.BarangWithStok{Barang: models.Barang{ID: 4, KodeBarang: "BRG-004", Tags: []string{"atk", "promo"}}}
+	serve := func(mockRepo *MockBarangRepositoryHandler, body string) int {
+		req := httptest.NewRequest("PUT", "/api/barang/4", strings.NewReader(body))
+		req.SetPathValue("id", "4")
+		w := httptest.NewRecorder()
+		handlers.NewBarangHandler(mockRepo).Update(w, req)
+		return w.Code
+	}
+
+	t.Run("Tags omitted are kept", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		mockRepo.On("Exists", 4).Return(true, nil)
+		mockRepo.On("GetByID", 4).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(b *models.Barang) bool {
+			return assert.ObjectsAreEqual([]string{"atk", "promo"}, b.Tags)
+		})).Return(nil)
+
+		code := serve(mockRepo, `{"nama_barang":"Pulpen","satuan":"pcs","harga_beli":1000,"harga_jual":1500}`)
+
+		assert.Equal(t, http.StatusOK, code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty tags clear all", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		mockRepo.On("Exists", 4).Return(true, nil)
+		mockRepo.On("GetByID", 4).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(b *models.Barang) bool {
+			return b.Tags != nil && len(b.Tags) == 0
+		})).Return(nil)
+
+		code := serve(mockRepo, `{"nama_barang":"Pulpen","satuan":"pcs","harga_beli":1000,"harga_jual":1500,"tags":[]}`)
+
+		assert.Equal(t, http.StatusOK, code)
+		mockRepo.AssertExpectations(t)
+	})
+}
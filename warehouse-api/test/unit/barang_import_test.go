@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"warehouse-api/documents"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newImportRequest(t *testing.T, filename string, content []byte, query string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filename)
+	assert.NoError(t, err)
+	part.Write(content)
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/barang/import"+query, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func decodeImportResult(t *testing.T, w *httptest.ResponseRecorder) models.BarangImportResult {
+	var resp struct {
+		Success bool                      `json:"success"`
+		Data    models.BarangImportResult `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func TestBarangHandlerImport(t *testing.T) {
+	t.Run("Success - CSV upsert with Indonesian numbers", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		csv := "kode_barang;nama_barang;satuan;harga_beli;harga_jual;tags\n" +
+			"BRG-001;Laptop Dell;unit;15.000.000;17.500.000,50;elektronik, promo\n" +
+			";Mouse Logitech;;150.000;200.000;\n"
+
+		mockRepo.On("Import", mock.MatchedBy(func(items []models.BarangImportItem) bool {
+			return len(items) == 2 &&
+				items[0].Row == 2 && items[0].Barang.KodeBarang == "BRG-001" && items[0].Barang.HargaJual == 17500000.5 &&
+				len(items[0].Barang.Tags) == 2 &&
+				items[1].Row == 3 && items[1].Barang.KodeBarang == "" && items[1].Barang.Satuan == "pcs"
+		}), false).Return(&models.BarangImportResult{Created: 1, Updated: 1, Errors: []models.ImportRowError{}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Import(w, newImportRequest(t, "barang.csv", []byte(csv), ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		result := decodeImportResult(t, w)
+		assert.Equal(t, 2, result.TotalRows)
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 1, result.Updated)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Dry run XLSX never commits", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		// File hasil export bisa langsung di-import kembali
+		var file bytes.Buffer
+		ew, _ := documents.NewExportWriter(&file, documents.ExportXLSX, documents.ExportLocaleID, "barang", []string{"Kode Barang", "Nama Barang", "Harga Beli", "Harga Jual"})
+		ew.WriteRow("BRG-010", "Keyboard", 250000.0, 300000.0)
+		ew.Close()
+
+		mockRepo.On("Import", mock.MatchedBy(func(items []models.BarangImportItem) bool {
+			return len(items) == 1 && items[0].Barang.NamaBarang == "Keyboard" && items[0].Barang.HargaBeli == 250000 && items[0].Barang.Tags == nil
+		}), true).Return(&models.BarangImportResult{Updated: 1, Errors: []models.ImportRowError{}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Import(w, newImportRequest(t, "barang.xlsx", file.Bytes(), "?dry_run=true"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, decodeImportResult(t, w).DryRun)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Invalid rows are reported and nothing is committed", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		csv := "nama_barang,harga_beli,harga_jual,barcodes\n" +
+			",1000,2000,\n" +
+			"Pensil,1000,2000,\n" +
+			"Buku,abc,2000,\n" +
+			"Penghapus,500,0,\n" +
+			"Spidol,500,800,123\n"
+
+		dbErr := models.ImportRowError{Row: 3, Error: "barcode sudah dipakai barang lain"}
+		mockRepo.On("Import", mock.Anything, true).Return(&models.BarangImportResult{Created: 0, Errors: []models.ImportRowError{dbErr}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Import(w, newImportRequest(t, "barang.csv", []byte(csv), ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		result := decodeImportResult(t, w)
+		assert.Equal(t, 5, result.TotalRows)
+		assert.Equal(t, 5, result.Failed)
+		rows := []int{}
+		for _, e := range result.Errors {
+			rows = append(rows, e.Row)
+		}
+		assert.Equal(t, []int{2, 3, 4, 5, 6}, rows)
+		assert.Equal(t, "Nama barang wajib diisi", result.Errors[0].Error)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Missing required column", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		w := httptest.NewRecorder()
+		handler.Import(w, newImportRequest(t, "barang.csv", []byte("nama_barang,harga_jual\nPensil,2000\n"), ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "harga_beli")
+		mockRepo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail - Unsupported file type", func(t *testing.T) {
+		mockRepo := new(MockBarangRepositoryHandler)
+		handler := handlers.NewBarangHandler(mockRepo)
+
+		w := httptest.NewRecorder()
+		handler.Import(w, newImportRequest(t, "barang.txt", []byte("x"), ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"testing"
+	"warehouse-api/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBarcode(t *testing.T) {
+	t.Run("Success - Valid EAN-13", func(t *testing.T) {
+		assert.NoError(t, utils.ValidateBarcode("4006381333931"))
+		assert.NoError(t, utils.ValidateBarcode("8992761166014"))
+	})
+
+	t.Run("Success - Valid EAN-8 and UPC-A", func(t *testing.T) {
+		assert.NoError(t, utils.ValidateBarcode("96385074"))
+		assert.NoError(t, utils.ValidateBarcode("036000291452"))
+	})
+
+	t.Run("Fail - Wrong check digit", func(t *testing.T) {
+		err := utils.ValidateBarcode("4006381333932")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "check digit")
+	})
+
+	t.Run("Fail - Non numeric and wrong length", func(t *testing.T) {
+		assert.Error(t, utils.ValidateBarcode("40063813339A1"))
+		assert.Error(t, utils.ValidateBarcode("12345"))
+		assert.Error(t, utils.ValidateBarcode(""))
+	})
+}
+
+func TestNormalizeBarcode(t *testing.T) {
+	assert.Equal(t, "4006381333931", utils.NormalizeBarcode(" 400-6381 333931 "))
+	assert.Equal(t, "0036000291452", utils.NormalizeBarcode("036000291452"), "UPC-A disimpan sebagai GTIN-13")
+	assert.Equal(t, "0036000291452", utils.NormalizeBarcode("0036000291452"))
+	assert.Equal(t, "96385074", utils.NormalizeBarcode("9638-5074"), "EAN-8 tidak diubah")
+	assert.NoError(t, utils.ValidateBarcode(utils.NormalizeBarcode("036000291452")))
+}
@@ -0,0 +1,27 @@
+package unit
+
+import (
+	"testing"
+	"time"
+	"warehouse-api/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 15, 10, 30, 45, 123456000, time.UTC)
+
+	cursor := utils.EncodeCursor(createdAt, 987)
+	gotTime, gotID, err := utils.DecodeCursor(cursor)
+
+	assert.NoError(t, err)
+	assert.True(t, createdAt.Equal(gotTime))
+	assert.Equal(t, 987, gotID)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, cursor := range []string{"bukan-base64!!", "MjAyNC0wMS0wMQ", utils.EncodeCursor(time.Now(), 0)} {
+		_, _, err := utils.DecodeCursor(cursor)
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor, cursor)
+	}
+}
@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDashboardRepository struct {
+	mock.Mock
+}
+
+func (m *MockDashboardRepository) GetStats(ctx context.Context, filter models.DashboardFilter) (*models.DashboardStats, error) {
+	args := m.Called(ctx, filter)
+	if fn, ok := args.Get(0).(func(context.Context) error); ok {
+		return nil, fn(ctx)
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DashboardStats), args.Error(1)
+}
+
+func TestDashboardHandlerGetStats(t *testing.T) {
+	t.Run("Success - Default filter", func(t *testing.T) {
+		repo := new(MockDashboardRepository)
+		handler := handlers.NewDashboardHandler(repo)
+		filter := models.DashboardFilter{TopN: 5, LowStockThreshold: 10}
+		repo.On("GetStats", mock.Anything, filter).Return(&models.DashboardStats{}, nil)
+
+		req := httptest.NewRequest("GET", "/api/dashboard", nil)
+		w := httptest.NewRecorder()
+		handler.GetStats(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Range, top and threshold", func(t *testing.T) {
+		repo := new(MockDashboardRepository)
+		handler := handlers.NewDashboardHandler(repo)
+		filter := models.DashboardFilter{StartDate: "2024-03-01", EndDate: "2024-03-31", TopN: 10, LowStockThreshold: 0}
+		repo.On("GetStats", mock.Anything, filter).Return(&models.DashboardStats{}, nil)
+
+		req := httptest.NewRequest("GET", "/api/dashboard?start_date=2024-03-01&end_date=2024-03-31&top=10&low_stock_threshold=0", nil)
+		w := httptest.NewRecorder()
+		handler.GetStats(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Invalid parameters", func(t *testing.T) {
+		for _, q := range []string{"start_date=kemarin", "start_date=2024-04-01&end_date=2024-03-01", "top=0", "top=51", "low_stock_threshold=-1"} {
+			repo := new(MockDashboardRepository)
+			handler := handlers.NewDashboardHandler(repo)
+
+			req := httptest.NewRequest("GET", "/api/dashboard?"+q, nil)
+			w := httptest.NewRecorder()
+			handler.GetStats(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, q)
+			repo.AssertNotCalled(t, "GetStats", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Fail - Query timeout", func(t *testing.T) {
+		repo := new(MockDashboardRepository)
+		handler := handlers.NewDashboardHandler(repo)
+		repo.On("GetStats", mock.Anything, mock.Anything).Return(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil)
+
+		// Deadline induk yang sudah lewat membuat timeout handler ikut habis
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		req := httptest.NewRequest("GET", "/api/dashboard", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		handler.GetStats(w, req)
+
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	})
+
+	t.Run("Fail - Repository error", func(t *testing.T) {
+		repo := new(MockDashboardRepository)
+		handler := handlers.NewDashboardHandler(repo)
+		repo.On("GetStats", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+		req := httptest.NewRequest("GET", "/api/dashboard", nil)
+		w := httptest.NewRecorder()
+		handler.GetStats(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-api/documents"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportWriterCSV(t *testing.T) {
+	createdAt := time.Date(2024, 8, 17, 9, 5, 0, 0, time.UTC)
+
+	t.Run("Success - Locale id", func(t *testing.T) {
+		var buf bytes.Buffer
+		ew, err := documents.NewExportWriter(&buf, documents.ExportCSV, documents.ExportLocaleID, "test", []string{"Nama", "Total", "Tanggal"})
+		assert.NoError(t, err)
+		assert.NoError(t, ew.WriteRow("=SUM(A1)", 1234567.5, createdAt))
+		assert.NoError(t, ew.Close())
+
+		out := strings.TrimPrefix(buf.String(), "\uFEFF")
+		assert.True(t, strings.HasPrefix(buf.String(), "\uFEFF"))
+
+		r := csv.NewReader(strings.NewReader(out))
+		r.Comma = ';'
+		records, err := r.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Nama", "Total", "Tanggal"}, records[0])
+		assert.Equal(t, []string{"'=SUM(A1)", "1.234.567,50", "17/08/2024 09:05:00"}, records[1])
+	})
+
+	t.Run("Success - Locale raw", func(t *testing.T) {
+		var buf bytes.Buffer
+		ew, err := documents.NewExportWriter(&buf, documents.ExportCSV, documents.ExportLocaleRaw, "test", []string{"Qty", "Total", "Tanggal"})
+		assert.NoError(t, err)
+		assert.NoError(t, ew.WriteRow(1500, 1234567.5, createdAt))
+		assert.NoError(t, ew.Close())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1500", "1234567.5", "2024-08-17T09:05:00Z"}, records[1])
+	})
+
+	t.Run("Fail - Unsupported format", func(t *testing.T) {
+		_, err := documents.NewExportWriter(io.Discard, "pdf", documents.ExportLocaleID, "test", nil)
+		assert.ErrorIs(t, err, documents.ErrUnsupportedExport)
+	})
+}
+
+func TestExportWriterXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	ew, err := documents.NewExportWriter(&buf, documents.ExportXLSX, documents.ExportLocaleID, "penjualan", []string{"No Faktur", "Total", "Tanggal"})
+	assert.NoError(t, err)
+	assert.NoError(t, ew.WriteRow("JUAL-<001>", 17500000.0, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+	assert.NoError(t, ew.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/styles.xml")
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, "JUAL-&lt;001&gt;")
+	assert.Contains(t, sheet, "<v>17500000</v>")
+	assert.Contains(t, sheet, "<v>45292.5</v>") // serial Excel untuk 1 Jan 2024 12:00
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+}
+
+func TestPenjualanHandlerExport(t *testing.T) {
+	t.Run("Success - CSV streams every filtered row", func(t *testing.T) {
+		mockRepo := new(MockPenjualanRepository)
+		handler := handlers.NewPenjualanHandler(nil, mockRepo)
+
+		rows := []models.JualHeader{
+			{NoFaktur: "JUAL-001", Customer: "Budi", Status: "completed", Total: 50000, User: &models.User{Username: "staff"}},
+			{NoFaktur: "JUAL-002", Customer: "Sari", Status: "completed", Total: 75000, User: &models.User{Username: "staff"}},
+		}
+		mockRepo.On("Stream", models.TransaksiFilter{Status: "completed"}, "", "", mock.Anything).Return(rows, nil)
+
+		req := httptest.NewRequest("GET", "/api/penjualan?format=csv&locale=raw&status=completed", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, "JUAL-002", records[2][0])
+		mockRepo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail - Invalid format", func(t *testing.T) {
+		mockRepo := new(MockPenjualanRepository)
+		handler := handlers.NewPenjualanHandler(nil, mockRepo)
+
+		req := httptest.NewRequest("GET", "/api/penjualan?format=ods", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Fail - Repository error before any row is sent", func(t *testing.T) {
+		mockRepo := new(MockPenjualanRepository)
+		handler := handlers.NewPenjualanHandler(nil, mockRepo)
+
+		mockRepo.On("Stream", models.TransaksiFilter{}, "", "", mock.Anything).Return(nil, errors.New("db down"))
+
+		req := httptest.NewRequest("GET", "/api/penjualan?format=xlsx", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	})
+}
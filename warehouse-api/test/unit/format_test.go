@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"testing"
+	"warehouse-api/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatNumberID(t *testing.T) {
+	assert.Equal(t, "1.234.567,50", utils.FormatNumberID(1234567.5, 2))
+	assert.Equal(t, "999", utils.FormatNumberID(999, 0))
+	assert.Equal(t, "-12.000", utils.FormatNumberID(-12000, 0))
+	assert.Equal(t, "0,00", utils.FormatNumberID(0, 2))
+}
+
+func TestFormatRupiah(t *testing.T) {
+	assert.Equal(t, "Rp 17.500.000", utils.FormatRupiah(17500000))
+	assert.Equal(t, "Rp 350.000", utils.FormatRupiah(349999.6))
+}
+
+func TestParseNumberID(t *testing.T) {
+	cases := map[string]float64{
+		"17500000":      17500000,
+		"17.500.000":    17500000,
+		"17.500.000,50": 17500000.5,
+		"Rp 17.500.000": 17500000,
+		"17,500,000.50": 17500000.5,
+		"1.500":         1500,
+		"12.5":          12.5,
+		"2,5":           2.5,
+	}
+	for input, want := range cases {
+		got, err := utils.ParseNumberID(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := utils.ParseNumberID("dua ribu")
+	assert.Error(t, err)
+}
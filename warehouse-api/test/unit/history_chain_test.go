@@ -0,0 +1,310 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// buildChain membuat n baris riwayat ber-hash untuk satu barang mulai dari id firstID
+func buildChain(barangID, firstID, n int) []models.HistoryStok {
+	var rows []models.HistoryStok
+	prev := ""
+	for i := 0; i < n; i++ {
+		h := models.HistoryStok{
+			ID:             firstID + i,
+			BarangID:       barangID,
+			UserID:         1,
+			JenisTransaksi: "masuk",
+			Jumlah:         5,
+			StokSebelum:    5 * i,
+			StokSesudah:    5 * (i + 1),
+			Keterangan:     fmt.Sprintf("Pembelian BLI%03d", firstID+i),
+			CreatedAt:      time.Date(2026, 1, 1+i, 9, 30, 0, 123456000, time.UTC),
+		}
+		h.PrevHash = prev
+		h.Hash = repositories.HistoryHash(h, prev)
+		prev = h.Hash
+		rows = append(rows, h)
+	}
+	return rows
+}
+
+// anchorsFor membuat anchor bertanda tangan dari rantai yang masih utuh (sebelum diubah di test)
+func anchorsFor(rows []models.HistoryStok) map[int]models.HistoryChainAnchor {
+	anchors := map[int]models.HistoryChainAnchor{}
+	for _, h := range rows {
+		a := anchors[h.BarangID]
+		a.BarangID, a.Baris, a.LastID, a.LastHash = h.BarangID, a.Baris+1, h.ID, h.Hash
+		a.SealedAt = time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+		anchors[h.BarangID] = a
+	}
+	for id, a := range anchors {
+		a.Signature = repositories.HistoryAnchorSignature(a)
+		anchors[id] = a
+	}
+	return anchors
+}
+
+func sealMarker() *models.HistoryChainSeal {
+	at := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	return &models.HistoryChainSeal{SealedAt: at, Signature: repositories.HistorySealSignature(at)}
+}
+
+// verifyChain memeriksa rows dengan anchor dan penanda seal; barang di unsealed ditandai belum di-seal
+func verifyChain(t *testing.T, rows []models.HistoryStok, anchors map[int]models.HistoryChainAnchor, seal *models.HistoryChainSeal, unsealed ...int) *models.HistoryChainReport {
+	t.Helper()
+	repo := new(MockStokRepository)
+	repo.On("HistoryChainAnchors", 0).Return(anchors, seal, nil)
+	repo.On("EachHistoryChain", 0, mock.Anything).Return(rows, unsealed, nil)
+
+	report, err := services.VerifyHistoryChain(repo, 0)
+	require.NoError(t, err)
+	return report
+}
+
+func TestHistoryHash(t *testing.T) {
+	h := buildChain(1, 1, 1)[0]
+
+	assert.Len(t, h.Hash, 64)
+	assert.Equal(t, h.Hash, repositories.HistoryHash(h, ""))
+	assert.NotEqual(t, h.Hash, repositories.HistoryHash(h, h.Hash), "prev hash ikut dihitung")
+
+	h.CreatedAt = h.CreatedAt.Add(time.Microsecond)
+	assert.NotEqual(t, h.Hash, repositories.HistoryHash(h, ""), "created_at dihitung sampai mikrodetik")
+}
+
+func TestHistoryHashKeyed(t *testing.T) {
+	h := buildChain(1, 1, 1)[0]
+
+	repositories.SetHistoryChainKey([]byte("kunci-lain-yang-tidak-dimiliki-penyerang"))
+	defer repositories.SetHistoryChainKey(nil)
+
+	assert.NotEqual(t, h.Hash, repositories.HistoryHash(h, ""), "hash bergantung pada kunci server")
+}
+
+func TestVerifyHistoryChain(t *testing.T) {
+	t.Run("Valid - intact chains for several barang", func(t *testing.T) {
+		rows := append(buildChain(1, 1, 3), buildChain(2, 10, 2)...)
+		report := verifyChain(t, rows, anchorsFor(rows), sealMarker())
+
+		assert.True(t, report.Valid)
+		assert.Equal(t, 2, report.Barang)
+		assert.Equal(t, 5, report.Baris)
+		assert.Nil(t, report.Rusak)
+	})
+
+	t.Run("Broken - edited row", func(t *testing.T) {
+		rows := buildChain(1, 1, 4)
+		anchors := anchorsFor(rows)
+		rows[1].Jumlah = 500
+
+		report := verifyChain(t, rows, anchors, sealMarker())
+
+		assert.False(t, report.Valid)
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 2, report.Rusak.HistoryID)
+		assert.Contains(t, report.Rusak.Alasan, "diubah")
+	})
+
+	t.Run("Broken - edited row with recomputed hash is caught on the next row", func(t *testing.T) {
+		rows := buildChain(1, 1, 4)
+		anchors := anchorsFor(rows)
+		rows[1].Jumlah = 500
+		rows[1].Hash = repositories.HistoryHash(rows[1], rows[1].PrevHash)
+
+		report := verifyChain(t, rows, anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 3, report.Rusak.HistoryID)
+		assert.Contains(t, report.Rusak.Alasan, "prev_hash")
+	})
+
+	t.Run("Broken - chain rebuilt without the server key", func(t *testing.T) {
+		rows := buildChain(1, 1, 3)
+		anchors := anchorsFor(rows)
+		repositories.SetHistoryChainKey([]byte("tebakan-penyerang"))
+		forged := buildChain(1, 1, 3)
+		forged[0].Jumlah = 500
+		repositories.SetHistoryChainKey(nil)
+
+		report := verifyChain(t, forged, anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 1, report.Rusak.HistoryID)
+	})
+
+	t.Run("Broken - deleted row", func(t *testing.T) {
+		rows := buildChain(1, 1, 4)
+		anchors := anchorsFor(rows)
+		rows = append(rows[:1], rows[2:]...)
+
+		report := verifyChain(t, rows, anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 3, report.Rusak.HistoryID)
+		assert.Equal(t, 1, report.Rusak.BarangID)
+	})
+
+	t.Run("Broken - newest rows deleted", func(t *testing.T) {
+		rows := buildChain(1, 1, 4)
+		anchors := anchorsFor(rows)
+
+		report := verifyChain(t, rows[:2], anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 1, report.Rusak.BarangID)
+		assert.Contains(t, report.Rusak.Alasan, "anchor")
+	})
+
+	t.Run("Broken - whole history of a barang deleted", func(t *testing.T) {
+		rows := append(buildChain(1, 1, 2), buildChain(2, 10, 2)...)
+		anchors := anchorsFor(rows)
+
+		report := verifyChain(t, rows[:2], anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 2, report.Rusak.BarangID)
+	})
+
+	t.Run("Broken - forged anchor", func(t *testing.T) {
+		rows := buildChain(1, 1, 3)
+		anchors := anchorsFor(rows)
+		a := anchors[1]
+		a.Baris, a.LastID, a.LastHash = 2, rows[1].ID, rows[1].Hash
+		anchors[1] = a
+
+		report := verifyChain(t, rows[:2], anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Contains(t, report.Rusak.Alasan, "dipalsukan")
+	})
+
+	t.Run("Broken - hash removed inside sealed chain", func(t *testing.T) {
+		rows := buildChain(1, 1, 3)
+		anchors := anchorsFor(rows)
+		rows[2].Hash, rows[2].PrevHash = "", ""
+
+		report := verifyChain(t, rows, anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 3, report.Rusak.HistoryID)
+	})
+
+	t.Run("Broken - unhashed rows before a chain", func(t *testing.T) {
+		rows := buildChain(1, 1, 3)
+		anchors := anchorsFor(rows)
+		rows[0].Hash = ""
+
+		report := verifyChain(t, rows, anchors, sealMarker())
+
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 1, report.Rusak.HistoryID)
+	})
+
+	t.Run("Broken - seal flag reverted and all hashes removed", func(t *testing.T) {
+		rows := buildChain(1, 1, 3)
+		anchors := anchorsFor(rows)
+		for i := range rows {
+			rows[i].Jumlah = 50
+			rows[i].Hash, rows[i].PrevHash = "", ""
+		}
+
+		report := verifyChain(t, rows, anchors, sealMarker(), 1)
+
+		assert.False(t, report.Valid)
+		require.NotNil(t, report.Rusak)
+		assert.Equal(t, 1, report.Rusak.HistoryID)
+		assert.Zero(t, report.BelumDiseal)
+	})
+
+	t.Run("Broken - anchor deleted after the seal", func(t *testing.T) {
+		rows := buildChain(1, 1, 3)
+		for i := range rows {
+			rows[i].Hash, rows[i].PrevHash = "", ""
+		}
+
+		report := verifyChain(t, rows, nil, sealMarker(), 1)
+
+		assert.False(t, report.Valid)
+		assert.Zero(t, report.BelumDiseal)
+	})
+
+	t.Run("Broken - forged seal marker", func(t *testing.T) {
+		seal := sealMarker()
+		seal.SealedAt = seal.SealedAt.Add(time.Hour)
+
+		report := verifyChain(t, nil, nil, seal)
+
+		assert.False(t, report.Valid)
+	})
+
+	t.Run("Valid - barang not sealed yet is only counted", func(t *testing.T) {
+		chain := buildChain(1, 1, 2)
+		legacy := []models.HistoryStok{{ID: 20, BarangID: 3}, {ID: 21, BarangID: 3}}
+
+		report := verifyChain(t, append(chain, legacy...), anchorsFor(chain), nil, 3)
+
+		assert.True(t, report.Valid)
+		assert.Equal(t, 2, report.BelumDiseal)
+	})
+
+	t.Run("Fail - repository error", func(t *testing.T) {
+		repo := new(MockStokRepository)
+		repo.On("HistoryChainAnchors", 4).Return(nil, nil, nil)
+		repo.On("EachHistoryChain", 4, mock.Anything).Return(nil, nil, errors.New("db down"))
+
+		_, err := services.VerifyHistoryChain(repo, 4)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestStokHandlerVerifyHistory(t *testing.T) {
+	t.Run("Success - broken chain is reported with 200", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+		rows := buildChain(7, 1, 2)
+		anchors := anchorsFor(rows)
+		rows[0].Keterangan = "diubah manual"
+		mockRepo.On("HistoryChainAnchors", 7).Return(anchors, sealMarker(), nil)
+		mockRepo.On("EachHistoryChain", 7, mock.Anything).Return(rows, nil, nil)
+
+		req := httptest.NewRequest("GET", "/api/history-stok/verify?barang_id=7", nil)
+		w := httptest.NewRecorder()
+
+		handler.VerifyHistory(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data models.HistoryChainReport `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Data.Valid)
+		require.NotNil(t, response.Data.Rusak)
+		assert.Equal(t, 1, response.Data.Rusak.HistoryID)
+	})
+
+	t.Run("Fail - invalid barang_id", func(t *testing.T) {
+		handler := handlers.NewStokHandler(new(MockStokRepository))
+
+		req := httptest.NewRequest("GET", "/api/history-stok/verify?barang_id=abc", nil)
+		w := httptest.NewRecorder()
+
+		handler.VerifyHistory(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
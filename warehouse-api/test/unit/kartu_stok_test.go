@@ -0,0 +1,196 @@
+package unit
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-api/config"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func kartuStokMovements() []models.StokMovement {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 9, 0, 0, 0, time.UTC) }
+	return []models.StokMovement{
+		{ID: 1, CreatedAt: day(1, 5), JenisTransaksi: "saldo_awal", Jumlah: 10, StokSebelum: 0, StokSesudah: 10, HargaSatuan: floatPtr(1000)},
+		{ID: 2, CreatedAt: day(1, 20), JenisTransaksi: "masuk", Jumlah: 10, StokSebelum: 10, StokSesudah: 20, Referensi: "BL-001", HargaSatuan: floatPtr(1300)},
+		{ID: 3, CreatedAt: day(2, 3), JenisTransaksi: "keluar", Jumlah: 5, StokSebelum: 20, StokSesudah: 15, Referensi: "JL-001"},
+		{ID: 4, CreatedAt: day(2, 10), JenisTransaksi: "adjustment", Jumlah: 10, StokSebelum: 15, StokSesudah: 25},
+	}
+}
+
+func TestKartuStokServiceGet(t *testing.T) {
+	barang := &models.BarangWithStok{Barang: models.Barang{ID: 1, KodeBarang: "BRG001", NamaBarang: "Mouse", HargaBeli: 1000}}
+
+	t.Run("Success - Opening balance, running balance and moving average value", func(t *testing.T) {
+		stokRepo := new(MockStokRepository)
+		barangRepo := new(MockBarangRepositoryHandler)
+		barangRepo.On("GetByID", 1).Return(barang, nil)
+		stokRepo.On("EachMovement", 1, "2024-02-29", mock.Anything).Return(kartuStokMovements(), nil)
+
+		kartu, err := services.NewKartuStokService(stokRepo, barangRepo).Get(context.Background(), 1, "2024-02-01", "2024-02-29")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 20, kartu.SaldoAwal)
+		assert.Equal(t, 23000.0, *kartu.NilaiSaldoAwal)
+		assert.Len(t, kartu.Entries, 2)
+
+		keluar := kartu.Entries[0]
+		assert.Equal(t, "JL-001", keluar.Referensi)
+		assert.Equal(t, 5, keluar.Keluar)
+		assert.Equal(t, 15, keluar.Saldo)
+		assert.Equal(t, 1150.0, *keluar.HargaSatuan)
+		assert.Equal(t, 5750.0, *keluar.Nilai)
+		assert.Equal(t, 17250.0, *keluar.NilaiSaldo)
+
+		// Barang masuk tanpa harga pokok membuat nilai persediaan tidak diketahui
+		adjustment := kartu.Entries[1]
+		assert.Equal(t, 10, adjustment.Masuk)
+		assert.Nil(t, adjustment.NilaiSaldo)
+
+		assert.Equal(t, 10, kartu.TotalMasuk)
+		assert.Equal(t, 5, kartu.TotalKeluar)
+		assert.Equal(t, 25, kartu.SaldoAkhir)
+		assert.Nil(t, kartu.NilaiSaldoAkhir)
+	})
+
+	t.Run("Success - No movement in period", func(t *testing.T) {
+		stokRepo := new(MockStokRepository)
+		barangRepo := new(MockBarangRepositoryHandler)
+		barangRepo.On("GetByID", 1).Return(barang, nil)
+		stokRepo.On("EachMovement", 1, "2024-01-31", mock.Anything).Return(kartuStokMovements()[:2], nil)
+
+		kartu, err := services.NewKartuStokService(stokRepo, barangRepo).Get(context.Background(), 1, "2024-01-25", "2024-01-31")
+
+		assert.NoError(t, err)
+		assert.Empty(t, kartu.Entries)
+		assert.Equal(t, 20, kartu.SaldoAwal)
+		assert.Equal(t, 20, kartu.SaldoAkhir)
+		assert.Equal(t, 23000.0, *kartu.NilaiSaldoAkhir)
+	})
+
+	t.Run("Success - Hidden movements move the balance but are not listed", func(t *testing.T) {
+		stokRepo := new(MockStokRepository)
+		barangRepo := new(MockBarangRepositoryHandler)
+		barangRepo.On("GetByID", 1).Return(barang, nil)
+		movements := kartuStokMovements()
+		movements[2].Hidden = true
+		stokRepo.On("EachMovement", 1, "2024-02-29", mock.Anything).Return(movements, nil)
+
+		kartu, err := services.NewKartuStokService(stokRepo, barangRepo).Get(context.Background(), 1, "2024-02-01", "2024-02-29")
+
+		assert.NoError(t, err)
+		if assert.Len(t, kartu.Entries, 1) {
+			assert.Equal(t, 4, kartu.Entries[0].HistoryID)
+			assert.Equal(t, 25, kartu.Entries[0].Saldo)
+		}
+		assert.Zero(t, kartu.TotalKeluar)
+		assert.Equal(t, 20, kartu.SaldoAwal)
+		assert.Equal(t, 25, kartu.SaldoAkhir)
+	})
+}
+
+type MockKartuStokService struct {
+	mock.Mock
+}
+
+func (m *MockKartuStokService) Get(ctx context.Context, barangID int, startDate, endDate string) (*models.KartuStok, error) {
+	args := m.Called(barangID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KartuStok), args.Error(1)
+}
+
+func TestKartuStokHandlerGet(t *testing.T) {
+	kartu := &models.KartuStok{
+		Barang:    &models.Barang{ID: 1, KodeBarang: "BRG001", NamaBarang: "Mouse", Satuan: "pcs"},
+		StartDate: "2024-02-01", EndDate: "2024-02-29",
+		SaldoAwal: 20, NilaiSaldoAwal: floatPtr(23000), SaldoAkhir: 15, TotalKeluar: 5,
+		Entries: []models.KartuStokEntry{{HistoryID: 3, Tanggal: time.Date(2024, 2, 3, 9, 0, 0, 0, time.UTC), JenisTransaksi: "keluar",
+			Referensi: "JL-001", Keluar: 5, Saldo: 15, HargaSatuan: floatPtr(1150), Nilai: floatPtr(5750), NilaiSaldo: floatPtr(17250)}},
+	}
+
+	serve := func(svc *MockKartuStokService, url string) *httptest.ResponseRecorder {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/stok/{id}/kartu", handlers.NewKartuStokHandler(svc, config.Company{Name: "PT Uji"}).Get)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w
+	}
+
+	t.Run("Success - JSON", func(t *testing.T) {
+		svc := new(MockKartuStokService)
+		svc.On("Get", 1, "2024-02-01", "2024-02-29").Return(kartu, nil)
+
+		w := serve(svc, "/api/stok/1/kartu?start_date=2024-02-01&end_date=2024-02-29")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"saldo_awal":20`)
+	})
+
+	t.Run("Success - Default period is current month of end_date", func(t *testing.T) {
+		svc := new(MockKartuStokService)
+		svc.On("Get", 1, "2024-02-01", "2024-02-15").Return(kartu, nil)
+
+		w := serve(svc, "/api/stok/1/kartu?end_date=2024-02-15")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("Success - CSV export with opening and closing rows", func(t *testing.T) {
+		svc := new(MockKartuStokService)
+		svc.On("Get", 1, "2024-02-01", "2024-02-29").Return(kartu, nil)
+
+		w := serve(svc, "/api/stok/1/kartu?start_date=2024-02-01&end_date=2024-02-29&format=csv&locale=raw")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 4)
+		assert.Contains(t, lines[1], "Saldo awal")
+		assert.Contains(t, lines[2], "JL-001")
+		assert.Contains(t, lines[2], "17250")
+		assert.Contains(t, lines[3], "Saldo akhir")
+	})
+
+	t.Run("Success - PDF", func(t *testing.T) {
+		svc := new(MockKartuStokService)
+		svc.On("Get", 1, "2024-02-01", "2024-02-29").Return(kartu, nil)
+
+		w := serve(svc, "/api/stok/1/kartu?start_date=2024-02-01&end_date=2024-02-29&format=pdf")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "%PDF"))
+	})
+
+	t.Run("Fail - Start after end", func(t *testing.T) {
+		svc := new(MockKartuStokService)
+
+		w := serve(svc, "/api/stok/1/kartu?start_date=2024-03-01&end_date=2024-02-29")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Fail - Barang not found", func(t *testing.T) {
+		svc := new(MockKartuStokService)
+		svc.On("Get", 99, "2024-02-01", "2024-02-29").Return(nil, sql.ErrNoRows)
+
+		w := serve(svc, "/api/stok/99/kartu?start_date=2024-02-01&end_date=2024-02-29")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
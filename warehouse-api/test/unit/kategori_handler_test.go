@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Kategori Repository
+type MockKategoriRepository struct {
+	mock.Mock
+}
+
+func (m *MockKategoriRepository) Create(ctx context.Context, kategori *models.Kategori) error {
+	args := m.Called(kategori)
+	return args.Error(0)
+}
+
+func (m *MockKategoriRepository) Update(ctx context.Context, kategori *models.Kategori) error {
+	args := m.Called(kategori)
+	return args.Error(0)
+}
+
+func (m *MockKategoriRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockKategoriRepository) GetByID(id int) (*models.Kategori, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Kategori), args.Error(1)
+}
+
+func (m *MockKategoriRepository) GetAll() ([]models.Kategori, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Kategori), args.Error(1)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestKategoriHandlerGetAll(t *testing.T) {
+	t.Run("Success - Return kategori as tree", func(t *testing.T) {
+		mockRepo := new(MockKategoriRepository)
+		handler := handlers.NewKategoriHandler(mockRepo)
+
+		mockRepo.On("GetAll").Return([]models.Kategori{
+			{ID: 1, Nama: "Elektronik"},
+			{ID: 2, Nama: "Laptop", ParentID: intPtr(1)},
+			{ID: 3, Nama: "Gaming", ParentID: intPtr(2)},
+			{ID: 4, Nama: "ATK"},
+		}, nil)
+
+		req := httptest.NewRequest("GET", "/api/kategori", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []models.Kategori `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Len(t, response.Data, 2)
+		assert.Equal(t, "Laptop", response.Data[0].Children[0].Nama)
+		assert.Equal(t, "Gaming", response.Data[0].Children[0].Children[0].Nama)
+	})
+}
+
+func TestKategoriHandlerUpdate(t *testing.T) {
+	t.Run("Fail - Parent is a descendant", func(t *testing.T) {
+		mockRepo := new(MockKategoriRepository)
+		handler := handlers.NewKategoriHandler(mockRepo)
+
+		mockRepo.On("GetByID", 1).Return(&models.Kategori{ID: 1, Nama: "Elektronik"}, nil)
+		mockRepo.On("GetByID", 3).Return(&models.Kategori{ID: 3, Nama: "Gaming", ParentID: intPtr(2)}, nil)
+		mockRepo.On("Update", mock.Anything).Return(repositories.ErrKategoriSiklus)
+
+		body, _ := json.Marshal(models.CreateKategoriRequest{Nama: "Elektronik", ParentID: intPtr(3)})
+		req := httptest.NewRequest("PUT", "/api/kategori/1", bytes.NewBuffer(body))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Update(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,252 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockKlasifikasiRepository struct {
+	mock.Mock
+}
+
+func (m *MockKlasifikasiRepository) Demand(startDate, endDate, periode string) ([]models.BarangDemand, error) {
+	args := m.Called(startDate, endDate, periode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BarangDemand), args.Error(1)
+}
+
+func (m *MockKlasifikasiRepository) SaveRun(run *models.KlasifikasiRun, items []models.BarangKlasifikasi) error {
+	args := m.Called(run, items)
+	run.ID = 1
+	return args.Error(0)
+}
+
+func (m *MockKlasifikasiRepository) GetLatestRun() (*models.KlasifikasiRun, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KlasifikasiRun), args.Error(1)
+}
+
+func (m *MockKlasifikasiRepository) GetItems(runID int) ([]models.BarangKlasifikasi, error) {
+	args := m.Called(runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BarangKlasifikasi), args.Error(1)
+}
+
+func (m *MockKlasifikasiRepository) CreateOpname(ctx context.Context, opname *models.StokOpname) error {
+	args := m.Called(opname)
+	opname.ID = 7
+	return args.Error(0)
+}
+
+func (m *MockKlasifikasiRepository) GetOpnameByID(id int) (*models.StokOpname, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StokOpname), args.Error(1)
+}
+
+func (m *MockKlasifikasiRepository) GetAllOpname(limit, offset int) ([]models.StokOpname, int, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]models.StokOpname), args.Int(1), args.Error(2)
+}
+
+var defaultKlasifikasiParams = models.KlasifikasiParams{Periode: "month", BatasA: 80, BatasB: 95, BatasX: 0.5, BatasY: 1.0}
+
+func TestKlasifikasiABCXYZ(t *testing.T) {
+	buckets := []string{"2024-01-01", "2024-02-01", "2024-03-01", "2024-04-01"}
+	demand := []models.BarangDemand{
+		{BarangID: 3, KodeBarang: "BRG003", Nilai: 150, Qty: 4, QtyPerPeriode: map[string]int{"2024-01-01": 4}},
+		{BarangID: 1, KodeBarang: "BRG001", Nilai: 700, Qty: 40, QtyPerPeriode: map[string]int{"2024-01-01": 10, "2024-02-01": 10, "2024-03-01": 10, "2024-04-01": 10}},
+		{BarangID: 4, KodeBarang: "BRG004", QtyPerPeriode: map[string]int{}},
+		{BarangID: 2, KodeBarang: "BRG002", Nilai: 150, Qty: 8, QtyPerPeriode: map[string]int{"2024-01-01": 2, "2024-02-01": 6}},
+	}
+
+	items := services.KlasifikasiABCXYZ(demand, buckets, defaultKlasifikasiParams)
+
+	assert.Len(t, items, 4)
+	byKode := map[string]models.BarangKlasifikasi{}
+	for _, it := range items {
+		byKode[it.KodeBarang] = it
+	}
+
+	// Urutan: BRG001 (70%), lalu BRG002 dan BRG003 (masing-masing 15%, urut kode), lalu BRG004
+	assert.Equal(t, "BRG001", items[0].KodeBarang)
+	assert.Equal(t, "BRG002", items[1].KodeBarang)
+
+	// Barang teratas selalu A walau sendirian sudah melewati batas; B selama kumulatif sebelumnya < 95%
+	assert.Equal(t, "A", byKode["BRG001"].KelasABC)
+	assert.Equal(t, 70.0, byKode["BRG001"].Kontribusi)
+	assert.Equal(t, "A", byKode["BRG002"].KelasABC) // kumulatif sebelumnya 70 < 80
+	assert.Equal(t, "B", byKode["BRG003"].KelasABC) // kumulatif sebelumnya 85
+	assert.Equal(t, 100.0, byKode["BRG003"].Kumulatif)
+	assert.Equal(t, "C", byKode["BRG004"].KelasABC)
+
+	// Permintaan stabil = X, dua periode = Y/Z, satu periode saja = Z, tanpa penjualan = Z tanpa CV
+	assert.Equal(t, "X", byKode["BRG001"].KelasXYZ)
+	assert.Equal(t, 0.0, *byKode["BRG001"].CV)
+	assert.Equal(t, "Z", byKode["BRG002"].KelasXYZ) // mean 2, sd ~2.449 -> CV 1.2247
+	assert.InDelta(t, 1.2247, *byKode["BRG002"].CV, 0.0001)
+	assert.Equal(t, "Z", byKode["BRG003"].KelasXYZ)
+	assert.Equal(t, "Z", byKode["BRG004"].KelasXYZ)
+	assert.Nil(t, byKode["BRG004"].CV)
+}
+
+func TestKlasifikasiServiceLatest(t *testing.T) {
+	t.Run("Filter limits items but not matrix", func(t *testing.T) {
+		repo := new(MockKlasifikasiRepository)
+		repo.On("GetLatestRun").Return(&models.KlasifikasiRun{ID: 3}, nil)
+		repo.On("GetItems", 3).Return([]models.BarangKlasifikasi{
+			{BarangID: 1, KelasABC: "A", KelasXYZ: "X", Nilai: 100},
+			{BarangID: 2, KelasABC: "B", KelasXYZ: "Z", Nilai: 50},
+			{BarangID: 3, KelasABC: "C", KelasXYZ: "X", Nilai: 10},
+		}, nil)
+
+		report, err := services.NewKlasifikasiService(repo).Latest(visibleCtx(1, models.VisibilityAll), models.KlasifikasiFilter{KelasABC: "b,a", KelasXYZ: "x"})
+
+		assert.NoError(t, err)
+		assert.Len(t, report.Items, 1)
+		assert.Equal(t, 1, report.Items[0].BarangID)
+		assert.Len(t, report.Matriks, 9)
+		assert.Equal(t, models.KlasifikasiSel{KelasABC: "C", KelasXYZ: "X", JumlahBarang: 1, Nilai: 10}, report.Matriks[6])
+		assert.False(t, report.Terbatas)
+	})
+
+	t.Run("Restricted caller only sees classes", func(t *testing.T) {
+		cv := 0.4
+		repo := new(MockKlasifikasiRepository)
+		repo.On("GetLatestRun").Return(&models.KlasifikasiRun{ID: 3}, nil)
+		repo.On("GetItems", 3).Return([]models.BarangKlasifikasi{
+			{BarangID: 1, KelasABC: "A", KelasXYZ: "Y", Nilai: 100, Kontribusi: 90, Kumulatif: 90, Qty: 12, Frekuensi: 4, CV: &cv},
+			{BarangID: 2, KelasABC: "C", KelasXYZ: "Z", Nilai: 10, Kontribusi: 10, Kumulatif: 100, Qty: 1, Frekuensi: 1},
+		}, nil)
+
+		for _, ctx := range []context.Context{visibleCtx(2, models.VisibilityOwn), context.Background()} {
+			report, err := services.NewKlasifikasiService(repo).Latest(ctx, models.KlasifikasiFilter{})
+
+			assert.NoError(t, err)
+			assert.True(t, report.Terbatas)
+			assert.Equal(t, models.BarangKlasifikasi{BarangID: 1, KelasABC: "A", KelasXYZ: "Y"}, report.Items[0])
+			assert.Equal(t, models.KlasifikasiSel{KelasABC: "A", KelasXYZ: "Y", JumlahBarang: 1}, report.Matriks[1])
+		}
+	})
+
+	t.Run("Invalid class letter", func(t *testing.T) {
+		repo := new(MockKlasifikasiRepository)
+
+		_, err := services.NewKlasifikasiService(repo).Latest(context.Background(), models.KlasifikasiFilter{KelasABC: "AD"})
+
+		assert.ErrorIs(t, err, services.ErrKelasTidakValid)
+		repo.AssertNotCalled(t, "GetLatestRun")
+	})
+}
+
+func TestKlasifikasiServiceCreateOpname(t *testing.T) {
+	repo := new(MockKlasifikasiRepository)
+	repo.On("GetLatestRun").Return(&models.KlasifikasiRun{ID: 3}, nil)
+	repo.On("CreateOpname", mock.MatchedBy(func(o *models.StokOpname) bool {
+		return o.KelasABC == "A" && o.KelasXYZ == "XY" && *o.KlasifikasiRunID == 3 && o.Status == "draft" && *o.UserID == 2
+	})).Return(nil)
+	repo.On("GetOpnameByID", 7).Return(&models.StokOpname{ID: 7, JumlahBarang: 12}, nil)
+
+	opname, err := services.NewKlasifikasiService(repo).CreateOpname(context.Background(), models.CreateOpnameRequest{KelasABC: "a", KelasXYZ: "YX"}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 12, opname.JumlahBarang)
+	repo.AssertExpectations(t)
+}
+
+func TestKlasifikasiHandler(t *testing.T) {
+	newHandler := func() (*handlers.KlasifikasiHandler, *MockKlasifikasiRepository) {
+		repo := new(MockKlasifikasiRepository)
+		return handlers.NewKlasifikasiHandler(services.NewKlasifikasiService(repo)), repo
+	}
+
+	t.Run("Run - staff is forbidden", func(t *testing.T) {
+		handler, repo := newHandler()
+		authz := middleware.NewAuthorizer(staffPermissions())
+		req := withRole(httptest.NewRequest("POST", "/api/reports/abc-xyz", nil), 2, "staff")
+		w := httptest.NewRecorder()
+		authz.Require(models.PermReportManage, handler.Run)(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		repo.AssertNotCalled(t, "Demand", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Run - saves run for the requested range", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("Demand", "2024-01-01", "2024-06-30", "month").Return([]models.BarangDemand{}, nil)
+		repo.On("SaveRun", mock.MatchedBy(func(run *models.KlasifikasiRun) bool {
+			return run.BatasA == 70 && run.BatasB == 95 && *run.UserID == 1
+		}), mock.Anything).Return(nil)
+
+		req := withRole(httptest.NewRequest("POST", "/api/reports/abc-xyz?start_date=2024-01-01&end_date=2024-06-30&batas_a=70", nil), 1, "admin")
+		w := httptest.NewRecorder()
+		handler.Run(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Run - invalid parameters", func(t *testing.T) {
+		for _, q := range []string{"periode=day", "start_date=2024-03-01&end_date=2024-03-20", "batas_a=96", "batas_x=abc", "batas_x=2"} {
+			handler, _ := newHandler()
+			req := withRole(httptest.NewRequest("POST", "/api/reports/abc-xyz?"+q, nil), 1, "admin")
+			w := httptest.NewRecorder()
+			handler.Run(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, q)
+		}
+	})
+
+	t.Run("Get - no run yet", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("GetLatestRun").Return(nil, repositories.ErrKlasifikasiNotFound)
+
+		w := httptest.NewRecorder()
+		handler.Get(w, httptest.NewRequest("GET", "/api/reports/abc-xyz", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("CreateOpname - requires a classification run", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("GetLatestRun").Return(nil, repositories.ErrKlasifikasiNotFound)
+
+		req := withRole(httptest.NewRequest("POST", "/api/stok-opname", bytes.NewBufferString(`{"kelas_abc":"A"}`)), 2, "staff")
+		w := httptest.NewRecorder()
+		handler.CreateOpname(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("CreateOpname - no barang in class", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("GetLatestRun").Return(&models.KlasifikasiRun{ID: 3}, nil)
+		repo.On("CreateOpname", mock.Anything).Return(repositories.ErrOpnameKosong)
+
+		req := withRole(httptest.NewRequest("POST", "/api/stok-opname", bytes.NewBufferString(`{"kelas_abc":"A","kelas_xyz":"Z"}`)), 2, "staff")
+		w := httptest.NewRecorder()
+		handler.CreateOpname(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
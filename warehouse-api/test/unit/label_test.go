@@ -0,0 +1,96 @@
+package unit
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"warehouse-api/documents"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderLabelPNG(t *testing.T) {
+	label := documents.Label{KodeBarang: "BRG-001", NamaBarang: "Laptop Dell XPS 13", HargaJual: 17500000, EAN: "8992761166014"}
+
+	for _, symbology := range []string{documents.SymbologyCode128, documents.SymbologyEAN13, documents.SymbologyQR} {
+		t.Run("Success - "+symbology, func(t *testing.T) {
+			data, err := documents.RenderLabelPNG(label, symbology)
+			assert.NoError(t, err)
+
+			img, err := png.Decode(bytes.NewReader(data))
+			assert.NoError(t, err)
+			assert.Equal(t, 400, img.Bounds().Dx())
+			assert.Equal(t, 240, img.Bounds().Dy())
+		})
+	}
+
+	t.Run("Fail - EAN-13 without barcode", func(t *testing.T) {
+		_, err := documents.RenderLabelPNG(documents.Label{KodeBarang: "BRG-002"}, documents.SymbologyEAN13)
+		assert.Error(t, err)
+	})
+}
+
+func TestRenderLabelSheetPDF(t *testing.T) {
+	t.Run("Success - Multi page sheet", func(t *testing.T) {
+		var labels []documents.Label
+		for i := 0; i < 30; i++ {
+			labels = append(labels, documents.Label{KodeBarang: "BRG-001", NamaBarang: "Mouse Wireless", HargaJual: 350000})
+		}
+
+		var buf bytes.Buffer
+		err := documents.RenderLabelSheetPDF(&buf, labels, documents.SymbologyCode128, documents.DefaultLabelLayout)
+
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF")))
+	})
+
+	t.Run("Success - Non-ASCII name is encoded for the core font", func(t *testing.T) {
+		labels := []documents.Label{{KodeBarang: "BRG-002", NamaBarang: "Kopi Café", HargaJual: 25000}}
+
+		var buf bytes.Buffer
+		require.NoError(t, documents.RenderLabelSheetPDF(&buf, labels, documents.SymbologyCode128, documents.DefaultLabelLayout))
+
+		content := pdfContent(t, buf.Bytes())
+		assert.Contains(t, content, "(Kopi Caf\351)", "é ditulis sebagai byte cp1252, bukan UTF-8")
+	})
+}
+
+// pdfContent mengembalikan isi semua stream PDF yang dikompresi FlateDecode
+func pdfContent(t *testing.T, raw []byte) string {
+	t.Helper()
+	var out strings.Builder
+	for {
+		start := bytes.Index(raw, []byte(">>\nstream\n"))
+		if start < 0 {
+			break
+		}
+		raw = raw[start+len(">>\nstream\n"):]
+		end := bytes.Index(raw, []byte("\nendstream"))
+		if end < 0 {
+			break
+		}
+		if r, err := zlib.NewReader(bytes.NewReader(raw[:end])); err == nil {
+			data, _ := io.ReadAll(r)
+			out.Write(data)
+		}
+		raw = raw[end:]
+	}
+	return out.String()
+}
+
+func TestParseLabelLayout(t *testing.T) {
+	layout, err := documents.ParseLabelLayout("2x7")
+	assert.NoError(t, err)
+	assert.Equal(t, documents.LabelLayout{Columns: 2, Rows: 7}, layout)
+
+	layout, err = documents.ParseLabelLayout("")
+	assert.NoError(t, err)
+	assert.Equal(t, documents.DefaultLabelLayout, layout)
+
+	_, err = documents.ParseLabelLayout("abc")
+	assert.Error(t, err)
+}
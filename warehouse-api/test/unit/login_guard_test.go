@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"warehouse-api/config"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepository) LockedFor(username string) (time.Duration, error) {
+	args := m.Called(username)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) RecordFailure(attempt *models.LoginAttempt, window time.Duration) (int, error) {
+	args := m.Called(attempt, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) Lock(username string, d time.Duration) error {
+	args := m.Called(username, d)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) Unlock(ctx context.Context, userID int, username string) error {
+	args := m.Called(userID, username)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) Reset(username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+type MockLoginGuard struct {
+	mock.Mock
+}
+
+func (m *MockLoginGuard) Check(username string) (time.Duration, error) {
+	args := m.Called(username)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockLoginGuard) Failed(attempt *models.LoginAttempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+func (m *MockLoginGuard) Succeeded(username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+func (m *MockLoginGuard) Unlock(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+var testLockout = config.LoginLockout{MaxAttempts: 3, Window: 15 * time.Minute, Duration: time.Minute, MaxDuration: 5 * time.Minute}
+
+func TestLoginGuard(t *testing.T) {
+	attempt := &models.LoginAttempt{Username: "andi", IP: "192.0.2.1", UserAgent: "curl/8.0", Alasan: "username atau password salah"}
+
+	t.Run("Below limit - not locked", func(t *testing.T) {
+		repo := new(MockLoginAttemptRepository)
+		repo.On("RecordFailure", attempt, testLockout.Window).Return(2, nil)
+		guard := services.NewLoginGuard(repo, new(MockUserRepository), testLockout)
+
+		assert.NoError(t, guard.Failed(attempt))
+		repo.AssertNotCalled(t, "Lock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Lock doubles per failure up to max", func(t *testing.T) {
+		expected := map[int]time.Duration{3: time.Minute, 4: 2 * time.Minute, 5: 4 * time.Minute, 6: 5 * time.Minute, 20: 5 * time.Minute}
+		for failures, d := range expected {
+			repo := new(MockLoginAttemptRepository)
+			repo.On("RecordFailure", attempt, testLockout.Window).Return(failures, nil)
+			repo.On("Lock", "andi", d).Return(nil)
+			guard := services.NewLoginGuard(repo, new(MockUserRepository), testLockout)
+
+			assert.NoError(t, guard.Failed(attempt))
+			repo.AssertExpectations(t)
+		}
+	})
+
+	t.Run("Success resets counter", func(t *testing.T) {
+		repo := new(MockLoginAttemptRepository)
+		repo.On("Reset", "andi").Return(nil)
+		guard := services.NewLoginGuard(repo, new(MockUserRepository), testLockout)
+
+		assert.NoError(t, guard.Succeeded("andi"))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Unlock by user id", func(t *testing.T) {
+		repo := new(MockLoginAttemptRepository)
+		users := new(MockUserRepository)
+		users.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi"}, nil)
+		users.On("GetByID", 99).Return(nil, sql.ErrNoRows)
+		repo.On("Unlock", 3, "andi").Return(nil)
+		guard := services.NewLoginGuard(repo, users, testLockout)
+
+		assert.NoError(t, guard.Unlock(context.Background(), 3))
+		assert.ErrorIs(t, guard.Unlock(context.Background(), 99), repositories.ErrUserNotFound)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUserHandlerUnlock(t *testing.T) {
+	mockGuard := new(MockLoginGuard)
+	handler := handlers.NewUserHandler(new(MockUserService), new(MockTokenService), mockGuard, new(MockTwoFactorService))
+	mockGuard.On("Unlock", 3).Return(nil)
+	mockGuard.On("Unlock", 99).Return(repositories.ErrUserNotFound)
+
+	serve := func(id string) int {
+		req := httptest.NewRequest("POST", "/api/users/"+id+"/unlock", nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.Unlock(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("3"))
+	assert.Equal(t, http.StatusNotFound, serve("99"))
+	assert.Equal(t, http.StatusBadRequest, serve("abc"))
+}
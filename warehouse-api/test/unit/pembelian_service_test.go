@@ -1,8 +1,11 @@
 package unit
 
 import (
+	"context"
 	"database/sql"
+	"slices"
 	"testing"
+	"time"
 	"warehouse-api/models"
 
 	"github.com/stretchr/testify/mock"
@@ -18,7 +21,7 @@ func (m *MockPembelianRepository) Create(tx *sql.Tx, header *models.BeliHeader,
 	return args.Error(0)
 }
 
-func (m *MockPembelianRepository) GetByID(id int) (*models.BeliHeader, error) {
+func (m *MockPembelianRepository) GetByID(ctx context.Context, id int) (*models.BeliHeader, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -26,8 +29,23 @@ func (m *MockPembelianRepository) GetByID(id int) (*models.BeliHeader, error) {
 	return args.Get(0).(*models.BeliHeader), args.Error(1)
 }
 
-func (m *MockPembelianRepository) GetAll(page, perPage int) ([]models.BeliHeader, int, error) {
-	args := m.Called(page, perPage)
+func (m *MockPembelianRepository) Stream(ctx context.Context, filter models.TransaksiFilter, sortBy, order string, fn func(models.BeliHeader) error) error {
+	args := m.Called(filter, sortBy, order, fn)
+	if rows, ok := args.Get(0).([]models.BeliHeader); ok {
+		for _, h := range rows {
+			if err := fn(h); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockPembelianRepository) GetAll(ctx context.Context, filter models.TransaksiFilter, limit, offset int, sortBy, order string) ([]models.BeliHeader, int, error) {
+	args := m.Called(filter, limit, offset, sortBy, order)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
 	return args.Get(0).([]models.BeliHeader), args.Int(1), args.Error(2)
 }
 
@@ -53,22 +71,128 @@ func (m *MockStokRepository) GetByBarangID(barangID int) (*models.Stok, error) {
 	return args.Get(0).(*models.Stok), args.Error(1)
 }
 
-func (m *MockStokRepository) GetAll(page, perPage int) ([]models.Stok, int, error) {
-	args := m.Called(page, perPage)
+func (m *MockStokRepository) GetByBarangIDWithTx(tx *sql.Tx, barangID int) (*models.Stok, error) {
+	args := m.Called(tx, barangID)
 	if args.Get(0) == nil {
-		return nil, args.Int(1), args.Error(2)
+		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.Stok), args.Int(1), args.Error(2)
+	return args.Get(0).(*models.Stok), args.Error(1)
 }
 
-func (m *MockStokRepository) GetHistory(barangID, page, perPage int) ([]models.HistoryStok, int, error) {
-	args := m.Called(barangID, page, perPage)
+func (m *MockStokRepository) GetAll() ([]models.Stok, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Stok), args.Error(1)
+}
+
+func (m *MockStokRepository) GetHistory(ctx context.Context, filter models.HistoryStokFilter, limit, offset int, sortBy, order string) ([]models.HistoryStok, int, error) {
+	args := m.Called(filter, limit, offset, sortBy, order)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
 	return args.Get(0).([]models.HistoryStok), args.Int(1), args.Error(2)
 }
 
+func (m *MockStokRepository) StreamHistory(ctx context.Context, filter models.HistoryStokFilter, fn func(models.HistoryStok) error) error {
+	args := m.Called(filter, fn)
+	if rows, ok := args.Get(0).([]models.HistoryStok); ok {
+		for _, h := range rows {
+			if err := fn(h); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockStokRepository) GetHistoryAfter(ctx context.Context, filter models.HistoryStokFilter, cursor *models.HistoryCursor, limit int) ([]models.HistoryStok, *models.HistoryCursor, error) {
+	args := m.Called(filter, cursor, limit)
+	var next *models.HistoryCursor
+	if c := args.Get(1); c != nil {
+		next = c.(*models.HistoryCursor)
+	}
+	if args.Get(0) == nil {
+		return nil, next, args.Error(2)
+	}
+	return args.Get(0).([]models.HistoryStok), next, args.Error(2)
+}
+
+func (m *MockStokRepository) GetSaldoAwal(tx *sql.Tx, barangID int) (int, bool, error) {
+	args := m.Called(tx, barangID)
+	return args.Int(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockStokRepository) EachMovement(ctx context.Context, barangID int, endDate string, fn func(models.StokMovement) error) error {
+	args := m.Called(barangID, endDate, fn)
+	if rows, ok := args.Get(0).([]models.StokMovement); ok {
+		for _, mv := range rows {
+			if err := fn(mv); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+// EachHistoryChain mengembalikan baris dari Return(rows, unsealed, err); barang di unsealed ([]int)
+// dianggap belum di-seal
+func (m *MockStokRepository) EachHistoryChain(barangID int, fn func(models.HistoryStok, bool) error) error {
+	args := m.Called(barangID, fn)
+	unsealed, _ := args.Get(1).([]int)
+	if rows, ok := args.Get(0).([]models.HistoryStok); ok {
+		for _, h := range rows {
+			if err := fn(h, !slices.Contains(unsealed, h.BarangID)); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(2)
+}
+
+func (m *MockStokRepository) HistoryChainAnchors(barangID int) (map[int]models.HistoryChainAnchor, *models.HistoryChainSeal, error) {
+	args := m.Called(barangID)
+	anchors, _ := args.Get(0).(map[int]models.HistoryChainAnchor)
+	seal, _ := args.Get(1).(*models.HistoryChainSeal)
+	return anchors, seal, args.Error(2)
+}
+
+func (m *MockStokRepository) SealHistory() (int, int, error) {
+	args := m.Called()
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockStokRepository) GetAsOf(date string) ([]models.StokAsOf, error) {
+	args := m.Called(date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StokAsOf), args.Error(1)
+}
+
+func (m *MockStokRepository) GetSnapshot(periode string) ([]models.StokAsOf, *time.Time, error) {
+	args := m.Called(periode)
+	var createdAt *time.Time
+	if t := args.Get(1); t != nil {
+		createdAt = t.(*time.Time)
+	}
+	if args.Get(0) == nil {
+		return nil, createdAt, args.Error(2)
+	}
+	return args.Get(0).([]models.StokAsOf), createdAt, args.Error(2)
+}
+
+func (m *MockStokRepository) HasSnapshot(periode string) (bool, error) {
+	args := m.Called(periode)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStokRepository) CreateSnapshot(ctx context.Context, periode string) (int, error) {
+	args := m.Called(periode)
+	return args.Int(0), args.Error(1)
+}
+
 type MockBarangRepository struct {
 	mock.Mock
 }
@@ -81,17 +205,17 @@ func (m *MockBarangRepository) GetByID(id int) (*models.Barang, error) {
 	return args.Get(0).(*models.Barang), args.Error(1)
 }
 
-func (m *MockBarangRepository) Create(barang *models.Barang) error {
+func (m *MockBarangRepository) Create(ctx context.Context, barang *models.Barang) error {
 	args := m.Called(barang)
 	return args.Error(0)
 }
 
-func (m *MockBarangRepository) Update(barang *models.Barang) error {
+func (m *MockBarangRepository) Update(ctx context.Context, barang *models.Barang) error {
 	args := m.Called(barang)
 	return args.Error(0)
 }
 
-func (m *MockBarangRepository) Delete(id int) error {
+func (m *MockBarangRepository) Delete(ctx context.Context, id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"warehouse-api/models"
@@ -18,7 +19,7 @@ func (m *MockPenjualanRepository) Create(tx *sql.Tx, header *models.JualHeader,
 	return args.Error(0)
 }
 
-func (m *MockPenjualanRepository) GetByID(id int) (*models.JualHeader, error) {
+func (m *MockPenjualanRepository) GetByID(ctx context.Context, id int) (*models.JualHeader, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -26,8 +27,23 @@ func (m *MockPenjualanRepository) GetByID(id int) (*models.JualHeader, error) {
 	return args.Get(0).(*models.JualHeader), args.Error(1)
 }
 
-func (m *MockPenjualanRepository) GetAll(page, perPage int) ([]models.JualHeader, int, error) {
-	args := m.Called(page, perPage)
+func (m *MockPenjualanRepository) Stream(ctx context.Context, filter models.TransaksiFilter, sortBy, order string, fn func(models.JualHeader) error) error {
+	args := m.Called(filter, sortBy, order, fn)
+	if rows, ok := args.Get(0).([]models.JualHeader); ok {
+		for _, h := range rows {
+			if err := fn(h); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockPenjualanRepository) GetAll(ctx context.Context, filter models.TransaksiFilter, limit, offset int, sortBy, order string) ([]models.JualHeader, int, error) {
+	args := m.Called(filter, limit, offset, sortBy, order)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
 	return args.Get(0).([]models.JualHeader), args.Int(1), args.Error(2)
 }
 
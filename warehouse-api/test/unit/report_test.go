@@ -0,0 +1,166 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) Aggregate(ctx context.Context, q models.ReportQuery) ([]models.ReportPoint, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ReportPoint), args.Error(1)
+}
+
+func (m *MockReportRepository) Summary(ctx context.Context, jenis, startDate, endDate string) (models.ReportValues, error) {
+	args := m.Called(jenis, startDate, endDate)
+	return args.Get(0).(models.ReportValues), args.Error(1)
+}
+
+func TestTimeBuckets(t *testing.T) {
+	days := services.TimeBuckets("day", "2024-02-27", "2024-03-02")
+	assert.Len(t, days, 5) // 2024 kabisat: 27, 28, 29 Feb, 1, 2 Mar
+
+	// 2024-03-06 hari Rabu, minggu dimulai Senin 2024-03-04
+	weeks := services.TimeBuckets("week", "2024-03-06", "2024-03-20")
+	assert.Equal(t, "2024-03-04", weeks[0].Format("2006-01-02"))
+	assert.Len(t, weeks, 3)
+
+	months := services.TimeBuckets("month", "2024-01-15", "2024-03-01")
+	assert.Len(t, months, 3)
+	assert.Equal(t, "2024-01-01", months[0].Format("2006-01-02"))
+}
+
+func TestPreviousPeriod(t *testing.T) {
+	start, end := services.PreviousPeriod("2024-03-01", "2024-03-31")
+	assert.Equal(t, "2024-01-30", start)
+	assert.Equal(t, "2024-02-29", end)
+}
+
+func TestReportServiceGet(t *testing.T) {
+	t.Run("Time series is zero-filled and compared by position", func(t *testing.T) {
+		repo := new(MockReportRepository)
+		q := models.ReportQuery{Jenis: "penjualan", GroupBy: "day", StartDate: "2024-03-01", EndDate: "2024-03-03", Limit: 10}
+		prev := q
+		prev.StartDate, prev.EndDate, prev.Limit = "2024-02-27", "2024-02-29", 0
+
+		repo.On("Aggregate", q).Return([]models.ReportPoint{
+			{Key: "2024-03-02", Label: "2024-03-02", ReportValues: models.ReportValues{Total: 300, Qty: 3, JumlahTransaksi: 1}},
+		}, nil)
+		repo.On("Summary", "penjualan", "2024-03-01", "2024-03-03").Return(models.ReportValues{Total: 300, Qty: 3, JumlahTransaksi: 1}, nil)
+		repo.On("Aggregate", prev).Return([]models.ReportPoint{
+			{Key: "2024-02-28", Label: "2024-02-28", ReportValues: models.ReportValues{Total: 200, Qty: 2, JumlahTransaksi: 1}},
+		}, nil)
+		repo.On("Summary", "penjualan", "2024-02-27", "2024-02-29").Return(models.ReportValues{Total: 200, Qty: 2, JumlahTransaksi: 1}, nil)
+
+		report, err := services.NewReportService(repo).Get(context.Background(), q, true)
+
+		assert.NoError(t, err)
+		assert.Len(t, report.Series, 3)
+		assert.Equal(t, "2024-03-01", report.Series[0].Key)
+		assert.Zero(t, report.Series[0].Total)
+		assert.Equal(t, 300.0, report.Series[1].Total)
+		assert.Equal(t, 200.0, report.Series[1].Previous.Total)
+		assert.Equal(t, 50.0, *report.Comparison.ChangePercent.Total)
+		assert.Equal(t, 0.0, *report.Comparison.ChangePercent.JumlahTransaksi)
+	})
+
+	t.Run("Categorical series compared by key, no change when previous is zero", func(t *testing.T) {
+		repo := new(MockReportRepository)
+		q := models.ReportQuery{Jenis: "pembelian", GroupBy: "supplier", StartDate: "2024-03-01", EndDate: "2024-03-31", Limit: 5}
+		prev := q
+		prev.StartDate, prev.EndDate, prev.Limit = "2024-01-30", "2024-02-29", 0
+
+		repo.On("Aggregate", q).Return([]models.ReportPoint{
+			{Key: "PT A", Label: "PT A", ReportValues: models.ReportValues{Total: 1000}},
+			{Key: "PT B", Label: "PT B", ReportValues: models.ReportValues{Total: 500}},
+		}, nil)
+		repo.On("Summary", "pembelian", "2024-03-01", "2024-03-31").Return(models.ReportValues{Total: 1500}, nil)
+		repo.On("Aggregate", prev).Return([]models.ReportPoint{
+			{Key: "PT B", Label: "PT B", ReportValues: models.ReportValues{Total: 800}},
+		}, nil)
+		repo.On("Summary", "pembelian", "2024-01-30", "2024-02-29").Return(models.ReportValues{}, nil)
+
+		report, err := services.NewReportService(repo).Get(context.Background(), q, true)
+
+		assert.NoError(t, err)
+		assert.Zero(t, report.Series[0].Previous.Total)
+		assert.Equal(t, 800.0, report.Series[1].Previous.Total)
+		assert.Nil(t, report.Comparison.ChangePercent.Total)
+	})
+}
+
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) Get(ctx context.Context, q models.ReportQuery, compare bool) (*models.Report, error) {
+	args := m.Called(q, compare)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Report), args.Error(1)
+}
+
+func TestReportHandler(t *testing.T) {
+	t.Run("Success - Penjualan grouped by customer with compare", func(t *testing.T) {
+		svc := new(MockReportService)
+		handler := handlers.NewReportHandler(svc)
+		q := models.ReportQuery{Jenis: "penjualan", GroupBy: "customer", StartDate: "2024-03-01", EndDate: "2024-03-31", Limit: 5}
+		svc.On("Get", q, true).Return(&models.Report{Series: []models.ReportPoint{}}, nil)
+
+		req := httptest.NewRequest("GET", "/api/reports/penjualan?group_by=customer&start_date=2024-03-01&end_date=2024-03-31&compare=true&limit=5", nil)
+		w := httptest.NewRecorder()
+		handler.Penjualan(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("Fail - customer grouping is not valid for pembelian", func(t *testing.T) {
+		svc := new(MockReportService)
+		handler := handlers.NewReportHandler(svc)
+
+		req := httptest.NewRequest("GET", "/api/reports/pembelian?group_by=customer", nil)
+		w := httptest.NewRecorder()
+		handler.Pembelian(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "supplier")
+	})
+
+	t.Run("Fail - Daily series too long", func(t *testing.T) {
+		svc := new(MockReportService)
+		handler := handlers.NewReportHandler(svc)
+
+		req := httptest.NewRequest("GET", "/api/reports/penjualan?group_by=day&start_date=2015-01-01&end_date=2024-12-31", nil)
+		w := httptest.NewRecorder()
+		handler.Penjualan(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Fail - Start after end", func(t *testing.T) {
+		svc := new(MockReportService)
+		handler := handlers.NewReportHandler(svc)
+
+		req := httptest.NewRequest("GET", "/api/reports/penjualan?start_date=2024-04-01&end_date=2024-03-01", nil)
+		w := httptest.NewRecorder()
+		handler.Penjualan(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -119,4 +119,21 @@ func TestJSONWithMeta(t *testing.T) {
 		assert.True(t, response.Success)
 		assert.NotNil(t, response.Meta)
 	})
+
+	t.Run("Success - Empty page keeps page and total", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		utils.JSONWithMeta(w, "Data retrieved", []string{}, models.Pagination{Page: 0, Limit: 10, Total: 0})
+
+		assert.Contains(t, w.Body.String(), `"meta":{"page":0,"limit":10,"total":0}`)
+	})
+}
+
+func TestJSONWithCursor(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	utils.JSONWithCursor(w, "Data retrieved", []string{"a"}, models.CursorPagination{Limit: 10, NextCursor: "abc"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"meta":{"limit":10,"next_cursor":"abc"}`)
 }
@@ -0,0 +1,352 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) GetAll() ([]models.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetPermissions() ([]models.Permission, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Permission), args.Error(1)
+}
+
+func (m *MockRoleRepository) Exists(nama string) (bool, error) {
+	args := m.Called(nama)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRoleRepository) Create(ctx context.Context, role *models.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) SetPermissions(ctx context.Context, nama string, permissions []string) error {
+	args := m.Called(nama, permissions)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Delete(ctx context.Context, nama string) error {
+	args := m.Called(nama)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) PermissionMap() (map[string][]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]string), args.Error(1)
+}
+
+// stubPermissions adalah PermissionChecker statis untuk test handler yang dibungkus Authorizer
+type stubPermissions map[string][]string
+
+func (s stubPermissions) HasPermission(role, permission string) (bool, error) {
+	if role == models.RoleAdmin {
+		return true, nil
+	}
+	for _, p := range s[role] {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// staffPermissions meniru permission bawaan role staff dari migration 009_rbac.sql
+func staffPermissions() stubPermissions {
+	return stubPermissions{"staff": {
+		models.PermBarangRead, models.PermBarangWrite, models.PermStokRead, models.PermStokOpname,
+		models.PermPembelianRead, models.PermPenjualanRead, models.PermPenjualanCreate, models.PermReportView,
+	}}
+}
+
+type errPermissions struct{}
+
+func (errPermissions) HasPermission(role, permission string) (bool, error) {
+	return false, errors.New("db down")
+}
+
+func TestAuthorizer(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	authz := middleware.NewAuthorizer(staffPermissions())
+
+	tests := []struct {
+		name       string
+		role       string
+		permission string
+		expected   int
+	}{
+		{"No role in context", "", models.PermBarangRead, http.StatusUnauthorized},
+		{"Staff allowed", "staff", models.PermBarangRead, http.StatusNoContent},
+		{"Staff forbidden", "staff", models.PermUserManage, http.StatusForbidden},
+		{"Admin always allowed", "admin", models.PermRoleManage, http.StatusNoContent},
+		{"Unknown role forbidden", "tamu", models.PermBarangRead, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/barang", nil)
+			if tt.role != "" {
+				req = withRole(req, 1, tt.role)
+			}
+			w := httptest.NewRecorder()
+			authz.Require(tt.permission, ok)(w, req)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+
+	t.Run("Checker error returns 500", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		middleware.NewAuthorizer(errPermissions{}).Require(models.PermBarangRead, ok)(w, withRole(httptest.NewRequest("GET", "/api/barang", nil), 1, "staff"))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestRoleService(t *testing.T) {
+	permMap := map[string][]string{
+		"admin":  {},
+		"staff":  {models.PermBarangRead, models.PermStokRead},
+		"gudang": {},
+	}
+
+	t.Run("HasPermission - uses cached map", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		repo.On("PermissionMap").Return(permMap, nil).Once()
+		service := services.NewRoleService(repo, time.Minute)
+
+		allowed, err := service.HasPermission("staff", models.PermBarangRead)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = service.HasPermission("staff", models.PermBarangDelete)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, err = service.HasPermission("gudang", models.PermBarangRead)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		repo.AssertNumberOfCalls(t, "PermissionMap", 1)
+	})
+
+	t.Run("HasPermission - admin bypasses repository", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		service := services.NewRoleService(repo, time.Minute)
+
+		allowed, err := service.HasPermission("admin", models.PermRoleManage)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		repo.AssertNotCalled(t, "PermissionMap")
+	})
+
+	t.Run("HasPermission - repository error", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		repo.On("PermissionMap").Return(nil, errors.New("db down"))
+		service := services.NewRoleService(repo, time.Minute)
+
+		_, err := service.HasPermission("staff", models.PermBarangRead)
+		assert.Error(t, err)
+	})
+
+	t.Run("SetPermissions - invalidates cache", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		repo.On("PermissionMap").Return(permMap, nil).Once()
+		repo.On("SetPermissions", "staff", []string{models.PermBarangRead, models.PermStokOpname}).Return(nil)
+		repo.On("PermissionMap").Return(map[string][]string{
+			"staff": {models.PermBarangRead, models.PermStokOpname},
+		}, nil).Once()
+		service := services.NewRoleService(repo, time.Minute)
+
+		allowed, _ := service.HasPermission("staff", models.PermStokOpname)
+		assert.False(t, allowed)
+
+		err := service.SetPermissions(context.Background(), "staff", []string{" stok:opname", "barang:read", "barang:read"})
+		assert.NoError(t, err)
+
+		allowed, _ = service.HasPermission("staff", models.PermStokOpname)
+		assert.True(t, allowed)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("SetPermissions - admin is fixed", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		service := services.NewRoleService(repo, time.Minute)
+
+		err := service.SetPermissions(context.Background(), "admin", []string{models.PermBarangRead})
+		assert.ErrorIs(t, err, services.ErrRoleAdminTetap)
+		repo.AssertNotCalled(t, "SetPermissions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Create - invalid name", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		service := services.NewRoleService(repo, time.Minute)
+
+		for _, nama := range []string{"", "a", "Gudang", "kepala gudang"} {
+			_, err := service.Create(context.Background(), models.CreateRoleRequest{Nama: nama})
+			assert.ErrorIs(t, err, services.ErrRoleNamaTidakValid, nama)
+		}
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Create - normalizes permissions", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		repo.On("Create", mock.MatchedBy(func(role *models.Role) bool {
+			return role.Nama == "gudang" && assert.ObjectsAreEqual([]string{"barang:read", "stok:read"}, role.Permissions)
+		})).Return(nil)
+		service := services.NewRoleService(repo, time.Minute)
+
+		role, err := service.Create(context.Background(), models.CreateRoleRequest{Nama: " gudang ", Permissions: []string{"stok:read", "barang:read", ""}})
+		assert.NoError(t, err)
+		assert.Equal(t, "gudang", role.Nama)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Permissions - admin gets full catalogue", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		repo.On("GetPermissions").Return([]models.Permission{{Kode: "barang:read"}, {Kode: "role:manage"}}, nil)
+		service := services.NewRoleService(repo, time.Minute)
+
+		perms, err := service.Permissions("admin")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"barang:read", "role:manage"}, perms)
+	})
+
+	t.Run("RoleExists - falls back to repository for roles missing from cache", func(t *testing.T) {
+		repo := new(MockRoleRepository)
+		repo.On("PermissionMap").Return(permMap, nil)
+		repo.On("Exists", "kasir").Return(true, nil)
+		service := services.NewRoleService(repo, time.Minute)
+
+		exists, err := service.RoleExists("staff")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = service.RoleExists("kasir")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestRoleHandler(t *testing.T) {
+	newHandler := func() (*handlers.RoleHandler, *MockRoleRepository) {
+		repo := new(MockRoleRepository)
+		return handlers.NewRoleHandler(services.NewRoleService(repo, time.Minute)), repo
+	}
+
+	t.Run("Create - duplicate role", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("Create", mock.Anything).Return(repositories.ErrRoleExists)
+
+		req := httptest.NewRequest("POST", "/api/roles", bytes.NewBufferString(`{"nama":"staff"}`))
+		w := httptest.NewRecorder()
+		handler.Create(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Create - unknown permission", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("Create", mock.Anything).Return(repositories.ErrPermissionNotFound)
+
+		req := httptest.NewRequest("POST", "/api/roles", bytes.NewBufferString(`{"nama":"gudang","permissions":["barang:fly"]}`))
+		w := httptest.NewRecorder()
+		handler.Create(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("SetPermissions - role not found", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("SetPermissions", "kasir", []string{"barang:read"}).Return(repositories.ErrRoleNotFound)
+
+		req := httptest.NewRequest("PUT", "/api/roles/kasir/permissions", bytes.NewBufferString(`{"permissions":["barang:read"]}`))
+		req.SetPathValue("nama", "kasir")
+		w := httptest.NewRecorder()
+		handler.SetPermissions(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Delete - role in use", func(t *testing.T) {
+		handler, repo := newHandler()
+		repo.On("Delete", "kasir").Return(repositories.ErrRoleInUse)
+
+		req := httptest.NewRequest("DELETE", "/api/roles/kasir", nil)
+		req.SetPathValue("nama", "kasir")
+		w := httptest.NewRecorder()
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestUserServiceWithRoles(t *testing.T) {
+	newService := func() (services.UserService, *MockUserRepository, *MockRoleRepository) {
+		userRepo := new(MockUserRepository)
+		roleRepo := new(MockRoleRepository)
+		roleRepo.On("PermissionMap").Return(map[string][]string{
+			"admin":  {},
+			"staff":  {models.PermBarangRead},
+			"gudang": {models.PermStokRead, models.PermStokOpname},
+		}, nil)
+		roles := services.NewRoleService(roleRepo, time.Minute)
+		return services.NewUserService(userRepo, services.WithRoles(roles)), userRepo, roleRepo
+	}
+
+	t.Run("Register - custom role accepted", func(t *testing.T) {
+		service, userRepo, _ := newService()
+		userRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil)
+
+		user, err := service.Register(context.Background(), &models.RegisterRequest{Username: "andi", Password: "rahasia", Role: "gudang"})
+		assert.NoError(t, err)
+		assert.Equal(t, "gudang", user.Role)
+	})
+
+	t.Run("Register - unknown role rejected", func(t *testing.T) {
+		service, userRepo, roleRepo := newService()
+		roleRepo.On("Exists", "kasir").Return(false, nil)
+
+		_, err := service.Register(context.Background(), &models.RegisterRequest{Username: "andi", Password: "rahasia", Role: "kasir"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "role harus")
+		userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("ValidateCredentials - fills permissions", func(t *testing.T) {
+		service, userRepo, _ := newService()
+		hash, _ := service.HashPassword("rahasia")
+		userRepo.On("GetByUsername", "andi").Return(&models.User{Username: "andi", Password: hash, Role: "gudang", IsActive: true}, nil)
+
+		user, err := service.ValidateCredentials("andi", "rahasia")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{models.PermStokOpname, models.PermStokRead}, user.Permissions)
+	})
+}
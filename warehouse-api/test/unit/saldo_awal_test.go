@@ -0,0 +1,166 @@
+package unit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseSaldoAwal(t *testing.T) {
+	t.Run("Success - Indonesian number format and optional harga", func(t *testing.T) {
+		rows := [][]string{
+			{"Kode Barang", "Qty", "Harga"},
+			{"BRG001", "1.500", "15.000.000,50"},
+			{"BRG002", "25", ""},
+			{"", "", ""},
+		}
+
+		items, rowErrors, err := services.ParseSaldoAwal(rows, "csv")
+
+		assert.NoError(t, err)
+		assert.Empty(t, rowErrors)
+		assert.Len(t, items, 2)
+		assert.Equal(t, 1500, items[0].Qty)
+		assert.Equal(t, 15000000.5, *items[0].Harga)
+		assert.Equal(t, 3, items[1].Row)
+		assert.Nil(t, items[1].Harga)
+	})
+
+	t.Run("Fail - Invalid rows are reported per row", func(t *testing.T) {
+		rows := [][]string{
+			{"kode_barang", "qty"},
+			{"BRG001", "-5"},
+			{"BRG002", "2,5"},
+			{"BRG003", "10"},
+			{"BRG003", "12"},
+			{"", "3"},
+		}
+
+		items, rowErrors, err := services.ParseSaldoAwal(rows, "csv")
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+		assert.Len(t, rowErrors, 4)
+		assert.Equal(t, 2, rowErrors[0].Row)
+		assert.Equal(t, 3, rowErrors[1].Row)
+		assert.Contains(t, rowErrors[2].Error, "baris 4")
+		assert.Equal(t, 6, rowErrors[3].Row)
+	})
+
+	t.Run("Fail - Missing qty column", func(t *testing.T) {
+		_, _, err := services.ParseSaldoAwal([][]string{{"kode_barang", "jumlah"}}, "csv")
+
+		assert.ErrorIs(t, err, services.ErrSaldoAwalFile)
+	})
+}
+
+func TestSaldoAwalServiceLoadUnknownBarang(t *testing.T) {
+	stokRepo := new(MockStokRepository)
+	barangRepo := new(MockBarangRepositoryHandler)
+	barangRepo.On("GetByKode", "BRG404").Return(nil, sql.ErrNoRows)
+	barangRepo.On("GetByKode", "BRG009").Return(&models.Barang{ID: 9, KodeBarang: "BRG009", IsActive: false}, nil)
+
+	// Tidak ada baris yang lolos validasi sehingga transaksi database tidak dibuka
+	service := services.NewSaldoAwalService(nil, stokRepo, barangRepo)
+	rows := [][]string{{"kode_barang", "qty"}, {"BRG404", "5"}, {"BRG009", "7"}}
+
+	result, err := service.Load(context.Background(), rows, "csv", models.SaldoAwalOptions{UserID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, "barang tidak ditemukan", result.Errors[0].Error)
+	assert.Equal(t, "barang sudah diarsipkan", result.Errors[1].Error)
+	stokRepo.AssertNotCalled(t, "CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// MockSaldoAwalService menjalankan Load lewat mock agar handler bisa diuji tanpa database
+type MockSaldoAwalService struct {
+	mock.Mock
+}
+
+func (m *MockSaldoAwalService) Load(ctx context.Context, rows [][]string, format string, opts models.SaldoAwalOptions) (*models.SaldoAwalResult, error) {
+	args := m.Called(rows, format, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SaldoAwalResult), args.Error(1)
+}
+
+func withRole(req *http.Request, userID int, role string) *http.Request {
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+	ctx = context.WithValue(ctx, middleware.RoleKey, role)
+	return req.WithContext(ctx)
+}
+
+func TestSaldoAwalHandlerLoad(t *testing.T) {
+	csv := []byte("kode_barang;qty;harga\nBRG001;10;1.000\n")
+	rows := [][]string{{"kode_barang", "qty", "harga"}, {"BRG001", "10", "1.000"}}
+
+	t.Run("Fail - Non admin", func(t *testing.T) {
+		mockService := new(MockSaldoAwalService)
+		handler := handlers.NewSaldoAwalHandler(mockService)
+		authz := middleware.NewAuthorizer(staffPermissions())
+
+		req := withRole(newImportRequest(t, "saldo.csv", csv, ""), 2, "staff")
+		w := httptest.NewRecorder()
+		authz.Require(models.PermStokAdjust, handler.Load)(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "Load", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success - Force and dry run passed to service", func(t *testing.T) {
+		mockService := new(MockSaldoAwalService)
+		handler := handlers.NewSaldoAwalHandler(mockService)
+		opts := models.SaldoAwalOptions{UserID: 1, Force: true, DryRun: true}
+		mockService.On("Load", rows, "csv", opts).Return(&models.SaldoAwalResult{DryRun: true, TotalRows: 1, Loaded: 1, TotalQty: 10, TotalNilai: 10000}, nil)
+
+		req := withRole(newImportRequest(t, "saldo.csv", csv, "?force=true&dry_run=true"), 1, "admin")
+		w := httptest.NewRecorder()
+		handler.Load(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data models.SaldoAwalResult `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, 10000.0, resp.Data.TotalNilai)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Already loaded rows return 400", func(t *testing.T) {
+		mockService := new(MockSaldoAwalService)
+		handler := handlers.NewSaldoAwalHandler(mockService)
+		result := &models.SaldoAwalResult{TotalRows: 1, Failed: 1, Errors: []models.ImportRowError{{Row: 2, KodeBarang: "BRG001", Error: "saldo awal sudah pernah dimuat, gunakan force untuk menimpa"}}}
+		mockService.On("Load", rows, "csv", models.SaldoAwalOptions{UserID: 1}).Return(result, nil)
+
+		req := withRole(newImportRequest(t, "saldo.csv", csv, ""), 1, "admin")
+		w := httptest.NewRecorder()
+		handler.Load(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "1 baris gagal")
+	})
+
+	t.Run("Fail - Invalid file header", func(t *testing.T) {
+		mockService := new(MockSaldoAwalService)
+		handler := handlers.NewSaldoAwalHandler(mockService)
+		mockService.On("Load", mock.Anything, "csv", mock.Anything).Return(nil, services.ErrSaldoAwalFile)
+
+		req := withRole(newImportRequest(t, "saldo.csv", []byte("kode;jumlah\n"), ""), 1, "admin")
+		w := httptest.NewRecorder()
+		handler.Load(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
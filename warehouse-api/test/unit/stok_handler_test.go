@@ -0,0 +1,197 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStokHandlerGetHistory(t *testing.T) {
+	t.Run("Success - Offset mode with filters", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		filter := models.HistoryStokFilter{BarangID: 4, UserID: 2, JenisTransaksi: "masuk", StartDate: "2024-01-01", EndDate: "2024-01-31"}
+		mockRepo.On("GetHistory", filter, 10, 0, "", "").Return([]models.HistoryStok{}, 0, nil)
+
+		req := httptest.NewRequest("GET", "/api/history-stok?barang_id=4&user_id=2&jenis_transaksi=masuk&start_date=2024-01-01&end_date=2024-01-31", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetHistory(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Cursor mode first page returns next_cursor", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		next := &models.HistoryCursor{CreatedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC), ID: 41}
+		mockRepo.On("GetHistoryAfter", models.HistoryStokFilter{JenisTransaksi: "keluar"}, (*models.HistoryCursor)(nil), 2).
+			Return([]models.HistoryStok{{ID: 42}, {ID: 41}}, next, nil)
+
+		req := httptest.NewRequest("GET", "/api/history-stok?cursor=&limit=2&jenis_transaksi=keluar", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetHistory(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp models.CursorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, utils.EncodeCursor(next.CreatedAt, next.ID), resp.Meta.NextCursor)
+		assert.NotContains(t, w.Body.String(), `"total"`)
+		mockRepo.AssertNotCalled(t, "GetHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success - Cursor mode last page has no next_cursor", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		createdAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+		mockRepo.On("GetHistoryAfter", models.HistoryStokFilter{}, &models.HistoryCursor{CreatedAt: createdAt, ID: 41}, 10).
+			Return([]models.HistoryStok{{ID: 40}}, nil, nil)
+
+		req := httptest.NewRequest("GET", "/api/history-stok?cursor="+utils.EncodeCursor(createdAt, 41), nil)
+		w := httptest.NewRecorder()
+
+		handler.GetHistory(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "next_cursor")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Invalid cursor", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		req := httptest.NewRequest("GET", "/api/history-stok?cursor=rusak", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetHistory(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestStokHandlerGetAllAsOf(t *testing.T) {
+	t.Run("Success - Uses frozen snapshot when available", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		createdAt := time.Date(2026, 10, 1, 0, 5, 0, 0, time.UTC)
+		mockRepo.On("GetSnapshot", "2026-09-30").Return([]models.StokAsOf{{BarangID: 1, KodeBarang: "BRG001", StokAkhir: 12}}, &createdAt, nil)
+
+		req := httptest.NewRequest("GET", "/api/stok?as_of=2026-09-30", nil)
+		w := httptest.NewRecorder()
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"source":"snapshot"`)
+		mockRepo.AssertNotCalled(t, "GetAsOf", mock.Anything)
+	})
+
+	t.Run("Success - Reconstructs from history without snapshot", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		mockRepo.On("GetSnapshot", "2026-09-15").Return(nil, nil, nil)
+		mockRepo.On("GetAsOf", "2026-09-15").Return([]models.StokAsOf{{BarangID: 1, StokAkhir: 7}}, nil)
+
+		req := httptest.NewRequest("GET", "/api/stok?as_of=2026-09-15", nil)
+		w := httptest.NewRecorder()
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"source":"history"`)
+		assert.Contains(t, w.Body.String(), `"stok_akhir":7`)
+	})
+
+	t.Run("Success - live=true skips snapshot", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		mockRepo.On("GetAsOf", "2026-09-30").Return([]models.StokAsOf{}, nil)
+
+		req := httptest.NewRequest("GET", "/api/stok?as_of=2026-09-30&live=true", nil)
+		w := httptest.NewRecorder()
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertNotCalled(t, "GetSnapshot", mock.Anything)
+	})
+
+	t.Run("Fail - Invalid as_of", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		req := httptest.NewRequest("GET", "/api/stok?as_of=30-09-2026", nil)
+		w := httptest.NewRecorder()
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestStokHandlerCreateSnapshot(t *testing.T) {
+	t.Run("Fail - Non admin", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+		authz := middleware.NewAuthorizer(staffPermissions())
+
+		req := withRole(httptest.NewRequest("POST", "/api/stok/snapshot?periode=2024-01-31", nil), 2, "staff")
+		w := httptest.NewRecorder()
+		authz.Require(models.PermStokAdjust, handler.CreateSnapshot)(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Fail - Snapshot already exists", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+		mockRepo.On("HasSnapshot", "2024-01-31").Return(true, nil)
+
+		req := withRole(httptest.NewRequest("POST", "/api/stok/snapshot?periode=2024-01-31", nil), 1, "admin")
+		w := httptest.NewRecorder()
+		handler.CreateSnapshot(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockRepo.AssertNotCalled(t, "CreateSnapshot", mock.Anything)
+	})
+
+	t.Run("Fail - Periode not in the past", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+
+		periode := time.Now().Format("2006-01-02")
+		req := withRole(httptest.NewRequest("POST", "/api/stok/snapshot?periode="+periode, nil), 1, "admin")
+		w := httptest.NewRecorder()
+		handler.CreateSnapshot(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Success - Creates snapshot", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		handler := handlers.NewStokHandler(mockRepo)
+		mockRepo.On("HasSnapshot", "2024-01-31").Return(false, nil)
+		mockRepo.On("CreateSnapshot", "2024-01-31").Return(5, nil)
+
+		req := withRole(httptest.NewRequest("POST", "/api/stok/snapshot?periode=2024-01-31", nil), 1, "admin")
+		w := httptest.NewRecorder()
+		handler.CreateSnapshot(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"jumlah_barang":5`)
+	})
+}
@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviousMonthEnd(t *testing.T) {
+	assert.Equal(t, "2026-09-30", services.PreviousMonthEnd(time.Date(2026, 10, 1, 0, 30, 0, 0, time.UTC)))
+	assert.Equal(t, "2024-02-29", services.PreviousMonthEnd(time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2025-12-31", services.PreviousMonthEnd(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestSnapshotPreviousMonth(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 30, 0, 0, time.UTC)
+
+	t.Run("Creates missing snapshot", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		mockRepo.On("HasSnapshot", "2026-09-30").Return(false, nil)
+		mockRepo.On("CreateSnapshot", "2026-09-30").Return(12, nil)
+
+		periode, n, err := services.SnapshotPreviousMonth(context.Background(), mockRepo, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2026-09-30", periode)
+		assert.Equal(t, 12, n)
+	})
+
+	t.Run("Existing snapshot is never rewritten", func(t *testing.T) {
+		mockRepo := new(MockStokRepository)
+		mockRepo.On("HasSnapshot", "2026-09-30").Return(true, nil)
+
+		_, n, err := services.SnapshotPreviousMonth(context.Background(), mockRepo, now)
+
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+		mockRepo.AssertNotCalled(t, "CreateSnapshot", "2026-09-30")
+	})
+}
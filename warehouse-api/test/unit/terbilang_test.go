@@ -0,0 +1,36 @@
+package unit
+
+import (
+	"testing"
+	"warehouse-api/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerbilang(t *testing.T) {
+	cases := map[float64]string{
+		0:             "nol",
+		1:             "satu",
+		11:            "sebelas",
+		15:            "lima belas",
+		100:           "seratus",
+		111:           "seratus sebelas",
+		1000:          "seribu",
+		1500:          "seribu lima ratus",
+		21000:         "dua puluh satu ribu",
+		1250000:       "satu juta dua ratus lima puluh ribu",
+		17500000:      "tujuh belas juta lima ratus ribu",
+		2000000000:    "dua miliar",
+		1000001:       "satu juta satu",
+		3000000000000: "tiga triliun",
+	}
+
+	for value, expected := range cases {
+		assert.Equal(t, expected, utils.Terbilang(value), "value %v", value)
+	}
+}
+
+func TestTerbilangRupiah(t *testing.T) {
+	assert.Equal(t, "Delapan belas juta tujuh ratus ribu rupiah", utils.TerbilangRupiah(18700000))
+	assert.Equal(t, "Seribu rupiah", utils.TerbilangRupiah(999.6))
+}
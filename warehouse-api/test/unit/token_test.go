@@ -0,0 +1,196 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"warehouse-api/auth"
+	"warehouse-api/config"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) CreateRefresh(userID int, tokenHash, familyID string, ttl time.Duration) error {
+	args := m.Called(userID, tokenHash, familyID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RotateRefresh(oldHash, newHash string, ttl time.Duration) (*models.RefreshToken, error) {
+	args := m.Called(oldHash, newHash, ttl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeRefreshFamily(userID int, tokenHash string) error {
+	args := m.Called(userID, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAccess(jti string, userID int, expiresAt time.Time) error {
+	args := m.Called(jti, userID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	args := m.Called(jti, userID, issuedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+var (
+	testAuthConfig = config.Auth{AccessTTL: 15 * time.Minute, RefreshTTL: 7 * 24 * time.Hour}
+	testKeys       = auth.NewKeySet(auth.NewHMACKey([]byte("test-secret")))
+)
+
+func parseTestToken(t *testing.T, tokenString string) *auth.Claims {
+	claims, err := testKeys.Verify(tokenString)
+	assert.NoError(t, err)
+	return claims
+}
+
+func TestTokenService(t *testing.T) {
+	user := &models.User{ID: 3, Username: "andi", Role: "staff"}
+
+	t.Run("Issue - short lived access token with jti and hashed refresh token", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		var storedHash string
+		repo.On("CreateRefresh", 3, mock.AnythingOfType("string"), mock.AnythingOfType("string"), testAuthConfig.RefreshTTL).
+			Run(func(args mock.Arguments) { storedHash = args.String(1) }).Return(nil)
+		service := services.NewTokenService(repo, new(MockUserRepository), testKeys, testAuthConfig)
+
+		tokens, err := service.Issue(user)
+		assert.NoError(t, err)
+		assert.Equal(t, 900, tokens.ExpiresIn)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.Equal(t, services.HashToken(tokens.RefreshToken), storedHash)
+		assert.NotEqual(t, tokens.RefreshToken, storedHash)
+
+		claims := parseTestToken(t, tokens.Token)
+		assert.NotEmpty(t, claims.ID)
+		assert.Equal(t, "staff", claims.Role)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), claims.ExpiresAt, 5*time.Second)
+	})
+
+	t.Run("Issue - each token has its own jti", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		repo.On("CreateRefresh", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		service := services.NewTokenService(repo, new(MockUserRepository), testKeys, testAuthConfig)
+
+		a, _ := service.Issue(user)
+		b, _ := service.Issue(user)
+		assert.NotEqual(t, parseTestToken(t, a.Token).ID, parseTestToken(t, b.Token).ID)
+		assert.NotEqual(t, a.RefreshToken, b.RefreshToken)
+	})
+
+	t.Run("Refresh - rotates and reloads role", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		users := new(MockUserRepository)
+		var newHash string
+		repo.On("RotateRefresh", services.HashToken("old"), mock.AnythingOfType("string"), testAuthConfig.RefreshTTL).
+			Run(func(args mock.Arguments) { newHash = args.String(1) }).
+			Return(&models.RefreshToken{ID: 9, UserID: 3, FamilyID: "fam"}, nil)
+		users.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi", Role: "gudang", IsActive: true}, nil)
+		service := services.NewTokenService(repo, users, testKeys, testAuthConfig)
+
+		tokens, err := service.Refresh("old")
+		assert.NoError(t, err)
+		assert.Equal(t, services.HashToken(tokens.RefreshToken), newHash)
+		assert.Equal(t, "gudang", parseTestToken(t, tokens.Token).Role)
+	})
+
+	t.Run("Refresh - unknown or reused token", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		repo.On("RotateRefresh", services.HashToken("unknown"), mock.Anything, mock.Anything).Return(nil, repositories.ErrRefreshTokenNotFound)
+		repo.On("RotateRefresh", services.HashToken("reused"), mock.Anything, mock.Anything).Return(nil, repositories.ErrRefreshTokenReused)
+		service := services.NewTokenService(repo, new(MockUserRepository), testKeys, testAuthConfig)
+
+		_, err := service.Refresh("unknown")
+		assert.ErrorIs(t, err, services.ErrRefreshTokenInvalid)
+		_, err = service.Refresh("reused")
+		assert.ErrorIs(t, err, services.ErrRefreshTokenReused)
+		_, err = service.Refresh("")
+		assert.ErrorIs(t, err, services.ErrRefreshTokenInvalid)
+	})
+
+	t.Run("Refresh - deactivated user", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		users := new(MockUserRepository)
+		repo.On("RotateRefresh", services.HashToken("old"), mock.Anything, mock.Anything).Return(&models.RefreshToken{ID: 9, UserID: 3, FamilyID: "fam"}, nil)
+		users.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi", Role: "gudang"}, nil)
+		service := services.NewTokenService(repo, users, testKeys, testAuthConfig)
+
+		_, err := service.Refresh("old")
+		assert.ErrorIs(t, err, services.ErrAkunNonaktif)
+	})
+
+	t.Run("Logout - denylists jti and revokes session", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		exp := time.Now().Add(time.Minute)
+		repo.On("RevokeAccess", "jti-1", 3, exp).Return(nil)
+		repo.On("RevokeRefreshFamily", 3, services.HashToken("refresh")).Return(nil)
+		service := services.NewTokenService(repo, new(MockUserRepository), testKeys, testAuthConfig)
+
+		assert.NoError(t, service.Logout(3, "jti-1", exp, "refresh"))
+		repo.AssertExpectations(t)
+	})
+}
+
+type stubDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (d stubDenylist) IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	return d.revoked[jti], d.err
+}
+
+func TestAuthMiddlewareDenylist(t *testing.T) {
+	middleware.SetTokenVerifier(testKeys)
+	defer middleware.SetTokenVerifier(nil)
+	defer middleware.SetTokenDenylist(nil)
+
+	sign := func(claims auth.Claims) string {
+		token, _ := testKeys.Sign(claims)
+		return token
+	}
+	claimsWith := func(jti string) auth.Claims {
+		return auth.Claims{UserID: 1, Role: "admin", ID: jti, IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+	}
+	serve := func(token string) *httptest.ResponseRecorder {
+		handler := middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "jti-ok", r.Context().Value(middleware.TokenIDKey))
+			w.WriteHeader(http.StatusOK)
+		}))
+		req := httptest.NewRequest("GET", "/api/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	middleware.SetTokenDenylist(stubDenylist{revoked: map[string]bool{"jti-revoked": true}})
+	assert.Equal(t, http.StatusOK, serve(sign(claimsWith("jti-ok"))).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(sign(claimsWith("jti-revoked"))).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(sign(claimsWith(""))).Code)
+
+	middleware.SetTokenDenylist(stubDenylist{err: errors.New("db down")})
+	assert.Equal(t, http.StatusInternalServerError, serve(sign(claimsWith("jti-ok"))).Code)
+}
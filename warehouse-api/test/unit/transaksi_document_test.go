@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+	"warehouse-api/config"
+	"warehouse-api/documents"
+	"warehouse-api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTransaksiDocuments(t *testing.T) {
+	company := config.Company{Name: "PT Gudang Sejahtera", Address: "Jl. Industri No. 1", Phone: "021-5550123"}
+	barang := &models.Barang{KodeBarang: "BRG-001", NamaBarang: "Laptop Dell XPS 13", Satuan: "unit"}
+
+	jual := &models.JualHeader{
+		ID: 1, NoFaktur: "JUAL-001", Customer: "Budi", Total: 35000000, CreatedAt: time.Now(),
+		User:    &models.User{Username: "staff"},
+		Details: []models.JualDetail{{BarangID: 1, Qty: 2, Harga: 17500000, Subtotal: 35000000, Barang: barang}},
+	}
+	beli := &models.BeliHeader{
+		ID: 1, NoFaktur: "BELI-001", Supplier: "PT Supplier", Total: 30000000, CreatedAt: time.Now(),
+		Details: []models.BeliDetail{{BarangID: 1, Qty: 2, Harga: 15000000, Subtotal: 30000000, Barang: barang}},
+	}
+
+	t.Run("Faktur penjualan", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, documents.RenderFakturPenjualan(&buf, company, jual))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF")))
+	})
+
+	t.Run("Surat jalan", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, documents.RenderSuratJalan(&buf, company, jual))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF")))
+	})
+
+	t.Run("Bukti pembelian", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, documents.RenderNotaPembelian(&buf, company, beli))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF")))
+	})
+}
@@ -0,0 +1,155 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPenjualanHandlerGetAll(t *testing.T) {
+	t.Run("Success - Default pagination", func(t *testing.T) {
+		mockRepo := new(MockPenjualanRepository)
+		handler := handlers.NewPenjualanHandler(nil, mockRepo)
+
+		mockRepo.On("GetAll", models.TransaksiFilter{}, 10, 0, "", "").Return([]models.JualHeader{{ID: 1, NoFaktur: "JUAL-001"}}, 25, nil)
+
+		req := httptest.NewRequest("GET", "/api/penjualan", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp models.APIResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NotNil(t, resp.Meta)
+		assert.Equal(t, 1, resp.Meta.Page)
+		assert.Equal(t, 10, resp.Meta.Limit)
+		assert.Equal(t, 25, resp.Meta.Total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Filters, sorting and page", func(t *testing.T) {
+		mockRepo := new(MockPenjualanRepository)
+		handler := handlers.NewPenjualanHandler(nil, mockRepo)
+
+		mockRepo.On("GetAll", mock.MatchedBy(func(f models.TransaksiFilter) bool {
+			return f.Party == "budi" && f.UserID == 3 && f.Status == "completed" && f.FakturPrefix == "JUAL-2024" &&
+				f.StartDate == "2024-01-01" && f.EndDate == "2024-01-31" &&
+				f.MinTotal != nil && *f.MinTotal == 1000 && f.MaxTotal != nil && *f.MaxTotal == 50000
+		}), 20, 20, "total", "desc").Return([]models.JualHeader{}, 0, nil)
+
+		req := httptest.NewRequest("GET", "/api/penjualan?page=2&limit=20&sort_by=total&order=desc&customer=budi&user_id=3&status=completed&no_faktur=JUAL-2024&start_date=2024-01-01&end_date=2024-01-31&min_total=1000&max_total=50000", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	for name, query := range map[string]string{
+		"invalid date":   "start_date=01-01-2024",
+		"invalid total":  "min_total=abc",
+		"inverted range": "min_total=500&max_total=100",
+		"invalid user":   "user_id=x",
+	} {
+		t.Run("Fail - "+name, func(t *testing.T) {
+			mockRepo := new(MockPenjualanRepository)
+			handler := handlers.NewPenjualanHandler(nil, mockRepo)
+
+			req := httptest.NewRequest("GET", "/api/penjualan?"+query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetAll(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockRepo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPembelianHandlerGetAll(t *testing.T) {
+	mockRepo := new(MockPembelianRepository)
+	handler := handlers.NewPembelianHandler(nil, mockRepo)
+
+	mockRepo.On("GetAll", models.TransaksiFilter{Party: "PT Maju"}, 10, 0, "tanggal", "asc").Return([]models.BeliHeader{}, 0, nil)
+
+	req := httptest.NewRequest("GET", "/api/pembelian?supplier=PT+Maju&sort_by=tanggal&order=asc", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetAll(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+type stubPenjualanService struct {
+	err error
+}
+
+func (s stubPenjualanService) Create(ctx context.Context, req models.CreatePenjualanRequest) (*models.JualHeader, error) {
+	return nil, s.err
+}
+
+func TestPenjualanHandlerCreateErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		code int
+	}{
+		"archived barang":    {fmt.Errorf("%w: barang ID 3", services.ErrBarangDiarsipkan), http.StatusBadRequest},
+		"insufficient stock": {fmt.Errorf("%w untuk Barang ID 3", services.ErrStokTidakCukup), http.StatusBadRequest},
+		"database error":     {errors.New("barang: koneksi terputus"), http.StatusInternalServerError},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := handlers.NewPenjualanHandler(stubPenjualanService{err: tc.err}, nil)
+
+			req := httptest.NewRequest("POST", "/api/penjualan", strings.NewReader(`{"customer":"Toko A","details":[{"barang_id":3,"qty":1,"harga":1000}]}`))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, 1))
+			w := httptest.NewRecorder()
+
+			handler.Create(w, req)
+
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+func TestPembelianServiceCreateArchivedBarang(t *testing.T) {
+	barangRepo := new(MockBarangRepositoryHandler)
+	barangRepo.On("GetByID", 3).Return(&models.BarangWithStok{Barang: models.Barang{ID: 3, IsActive: false}}, nil)
+	service := services.NewPembelianService(nil, new(MockPembelianRepository), new(MockStokRepository), barangRepo)
+
+	_, err := service.Create(context.Background(), models.CreatePembelianRequest{
+		Supplier: "PT Maju",
+		Details:  []models.CreatePembelianDetail{{BarangID: 3, Qty: 1, Harga: 1000}},
+	})
+
+	assert.ErrorIs(t, err, services.ErrBarangDiarsipkan)
+}
+
+func TestPembelianServiceCreateBarcodeMismatch(t *testing.T) {
+	barangRepo := new(MockBarangRepositoryHandler)
+	barangRepo.On("GetByBarcode", "0036000291452").Return(&models.BarangWithStok{Barang: models.Barang{ID: 8, IsActive: true}}, nil)
+	service := services.NewPembelianService(nil, new(MockPembelianRepository), new(MockStokRepository), barangRepo)
+
+	_, err := service.Create(context.Background(), models.CreatePembelianRequest{
+		Supplier: "PT Maju",
+		Details:  []models.CreatePembelianDetail{{BarangID: 3, Barcode: "036000291452", Qty: 1, Harga: 1000}},
+	})
+
+	assert.ErrorIs(t, err, services.ErrBarcodeBedaBarang)
+	barangRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
@@ -0,0 +1,383 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"warehouse-api/auth"
+	"warehouse-api/config"
+	"warehouse-api/handlers"
+	"warehouse-api/models"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) GetSecret(userID int) (string, bool, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockTwoFactorRepository) SetPendingSecret(userID int, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Enable(ctx context.Context, userID int, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Disable(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) UseStep(userID int, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) CreateChallenge(userID int, tokenHash string, ttl time.Duration) error {
+	args := m.Called(userID, tokenHash, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) GetChallenge(tokenHash string, maxAttempts int) (int, error) {
+	args := m.Called(tokenHash, maxAttempts)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) FailChallenge(tokenHash string) error {
+	args := m.Called(tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) DeleteChallenge(tokenHash string) error {
+	args := m.Called(tokenHash)
+	return args.Error(0)
+}
+
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Required(user *models.User) bool {
+	args := m.Called(user)
+	return args.Bool(0)
+}
+
+func (m *MockTwoFactorService) StartChallenge(user *models.User) (*models.TwoFactorChallenge, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TwoFactorChallenge), args.Error(1)
+}
+
+func (m *MockTwoFactorService) ChallengeSetup(challengeToken string) (*models.TwoFactorSetup, error) {
+	args := m.Called(challengeToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TwoFactorSetup), args.Error(1)
+}
+
+func (m *MockTwoFactorService) CompleteChallenge(ctx context.Context, challengeToken, code string) (*models.User, []string, error) {
+	args := m.Called(challengeToken, code)
+	var user *models.User
+	if args.Get(0) != nil {
+		user = args.Get(0).(*models.User)
+	}
+	var codes []string
+	if args.Get(1) != nil {
+		codes = args.Get(1).([]string)
+	}
+	return user, codes, args.Error(2)
+}
+
+func (m *MockTwoFactorService) Setup(userID int) (*models.TwoFactorSetup, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TwoFactorSetup), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Enable(ctx context.Context, userID int, code string) ([]string, error) {
+	args := m.Called(userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Disable(ctx context.Context, userID int, password, code string) error {
+	args := m.Called(userID, password, code)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	args := m.Called(userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// Secret ASCII "12345678901234567890" dari vektor uji RFC 6238 (SHA1)
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP(t *testing.T) {
+	t.Run("RFC 6238 test vectors (6 digit terakhir)", func(t *testing.T) {
+		vectors := map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"}
+		for unix, want := range vectors {
+			code, err := auth.TOTPCode(rfcSecret, time.Unix(unix, 0))
+			require.NoError(t, err)
+			assert.Equal(t, want, code, "t=%d", unix)
+		}
+	})
+
+	t.Run("Accepts one step of clock skew", func(t *testing.T) {
+		now := time.Unix(1234567890, 0)
+		prev, _ := auth.TOTPCode(rfcSecret, now.Add(-30*time.Second))
+		old, _ := auth.TOTPCode(rfcSecret, now.Add(-90*time.Second))
+
+		step, ok := auth.ValidateTOTP(rfcSecret, prev, now)
+		assert.True(t, ok)
+		assert.Equal(t, now.Unix()/30-1, step)
+		_, ok = auth.ValidateTOTP(rfcSecret, old, now)
+		assert.False(t, ok)
+	})
+
+	t.Run("Provisioning URI", func(t *testing.T) {
+		secret, err := auth.GenerateTOTPSecret()
+		require.NoError(t, err)
+		assert.Len(t, secret, 32)
+
+		uri := auth.TOTPURI("PT Gudang", "admin", secret)
+		assert.True(t, strings.HasPrefix(uri, "otpauth://totp/PT%20Gudang:admin?"))
+		assert.Contains(t, uri, "secret="+secret)
+		assert.Contains(t, uri, "issuer=PT+Gudang")
+	})
+}
+
+var testTwoFactorConfig = config.TwoFactor{Issuer: "Warehouse", RequireAdmin: true, ChallengeTTL: 5 * time.Minute}
+
+func TestTwoFactorService(t *testing.T) {
+	secret, _ := auth.GenerateTOTPSecret()
+	currentCode := func() string {
+		code, _ := auth.TOTPCode(secret, time.Now())
+		return code
+	}
+
+	t.Run("Required - enrolled user or forced admin", func(t *testing.T) {
+		service := services.NewTwoFactorService(new(MockTwoFactorRepository), new(MockUserRepository), testTwoFactorConfig)
+		assert.True(t, service.Required(&models.User{Role: "staff", TwoFactorEnabled: true}))
+		assert.True(t, service.Required(&models.User{Role: "admin"}))
+		assert.False(t, service.Required(&models.User{Role: "staff"}))
+	})
+
+	t.Run("Setup - pending secret and QR", func(t *testing.T) {
+		repo := new(MockTwoFactorRepository)
+		users := new(MockUserRepository)
+		users.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi"}, nil)
+		repo.On("SetPendingSecret", 3, mock.AnythingOfType("string")).Return(nil)
+		service := services.NewTwoFactorService(repo, users, testTwoFactorConfig)
+
+		setup, err := service.Setup(3)
+		require.NoError(t, err)
+		assert.Contains(t, setup.OTPAuthURL, "Warehouse:andi")
+		assert.True(t, strings.HasPrefix(setup.QRCode, "data:image/png;base64,"))
+		repo.AssertCalled(t, "SetPendingSecret", 3, setup.Secret)
+	})
+
+	t.Run("Enable - returns recovery codes and stores hashes", func(t *testing.T) {
+		repo := new(MockTwoFactorRepository)
+		repo.On("GetSecret", 3).Return(secret, false, nil)
+		repo.On("UseStep", 3, mock.AnythingOfType("int64")).Return(true, nil)
+		var stored []string
+		repo.On("Enable", 3, mock.Anything).Run(func(args mock.Arguments) { stored = args.Get(1).([]string) }).Return(nil)
+		service := services.NewTwoFactorService(repo, new(MockUserRepository), testTwoFactorConfig)
+
+		codes, err := service.Enable(context.Background(), 3, currentCode())
+		require.NoError(t, err)
+		require.Len(t, codes, 10)
+		assert.Regexp(t, `^[0-9a-f]{5}-[0-9a-f]{5}$`, codes[0])
+		assert.Equal(t, services.HashToken(strings.ReplaceAll(codes[0], "-", "")), stored[0])
+	})
+
+	t.Run("Enable - wrong or replayed code", func(t *testing.T) {
+		repo := new(MockTwoFactorRepository)
+		repo.On("GetSecret", 3).Return(secret, false, nil)
+		repo.On("UseStep", 3, mock.AnythingOfType("int64")).Return(false, nil)
+		service := services.NewTwoFactorService(repo, new(MockUserRepository), testTwoFactorConfig)
+
+		_, err := service.Enable(context.Background(), 3, "000000x")
+		assert.ErrorIs(t, err, services.ErrKode2FASalah)
+		_, err = service.Enable(context.Background(), 3, currentCode())
+		assert.ErrorIs(t, err, services.ErrKode2FASalah)
+		repo.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CompleteChallenge - recovery code", func(t *testing.T) {
+		repo := new(MockTwoFactorRepository)
+		users := new(MockUserRepository)
+		repo.On("GetChallenge", services.HashToken("challenge"), 5).Return(3, nil)
+		users.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi", TwoFactorEnabled: true}, nil)
+		repo.On("GetSecret", 3).Return(secret, true, nil)
+		repo.On("UseRecoveryCode", 3, services.HashToken("abcde12345")).Return(true, nil)
+		repo.On("DeleteChallenge", services.HashToken("challenge")).Return(nil)
+		service := services.NewTwoFactorService(repo, users, testTwoFactorConfig)
+
+		user, codes, err := service.CompleteChallenge(context.Background(), "challenge", "ABCDE-12345")
+		require.NoError(t, err)
+		assert.Equal(t, "andi", user.Username)
+		assert.Nil(t, codes)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("CompleteChallenge - wrong code counts an attempt", func(t *testing.T) {
+		repo := new(MockTwoFactorRepository)
+		users := new(MockUserRepository)
+		repo.On("GetChallenge", services.HashToken("challenge"), 5).Return(3, nil)
+		users.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi", TwoFactorEnabled: true}, nil)
+		repo.On("GetSecret", 3).Return(secret, true, nil)
+		repo.On("UseRecoveryCode", 3, mock.Anything).Return(false, nil)
+		repo.On("FailChallenge", services.HashToken("challenge")).Return(nil)
+		service := services.NewTwoFactorService(repo, users, testTwoFactorConfig)
+
+		user, _, err := service.CompleteChallenge(context.Background(), "challenge", "salah-salah")
+		assert.ErrorIs(t, err, services.ErrKode2FASalah)
+		assert.Equal(t, "andi", user.Username)
+		repo.AssertNotCalled(t, "DeleteChallenge", mock.Anything)
+	})
+
+	t.Run("Disable - forced for admin", func(t *testing.T) {
+		users := new(MockUserRepository)
+		users.On("GetByID", 1).Return(&models.User{ID: 1, Role: "admin", TwoFactorEnabled: true}, nil)
+		service := services.NewTwoFactorService(new(MockTwoFactorRepository), users, testTwoFactorConfig)
+
+		assert.ErrorIs(t, service.Disable(context.Background(), 1, "admin123", currentCode()), services.Err2FAWajib)
+	})
+
+	t.Run("Disable - requires password", func(t *testing.T) {
+		repo := new(MockTwoFactorRepository)
+		users := new(MockUserRepository)
+		hash, _ := bcrypt.GenerateFromPassword([]byte("rahasia"), bcrypt.MinCost)
+		users.On("GetByID", 3).Return(&models.User{ID: 3, Role: "staff", TwoFactorEnabled: true}, nil)
+		users.On("GetPasswordHash", 3).Return(string(hash), nil)
+		service := services.NewTwoFactorService(repo, users, testTwoFactorConfig)
+
+		assert.ErrorIs(t, service.Disable(context.Background(), 3, "salah", currentCode()), services.ErrPasswordSalah)
+		repo.AssertNotCalled(t, "Disable", mock.Anything)
+	})
+}
+
+func TestUserHandlerLoginTwoFactor(t *testing.T) {
+	user := &models.User{ID: 1, Username: "admin", Role: "admin", TwoFactorEnabled: true}
+
+	t.Run("Password step returns challenge instead of token", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokens := new(MockTokenService)
+		mockGuard := new(MockLoginGuard)
+		mockTwoFactor := new(MockTwoFactorService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, mockGuard, mockTwoFactor)
+		mockGuard.On("Check", "admin").Return(time.Duration(0), nil)
+		mockService.On("ValidateCredentials", "admin", "admin123").Return(user, nil)
+		mockTwoFactor.On("Required", user).Return(true)
+		mockTwoFactor.On("StartChallenge", user).Return(&models.TwoFactorChallenge{TwoFactorRequired: true, ChallengeToken: "challenge", ExpiresIn: 300}, nil)
+
+		req := httptest.NewRequest("POST", "/api/login", bytes.NewBufferString(`{"username":"admin","password":"admin123"}`))
+		w := httptest.NewRecorder()
+		handler.Login(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"challenge_token":"challenge"`)
+		assert.NotContains(t, w.Body.String(), `"refresh_token"`)
+		mockTokens.AssertNotCalled(t, "Issue", mock.Anything)
+		mockGuard.AssertNotCalled(t, "Succeeded", mock.Anything)
+	})
+
+	t.Run("Code step issues tokens", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokens := new(MockTokenService)
+		mockGuard := new(MockLoginGuard)
+		mockTwoFactor := new(MockTwoFactorService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, mockGuard, mockTwoFactor)
+		mockTwoFactor.On("CompleteChallenge", "challenge", "123456").Return(user, []string{"abcde-12345"}, nil)
+		mockService.On("Profile", 1).Return(user, nil)
+		mockGuard.On("Succeeded", "admin").Return(nil)
+		mockTokens.On("Issue", user).Return(&models.TokenResponse{Token: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil)
+
+		req := httptest.NewRequest("POST", "/api/login/2fa", bytes.NewBufferString(`{"challenge_token":"challenge","code":"123456"}`))
+		w := httptest.NewRecorder()
+		handler.LoginTwoFactor(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"access"`)
+		assert.Contains(t, w.Body.String(), `"recovery_codes":["abcde-12345"]`)
+	})
+
+	t.Run("Wrong code is recorded for lockout", func(t *testing.T) {
+		mockGuard := new(MockLoginGuard)
+		mockTwoFactor := new(MockTwoFactorService)
+		handler := handlers.NewUserHandler(new(MockUserService), new(MockTokenService), mockGuard, mockTwoFactor)
+		mockTwoFactor.On("CompleteChallenge", "challenge", "000000").Return(user, nil, services.ErrKode2FASalah)
+		mockGuard.On("Failed", mock.MatchedBy(func(a *models.LoginAttempt) bool {
+			return a.Username == "admin" && a.Alasan == services.ErrKode2FASalah.Error()
+		})).Return(nil)
+
+		req := httptest.NewRequest("POST", "/api/login/2fa", bytes.NewBufferString(`{"challenge_token":"challenge","code":"000000"}`))
+		w := httptest.NewRecorder()
+		handler.LoginTwoFactor(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockGuard.AssertExpectations(t)
+	})
+
+	t.Run("Expired challenge", func(t *testing.T) {
+		mockTwoFactor := new(MockTwoFactorService)
+		handler := handlers.NewUserHandler(new(MockUserService), new(MockTokenService), new(MockLoginGuard), mockTwoFactor)
+		mockTwoFactor.On("CompleteChallenge", "old", "123456").Return(nil, nil, services.ErrChallengeInvalid)
+
+		req := httptest.NewRequest("POST", "/api/login/2fa", bytes.NewBufferString(`{"challenge_token":"old","code":"123456"}`))
+		w := httptest.NewRecorder()
+		handler.LoginTwoFactor(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestTwoFactorHandlerDisableForced(t *testing.T) {
+	mockTwoFactor := new(MockTwoFactorService)
+	handler := handlers.NewTwoFactorHandler(mockTwoFactor)
+	mockTwoFactor.On("Disable", 1, "admin123", "123456").Return(services.Err2FAWajib)
+
+	req := withRole(httptest.NewRequest("POST", "/api/me/2fa/disable", bytes.NewBufferString(`{"password":"admin123","code":"123456"}`)), 1, "admin")
+	w := httptest.NewRecorder()
+	handler.Disable(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
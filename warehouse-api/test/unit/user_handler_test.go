@@ -2,12 +2,18 @@ package unit
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"warehouse-api/handlers"
+	"warehouse-api/middleware"
 	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -18,7 +24,7 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) Register(req *models.RegisterRequest) (*models.User, error) {
+func (m *MockUserService) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -47,10 +53,79 @@ func (m *MockUserService) HashPassword(password string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockUserService) Profile(id int) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) Update(ctx context.Context, id int, req *models.UpdateUserRequest) (*models.User, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) SetActive(ctx context.Context, id int, active bool) error {
+	args := m.Called(id, active)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ResetPassword(ctx context.Context, id int, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ChangePassword(ctx context.Context, id int, currentPassword, newPassword string) error {
+	args := m.Called(id, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) Issue(user *models.User) (*models.TokenResponse, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TokenResponse), args.Error(1)
+}
+
+func (m *MockTokenService) Refresh(refreshToken string) (*models.TokenResponse, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TokenResponse), args.Error(1)
+}
+
+func (m *MockTokenService) Logout(userID int, jti string, expiresAt time.Time, refreshToken string) error {
+	args := m.Called(userID, jti, expiresAt, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) LogoutAll(ctx context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) IsRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	args := m.Called(jti, userID, issuedAt)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestUserHandlerLogin(t *testing.T) {
 	t.Run("Success - Valid login", func(t *testing.T) {
 		mockService := new(MockUserService)
-		handler := handlers.NewUserHandler(mockService)
+		mockTokens := new(MockTokenService)
+		mockGuard := new(MockLoginGuard)
+		mockTwoFactor := new(MockTwoFactorService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, mockGuard, mockTwoFactor)
 
 		loginReq := models.LoginRequest{
 			Username: "admin",
@@ -64,6 +139,10 @@ func TestUserHandlerLogin(t *testing.T) {
 		}
 
 		mockService.On("ValidateCredentials", loginReq.Username, loginReq.Password).Return(user, nil)
+		mockTokens.On("Issue", user).Return(&models.TokenResponse{Token: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil)
+		mockGuard.On("Check", "admin").Return(time.Duration(0), nil)
+		mockGuard.On("Succeeded", "admin").Return(nil)
+		mockTwoFactor.On("Required", user).Return(false)
 
 		body, _ := json.Marshal(loginReq)
 		req := httptest.NewRequest("POST", "/api/login", bytes.NewBuffer(body))
@@ -77,12 +156,54 @@ func TestUserHandlerLogin(t *testing.T) {
 		var response models.APIResponse
 		json.Unmarshal(w.Body.Bytes(), &response)
 		assert.True(t, response.Success)
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, "access", data["token"])
+		assert.Equal(t, "refresh", data["refresh_token"])
+		assert.Equal(t, float64(900), data["expires_in"])
 		mockService.AssertExpectations(t)
+		mockGuard.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Wrong password is recorded", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockGuard := new(MockLoginGuard)
+		handler := handlers.NewUserHandler(mockService, new(MockTokenService), mockGuard, new(MockTwoFactorService))
+		mockGuard.On("Check", "admin").Return(time.Duration(0), nil)
+		mockService.On("ValidateCredentials", "admin", "salah").Return(nil, errors.New("username atau password salah"))
+		mockGuard.On("Failed", &models.LoginAttempt{
+			Username:  "admin",
+			IP:        "192.0.2.1",
+			UserAgent: "curl/8.0",
+			Alasan:    "username atau password salah",
+		}).Return(nil)
+
+		req := httptest.NewRequest("POST", "/api/login", bytes.NewBufferString(`{"username":"admin","password":"salah"}`))
+		req.Header.Set("User-Agent", "curl/8.0")
+		w := httptest.NewRecorder()
+		handler.Login(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockGuard.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Locked username", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockGuard := new(MockLoginGuard)
+		handler := handlers.NewUserHandler(mockService, new(MockTokenService), mockGuard, new(MockTwoFactorService))
+		mockGuard.On("Check", "admin").Return(90*time.Second+time.Millisecond, nil)
+
+		req := httptest.NewRequest("POST", "/api/login", bytes.NewBufferString(`{"username":"admin","password":"admin123"}`))
+		w := httptest.NewRecorder()
+		handler.Login(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "91", w.Header().Get("Retry-After"))
+		mockService.AssertNotCalled(t, "ValidateCredentials", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Fail - Invalid JSON", func(t *testing.T) {
 		mockService := new(MockUserService)
-		handler := handlers.NewUserHandler(mockService)
+		handler := handlers.NewUserHandler(mockService, new(MockTokenService), new(MockLoginGuard), new(MockTwoFactorService))
 
 		req := httptest.NewRequest("POST", "/api/login", bytes.NewBufferString("invalid json"))
 		req.Header.Set("Content-Type", "application/json")
@@ -102,3 +223,199 @@ func TestUserHandlerRegister(t *testing.T) {
 		t.Skip("Requires auth middleware context - tested in integration tests")
 	})
 }
+
+func TestUserHandlerRefresh(t *testing.T) {
+	t.Run("Success - Rotated tokens returned", func(t *testing.T) {
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(new(MockUserService), mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockTokens.On("Refresh", "old").Return(&models.TokenResponse{Token: "access", RefreshToken: "new", ExpiresIn: 900}, nil)
+
+		req := httptest.NewRequest("POST", "/api/refresh", bytes.NewBufferString(`{"refresh_token":"old"}`))
+		w := httptest.NewRecorder()
+		handler.Refresh(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"new"`)
+	})
+
+	t.Run("Fail - Reused token", func(t *testing.T) {
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(new(MockUserService), mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockTokens.On("Refresh", "old").Return(nil, services.ErrRefreshTokenReused)
+
+		req := httptest.NewRequest("POST", "/api/refresh", bytes.NewBufferString(`{"refresh_token":"old"}`))
+		w := httptest.NewRecorder()
+		handler.Refresh(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestUserHandlerLogout(t *testing.T) {
+	expires := time.Now().Add(15 * time.Minute).Truncate(time.Second)
+	withToken := func(req *http.Request) *http.Request {
+		req = withRole(req, 7, "staff")
+		ctx := context.WithValue(req.Context(), middleware.TokenIDKey, "jti-1")
+		ctx = context.WithValue(ctx, middleware.TokenExpiresKey, expires)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("Success - Revokes current token and session", func(t *testing.T) {
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(new(MockUserService), mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockTokens.On("Logout", 7, "jti-1", expires, "refresh").Return(nil)
+
+		req := withToken(httptest.NewRequest("POST", "/api/logout", bytes.NewBufferString(`{"refresh_token":"refresh"}`)))
+		w := httptest.NewRecorder()
+		handler.Logout(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("Success - Empty body", func(t *testing.T) {
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(new(MockUserService), mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockTokens.On("Logout", 7, "jti-1", expires, "").Return(nil)
+
+		req := withToken(httptest.NewRequest("POST", "/api/logout", nil))
+		w := httptest.NewRecorder()
+		handler.Logout(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("Success - All sessions", func(t *testing.T) {
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(new(MockUserService), mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockTokens.On("LogoutAll", 7).Return(nil)
+
+		req := withToken(httptest.NewRequest("POST", "/api/logout", bytes.NewBufferString(`{"all":true}`)))
+		w := httptest.NewRecorder()
+		handler.Logout(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokens.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserHandlerManage(t *testing.T) {
+	t.Run("Update - Email taken", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := handlers.NewUserHandler(mockService, new(MockTokenService), new(MockLoginGuard), new(MockTwoFactorService))
+		mockService.On("Update", 4, mock.Anything).Return(nil, repositories.ErrEmailExists)
+
+		req := httptest.NewRequest("PUT", "/api/users/4", bytes.NewBufferString(`{"email":"a@example.com","full_name":"A","role":"staff"}`))
+		req.SetPathValue("id", "4")
+		w := httptest.NewRecorder()
+		handler.Update(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Update - Role change revokes sessions inside the update", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockService.On("Update", 4, mock.Anything).Return(&models.User{ID: 4, Role: "staff"}, nil)
+
+		req := httptest.NewRequest("PUT", "/api/users/4", bytes.NewBufferString(`{"email":"a@example.com","full_name":"A","role":"staff"}`))
+		req.SetPathValue("id", "4")
+		w := httptest.NewRecorder()
+		handler.Update(w, req)
+
+		// Pencabutan sesi commit bersama perubahan role di repository, bukan panggilan terpisah
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+		mockTokens.AssertNotCalled(t, "LogoutAll", mock.Anything)
+	})
+
+	t.Run("Deactivate - Revokes sessions", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockService.On("SetActive", 4, false).Return(nil)
+
+		req := withRole(httptest.NewRequest("POST", "/api/users/4/deactivate", nil), 1, "admin")
+		req.SetPathValue("id", "4")
+		w := httptest.NewRecorder()
+		handler.Deactivate(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+		mockTokens.AssertNotCalled(t, "LogoutAll", mock.Anything)
+	})
+
+	t.Run("Deactivate - Own account rejected", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := handlers.NewUserHandler(mockService, new(MockTokenService), new(MockLoginGuard), new(MockTwoFactorService))
+
+		req := withRole(httptest.NewRequest("POST", "/api/users/1/deactivate", nil), 1, "admin")
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+		handler.Deactivate(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "SetActive", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Deactivate - Unknown user", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockService.On("SetActive", 99, false).Return(repositories.ErrUserNotFound)
+
+		req := withRole(httptest.NewRequest("POST", "/api/users/99/deactivate", nil), 1, "admin")
+		req.SetPathValue("id", "99")
+		w := httptest.NewRecorder()
+		handler.Deactivate(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockTokens.AssertNotCalled(t, "LogoutAll", mock.Anything)
+	})
+}
+
+func TestUserHandlerMe(t *testing.T) {
+	t.Run("Profile from token", func(t *testing.T) {
+		mockService := new(MockUserService)
+		handler := handlers.NewUserHandler(mockService, new(MockTokenService), new(MockLoginGuard), new(MockTwoFactorService))
+		mockService.On("Profile", 7).Return(&models.User{ID: 7, Username: "andi", Role: "staff", IsActive: true}, nil)
+
+		req := withRole(httptest.NewRequest("GET", "/api/me", nil), 7, "staff")
+		w := httptest.NewRecorder()
+		handler.Me(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"username":"andi"`)
+	})
+
+	t.Run("Change password - Revokes all sessions", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockService.On("ChangePassword", 7, "lama123", "baru456").Return(nil)
+
+		req := withRole(httptest.NewRequest("POST", "/api/me/password", bytes.NewBufferString(`{"current_password":"lama123","new_password":"baru456"}`)), 7, "staff")
+		w := httptest.NewRecorder()
+		handler.ChangePassword(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+		mockTokens.AssertNotCalled(t, "LogoutAll", mock.Anything)
+	})
+
+	t.Run("Change password - Wrong current password", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokens := new(MockTokenService)
+		handler := handlers.NewUserHandler(mockService, mockTokens, new(MockLoginGuard), new(MockTwoFactorService))
+		mockService.On("ChangePassword", 7, "salah", "baru456").Return(services.ErrPasswordSalah)
+
+		req := withRole(httptest.NewRequest("POST", "/api/me/password", bytes.NewBufferString(`{"current_password":"salah","new_password":"baru456"}`)), 7, "staff")
+		w := httptest.NewRecorder()
+		handler.ChangePassword(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockTokens.AssertNotCalled(t, "LogoutAll", mock.Anything)
+	})
+}
@@ -1,13 +1,17 @@
 package unit
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"warehouse-api/config"
 	"warehouse-api/models"
+	"warehouse-api/repositories"
 	"warehouse-api/services"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Mock Repository
@@ -23,7 +27,7 @@ func (m *MockUserRepository) GetAll() ([]models.User, error) {
 	return args.Get(0).([]models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Create(user *models.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
@@ -44,6 +48,26 @@ func (m *MockUserRepository) GetByID(id int) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) Update(ctx context.Context, user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetActive(ctx context.Context, id int, active bool) error {
+	args := m.Called(id, active)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetPasswordHash(id int) (string, error) {
+	args := m.Called(id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	args := m.Called(id, hash)
+	return args.Error(0)
+}
+
 // Test HashPassword
 func TestHashPassword(t *testing.T) {
 	mockRepo := new(MockUserRepository)
@@ -83,7 +107,7 @@ func TestRegister(t *testing.T) {
 
 		mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil)
 
-		user, err := service.Register(req)
+		user, err := service.Register(context.Background(), req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -103,7 +127,7 @@ func TestRegister(t *testing.T) {
 			Role:     "staff",
 		}
 
-		user, err := service.Register(req)
+		user, err := service.Register(context.Background(), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -121,7 +145,7 @@ func TestRegister(t *testing.T) {
 			Role:     "staff",
 		}
 
-		user, err := service.Register(req)
+		user, err := service.Register(context.Background(), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -138,7 +162,7 @@ func TestRegister(t *testing.T) {
 			Role:     "staff",
 		}
 
-		user, err := service.Register(req)
+		user, err := service.Register(context.Background(), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -156,7 +180,7 @@ func TestRegister(t *testing.T) {
 			Role:     "invalidrole",
 		}
 
-		user, err := service.Register(req)
+		user, err := service.Register(context.Background(), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -176,7 +200,7 @@ func TestRegister(t *testing.T) {
 
 		mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(errors.New("database error"))
 
-		user, err := service.Register(req)
+		user, err := service.Register(context.Background(), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -198,6 +222,7 @@ func TestValidateCredentials(t *testing.T) {
 			Username: "testuser",
 			Password: hashedPassword,
 			Role:     "staff",
+			IsActive: true,
 		}
 
 		mockRepo.On("GetByUsername", "testuser").Return(expectedUser, nil)
@@ -245,4 +270,150 @@ func TestValidateCredentials(t *testing.T) {
 		assert.Contains(t, err.Error(), "username atau password")
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Fail - Inactive account", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+
+		hashedPassword, _ := service.HashPassword("password123")
+		mockRepo.On("GetByUsername", "testuser").Return(&models.User{ID: 1, Username: "testuser", Password: hashedPassword}, nil)
+
+		user, err := service.ValidateCredentials("testuser", "password123")
+
+		assert.ErrorIs(t, err, services.ErrAkunNonaktif)
+		assert.Nil(t, user)
+	})
+
+	t.Run("Fail - Inactive account with wrong password does not reveal status", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+
+		hashedPassword, _ := service.HashPassword("password123")
+		mockRepo.On("GetByUsername", "testuser").Return(&models.User{ID: 1, Username: "testuser", Password: hashedPassword}, nil)
+
+		_, err := service.ValidateCredentials("testuser", "wrongpassword")
+
+		assert.NotErrorIs(t, err, services.ErrAkunNonaktif)
+		assert.Contains(t, err.Error(), "username atau password")
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	t.Run("Success - Trims fields and keeps username", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+
+		mockRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+			return u.ID == 4 && u.Email == "andi@example.com" && u.FullName == "Andi" && u.Role == "admin"
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*models.User).Username = "andi"
+		}).Return(nil)
+
+		user, err := service.Update(context.Background(), 4, &models.UpdateUserRequest{Email: " andi@example.com ", FullName: "Andi ", Role: "admin"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "andi", user.Username)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Invalid role", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+
+		_, err := service.Update(context.Background(), 4, &models.UpdateUserRequest{Email: "a@example.com", FullName: "A", Role: "invalidrole"})
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Fail - Email taken", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+		mockRepo.On("Update", mock.Anything).Return(repositories.ErrEmailExists)
+
+		_, err := service.Update(context.Background(), 4, &models.UpdateUserRequest{Email: "a@example.com", FullName: "A", Role: "staff"})
+
+		assert.ErrorIs(t, err, repositories.ErrEmailExists)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("lama123"), bcrypt.MinCost)
+
+	t.Run("Success - Stores new hash", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+		mockRepo.On("GetPasswordHash", 3).Return(string(hash), nil)
+		mockRepo.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi"}, nil).Maybe()
+		mockRepo.On("UpdatePassword", 3, mock.MatchedBy(func(h string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(h), []byte("baru456")) == nil
+		})).Return(nil)
+
+		assert.NoError(t, service.ChangePassword(context.Background(), 3, "lama123", "baru456"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Wrong current password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+		mockRepo.On("GetPasswordHash", 3).Return(string(hash), nil)
+		mockRepo.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi"}, nil).Maybe()
+
+		err := service.ChangePassword(context.Background(), 3, "salah", "baru456")
+
+		assert.ErrorIs(t, err, services.ErrPasswordSalah)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail - New password too short", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := services.NewUserService(mockRepo)
+		mockRepo.On("GetPasswordHash", 3).Return(string(hash), nil)
+		mockRepo.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi"}, nil).Maybe()
+
+		err := service.ChangePassword(context.Background(), 3, "lama123", "123")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "minimal 6")
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := config.PasswordPolicy{MinLength: 8, MinClasses: 3}
+	register := func(username, password string) error {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("Create", mock.Anything).Return(nil)
+		service := services.NewUserService(mockRepo, services.WithPasswordPolicy(policy))
+		_, err := service.Register(context.Background(), &models.RegisterRequest{Username: username, Password: password, Role: "staff"})
+		return err
+	}
+
+	t.Run("Too short", func(t *testing.T) {
+		err := register("andi", "Ab1!")
+		assert.EqualError(t, err, "password minimal 8 karakter")
+	})
+
+	t.Run("Not enough character classes", func(t *testing.T) {
+		err := register("andi", "abcdefgh1")
+		assert.ErrorContains(t, err, "minimal 3 dari")
+	})
+
+	t.Run("Equal to username", func(t *testing.T) {
+		err := register("Gudang2024!", "gudang2024!")
+		assert.EqualError(t, err, "password tidak boleh sama dengan username")
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, register("andi", "Rahasia2024"))
+	})
+
+	t.Run("Applied on admin reset", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("GetByID", 3).Return(&models.User{ID: 3, Username: "andi"}, nil)
+		service := services.NewUserService(mockRepo, services.WithPasswordPolicy(policy))
+
+		assert.ErrorContains(t, service.ResetPassword(context.Background(), 3, "abcdefgh"), "minimal 3 dari")
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
 }
@@ -0,0 +1,280 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"warehouse-api/auth"
+	"warehouse-api/ctxkeys"
+	"warehouse-api/handlers"
+	"warehouse-api/middleware"
+	"warehouse-api/models"
+	"warehouse-api/repositories"
+	"warehouse-api/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockShareRepository struct {
+	mock.Mock
+}
+
+func (m *MockShareRepository) GetAll(jenis string, transaksiID int) ([]models.TransaksiShare, error) {
+	args := m.Called(jenis, transaksiID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TransaksiShare), args.Error(1)
+}
+
+func (m *MockShareRepository) Add(ctx context.Context, share *models.TransaksiShare) error {
+	args := m.Called(share)
+	return args.Error(0)
+}
+
+func (m *MockShareRepository) Remove(ctx context.Context, jenis string, transaksiID, userID int) error {
+	args := m.Called(jenis, transaksiID, userID)
+	return args.Error(0)
+}
+
+type stubRoleLookup map[string][]string
+
+func (s stubRoleLookup) RoleExists(nama string) (bool, error) {
+	_, ok := s[nama]
+	return ok, nil
+}
+
+func (s stubRoleLookup) Permissions(role string) ([]string, error) {
+	return s[role], nil
+}
+
+// visibleCtx meniru context request setelah AuthMiddleware
+func visibleCtx(userID int, visibility string) context.Context {
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID)
+	return context.WithValue(ctx, middleware.VisibilityKey, visibility)
+}
+
+func TestVisibilityFor(t *testing.T) {
+	assert.Equal(t, models.VisibilityAll, models.VisibilityFor(models.RoleAdmin, nil))
+	assert.Equal(t, models.VisibilityAll, models.VisibilityFor("supervisor", []string{models.PermPenjualanRead, models.PermTransaksiReadAll}))
+	assert.Equal(t, models.VisibilityOwn, models.VisibilityFor("staff", []string{models.PermPenjualanRead}))
+	assert.Equal(t, models.VisibilityOwn, models.VisibilityFor(models.RoleService, nil))
+}
+
+func TestVisibleTo(t *testing.T) {
+	userID, restricted := repositories.VisibleTo(visibleCtx(3, models.VisibilityOwn))
+	assert.True(t, restricted)
+	assert.Equal(t, 3, userID)
+
+	_, restricted = repositories.VisibleTo(visibleCtx(3, models.VisibilityAll))
+	assert.False(t, restricted)
+
+	userID, restricted = repositories.VisibleTo(context.Background())
+	assert.True(t, restricted, "context tanpa visibilitas dibatasi")
+	assert.Zero(t, userID)
+
+	userID, restricted = repositories.VisibleTo(context.WithValue(context.Background(), middleware.UserIDKey, 5))
+	assert.True(t, restricted)
+	assert.Equal(t, 5, userID)
+
+	_, restricted = repositories.VisibleTo(ctxkeys.WithSystem(context.Background()))
+	assert.False(t, restricted, "CLI dan scheduler ditandai eksplisit")
+}
+
+func TestTokenVisibility(t *testing.T) {
+	repo := new(MockTokenRepository)
+	repo.On("CreateRefresh", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("Admin sees all", func(t *testing.T) {
+		service := services.NewTokenService(repo, new(MockUserRepository), testKeys, testAuthConfig)
+
+		tokens, err := service.Issue(&models.User{ID: 1, Username: "admin", Role: models.RoleAdmin})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityAll, tokens.Visibility)
+		assert.Equal(t, models.VisibilityAll, parseTestToken(t, tokens.Token).Visibility)
+	})
+
+	t.Run("Staff sees own", func(t *testing.T) {
+		service := services.NewTokenService(repo, new(MockUserRepository), testKeys, testAuthConfig)
+
+		tokens, err := service.Issue(&models.User{ID: 3, Username: "andi", Role: "staff", Permissions: []string{models.PermPenjualanRead}})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityOwn, parseTestToken(t, tokens.Token).Visibility)
+	})
+
+	t.Run("Role permissions are looked up when user has none loaded", func(t *testing.T) {
+		roles := stubRoleLookup{"supervisor": {models.PermTransaksiReadAll}}
+		service := services.NewTokenService(repo, new(MockUserRepository), testKeys, testAuthConfig, services.WithTokenRoles(roles))
+
+		tokens, err := service.Issue(&models.User{ID: 4, Username: "sari", Role: "supervisor"})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityAll, parseTestToken(t, tokens.Token).Visibility)
+	})
+}
+
+func TestAuthMiddlewareVisibility(t *testing.T) {
+	middleware.SetTokenVerifier(testKeys)
+	defer middleware.SetTokenVerifier(nil)
+
+	var got interface{}
+	handler := middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Context().Value(middleware.VisibilityKey)
+	}))
+	serve := func(visibility string) {
+		token, err := testKeys.Sign(auth.Claims{UserID: 3, Role: "staff", Visibility: visibility, ID: "jti", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)})
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/api/penjualan", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(models.VisibilityAll)
+	assert.Equal(t, models.VisibilityAll, got)
+
+	serve(models.VisibilityOwn)
+	assert.Equal(t, models.VisibilityOwn, got)
+
+	serve("")
+	assert.Equal(t, models.VisibilityOwn, got, "token lama tanpa claim vis dibatasi")
+}
+
+func newShareService(shares *MockShareRepository, penjualan *MockPenjualanRepository, users *MockUserRepository) services.ShareService {
+	return services.NewShareService(shares, penjualan, new(MockPembelianRepository), users)
+}
+
+func TestShareService(t *testing.T) {
+	t.Run("Success - owner shares with another user", func(t *testing.T) {
+		shares, penjualan, users := new(MockShareRepository), new(MockPenjualanRepository), new(MockUserRepository)
+		penjualan.On("GetByID", 10).Return(&models.JualHeader{ID: 10, UserID: 3}, nil)
+		users.On("GetByID", 5).Return(&models.User{ID: 5, Username: "budi"}, nil)
+		shares.On("Add", mock.MatchedBy(func(s *models.TransaksiShare) bool {
+			return s.Jenis == models.TransaksiPenjualan && s.TransaksiID == 10 && s.UserID == 5
+		})).Return(nil)
+
+		share, err := newShareService(shares, penjualan, users).Share(visibleCtx(3, models.VisibilityOwn), models.TransaksiPenjualan, 10, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, "budi", share.Username)
+		shares.AssertExpectations(t)
+	})
+
+	t.Run("Fail - transaction outside visibility", func(t *testing.T) {
+		penjualan := new(MockPenjualanRepository)
+		penjualan.On("GetByID", 10).Return(nil, sql.ErrNoRows)
+
+		_, err := newShareService(new(MockShareRepository), penjualan, new(MockUserRepository)).Share(visibleCtx(3, models.VisibilityOwn), models.TransaksiPenjualan, 10, 5)
+
+		assert.ErrorIs(t, err, services.ErrTransaksiNotFound)
+	})
+
+	t.Run("Fail - shared user cannot reshare", func(t *testing.T) {
+		penjualan := new(MockPenjualanRepository)
+		penjualan.On("GetByID", 10).Return(&models.JualHeader{ID: 10, UserID: 3}, nil)
+
+		_, err := newShareService(new(MockShareRepository), penjualan, new(MockUserRepository)).Share(visibleCtx(5, models.VisibilityOwn), models.TransaksiPenjualan, 10, 6)
+
+		assert.ErrorIs(t, err, services.ErrShareDitolak)
+	})
+
+	t.Run("Success - read-all user manages any share", func(t *testing.T) {
+		shares, penjualan := new(MockShareRepository), new(MockPenjualanRepository)
+		penjualan.On("GetByID", 10).Return(&models.JualHeader{ID: 10, UserID: 3}, nil)
+		shares.On("Remove", models.TransaksiPenjualan, 10, 5).Return(nil)
+
+		err := newShareService(shares, penjualan, new(MockUserRepository)).Unshare(visibleCtx(1, models.VisibilityAll), models.TransaksiPenjualan, 10, 5)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Fail - share with owner", func(t *testing.T) {
+		penjualan := new(MockPenjualanRepository)
+		penjualan.On("GetByID", 10).Return(&models.JualHeader{ID: 10, UserID: 3}, nil)
+
+		_, err := newShareService(new(MockShareRepository), penjualan, new(MockUserRepository)).Share(visibleCtx(3, models.VisibilityOwn), models.TransaksiPenjualan, 10, 3)
+
+		assert.ErrorIs(t, err, services.ErrShareKePemilik)
+	})
+
+	t.Run("Fail - unknown target user", func(t *testing.T) {
+		penjualan, users := new(MockPenjualanRepository), new(MockUserRepository)
+		penjualan.On("GetByID", 10).Return(&models.JualHeader{ID: 10, UserID: 3}, nil)
+		users.On("GetByID", 99).Return(nil, sql.ErrNoRows)
+
+		_, err := newShareService(new(MockShareRepository), penjualan, users).Share(visibleCtx(3, models.VisibilityOwn), models.TransaksiPenjualan, 10, 99)
+
+		assert.ErrorIs(t, err, services.ErrSharePenggunaTidakAda)
+	})
+}
+
+func TestShareHandler(t *testing.T) {
+	serve := func(fn http.HandlerFunc, method, target, body string, pathValues map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		for k, v := range pathValues {
+			req.SetPathValue(k, v)
+		}
+		req = req.WithContext(visibleCtx(3, models.VisibilityOwn))
+		w := httptest.NewRecorder()
+		fn(w, req)
+		return w
+	}
+
+	t.Run("Success - create returns 201", func(t *testing.T) {
+		shares, penjualan, users := new(MockShareRepository), new(MockPenjualanRepository), new(MockUserRepository)
+		penjualan.On("GetByID", 10).Return(&models.JualHeader{ID: 10, UserID: 3}, nil)
+		users.On("GetByID", 5).Return(&models.User{ID: 5, Username: "budi"}, nil)
+		shares.On("Add", mock.Anything).Return(nil)
+		handler := handlers.NewShareHandler(newShareService(shares, penjualan, users))
+
+		w := serve(handler.SharePenjualan, "POST", "/api/penjualan/10/share", `{"user_id":5}`, map[string]string{"id": "10"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Fail - invisible transaction is 404", func(t *testing.T) {
+		penjualan := new(MockPenjualanRepository)
+		penjualan.On("GetByID", 10).Return(nil, sql.ErrNoRows)
+		handler := handlers.NewShareHandler(newShareService(new(MockShareRepository), penjualan, new(MockUserRepository)))
+
+		w := serve(handler.ListPenjualan, "GET", "/api/penjualan/10/share", "", map[string]string{"id": "10"})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Fail - non-owner is 403", func(t *testing.T) {
+		pembelian := new(MockPembelianRepository)
+		pembelian.On("GetByID", 8).Return(&models.BeliHeader{ID: 8, UserID: 9}, nil)
+		handler := handlers.NewShareHandler(services.NewShareService(new(MockShareRepository), new(MockPenjualanRepository), pembelian, new(MockUserRepository)))
+
+		w := serve(handler.UnsharePembelian, "DELETE", "/api/pembelian/8/share/5", "", map[string]string{"id": "8", "user_id": "5"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Fail - share not found is 404", func(t *testing.T) {
+		shares, penjualan := new(MockShareRepository), new(MockPenjualanRepository)
+		penjualan.On("GetByID", 10).Return(&models.JualHeader{ID: 10, UserID: 3}, nil)
+		shares.On("Remove", models.TransaksiPenjualan, 10, 5).Return(repositories.ErrShareNotFound)
+		handler := handlers.NewShareHandler(newShareService(shares, penjualan, new(MockUserRepository)))
+
+		w := serve(handler.UnsharePenjualan, "DELETE", "/api/penjualan/10/share/5", "", map[string]string{"id": "10", "user_id": "5"})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Fail - invalid body", func(t *testing.T) {
+		handler := handlers.NewShareHandler(newShareService(new(MockShareRepository), new(MockPenjualanRepository), new(MockUserRepository)))
+
+		w := serve(handler.SharePenjualan, "POST", "/api/penjualan/10/share", `{}`, map[string]string{"id": "10"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
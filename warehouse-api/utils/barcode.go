@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormalizeBarcode menghapus spasi dan tanda hubung yang sering ikut tercetak di label, lalu
+// mengubah UPC-A (12 digit) ke bentuk GTIN-13-nya (diawali 0) agar scanner yang mengirim UPC-A
+// maupun EAN-13 menemukan barang yang sama. EAN-8 tetap 8 digit.
+func NormalizeBarcode(code string) string {
+	code = strings.TrimSpace(code)
+	code = strings.ReplaceAll(code, " ", "")
+	code = strings.ReplaceAll(code, "-", "")
+	if len(code) == 12 && isDigits(code) {
+		code = "0" + code
+	}
+	return code
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// GTINCheckDigit menghitung check digit GS1 (mod 10) untuk data tanpa check digit.
+// Dipakai untuk EAN-8, UPC-A dan EAN-13.
+func GTINCheckDigit(data string) int {
+	sum := 0
+	// Bobot dihitung dari kanan: digit paling kanan berbobot 3
+	for i := len(data) - 1; i >= 0; i-- {
+		d := int(data[i] - '0')
+		if (len(data)-1-i)%2 == 0 {
+			sum += d * 3
+		} else {
+			sum += d
+		}
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidateBarcode memvalidasi barcode EAN-13, EAN-8 atau UPC-A termasuk check digit-nya
+func ValidateBarcode(code string) error {
+	if code == "" {
+		return errors.New("barcode wajib diisi")
+	}
+
+	if !isDigits(code) {
+		return errors.New("barcode hanya boleh berisi angka")
+	}
+
+	switch len(code) {
+	case 8, 12, 13:
+	default:
+		return errors.New("panjang barcode harus 8 (EAN-8), 12 (UPC-A) atau 13 (EAN-13) digit")
+	}
+
+	expected := GTINCheckDigit(code[:len(code)-1])
+	if int(code[len(code)-1]-'0') != expected {
+		return errors.New("check digit barcode tidak valid")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FormatNumberID memformat angka dengan pemisah ribuan titik dan desimal koma (format Indonesia).
+// Contoh: 1234567.5 dengan decimals 2 -> "1.234.567,50"
+func FormatNumberID(value float64, decimals int) string {
+	negative := value < 0
+	value = math.Abs(value)
+
+	formatted := strconv.FormatFloat(value, 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(formatted, ".")
+
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+
+	result := b.String()
+	if fracPart != "" {
+		result += "," + fracPart
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// FormatRupiah memformat nilai uang tanpa desimal, misal "Rp 17.500.000"
+func FormatRupiah(value float64) string {
+	return "Rp " + FormatNumberID(math.Round(value), 0)
+}
+
+// ParseNumberID membaca angka dari spreadsheet, baik format Indonesia ("17.500.000,50")
+// maupun format biasa ("17500000.5" atau "17,500,000.5"). Pemisah yang muncul terakhir
+// dianggap desimal; titik tanpa koma dianggap pemisah ribuan hanya jika polanya
+// kelipatan tiga digit ("1.500" -> 1500).
+func ParseNumberID(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "Rp"), "rp")
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case lastComma >= 0 && lastDot > lastComma, strings.Count(s, ",") > 1:
+		// format Inggris "17,500,000.5"
+		s = strings.ReplaceAll(s, ",", "")
+	case lastComma >= 0:
+		// format Indonesia "17.500.000,5"
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	case thousandsDotPattern.MatchString(s):
+		s = strings.ReplaceAll(s, ".", "")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+var thousandsDotPattern = regexp.MustCompile(`^-?\d{1,3}(\.\d{3})+$`)
@@ -42,3 +42,14 @@ func JSONCreated(w http.ResponseWriter, message string, data interface{}) {
 func JSONWithMeta(w http.ResponseWriter, message string, data interface{}, meta models.Pagination) {
 	JSONResponse(w, http.StatusOK, true, message, data, &meta)
 }
+
+// JSONWithCursor sends a success response with cursor pagination metadata
+func JSONWithCursor(w http.ResponseWriter, message string, data interface{}, meta models.CursorPagination) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	resp := models.CursorResponse{Success: true, Message: message, Data: data, Meta: &meta}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
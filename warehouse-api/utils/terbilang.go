@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+var satuanKata = []string{"", "satu", "dua", "tiga", "empat", "lima", "enam", "tujuh", "delapan", "sembilan", "sepuluh", "sebelas"}
+
+// terbilangInt mengubah bilangan bulat positif menjadi kata dalam bahasa Indonesia
+func terbilangInt(n int64) string {
+	switch {
+	case n < 12:
+		return satuanKata[n]
+	case n < 20:
+		return terbilangInt(n-10) + " belas"
+	case n < 100:
+		return strings.TrimSpace(terbilangInt(n/10) + " puluh " + terbilangInt(n%10))
+	case n < 200:
+		return strings.TrimSpace("seratus " + terbilangInt(n-100))
+	case n < 1000:
+		return strings.TrimSpace(terbilangInt(n/100) + " ratus " + terbilangInt(n%100))
+	case n < 2000:
+		return strings.TrimSpace("seribu " + terbilangInt(n-1000))
+	case n < 1000000:
+		return strings.TrimSpace(terbilangInt(n/1000) + " ribu " + terbilangInt(n%1000))
+	case n < 1000000000:
+		return strings.TrimSpace(terbilangInt(n/1000000) + " juta " + terbilangInt(n%1000000))
+	case n < 1000000000000:
+		return strings.TrimSpace(terbilangInt(n/1000000000) + " miliar " + terbilangInt(n%1000000000))
+	default:
+		return strings.TrimSpace(terbilangInt(n/1000000000000) + " triliun " + terbilangInt(n%1000000000000))
+	}
+}
+
+// Terbilang mengubah nilai menjadi kata dalam bahasa Indonesia, dibulatkan ke rupiah terdekat.
+// Contoh: 1250000 -> "satu juta dua ratus lima puluh ribu"
+func Terbilang(value float64) string {
+	n := int64(math.Round(math.Abs(value)))
+	if n == 0 {
+		return "nol"
+	}
+
+	result := terbilangInt(n)
+	if value < 0 {
+		result = "minus " + result
+	}
+	return result
+}
+
+// TerbilangRupiah menghasilkan teks terbilang untuk dokumen, misal "Satu juta rupiah"
+func TerbilangRupiah(value float64) string {
+	text := Terbilang(value) + " rupiah"
+	return strings.ToUpper(text[:1]) + text[1:]
+}
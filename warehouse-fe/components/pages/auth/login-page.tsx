@@ -13,11 +13,13 @@ import {
 } from "@nextui-org/react";
 import { EyeIcon, EyeSlashIcon } from "@heroicons/react/24/outline";
 import { useAuth } from "@/components/providers/auth-provider";
+import { authApi } from "@/lib/api";
+import type { TwoFactorChallenge, TwoFactorSetup } from "@/lib/types";
 import Link from "next/link";
 
 export default function LoginPage() {
   const router = useRouter();
-  const { login } = useAuth();
+  const { login, completeTwoFactor } = useAuth();
   const [formData, setFormData] = useState({
     username: "",
     password: "",
@@ -25,6 +27,10 @@ export default function LoginPage() {
   const [error, setError] = useState("");
   const [loading, setLoading] = useState(false);
   const [isVisible, setIsVisible] = useState(false);
+  const [challenge, setChallenge] = useState<TwoFactorChallenge | null>(null);
+  const [setup, setSetup] = useState<TwoFactorSetup | null>(null);
+  const [code, setCode] = useState("");
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
 
   const toggleVisibility = () => setIsVisible(!isVisible);
 
@@ -34,9 +40,14 @@ export default function LoginPage() {
     setLoading(true);
 
     try {
-      await login(formData);
-      // Logic redirection moved to auth-provider or handled here if needed,
-      // but auth-provider already handles it.
+      const result = await login(formData);
+      // Tanpa 2FA auth-provider langsung mengarahkan ke dashboard
+      if (result) {
+        setChallenge(result);
+        if (result.setup_required) {
+          setSetup(await authApi.loginTwoFactorSetup(result.challenge_token));
+        }
+      }
     } catch (err: any) {
       setError(
         err.response?.data?.message ||
@@ -47,6 +58,34 @@ export default function LoginPage() {
     }
   };
 
+  const handleCodeSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!challenge) return;
+    setError("");
+    setLoading(true);
+
+    try {
+      const codes = await completeTwoFactor(challenge.challenge_token, code);
+      if (codes?.length) {
+        setRecoveryCodes(codes);
+      }
+    } catch (err: any) {
+      setError(err.response?.data?.message || "Invalid code.");
+      // Challenge kedaluwarsa atau percobaan habis: kembali ke langkah password
+      const message: string = err.response?.data?.message ?? "";
+      if (
+        err.response?.status === 401 &&
+        !message.includes("kode 2FA salah")
+      ) {
+        setChallenge(null);
+        setSetup(null);
+      }
+    } finally {
+      setCode("");
+      setLoading(false);
+    }
+  };
+
   return (
     <div className="flex min-h-screen items-center justify-center bg-white px-4 py-12 sm:px-6 lg:px-8">
       <div className="w-full max-w-md space-y-8">
@@ -58,51 +97,113 @@ export default function LoginPage() {
             <p className="text-sm text-gray-500">Sign in to your account</p>
           </CardHeader>
           <CardBody>
-            <form onSubmit={handleSubmit} className="flex flex-col gap-4">
-              <Input
-                label="Username"
-                placeholder="Enter your username"
-                variant="bordered"
-                value={formData.username}
-                onValueChange={(val) =>
-                  setFormData({ ...formData, username: val })
-                }
-                isRequired
-              />
-              <Input
-                label="Password"
-                placeholder="Enter your password"
-                variant="bordered"
-                value={formData.password}
-                onValueChange={(val) =>
-                  setFormData({ ...formData, password: val })
-                }
-                endContent={
-                  <button
-                    className="focus:outline-none"
-                    type="button"
-                    onClick={toggleVisibility}>
-                    {isVisible ? (
-                      <EyeSlashIcon className="h-5 w-5 text-gray-400" />
-                    ) : (
-                      <EyeIcon className="h-5 w-5 text-gray-400" />
-                    )}
-                  </button>
-                }
-                type={isVisible ? "text" : "password"}
-                isRequired
-              />
-              {error && (
-                <p className="text-center text-sm text-danger">{error}</p>
-              )}
-              <Button
-                color="primary"
-                type="submit"
-                isLoading={loading}
-                className="w-full font-semibold text-white">
-                Sign in
-              </Button>
-            </form>
+            {recoveryCodes.length > 0 ? (
+              <div className="flex flex-col gap-4">
+                <p className="text-sm text-gray-600">
+                  Two-factor authentication is now active. Save these recovery
+                  codes somewhere safe; each can be used once if you lose your
+                  authenticator.
+                </p>
+                <ul className="grid grid-cols-2 gap-2 rounded-md bg-gray-50 p-3 font-mono text-sm">
+                  {recoveryCodes.map((c) => (
+                    <li key={c}>{c}</li>
+                  ))}
+                </ul>
+                <Button
+                  color="primary"
+                  className="w-full font-semibold text-white"
+                  onPress={() => router.push("/dashboard")}>
+                  Continue
+                </Button>
+              </div>
+            ) : challenge ? (
+              <form onSubmit={handleCodeSubmit} className="flex flex-col gap-4">
+                {setup ? (
+                  <div className="flex flex-col items-center gap-2 text-center">
+                    <p className="text-sm text-gray-600">
+                      Your account requires two-factor authentication. Scan
+                      this QR code with an authenticator app, then enter the
+                      6-digit code.
+                    </p>
+                    <Image src={setup.qr_code} alt="QR 2FA" width={192} />
+                    <p className="break-all font-mono text-xs text-gray-500">
+                      {setup.secret}
+                    </p>
+                  </div>
+                ) : (
+                  <p className="text-sm text-gray-600">
+                    Enter the 6-digit code from your authenticator app, or one
+                    of your recovery codes.
+                  </p>
+                )}
+                <Input
+                  label="Code"
+                  placeholder="123456"
+                  variant="bordered"
+                  value={code}
+                  onValueChange={setCode}
+                  autoComplete="one-time-code"
+                  autoFocus
+                  isRequired
+                />
+                {error && (
+                  <p className="text-center text-sm text-danger">{error}</p>
+                )}
+                <Button
+                  color="primary"
+                  type="submit"
+                  isLoading={loading}
+                  className="w-full font-semibold text-white">
+                  Verify
+                </Button>
+              </form>
+            ) : (
+              <form onSubmit={handleSubmit} className="flex flex-col gap-4">
+                <Input
+                  label="Username"
+                  placeholder="Enter your username"
+                  variant="bordered"
+                  value={formData.username}
+                  onValueChange={(val) =>
+                    setFormData({ ...formData, username: val })
+                  }
+                  isRequired
+                />
+                <Input
+                  label="Password"
+                  placeholder="Enter your password"
+                  variant="bordered"
+                  value={formData.password}
+                  onValueChange={(val) =>
+                    setFormData({ ...formData, password: val })
+                  }
+                  endContent={
+                    <button
+                      className="focus:outline-none"
+                      type="button"
+                      onClick={toggleVisibility}>
+                      {isVisible ? (
+                        <EyeSlashIcon className="h-5 w-5 text-gray-400" />
+                      ) : (
+                        <EyeIcon className="h-5 w-5 text-gray-400" />
+                      )}
+                    </button>
+                  }
+                  type={isVisible ? "text" : "password"}
+                  isRequired
+                />
+                {error && (
+                  <p className="text-center text-sm text-danger">{error}</p>
+                )}
+                <Button
+                  color="primary"
+                  type="submit"
+                  isLoading={loading}
+                  className="w-full font-semibold text-white">
+                  Sign in
+                </Button>
+              </form>
+            )}
           </CardBody>
           <CardFooter className="justify-center">
             <p className="text-sm text-gray-500">
@@ -13,6 +13,10 @@ import {
   CubeIcon,
   CurrencyDollarIcon,
   ChartBarIcon,
+  BanknotesIcon,
+  ReceiptPercentIcon,
+  ShoppingCartIcon,
+  ExclamationTriangleIcon,
 } from "@heroicons/react/24/outline";
 import { AppLayout } from "@/components/layout/app-layout";
 import { dashboardApi } from "@/lib/api";
@@ -84,7 +88,14 @@ export default function DashboardPage() {
 
   const fetchStats = useCallback(async () => {
     try {
-      const data = await dashboardApi.getStats();
+      // KPI periode: bulan berjalan sampai hari ini
+      const now = new Date();
+      const pad = (n: number) => String(n).padStart(2, "0");
+      const today = `${now.getFullYear()}-${pad(now.getMonth() + 1)}-${pad(now.getDate())}`;
+      const data = await dashboardApi.getStats({
+        start_date: today.slice(0, 8) + "01",
+        end_date: today,
+      });
       setStats(data);
     } catch (error) {
       console.error("Failed to fetch dashboard stats", error);
@@ -160,6 +171,44 @@ export default function DashboardPage() {
           />
         </div>
 
+        {/* KPI Bulan Ini */}
+        <div className="grid gap-6 sm:grid-cols-2 lg:grid-cols-4">
+          <StatCard
+            title="Pendapatan Bulan Ini"
+            value={formatCurrency(stats?.pendapatan)}
+            icon={BanknotesIcon}
+            className="bg-emerald-50"
+            iconClassName="text-emerald-600"
+          />
+          <StatCard
+            title={`Laba Kotor${
+              stats?.margin_kotor != null
+                ? ` (${stats.margin_kotor.toLocaleString("id-ID")}%)`
+                : ""
+            }`}
+            value={formatCurrency(stats?.laba_kotor)}
+            icon={ReceiptPercentIcon}
+            className="bg-teal-50"
+            iconClassName="text-teal-600"
+          />
+          <StatCard
+            title={`${(stats?.jumlah_faktur ?? 0).toLocaleString("id-ID")} Faktur, Rata-rata`}
+            value={formatCurrency(stats?.rata_rata_belanja)}
+            icon={ShoppingCartIcon}
+            className="bg-sky-50"
+            iconClassName="text-sky-600"
+          />
+          <StatCard
+            title="Stok Menipis / Dead Stock"
+            value={`${(stats?.low_stock ?? 0).toLocaleString("id-ID")} / ${(
+              stats?.dead_stock ?? 0
+            ).toLocaleString("id-ID")}`}
+            icon={ExclamationTriangleIcon}
+            className="bg-red-50"
+            iconClassName="text-red-600"
+          />
+        </div>
+
         {/* Content Section */}
         <div className="grid gap-6 lg:grid-cols-3">
           {/* Top Products Table */}
@@ -169,7 +218,7 @@ export default function DashboardPage() {
                 Produk Terlaris
               </h3>
               <p className="text-sm text-gray-500">
-                Barang dengan penjualan tertinggi
+                Barang dengan penjualan tertinggi bulan ini
               </p>
             </CardHeader>
             <CardBody className="px-6 pb-6">
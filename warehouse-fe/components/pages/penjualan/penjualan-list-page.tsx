@@ -1,6 +1,6 @@
 "use client";
 
-import { useEffect, useMemo, useState, Key } from "react";
+import { useCallback, useEffect, useState, Key } from "react";
 import {
   Button,
   Chip,
@@ -38,42 +38,33 @@ export default function PenjualanListPage() {
   const [search, setSearch] = useState("");
   const [page, setPage] = useState(1);
   const [rowsPerPage, setRowsPerPage] = useState(10);
+  const [totalPages, setTotalPages] = useState(1);
   const [startDate, setStartDate] = useState<any>(null);
   const [endDate, setEndDate] = useState<any>(null);
 
   const { isOpen, onOpen, onOpenChange } = useDisclosure();
 
-  useEffect(() => {
-    fetchData();
-  }, [startDate, endDate]);
-
-  const fetchData = async () => {
+  const fetchData = useCallback(async () => {
     setLoading(true);
     try {
-      const params: any = {};
+      const params: any = { page, limit: rowsPerPage };
+      if (search) params.customer = search;
       if (startDate) params.start_date = startDate.toString();
       if (endDate) params.end_date = endDate.toString();
 
       const res = await penjualanApi.getAll(params);
-      setData(res);
+      setData(res.data || []);
+      setTotalPages(Math.ceil((res.meta?.total || 0) / rowsPerPage) || 1);
     } catch (e) {
       console.error(e);
     } finally {
       setLoading(false);
     }
-  };
+  }, [page, rowsPerPage, search, startDate, endDate]);
 
-  const filteredData = data.filter(
-    (item) =>
-      item.no_faktur.toLowerCase().includes(search.toLowerCase()) ||
-      item.customer.toLowerCase().includes(search.toLowerCase()),
-  );
-
-  const totalPages = Math.ceil(filteredData.length / rowsPerPage) || 1;
-  const items = useMemo(
-    () => filteredData.slice((page - 1) * rowsPerPage, page * rowsPerPage),
-    [filteredData, page, rowsPerPage],
-  );
+  useEffect(() => {
+    fetchData();
+  }, [fetchData]);
 
   const columns: Column[] = [
     { uid: "no_faktur", name: "NO FAKTUR" },
@@ -173,7 +164,7 @@ export default function PenjualanListPage() {
 
         <DataTable
           columns={columns}
-          data={items}
+          data={data}
           isLoading={loading}
           emptyContent="Belum ada transaksi."
           page={page}
@@ -189,7 +180,7 @@ export default function PenjualanListPage() {
             setSearch(v);
             setPage(1);
           }}
-          searchPlaceholder="Cari faktur / customer..."
+          searchPlaceholder="Cari customer..."
           renderCell={renderCell}
           filtersContent={
             <div className="flex gap-2 rounded">
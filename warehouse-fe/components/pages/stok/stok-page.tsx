@@ -1,6 +1,6 @@
 "use client";
 
-import { useEffect, useState, useMemo, Key } from "react";
+import { useCallback, useEffect, useState, useMemo, Key } from "react";
 import {
   Chip,
   Tabs,
@@ -24,6 +24,16 @@ import { HistoryStok, Stok } from "@/lib/types";
 import { toast } from "sonner";
 import { DataTable, Column } from "@/components/table/data-table";
 
+// Saldo awal menambah stok, kecuali koreksi saldo awal (force) yang jumlahnya negatif
+const isStokMasuk = (h: HistoryStok) =>
+  h.jenis_transaksi === "masuk" ||
+  (h.jenis_transaksi === "saldo_awal" && h.jumlah >= 0);
+
+const formatJumlah = (h: HistoryStok) =>
+  h.jenis_transaksi === "saldo_awal"
+    ? `${h.jumlah >= 0 ? "+" : ""}${h.jumlah}`
+    : `${h.jenis_transaksi === "masuk" ? "+" : "-"}${h.jumlah}`;
+
 export default function StokPage() {
   const [historyData, setHistoryData] = useState<HistoryStok[]>([]);
   const [stockData, setStockData] = useState<Stok[]>([]);
@@ -39,6 +49,7 @@ export default function StokPage() {
   );
 
   const [rowsPerPage, setRowsPerPage] = useState(10);
+  const [totalPagesHistory, setTotalPagesHistory] = useState(1);
   const handleSearchChange = (value: string) => {
     setSearch(value);
     setPage(1);
@@ -77,62 +88,52 @@ export default function StokPage() {
   };
 
   useEffect(() => {
-    fetchData();
+    fetchStock();
   }, []);
 
-  const fetchData = async () => {
-    setLoading(true);
+  const fetchStock = async () => {
     try {
-      const [history, stocks] = await Promise.all([
-        stokApi.getHistory(),
-        stokApi.getAll(),
-      ]);
-      setHistoryData(history);
-      setStockData(stocks);
+      setStockData(await stokApi.getAll());
     } catch (err) {
       console.error(err);
-    } finally {
-      setLoading(false);
     }
   };
 
-  const fetchHistoryByBarang = async (barangId: number) => {
+  // Riwayat dipaginasi & dicari di server
+  const fetchHistory = useCallback(async () => {
     setLoading(true);
     try {
-      const history = await stokApi.getHistory(barangId);
-      setHistoryData(history);
-      setFilteredBarangId(barangId);
-      setActiveTab("history");
+      const res = await stokApi.getHistory(filteredBarangId ?? undefined, {
+        page,
+        limit: rowsPerPage,
+        search: search || undefined,
+      });
+      setHistoryData(res.data || []);
+      setTotalPagesHistory(
+        Math.ceil((res.meta?.total || 0) / rowsPerPage) || 1,
+      );
     } catch (err) {
       console.error(err);
-      toast.error("Gagal mengambil riwayat barang");
+      toast.error("Gagal mengambil riwayat stok");
     } finally {
       setLoading(false);
     }
-  };
+  }, [filteredBarangId, page, rowsPerPage, search]);
 
-  const resetHistory = async () => {
-    setLoading(true);
-    try {
-      const history = await stokApi.getHistory();
-      setHistoryData(history);
-      setFilteredBarangId(null);
-    } catch (err) {
-      console.error(err);
-    } finally {
-      setLoading(false);
-    }
+  useEffect(() => {
+    fetchHistory();
+  }, [fetchHistory]);
+
+  const fetchHistoryByBarang = (barangId: number) => {
+    setFilteredBarangId(barangId);
+    setActiveTab("history");
+    setPage(1);
   };
 
-  const filteredHistory = useMemo(() => {
-    const lowerSearch = search.toLowerCase();
-    return historyData.filter(
-      (item) =>
-        item.barang?.nama_barang.toLowerCase().includes(lowerSearch) ||
-        item.barang?.kode_barang?.toLowerCase().includes(lowerSearch) ||
-        item.jenis_transaksi.toLowerCase().includes(lowerSearch),
-    );
-  }, [search, historyData]);
+  const resetHistory = () => {
+    setFilteredBarangId(null);
+    setPage(1);
+  };
 
   const filteredStock = useMemo(() => {
     const lowerSearch = search.toLowerCase();
@@ -143,10 +144,6 @@ export default function StokPage() {
     );
   }, [search, stockData]);
 
-  const itemsHistory = filteredHistory.slice(
-    (page - 1) * rowsPerPage,
-    page * rowsPerPage,
-  );
   const itemsStock = filteredStock.slice(
     (page - 1) * rowsPerPage,
     page * rowsPerPage,
@@ -241,7 +238,7 @@ export default function StokPage() {
         return (
           <Chip
             size="sm"
-            color={item.jenis_transaksi === "masuk" ? "success" : "danger"}
+            color={isStokMasuk(item) ? "success" : "danger"}
             variant="flat"
             className="capitalize">
             {item.jenis_transaksi}
@@ -251,12 +248,9 @@ export default function StokPage() {
         return (
           <span
             className={`font-bold ${
-              item.jenis_transaksi === "masuk"
-                ? "text-green-600"
-                : "text-red-500"
+              isStokMasuk(item) ? "text-green-600" : "text-red-500"
             }`}>
-            {item.jenis_transaksi === "masuk" ? "+" : "-"}
-            {item.jumlah}
+            {formatJumlah(item)}
           </span>
         );
       case "stok_sebelum":
@@ -286,8 +280,6 @@ export default function StokPage() {
     }
   };
 
-  const totalPagesHistory =
-    Math.ceil(filteredHistory.length / rowsPerPage) || 1;
   const totalPagesStock = Math.ceil(filteredStock.length / rowsPerPage) || 1;
 
   return (
@@ -345,7 +337,7 @@ export default function StokPage() {
               )}
               <DataTable
                 columns={historyColumns}
-                data={itemsHistory}
+                data={historyData}
                 isLoading={loading}
                 page={page}
                 totalPages={totalPagesHistory}
@@ -380,7 +372,7 @@ export default function StokPage() {
                           <Chip
                             size="sm"
                             color={
-                              selectedHistory.jenis_transaksi === "masuk"
+                              isStokMasuk(selectedHistory)
                                 ? "success"
                                 : "warning"
                             }
@@ -399,11 +391,8 @@ export default function StokPage() {
                             Jumlah
                           </p>
                           <p
-                            className={`font-semibold text-lg ${selectedHistory.jenis_transaksi === "masuk" ? "text-green-600" : "text-amber-600"}`}>
-                            {selectedHistory.jenis_transaksi === "masuk"
-                              ? "+"
-                              : "-"}
-                            {selectedHistory.jumlah}
+                            className={`font-semibold text-lg ${isStokMasuk(selectedHistory) ? "text-green-600" : "text-amber-600"}`}>
+                            {formatJumlah(selectedHistory)}
                           </p>
                         </div>
                         <div className="space-y-1">
@@ -269,7 +269,7 @@ export default function UserPage() {
                     selectedKeys={[formData.role]}
                     variant="bordered"
                     onSelectionChange={(keys: any) => {
-                      const selected = Array.from(keys)[0] as "admin" | "staff";
+                      const selected = Array.from(keys)[0] as string;
                       if (selected) {
                         setFormData({ ...formData, role: selected });
                       }
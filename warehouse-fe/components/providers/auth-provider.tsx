@@ -3,12 +3,23 @@
 import React, { createContext, useContext, useState, useEffect } from "react";
 import { useRouter } from "next/navigation";
 import { authApi } from "@/lib/api";
-import type { User, LoginRequest } from "@/lib/types";
+import type {
+  User,
+  LoginRequest,
+  LoginResponse,
+  TwoFactorChallenge,
+} from "@/lib/types";
 
 interface AuthContextType {
   user: User | null;
   token: string | null;
-  login: (credentials: LoginRequest) => Promise<void>;
+  // Mengembalikan challenge bila akun memakai 2FA; lanjutkan dengan completeTwoFactor
+  login: (credentials: LoginRequest) => Promise<TwoFactorChallenge | null>;
+  // Mengembalikan kode pemulihan bila 2FA baru didaftarkan; halaman yang mengarahkan ke dashboard
+  completeTwoFactor: (
+    challengeToken: string,
+    code: string,
+  ) => Promise<string[] | undefined>;
   logout: () => void;
   isLoading: boolean;
 }
@@ -38,34 +49,60 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
     setIsLoading(false);
   }, []);
 
+  const storeSession = (response: LoginResponse) => {
+    setToken(response.token);
+    setUser(response.user);
+
+    localStorage.setItem("token", response.token);
+    localStorage.setItem("refresh_token", response.refresh_token);
+    localStorage.setItem("user", JSON.stringify(response.user));
+  };
+
   const login = async (credentials: LoginRequest) => {
     try {
       const response = await authApi.login(credentials);
-      setToken(response.token);
-
-      const userData = response.user;
-      setUser(userData);
-
-      localStorage.setItem("token", response.token);
-      localStorage.setItem("user", JSON.stringify(userData));
+      if ("two_factor_required" in response) {
+        return response;
+      }
 
+      storeSession(response);
       router.push("/dashboard");
+      return null;
     } catch (error: any) {
       console.error("Login error:", error);
       throw error;
     }
   };
 
+  const completeTwoFactor = async (challengeToken: string, code: string) => {
+    const response = await authApi.loginTwoFactor(challengeToken, code);
+    storeSession(response);
+    if (!response.recovery_codes?.length) {
+      router.push("/dashboard");
+    }
+    return response.recovery_codes;
+  };
+
   const logout = () => {
+    // Cabut token di server; sesi lokal tetap dibersihkan walaupun gagal
+    const currentToken = localStorage.getItem("token");
+    if (currentToken) {
+      authApi
+        .logout(currentToken, localStorage.getItem("refresh_token"))
+        .catch((error) => console.error("Logout error:", error));
+    }
+
     setToken(null);
     setUser(null);
     localStorage.removeItem("token");
+    localStorage.removeItem("refresh_token");
     localStorage.removeItem("user");
     router.push("/login");
   };
 
   return (
-    <AuthContext.Provider value={{ user, token, login, logout, isLoading }}>
+    <AuthContext.Provider
+      value={{ user, token, login, completeTwoFactor, logout, isLoading }}>
       {children}
     </AuthContext.Provider>
   );
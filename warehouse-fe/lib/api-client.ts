@@ -1,10 +1,23 @@
-import axios, { AxiosInstance, AxiosError } from "axios";
+import axios, {
+  AxiosInstance,
+  AxiosError,
+  InternalAxiosRequestConfig,
+} from "axios";
 
 const API_BASE_URL =
   process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api";
 
+const clearSession = () => {
+  localStorage.removeItem("token");
+  localStorage.removeItem("refresh_token");
+  localStorage.removeItem("user");
+  window.location.href = "/login";
+};
+
 class ApiClient {
   private client: AxiosInstance;
+  // Satu refresh untuk semua request yang gagal bersamaan (refresh token hanya bisa dipakai sekali)
+  private refreshing: Promise<string> | null = null;
 
   constructor() {
     this.client = axios.create({
@@ -31,18 +44,55 @@ class ApiClient {
     // Response interceptor untuk handle errors
     this.client.interceptors.response.use(
       (response) => response,
-      (error: AxiosError) => {
-        if (error.response?.status === 401) {
-          // Token expired atau invalid, redirect ke login
-          localStorage.removeItem("token");
-          localStorage.removeItem("user");
-          window.location.href = "/login";
+      async (error: AxiosError) => {
+        const original = error.config as
+          | (InternalAxiosRequestConfig & { _retry?: boolean })
+          | undefined;
+        const isAuthRoute = [
+          "/login",
+          "/login/2fa",
+          "/login/2fa/setup",
+          "/refresh",
+          "/logout",
+        ].includes(original?.url ?? "");
+
+        if (error.response?.status === 401 && original && !isAuthRoute) {
+          // Access token kedaluwarsa: coba sekali dengan refresh token
+          const refreshToken = localStorage.getItem("refresh_token");
+          if (refreshToken && !original._retry) {
+            original._retry = true;
+            try {
+              const token = await this.refresh(refreshToken);
+              original.headers.Authorization = `Bearer ${token}`;
+              return this.client(original);
+            } catch {
+              // jatuh ke clearSession di bawah
+            }
+          }
+          clearSession();
         }
         return Promise.reject(error);
       },
     );
   }
 
+  private refresh(refreshToken: string): Promise<string> {
+    if (!this.refreshing) {
+      this.refreshing = axios
+        .post(`${API_BASE_URL}/refresh`, { refresh_token: refreshToken })
+        .then((res) => {
+          const data = res.data.data;
+          localStorage.setItem("token", data.token);
+          localStorage.setItem("refresh_token", data.refresh_token);
+          return data.token as string;
+        })
+        .finally(() => {
+          this.refreshing = null;
+        });
+    }
+    return this.refreshing;
+  }
+
   getInstance(): AxiosInstance {
     return this.client;
   }
@@ -2,31 +2,125 @@ import { apiClient } from "./api-client";
 import type {
   LoginRequest,
   LoginResponse,
+  TwoFactorChallenge,
+  TwoFactorSetup,
   RegisterRequest,
+  UpdateUserRequest,
+  ChangePasswordRequest,
+  Role,
+  Permission,
+  CreateRoleRequest,
+  ApiKey,
+  CreateApiKeyRequest,
+  CreateApiKeyResponse,
+  AuditLog,
+  AuditParams,
   Barang,
   CreateBarangRequest,
   Stok,
   HistoryStok,
+  HistoryChainReport,
   BeliHeader,
   CreatePembelianRequest,
   JualHeader,
   CreatePenjualanRequest,
+  TransaksiShare,
   DashboardStats,
+  DashboardParams,
   APIResponse,
   User,
   PaginatedResponse,
+  TransaksiListParams,
+  HistoryStokParams,
+  KartuStok,
+  StokAsOfResult,
+  Report,
+  ReportParams,
+  KlasifikasiReport,
+  KlasifikasiRunParams,
+  CreateOpnameRequest,
+  StokOpname,
 } from "./types";
 
 // Auth API
 export const authApi = {
-  login: async (data: LoginRequest): Promise<LoginResponse> => {
+  login: async (
+    data: LoginRequest,
+  ): Promise<LoginResponse | TwoFactorChallenge> => {
+    const response = await apiClient.post<
+      APIResponse<LoginResponse | TwoFactorChallenge>
+    >("/login", data);
+    return response.data.data;
+  },
+
+  loginTwoFactor: async (
+    challengeToken: string,
+    code: string,
+  ): Promise<LoginResponse> => {
     const response = await apiClient.post<APIResponse<LoginResponse>>(
-      "/login",
-      data,
+      "/login/2fa",
+      { challenge_token: challengeToken, code },
+    );
+    return response.data.data;
+  },
+
+  loginTwoFactorSetup: async (
+    challengeToken: string,
+  ): Promise<TwoFactorSetup> => {
+    const response = await apiClient.post<APIResponse<TwoFactorSetup>>(
+      "/login/2fa/setup",
+      { challenge_token: challengeToken },
+    );
+    return response.data.data;
+  },
+
+  // Token dikirim eksplisit karena localStorage langsung dibersihkan saat logout
+  logout: async (token: string, refreshToken?: string | null) => {
+    await apiClient.post(
+      "/logout",
+      { refresh_token: refreshToken || undefined },
+      { headers: { Authorization: `Bearer ${token}` } },
     );
+  },
+
+  logoutAll: async () => {
+    await apiClient.post("/logout", { all: true });
+  },
+
+  me: async (): Promise<User> => {
+    const response = await apiClient.get<APIResponse<User>>("/me");
+    return response.data.data;
+  },
+
+  // Semua sesi dicabut setelah password diganti, user harus login ulang
+  changePassword: async (data: ChangePasswordRequest) => {
+    await apiClient.post("/me/password", data);
+  },
+
+  twoFactorSetup: async (): Promise<TwoFactorSetup> => {
+    const response =
+      await apiClient.post<APIResponse<TwoFactorSetup>>("/me/2fa/setup");
     return response.data.data;
   },
 
+  twoFactorEnable: async (code: string): Promise<string[]> => {
+    const response = await apiClient.post<
+      APIResponse<{ recovery_codes: string[] }>
+    >("/me/2fa/enable", { code });
+    return response.data.data.recovery_codes;
+  },
+
+  twoFactorDisable: async (password: string, code: string) => {
+    await apiClient.post("/me/2fa/disable", { password, code });
+  },
+
+  twoFactorRecoveryCodes: async (code: string): Promise<string[]> => {
+    const response = await apiClient.post<
+      APIResponse<{ recovery_codes: string[] }>
+    >("/me/2fa/recovery-codes", { code });
+    return response.data.data.recovery_codes;
+  },
+
   register: async (data: RegisterRequest): Promise<User> => {
     const response = await apiClient.post<APIResponse<User>>("/register", data);
     return response.data.data;
@@ -39,6 +133,92 @@ export const userApi = {
     const response = await apiClient.get<APIResponse<User[]>>("/users");
     return response.data.data || [];
   },
+
+  update: async (id: number, data: UpdateUserRequest): Promise<User> => {
+    const response = await apiClient.put<APIResponse<User>>(
+      `/users/${id}`,
+      data,
+    );
+    return response.data.data;
+  },
+
+  activate: async (id: number) => {
+    await apiClient.post(`/users/${id}/activate`);
+  },
+
+  deactivate: async (id: number) => {
+    await apiClient.post(`/users/${id}/deactivate`);
+  },
+
+  resetPassword: async (id: number, password: string) => {
+    await apiClient.post(`/users/${id}/reset-password`, { password });
+  },
+
+  revokeSessions: async (id: number) => {
+    await apiClient.post(`/users/${id}/logout-all`);
+  },
+
+  unlock: async (id: number) => {
+    await apiClient.post(`/users/${id}/unlock`);
+  },
+};
+
+// Role & Permission API
+export const roleApi = {
+  getAll: async (): Promise<Role[]> => {
+    const response = await apiClient.get<APIResponse<Role[]>>("/roles");
+    return response.data.data || [];
+  },
+
+  getPermissions: async (): Promise<Permission[]> => {
+    const response =
+      await apiClient.get<APIResponse<Permission[]>>("/permissions");
+    return response.data.data || [];
+  },
+
+  create: async (data: CreateRoleRequest): Promise<Role> => {
+    const response = await apiClient.post<APIResponse<Role>>("/roles", data);
+    return response.data.data;
+  },
+
+  setPermissions: async (nama: string, permissions: string[]) => {
+    await apiClient.put(`/roles/${nama}/permissions`, { permissions });
+  },
+
+  delete: async (nama: string) => {
+    await apiClient.delete(`/roles/${nama}`);
+  },
+};
+
+// API key integrasi (butuh apikey:manage)
+export const apiKeyApi = {
+  getAll: async (): Promise<ApiKey[]> => {
+    const response = await apiClient.get<APIResponse<ApiKey[]>>("/api-keys");
+    return response.data.data || [];
+  },
+
+  create: async (data: CreateApiKeyRequest): Promise<CreateApiKeyResponse> => {
+    const response = await apiClient.post<APIResponse<CreateApiKeyResponse>>(
+      "/api-keys",
+      data,
+    );
+    return response.data.data;
+  },
+
+  revoke: async (id: number) => {
+    await apiClient.delete(`/api-keys/${id}`);
+  },
+};
+
+// Audit log perubahan data (butuh audit:read)
+export const auditApi = {
+  getAll: async (params?: AuditParams): Promise<PaginatedResponse<AuditLog>> => {
+    const response = await apiClient.get<PaginatedResponse<AuditLog>>(
+      "/audit",
+      { params },
+    );
+    return response.data;
+  },
 };
 
 // Barang API
@@ -106,31 +286,62 @@ export const stokApi = {
     return response.data.data || [];
   },
 
+  getAsOf: async (asOf: string, live = false): Promise<StokAsOfResult> => {
+    const response = await apiClient.get<APIResponse<StokAsOfResult>>("/stok", {
+      params: { as_of: asOf, ...(live ? { live: true } : {}) },
+    });
+    return response.data.data;
+  },
+
   // Note: Backend might need an endpoint for single stock if not available
   // Assuming /stok/{id} or list filter
-  getHistory: async (barangId?: number): Promise<HistoryStok[]> => {
+  getHistory: async (
+    barangId?: number,
+    params?: HistoryStokParams,
+  ): Promise<PaginatedResponse<HistoryStok>> => {
     const url = barangId ? `/history-stok/${barangId}` : "/history-stok";
-    const response = await apiClient.get<APIResponse<HistoryStok[]>>(url);
-    return response.data.data || [];
+    const response = await apiClient.get<PaginatedResponse<HistoryStok>>(url, {
+      params,
+    });
+    return response.data;
+  },
+
+  // butuh audit:read
+  verifyHistory: async (barangId?: number): Promise<HistoryChainReport> => {
+    const response = await apiClient.get<APIResponse<HistoryChainReport>>(
+      "/history-stok/verify",
+      { params: barangId ? { barang_id: barangId } : undefined },
+    );
+    return response.data.data;
   },
 
   getById: async (id: number): Promise<Stok> => {
     const response = await apiClient.get<APIResponse<Stok>>(`/stok/${id}`);
     return response.data.data;
   },
+
+  getKartu: async (
+    barangId: number,
+    params?: { start_date?: string; end_date?: string },
+  ): Promise<KartuStok> => {
+    const response = await apiClient.get<APIResponse<KartuStok>>(
+      `/stok/${barangId}/kartu`,
+      { params },
+    );
+    return response.data.data;
+  },
 };
 
 // Pembelian API
 export const pembelianApi = {
-  getAll: async (params?: {
-    start_date?: string;
-    end_date?: string;
-  }): Promise<BeliHeader[]> => {
-    const response = await apiClient.get<APIResponse<BeliHeader[]>>(
+  getAll: async (
+    params?: TransaksiListParams & { supplier?: string },
+  ): Promise<PaginatedResponse<BeliHeader>> => {
+    const response = await apiClient.get<PaginatedResponse<BeliHeader>>(
       "/pembelian",
       { params },
     );
-    return response.data.data || [];
+    return response.data;
   },
 
   getById: async (id: number): Promise<BeliHeader> => {
@@ -147,19 +358,37 @@ export const pembelianApi = {
     );
     return response.data.data;
   },
+
+  getShares: async (id: number): Promise<TransaksiShare[]> => {
+    const response = await apiClient.get<APIResponse<TransaksiShare[]>>(
+      `/pembelian/${id}/share`,
+    );
+    return response.data.data;
+  },
+
+  share: async (id: number, userId: number): Promise<TransaksiShare> => {
+    const response = await apiClient.post<APIResponse<TransaksiShare>>(
+      `/pembelian/${id}/share`,
+      { user_id: userId },
+    );
+    return response.data.data;
+  },
+
+  unshare: async (id: number, userId: number): Promise<void> => {
+    await apiClient.delete(`/pembelian/${id}/share/${userId}`);
+  },
 };
 
 // Penjualan API
 export const penjualanApi = {
-  getAll: async (params?: {
-    start_date?: string;
-    end_date?: string;
-  }): Promise<JualHeader[]> => {
-    const response = await apiClient.get<APIResponse<JualHeader[]>>(
+  getAll: async (
+    params?: TransaksiListParams & { customer?: string },
+  ): Promise<PaginatedResponse<JualHeader>> => {
+    const response = await apiClient.get<PaginatedResponse<JualHeader>>(
       "/penjualan",
       { params },
     );
-    return response.data.data || [];
+    return response.data;
   },
 
   getById: async (id: number): Promise<JualHeader> => {
@@ -176,13 +405,104 @@ export const penjualanApi = {
     );
     return response.data.data;
   },
+
+  getShares: async (id: number): Promise<TransaksiShare[]> => {
+    const response = await apiClient.get<APIResponse<TransaksiShare[]>>(
+      `/penjualan/${id}/share`,
+    );
+    return response.data.data;
+  },
+
+  share: async (id: number, userId: number): Promise<TransaksiShare> => {
+    const response = await apiClient.post<APIResponse<TransaksiShare>>(
+      `/penjualan/${id}/share`,
+      { user_id: userId },
+    );
+    return response.data.data;
+  },
+
+  unshare: async (id: number, userId: number): Promise<void> => {
+    await apiClient.delete(`/penjualan/${id}/share/${userId}`);
+  },
 };
 
 // Dashboard API
 export const dashboardApi = {
-  getStats: async (): Promise<DashboardStats> => {
-    const response =
-      await apiClient.get<APIResponse<DashboardStats>>("/dashboard");
+  getStats: async (params?: DashboardParams): Promise<DashboardStats> => {
+    const response = await apiClient.get<APIResponse<DashboardStats>>(
+      "/dashboard",
+      { params },
+    );
+    return response.data.data;
+  },
+};
+
+// Reports API
+export const reportApi = {
+  penjualan: async (params?: ReportParams): Promise<Report> => {
+    const response = await apiClient.get<APIResponse<Report>>(
+      "/reports/penjualan",
+      { params },
+    );
+    return response.data.data;
+  },
+
+  pembelian: async (params?: ReportParams): Promise<Report> => {
+    const response = await apiClient.get<APIResponse<Report>>(
+      "/reports/pembelian",
+      { params },
+    );
+    return response.data.data;
+  },
+
+  abcXyz: async (params?: {
+    kelas_abc?: string;
+    kelas_xyz?: string;
+  }): Promise<KlasifikasiReport> => {
+    const response = await apiClient.get<APIResponse<KlasifikasiReport>>(
+      "/reports/abc-xyz",
+      { params },
+    );
+    return response.data.data;
+  },
+
+  runAbcXyz: async (
+    params?: KlasifikasiRunParams,
+  ): Promise<KlasifikasiReport> => {
+    const response = await apiClient.post<APIResponse<KlasifikasiReport>>(
+      "/reports/abc-xyz",
+      null,
+      { params },
+    );
+    return response.data.data;
+  },
+};
+
+// Stock Opname API
+export const stokOpnameApi = {
+  getAll: async (params?: {
+    page?: number;
+    limit?: number;
+  }): Promise<PaginatedResponse<StokOpname>> => {
+    const response = await apiClient.get<PaginatedResponse<StokOpname>>(
+      "/stok-opname",
+      { params },
+    );
+    return response.data;
+  },
+
+  getById: async (id: number): Promise<StokOpname> => {
+    const response = await apiClient.get<APIResponse<StokOpname>>(
+      `/stok-opname/${id}`,
+    );
+    return response.data.data;
+  },
+
+  create: async (data: CreateOpnameRequest): Promise<StokOpname> => {
+    const response = await apiClient.post<APIResponse<StokOpname>>(
+      "/stok-opname",
+      data,
+    );
     return response.data.data;
   },
 };
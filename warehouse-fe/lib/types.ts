@@ -4,7 +4,10 @@ export interface User {
   username: string;
   email: string;
   full_name: string;
-  role: "admin" | "staff";
+  role: string;
+  is_active: boolean;
+  two_factor_enabled: boolean;
+  permissions?: string[]; // hanya diisi pada respons login dan GET /me
   created_at?: string;
   updated_at?: string;
 }
@@ -14,9 +17,33 @@ export interface LoginRequest {
   password: string;
 }
 
-export interface LoginResponse {
+export interface TokenResponse {
   token: string;
+  refresh_token: string;
+  expires_in: number; // detik
+  visibility: Visibility;
+}
+
+// all: semua transaksi; own: hanya milik sendiri dan yang dibagikan
+export type Visibility = "all" | "own";
+
+export interface LoginResponse extends TokenResponse {
   user: User;
+  recovery_codes?: string[]; // hanya saat 2FA baru didaftarkan di langkah login
+}
+
+// Dikembalikan POST /login sebagai pengganti token bila akun memakai 2FA
+export interface TwoFactorChallenge {
+  two_factor_required: true;
+  setup_required: boolean;
+  challenge_token: string;
+  expires_in: number; // detik
+}
+
+export interface TwoFactorSetup {
+  secret: string;
+  otpauth_url: string;
+  qr_code: string; // data URL PNG
 }
 
 export interface RegisterRequest {
@@ -24,7 +51,88 @@ export interface RegisterRequest {
   password: string;
   email: string;
   full_name: string;
-  role: "admin" | "staff";
+  role: string;
+}
+
+export interface UpdateUserRequest {
+  email: string;
+  full_name: string;
+  role: string;
+}
+
+export interface ChangePasswordRequest {
+  current_password: string;
+  new_password: string;
+}
+
+// Role & permission (RBAC)
+export interface Permission {
+  kode: string;
+  deskripsi: string;
+}
+
+export interface Role {
+  nama: string;
+  deskripsi: string;
+  is_system: boolean;
+  jumlah_user: number;
+  permissions: string[];
+}
+
+export interface CreateRoleRequest {
+  nama: string;
+  deskripsi?: string;
+  permissions: string[];
+}
+
+// API key untuk integrasi (dikirim lewat header X-API-Key)
+export interface ApiKey {
+  id: number;
+  nama: string;
+  prefix: string;
+  user_id: number;
+  username: string;
+  permissions: string[];
+  expires_at: string | null;
+  last_used_at: string | null;
+  revoked_at: string | null;
+  created_by: number;
+  created_at: string;
+}
+
+export interface CreateApiKeyRequest {
+  nama: string;
+  permissions: string[];
+  expires_in_days?: number; // 0 = tanpa batas
+}
+
+// key lengkap hanya dikembalikan sekali saat dibuat
+export interface CreateApiKeyResponse extends ApiKey {
+  key: string;
+}
+
+// Audit log: sebelum/sesudah hanya berisi field yang berubah
+export type AuditAksi = "create" | "update" | "delete";
+
+export interface AuditLog {
+  id: number;
+  user_id: number | null;
+  username: string;
+  aksi: AuditAksi;
+  entitas: string;
+  entitas_id: string;
+  sebelum: Record<string, unknown> | null;
+  sesudah: Record<string, unknown> | null;
+  ip: string;
+  request_id: string;
+  created_at: string;
+}
+
+export interface AuditParams extends ListParams {
+  entitas?: string;
+  entitas_id?: string;
+  user_id?: number;
+  aksi?: AuditAksi;
 }
 
 // Barang (Inventory)
@@ -37,6 +145,14 @@ export interface Barang {
   harga_beli: number;
   harga_jual: number;
   stok?: number;
+  klasifikasi?: KlasifikasiBarang;
+}
+
+export interface KlasifikasiBarang {
+  kelas_abc: KelasABC;
+  kelas_xyz: KelasXYZ;
+  run_id: number;
+  run_at: string;
 }
 
 export interface CreateBarangRequest {
@@ -65,11 +181,70 @@ export interface HistoryStok {
   stok_sebelum: number;
   stok_sesudah: number;
   keterangan: string;
+  jual_header_id?: number;
+  beli_header_id?: number;
   created_at: string;
   barang?: Barang;
   user?: User;
 }
 
+// Hasil verifikasi hash chain history_stok (berhenti di link rusak pertama)
+export interface HistoryChainReport {
+  valid: boolean;
+  barang_diperiksa: number;
+  baris_diperiksa: number;
+  baris_belum_diseal: number;
+  rusak?: {
+    history_id: number;
+    barang_id: number;
+    alasan: string;
+  };
+}
+
+export interface StokAsOf {
+  barang_id: number;
+  kode_barang: string;
+  nama_barang: string;
+  satuan: string;
+  stok_akhir: number;
+  last_movement_at?: string;
+}
+
+export interface StokAsOfResult {
+  as_of: string;
+  source: "snapshot" | "history";
+  snapshot_at?: string;
+  items: StokAsOf[];
+}
+
+export interface KartuStokEntry {
+  history_id: number;
+  tanggal: string;
+  jenis_transaksi: string;
+  referensi: string;
+  keterangan: string;
+  petugas: string;
+  masuk: number;
+  keluar: number;
+  saldo: number;
+  harga_satuan: number | null;
+  nilai: number | null;
+  nilai_saldo: number | null;
+}
+
+export interface KartuStok {
+  barang: Barang;
+  start_date: string;
+  end_date: string;
+  saldo_awal: number;
+  nilai_saldo_awal: number | null;
+  total_masuk: number;
+  total_keluar: number;
+  saldo_akhir: number;
+  nilai_saldo_akhir: number | null;
+  entries: KartuStokEntry[];
+}
+
 // Pembelian (Purchasing)
 export interface BeliHeader {
   id: number;
@@ -138,10 +313,32 @@ export interface CreatePenjualanRequest {
   }[];
 }
 
+// Pembagian transaksi ke pengguna dengan visibilitas "own"
+export type JenisTransaksi = "penjualan" | "pembelian";
+
+export interface TransaksiShare {
+  jenis: JenisTransaksi;
+  transaksi_id: number;
+  user_id: number;
+  username: string;
+  shared_by: number;
+  created_at: string;
+}
+
 // Dashboard
 export interface TopProduct {
   nama_barang: string;
   total_terjual: number;
+  total_nilai: number;
+}
+
+export interface KategoriStat {
+  kategori_id: number;
+  nama_kategori: string;
+  parent_id: number | null;
+  total_barang: number;
+  total_stok: number;
+  total_nilai: number;
 }
 
 export interface DashboardStats {
@@ -150,6 +347,25 @@ export interface DashboardStats {
   total_stok: number;
   total_nilai_aset: number;
   top_selling_products: TopProduct[];
+  kategori_stats: KategoriStat[];
+  start_date?: string;
+  end_date?: string;
+  pendapatan: number;
+  pembelian: number;
+  hpp: number;
+  laba_kotor: number;
+  margin_kotor: number | null;
+  jumlah_faktur: number;
+  rata_rata_belanja: number;
+  dead_stock: number;
+  low_stock: number;
+}
+
+export interface DashboardParams {
+  start_date?: string;
+  end_date?: string;
+  top?: number;
+  low_stock_threshold?: number;
 }
 
 // API Utilities
@@ -166,9 +382,168 @@ export interface PaginationMeta {
   total_pages: number;
 }
 
+export interface ListParams {
+  page?: number;
+  limit?: number;
+  sort_by?: string;
+  order?: "asc" | "desc";
+  start_date?: string;
+  end_date?: string;
+}
+
+export interface TransaksiListParams extends ListParams {
+  user_id?: number;
+  status?: string;
+  no_faktur?: string;
+  min_total?: number;
+  max_total?: number;
+}
+
+export interface HistoryStokParams extends ListParams {
+  search?: string;
+  user_id?: number;
+  jenis_transaksi?: "masuk" | "keluar" | "saldo_awal";
+}
+
 export interface PaginatedResponse<T> {
   success: boolean;
   message: string;
   data: T[];
   meta: PaginationMeta;
 }
+
+// Laporan (Reports)
+export type ReportGroupBy =
+  | "day"
+  | "week"
+  | "month"
+  | "barang"
+  | "customer"
+  | "supplier"
+  | "user";
+
+export interface ReportParams {
+  group_by?: ReportGroupBy;
+  start_date?: string;
+  end_date?: string;
+  compare?: boolean;
+  limit?: number;
+}
+
+export interface ReportValues {
+  total: number;
+  qty: number;
+  jumlah_transaksi: number;
+}
+
+export interface ReportPoint extends ReportValues {
+  key: string;
+  label: string;
+  previous?: ReportValues;
+}
+
+export interface Report {
+  jenis: "penjualan" | "pembelian";
+  group_by: ReportGroupBy;
+  start_date: string;
+  end_date: string;
+  summary: ReportValues;
+  series: ReportPoint[];
+  comparison?: {
+    start_date: string;
+    end_date: string;
+    summary: ReportValues;
+    change_percent: {
+      total: number | null;
+      qty: number | null;
+      jumlah_transaksi: number | null;
+    };
+  };
+}
+
+// Klasifikasi ABC/XYZ
+export type KelasABC = "A" | "B" | "C";
+export type KelasXYZ = "X" | "Y" | "Z";
+
+export interface KlasifikasiRunParams {
+  start_date?: string;
+  end_date?: string;
+  periode?: "month" | "week";
+  batas_a?: number;
+  batas_b?: number;
+  batas_x?: number;
+  batas_y?: number;
+}
+
+export interface KlasifikasiRun {
+  id: number;
+  start_date: string;
+  end_date: string;
+  periode: "month" | "week";
+  batas_a: number;
+  batas_b: number;
+  batas_x: number;
+  batas_y: number;
+  jumlah_barang: number;
+  user_id: number | null;
+  created_at: string;
+}
+
+export interface BarangKlasifikasi {
+  barang_id: number;
+  kode_barang: string;
+  nama_barang: string;
+  kelas_abc: KelasABC;
+  kelas_xyz: KelasXYZ;
+  nilai: number;
+  kontribusi: number;
+  kumulatif: number;
+  qty: number;
+  frekuensi: number;
+  cv: number | null;
+}
+
+export interface KlasifikasiReport {
+  run: KlasifikasiRun;
+  matriks: {
+    kelas_abc: KelasABC;
+    kelas_xyz: KelasXYZ;
+    jumlah_barang: number;
+    nilai: number;
+  }[];
+  items: BarangKlasifikasi[];
+  // true bila angka penjualan disembunyikan (tanpa transaksi:read-all)
+  terbatas: boolean;
+}
+
+// Stock Opname
+export interface CreateOpnameRequest {
+  kelas_abc?: string;
+  kelas_xyz?: string;
+  keterangan?: string;
+}
+
+export interface StokOpnameDetail {
+  barang_id: number;
+  kode_barang: string;
+  nama_barang: string;
+  satuan: string;
+  kelas_abc: KelasABC;
+  kelas_xyz: KelasXYZ;
+  stok_sistem: number;
+  stok_fisik: number | null;
+}
+
+export interface StokOpname {
+  id: number;
+  no_opname: string;
+  klasifikasi_run_id: number | null;
+  kelas_abc: string;
+  kelas_xyz: string;
+  keterangan: string;
+  status: string;
+  user_id: number | null;
+  jumlah_barang: number;
+  created_at: string;
+  items?: StokOpnameDetail[];
+}